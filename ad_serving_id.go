@@ -0,0 +1,66 @@
+package vast
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// uuidLen is the length of the canonical textual form of a UUID.
+const uuidLen = 36
+
+// NewAdServingID returns a new AdServingId in the ServerName-UUID format
+// recommended by VAST 4.1, with a random UUID. DefaultAdSystem is used when
+// serverName is empty.
+func NewAdServingID(serverName string) string {
+	if serverName == "" {
+		serverName = DefaultAdSystem
+	}
+	return serverName + "-" + newUUID()
+}
+
+// ParseAdServingID splits an AdServingId in the ServerName-UUID format into
+// its server name and UUID parts.
+func ParseAdServingID(id string) (serverName, uuid string, err error) {
+	if len(id) < uuidLen+2 || id[len(id)-uuidLen-1] != '-' {
+		return "", "", fmt.Errorf("invalid AdServingId %q: expected ServerName-UUID", id)
+	}
+	serverName, uuid = id[:len(id)-uuidLen-1], id[len(id)-uuidLen:]
+	if strings.TrimSpace(serverName) != serverName {
+		return "", "", fmt.Errorf("invalid AdServingId %q: server name has surrounding spaces", id)
+	}
+	if !isUUID(uuid) {
+		return "", "", fmt.Errorf("invalid AdServingId %q: malformed UUID", id)
+	}
+	return serverName, uuid, nil
+}
+
+// ValidateAdServingID returns an error if id doesn't follow the ServerName-UUID
+// format.
+func ValidateAdServingID(id string) error {
+	if id == "" {
+		return errors.New("invalid AdServingId: missing")
+	}
+	_, _, err := ParseAdServingID(id)
+	return err
+}
+
+// isUUID returns true if s is a UUID in its canonical 8-4-4-4-12 form.
+func isUUID(s string) bool {
+	if len(s) != uuidLen {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
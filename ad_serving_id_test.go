@@ -0,0 +1,40 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAdServingID(t *testing.T) {
+	id := NewAdServingID("MyServer")
+	assert.Regexp(t, `^MyServer-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+	assert.NotEqual(t, id, NewAdServingID("MyServer"))
+	assert.NoError(t, ValidateAdServingID(id))
+
+	server, uuid, err := ParseAdServingID(NewAdServingID(""))
+	if assert.NoError(t, err) {
+		assert.Equal(t, DefaultAdSystem, server)
+		assert.Len(t, uuid, 36)
+	}
+}
+
+func TestParseAdServingID(t *testing.T) {
+	server, uuid, err := ParseAdServingID("Server-Name-47ed3bac-1768-4b9a-9d0e-0b92422ab066")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Server-Name", server)
+		assert.Equal(t, "47ed3bac-1768-4b9a-9d0e-0b92422ab066", uuid)
+	}
+
+	for id, msg := range map[string]string{
+		"":                                     "invalid AdServingId: missing",
+		"a532d16d-4d7f-4440-bd29-2ec0e693fc80": `invalid AdServingId "a532d16d-4d7f-4440-bd29-2ec0e693fc80": expected ServerName-UUID`,
+		"DSP-1":                                `invalid AdServingId "DSP-1": expected ServerName-UUID`,
+		"DSP_47ed3bac-1768-4b9a-9d0e-0b92422ab066":  `invalid AdServingId "DSP_47ed3bac-1768-4b9a-9d0e-0b92422ab066": expected ServerName-UUID`,
+		"DSP-47ed3bac-1768-4b9a-9d0e-0b92422ab06z":  `invalid AdServingId "DSP-47ed3bac-1768-4b9a-9d0e-0b92422ab06z": malformed UUID`,
+		"DSP-47ed3bac+1768-4b9a-9d0e-0b92422ab066":  `invalid AdServingId "DSP-47ed3bac+1768-4b9a-9d0e-0b92422ab066": malformed UUID`,
+		" DSP-47ed3bac-1768-4b9a-9d0e-0b92422ab066": `invalid AdServingId " DSP-47ed3bac-1768-4b9a-9d0e-0b92422ab066": server name has surrounding spaces`,
+	} {
+		assert.EqualError(t, ValidateAdServingID(id), msg, id)
+	}
+}
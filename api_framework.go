@@ -0,0 +1,75 @@
+package vast
+
+import "strings"
+
+// API frameworks found in apiFramework attributes
+const (
+	APIFrameworkVPAID = "VPAID"
+	APIFrameworkSIMID = "SIMID"
+	APIFrameworkOMID  = "omid"
+	APIFrameworkMRAID = "MRAID"
+)
+
+// IsAPIFramework returns true if framework names the given API framework,
+// ignoring case.
+func IsAPIFramework(framework, api string) bool {
+	return strings.EqualFold(strings.TrimSpace(framework), api)
+}
+
+// IsInteractive returns true if the media file must be run through an
+// interactive API (VPAID or SIMID) rather than simply played.
+func (m *MediaFile) IsInteractive() bool {
+	return IsAPIFramework(m.APIFramework, APIFrameworkVPAID) || IsAPIFramework(m.APIFramework, APIFrameworkSIMID)
+}
+
+// IsVPAID returns true if the media file is a VPAID unit.
+func (m *MediaFile) IsVPAID() bool {
+	return IsAPIFramework(m.APIFramework, APIFrameworkVPAID)
+}
+
+// RequiresSIMID returns true if the creative, or any of its media files,
+// interactive creative files, non-linears or companions, declares the SIMID
+// API framework.
+func (c *Creative) RequiresSIMID() bool {
+	return c.requires(APIFrameworkSIMID)
+}
+
+// RequiresVPAID returns true if the creative, or any of its media files,
+// interactive creative files, non-linears or companions, declares the VPAID
+// API framework.
+func (c *Creative) RequiresVPAID() bool {
+	return c.requires(APIFrameworkVPAID)
+}
+
+func (c *Creative) requires(api string) bool {
+	if IsAPIFramework(c.APIFramework, api) {
+		return true
+	}
+	if c.Linear != nil {
+		for _, m := range c.Linear.MediaFiles {
+			if IsAPIFramework(m.APIFramework, api) {
+				return true
+			}
+		}
+		for _, f := range c.Linear.InteractiveCreativeFiles {
+			if IsAPIFramework(f.APIFramework, api) {
+				return true
+			}
+		}
+	}
+	if c.NonLinearAds != nil {
+		for _, nl := range c.NonLinearAds.NonLinears {
+			if IsAPIFramework(nl.APIFramework, api) {
+				return true
+			}
+		}
+	}
+	if c.CompanionAds != nil {
+		for _, comp := range c.CompanionAds.Companions {
+			if IsAPIFramework(comp.APIFramework, api) {
+				return true
+			}
+		}
+	}
+	return false
+}
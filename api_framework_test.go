@@ -0,0 +1,34 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMediaFileIsInteractive(t *testing.T) {
+	assert.False(t, (&MediaFile{Type: "video/mp4"}).IsInteractive())
+	assert.True(t, (&MediaFile{Type: "application/javascript", APIFramework: "VPAID"}).IsInteractive())
+	assert.True(t, (&MediaFile{Type: "video/mp4", APIFramework: "simid"}).IsInteractive())
+	assert.False(t, (&MediaFile{Type: "video/mp4", APIFramework: APIFrameworkOMID}).IsInteractive())
+	assert.True(t, (&MediaFile{APIFramework: " vpaid "}).IsVPAID())
+}
+
+func TestCreativeRequiresSIMID(t *testing.T) {
+	c := Creative{}
+	assert.False(t, c.RequiresSIMID())
+
+	c.APIFramework = APIFrameworkSIMID
+	assert.True(t, c.RequiresSIMID())
+
+	c = Creative{Linear: &Linear{MediaFiles: []MediaFile{{Type: "video/mp4"}, {Type: "text/html", APIFramework: "SIMID"}}}}
+	assert.True(t, c.RequiresSIMID())
+	assert.False(t, c.RequiresVPAID())
+
+	c = Creative{NonLinearAds: &NonLinearAds{NonLinears: []NonLinear{{APIFramework: APIFrameworkVPAID}}}}
+	assert.False(t, c.RequiresSIMID())
+	assert.True(t, c.RequiresVPAID())
+
+	c = Creative{CompanionAds: &CompanionAds{Companions: []Companion{{APIFramework: APIFrameworkSIMID}}}}
+	assert.True(t, c.RequiresSIMID())
+}
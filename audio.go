@@ -0,0 +1,160 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"time"
+)
+
+// IsAudioMIMEType returns true if t is an audio MIME type, such as
+// "audio/mpeg".
+func IsAudioMIMEType(t string) bool {
+	return strings.HasPrefix(baseMIMEType(t), "audio/")
+}
+
+// IsAudio returns true if the media file is an audio rendition.
+func (m *MediaFile) IsAudio() bool {
+	return IsAudioMIMEType(m.Type)
+}
+
+// IsAudio returns true if the ad is an audio ad: it is either flagged with
+// adType="audio", or it is an InLine ad whose linear creatives only carry
+// audio media files.
+func (a *Ad) IsAudio() bool {
+	if a.AdType != "" {
+		return a.AdType == AdTypeAudio
+	}
+	if a.InLine == nil {
+		return false
+	}
+	found := false
+	for _, c := range a.InLine.Creatives {
+		if c.Linear == nil {
+			continue
+		}
+		for i := range c.Linear.MediaFiles {
+			if !c.Linear.MediaFiles[i].IsAudio() {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// NewAudioAd returns a minimal valid VAST 4.2 document made of a single
+// InLine audio ad playing the given media files. The returned error is a
+// ValidationErrors if the ad would be incomplete or if one of the media files
+// isn't an audio file.
+func NewAudioAd(title string, duration time.Duration, media []MediaSpec, impressions []string) (*VAST, error) {
+	v, err := NewLinearAd(title, duration, media, impressions)
+	if err != nil {
+		return nil, err
+	}
+	v.Ads[0].AdType = AdTypeAudio
+	if err := v.ValidateAudio(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ValidateAudio checks the document like Validate, considering every ad is to
+// be played in an audio-only context: video media files and visual elements
+// such as icons, non-linear creatives and image companions are reported.
+// Validate only applies these checks to ads with adType="audio".
+func (v *VAST) ValidateAudio() error {
+	vd := &validator{version: v.EffectiveVersion(), audioOnly: true}
+	vd.vast("VAST", v)
+	return vd.result()
+}
+
+// daastDocument captures the DAAST elements which have been renamed in VAST.
+type daastDocument struct {
+	Ads []struct {
+		InLine *struct {
+			Creatives []struct {
+				Linear *struct {
+					AdInteractions *VideoClicks
+				}
+			} `xml:"Creatives>Creative"`
+		}
+		Wrapper *struct {
+			DAASTAdTagURI CDATAURI
+			Creatives     []struct {
+				Linear *struct {
+					AdInteractions *VideoClicks
+				}
+			} `xml:"Creatives>Creative"`
+		}
+	} `xml:"Ad"`
+}
+
+// FromDAAST converts a DAAST 1.0 document into a VAST 4.1 document. Every ad
+// is flagged with adType="audio", DAASTAdTagURI becomes VASTAdTagURI and
+// AdInteractions become VideoClicks. InLine ads missing the elements required
+// by VAST 4.1 get a generated AdServingId and an "unknown" UniversalAdId.
+func FromDAAST(data []byte) (*VAST, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			if se.Name.Local != "DAAST" {
+				return nil, errors.New("not a DAAST document: root element is " + se.Name.Local)
+			}
+			break
+		}
+	}
+
+	var v VAST
+	if err := xml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	var d daastDocument
+	if err := xml.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	v.Version = string(Version4_1)
+	v.XMLNS = ""
+	for i := range v.Ads {
+		ad := &v.Ads[i]
+		ad.AdType = AdTypeAudio
+		if in := ad.InLine; in != nil {
+			if in.AdServingId == "" {
+				in.AdServingId = NewAdServingID(DefaultAdSystem)
+			}
+			// DAAST categories are IAB codes without authority
+			for j := range in.Categories {
+				if in.Categories[j].Authority == "" {
+					in.Categories[j].Authority = IABCategoryAuthority
+				}
+			}
+			for j := range in.Creatives {
+				c := &in.Creatives[j]
+				if c.UniversalAdID == nil {
+					c.UniversalAdID = &UniversalAdID{IDRegistry: "unknown", ID: "unknown"}
+				}
+				if dl := d.Ads[i].InLine.Creatives[j].Linear; c.Linear != nil && dl != nil && dl.AdInteractions != nil {
+					c.Linear.VideoClicks = dl.AdInteractions
+				}
+			}
+		}
+		if w := ad.Wrapper; w != nil {
+			dw := d.Ads[i].Wrapper
+			if w.VASTAdTagURI.URI == "" {
+				w.VASTAdTagURI = dw.DAASTAdTagURI
+			}
+			for j := range w.Creatives {
+				if dl := dw.Creatives[j].Linear; w.Creatives[j].Linear != nil && dl != nil && dl.AdInteractions != nil {
+					w.Creatives[j].Linear.VideoClicks = dl.AdInteractions
+				}
+			}
+		}
+	}
+	v.InferredVersion = inferVersion(&v)
+	return &v, nil
+}
@@ -0,0 +1,107 @@
+package vast
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAudio(t *testing.T) {
+	assert.True(t, IsAudioMIMEType(MIMEAudioMPEG))
+	assert.True(t, IsAudioMIMEType("Audio/MP4; codecs=mp4a.40.2"))
+	assert.False(t, IsAudioMIMEType(MIMEVideoMP4))
+
+	ad := Ad{InLine: &InLine{Creatives: []Creative{
+		{Linear: &Linear{MediaFiles: []MediaFile{{Type: MIMEAudioMPEG}, {Type: MIMEAudioAAC}}}},
+		{CompanionAds: &CompanionAds{}},
+	}}}
+	assert.True(t, ad.IsAudio())
+	ad.InLine.Creatives[0].Linear.MediaFiles[1].Type = MIMEVideoMP4
+	assert.False(t, ad.IsAudio())
+	ad.AdType = AdTypeAudio
+	assert.True(t, ad.IsAudio())
+	assert.False(t, (&Ad{InLine: &InLine{}}).IsAudio())
+	assert.False(t, (&Ad{Wrapper: &Wrapper{}}).IsAudio())
+}
+
+func TestNewAudioAd(t *testing.T) {
+	v, err := NewAudioAd("audio", 30*time.Second, []MediaSpec{{URI: "http://example.com/ad.mp3", Type: MIMEAudioMPEG, Bitrate: 128}}, []string{"http://example.com/imp"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, AdTypeAudio, v.Ads[0].AdType)
+		assert.NoError(t, v.Validate())
+	}
+
+	_, err = NewAudioAd("video", 30*time.Second, []MediaSpec{{URI: "http://example.com/ad.mp4", Type: MIMEVideoMP4, Width: 640, Height: 360}}, []string{"http://example.com/imp"})
+	assert.EqualError(t, err, `invalid VAST.Ad[0].InLine.Creative[0].Linear.MediaFile[0]: not an audio MIME type "video/mp4"`)
+}
+
+func TestValidateAudio(t *testing.T) {
+	v, err := Skeleton(Version4_2, AdKindAudio)
+	if !assert.NoError(t, err) {
+		return
+	}
+	c := &v.Ads[0].InLine.Creatives[0]
+	c.Linear.Icons = &Icons{Icon: []Icon{{Width: 20, Height: 20, XPosition: IconLeft, YPosition: IconTop, StaticResource: &StaticResource{CreativeType: "image/png", URI: "http://example.com/icon.png"}}}}
+	c.CompanionAds = &CompanionAds{Companions: []Companion{
+		{Width: 300, Height: 250, StaticResource: &StaticResource{CreativeType: "image/png", URI: "http://example.com/banner.png"}},
+		{Width: 300, Height: 250, HTMLResource: &HTMLResource{HTML: "<p>hi</p>"}},
+	}}
+	assert.EqualError(t, v.Validate(), "invalid VAST.Ad[0].InLine.Creative[0].Linear.Icons: not allowed in audio ad; "+
+		"invalid VAST.Ad[0].InLine.Creative[0].CompanionAds.Companion[0]: image companion not allowed in audio ad")
+
+	// without adType, only ValidateAudio applies the audio checks
+	v.Ads[0].AdType = ""
+	assert.NoError(t, v.Validate())
+	assert.Error(t, v.ValidateAudio())
+
+	c.Linear.Icons = nil
+	c.CompanionAds = nil
+	c.NonLinearAds = &NonLinearAds{NonLinears: []NonLinear{{Width: 300, Height: 50, StaticResource: &StaticResource{CreativeType: "image/png", URI: "http://example.com/overlay.png"}}}}
+	assert.EqualError(t, v.ValidateAudio(), "invalid VAST.Ad[0].InLine.Creative[0].NonLinearAds: not allowed in audio ad")
+}
+
+func TestFromDAAST(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/daast_inline_wrapper.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	v, err := FromDAAST(b)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "4.1", v.Version)
+	assert.Equal(t, Version4_1, v.InferredVersion)
+	if assert.Len(t, v.Ads, 2) {
+		in := v.Ads[0].InLine
+		assert.Equal(t, AdTypeAudio, v.Ads[0].AdType)
+		assert.NoError(t, ValidateAdServingID(in.AdServingId))
+		assert.Equal(t, &UniversalAdID{IDRegistry: "unknown", ID: "unknown"}, in.Creatives[0].UniversalAdID)
+		assert.Equal(t, []Category{{Authority: IABCategoryAuthority, Code: "IAB1"}}, in.Categories)
+		linear := in.Creatives[0].Linear
+		assert.Equal(t, Duration(30*time.Second), linear.Duration)
+		assert.Equal(t, URI("http://example.com/ad.mp3"), linear.MediaFiles[0].URI)
+		if assert.NotNil(t, linear.VideoClicks) {
+			assert.Equal(t, URI("http://example.com/landing"), linear.VideoClicks.ClickThroughs[0].URI)
+			assert.Equal(t, URI("http://example.com/click"), linear.VideoClicks.ClickTrackings[0].URI)
+		}
+
+		w := v.Ads[1].Wrapper
+		assert.Equal(t, AdTypeAudio, v.Ads[1].AdType)
+		assert.Equal(t, "http://example.com/daast.xml", string(w.VASTAdTagURI.URI))
+		assert.Equal(t, URI("http://example.com/wrapper-click"), w.Creatives[0].Linear.VideoClicks.ClickTrackings[0].URI)
+	}
+	// the image companion of the DAAST ad isn't playable in an audio context
+	assert.EqualError(t, v.Validate(), "invalid VAST.Ad[0].InLine.Creative[1].CompanionAds.Companion[0]: image companion not allowed in audio ad")
+
+	out, err := xml.Marshal(v)
+	if assert.NoError(t, err) {
+		assert.NotContains(t, string(out), "AdInteractions")
+		assert.Contains(t, string(out), `<VAST version="4.1">`)
+	}
+
+	_, err = FromDAAST([]byte(`<VAST version="3.0"></VAST>`))
+	assert.EqualError(t, err, "not a DAAST document: root element is VAST")
+}
@@ -0,0 +1,203 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/resolve"
+	"github.com/stretchr/testify/assert"
+)
+
+var sizes = []string{"small", "medium", "large"}
+
+func fixture(tb testing.TB, name string) []byte {
+	b, err := ioutil.ReadFile("testdata/" + name + ".xml")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return b
+}
+
+func decode(tb testing.TB, name string) *vast.VAST {
+	var v vast.VAST
+	if err := xml.Unmarshal(fixture(tb, name), &v); err != nil {
+		tb.Fatal(err)
+	}
+	return &v
+}
+
+// fixtureTransport serves the fixtures from https://ads.example.com/.
+type fixtureTransport struct {
+	docs map[string][]byte
+}
+
+func newFixtureTransport(tb testing.TB) *fixtureTransport {
+	t := &fixtureTransport{docs: map[string][]byte{}}
+	for _, name := range append(sizes, "wrapper") {
+		t.docs["/"+name+".xml"] = fixture(tb, name)
+	}
+	return t
+}
+
+func (t *fixtureTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	b, ok := t.docs[path.Clean(r.URL.Path)]
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusNotFound
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/xml"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(b)),
+		Request:    r,
+	}, nil
+}
+
+func BenchmarkDecode(b *testing.B) {
+	for _, size := range sizes {
+		data := fixture(b, size)
+		b.Run(size, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var v vast.VAST
+				if err := xml.Unmarshal(data, &v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeInterned(b *testing.B) {
+	in := vast.NewInterner()
+	for _, size := range sizes {
+		data := fixture(b, size)
+		b.Run(size, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var v vast.VAST
+				if err := in.Unmarshal(data, &v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	for _, size := range sizes {
+		v := decode(b, size)
+		b.Run(size, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := xml.Marshal(v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFastEncode(b *testing.B) {
+	for _, size := range sizes {
+		v := decode(b, size)
+		b.Run(size, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := vast.FastMarshal(v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	for _, size := range sizes {
+		v := decode(b, size)
+		b.Run(size, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				v.Validate()
+			}
+		})
+	}
+}
+
+func BenchmarkResolve(b *testing.B) {
+	r := &resolve.Resolver{Transport: newFixtureTransport(b)}
+	wrapper := fixture(b, "wrapper")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var v vast.VAST
+		if err := xml.Unmarshal(wrapper, &v); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := r.Resolve(context.Background(), &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// budgets are the maximum number of allocations of the operations, about
+// 25% above their measures so that only regressions fail.
+var budgets = map[string]map[string]float64{
+	"decode":      {"small": 850, "medium": 4100, "large": 34000},
+	"encode":      {"small": 150, "medium": 700, "large": 5600},
+	"fast-encode": {"small": 4, "medium": 60, "large": 425},
+	"validate":    {"small": 45, "medium": 210, "large": 1700},
+}
+
+// resolveBudget is the maximum number of allocations of the resolution of
+// wrapper.xml.
+const resolveBudget = 4400
+
+func TestBudgets(t *testing.T) {
+	for _, size := range sizes {
+		data := fixture(t, size)
+		v := decode(t, size)
+		ops := map[string]func(){
+			"decode": func() {
+				var v vast.VAST
+				xml.Unmarshal(data, &v)
+			},
+			"encode":      func() { xml.Marshal(v) },
+			"fast-encode": func() { vast.FastMarshal(v) },
+			"validate":    func() { v.Validate() },
+		}
+		for op, f := range ops {
+			assert.LessOrEqual(t, testing.AllocsPerRun(5, f), budgets[op][size], op+" "+size)
+		}
+	}
+
+	r := &resolve.Resolver{Transport: newFixtureTransport(t)}
+	wrapper := fixture(t, "wrapper")
+	n := testing.AllocsPerRun(5, func() {
+		var v vast.VAST
+		xml.Unmarshal(wrapper, &v)
+		r.Resolve(context.Background(), &v)
+	})
+	assert.LessOrEqual(t, n, float64(resolveBudget), "resolve")
+}
+
+// TestFixtures checks that the fixtures are valid documents, and that
+// wrapper.xml resolves to the ads of medium.xml.
+func TestFixtures(t *testing.T) {
+	for _, size := range sizes {
+		assert.NoError(t, decode(t, size).Validate(), size)
+	}
+	r := &resolve.Resolver{Transport: newFixtureTransport(t)}
+	res, err := r.Resolve(context.Background(), decode(t, "wrapper"))
+	if assert.NoError(t, err) {
+		assert.Len(t, res.Ads, 3)
+		assert.Empty(t, res.Errors)
+	}
+}
@@ -0,0 +1,24 @@
+// Package bench benchmarks the decoding, encoding, validation and resolution
+// of representative documents, found in testdata:
+//
+//	small.xml   one InLine ad with three media files (4 KB)
+//	medium.xml  a pod of three ads with verifications, companions and
+//	            extensions (18 KB)
+//	large.xml   a pod of 25 of the ads of medium.xml (150 KB)
+//	wrapper.xml a Wrapper ad pointing to medium.xml
+//
+// Run the benchmarks with:
+//
+//	go test -run '^$' -bench . -benchmem ./bench
+//
+// The performance budgets are the number of allocations per operation,
+// checked by TestBudgets so that regressions fail the tests, as they are
+// stable across machines unlike timings. For reference, the timings below
+// were measured alongside them on a 2.1 GHz Xeon:
+//
+//	Decode       small 144 µs   medium 666 µs   large 5.5 ms
+//	Encode       small  33 µs   medium 202 µs   large 1.6 ms
+//	FastEncode   small   6 µs   medium  40 µs   large 367 µs
+//	Validate     small   2 µs   medium  11 µs   large 104 µs
+//	Resolve      wrapper 717 µs
+package bench
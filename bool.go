@@ -0,0 +1,51 @@
+package vast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Bool is a boolean attribute value. It is lenient when decoding, accepting
+// "true"/"false", "1"/"0" and "yes"/"no" regardless of case as some ad
+// servers do, and always encodes to "true" or "false".
+type Bool bool
+
+// NewBool returns a pointer to a Bool holding b, to fill optional attributes.
+func NewBool(b bool) *Bool {
+	v := Bool(b)
+	return &v
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (b Bool) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatBool(bool(b))), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (b *Bool) UnmarshalText(data []byte) error {
+	switch strings.ToLower(strings.TrimSpace(string(data))) {
+	case "true", "1", "yes":
+		*b = true
+	case "false", "0", "no":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean: %s", data)
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoding the value as
+// a JSON boolean rather than a string.
+func (b Bool) MarshalJSON() ([]byte, error) {
+	return b.MarshalText()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting both
+// JSON booleans and strings.
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	if s, err := strconv.Unquote(string(data)); err == nil {
+		data = []byte(s)
+	}
+	return b.UnmarshalText(data)
+}
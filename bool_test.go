@@ -0,0 +1,58 @@
+package vast
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoolUnmarshal(t *testing.T) {
+	for in, want := range map[string]Bool{
+		"true": true, "TRUE": true, "1": true, "yes": true, " Yes ": true,
+		"false": false, "False": false, "0": false, "no": false, "NO": false,
+	} {
+		b := Bool(!want)
+		if assert.NoError(t, b.UnmarshalText([]byte(in)), in) {
+			assert.Equal(t, want, b, in)
+		}
+	}
+	var b Bool
+	assert.EqualError(t, b.UnmarshalText([]byte("maybe")), "invalid boolean: maybe")
+}
+
+func TestBoolWrapperAttributes(t *testing.T) {
+	var w Wrapper
+	err := xml.Unmarshal([]byte(`<Wrapper fallbackOnNoAd="1" allowMultipleAds="no"></Wrapper>`), &w)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.NotNil(t, w.FallbackOnNoAd) {
+		assert.Equal(t, Bool(true), *w.FallbackOnNoAd)
+	}
+	if assert.NotNil(t, w.AllowMultipleAds) {
+		assert.Equal(t, Bool(false), *w.AllowMultipleAds)
+	}
+	assert.Nil(t, w.FollowAdditionalWrappers)
+
+	b, err := xml.Marshal(Wrapper{FallbackOnNoAd: NewBool(true), AllowMultipleAds: NewBool(false)})
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(b), `fallbackOnNoAd="true" allowMultipleAds="false"`)
+		assert.NotContains(t, string(b), "followAdditionalWrappers")
+	}
+}
+
+func TestBoolJSON(t *testing.T) {
+	b, err := json.Marshal(Bool(true))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "true", string(b))
+	}
+	var v Bool
+	if assert.NoError(t, json.Unmarshal([]byte(`"yes"`), &v)) {
+		assert.Equal(t, Bool(true), v)
+	}
+	if assert.NoError(t, json.Unmarshal([]byte(`false`), &v)) {
+		assert.Equal(t, Bool(false), v)
+	}
+}
@@ -0,0 +1,401 @@
+package vast
+
+import (
+	"time"
+)
+
+// MediaFileBuilder builds a MediaFile using fluent attribute setters.
+type MediaFileBuilder struct {
+	mf MediaFile
+}
+
+// NewMediaFile returns a builder for a progressive media file of the given
+// MIME type located at uri.
+func NewMediaFile(uri, mimeType string) *MediaFileBuilder {
+	return &MediaFileBuilder{mf: MediaFile{
+		Delivery: DeliveryProgressive,
+		Type:     mimeType,
+		URI:      URI(uri),
+	}}
+}
+
+// ID sets the optional identifier of the media file.
+func (b *MediaFileBuilder) ID(id string) *MediaFileBuilder {
+	b.mf.ID = id
+	return b
+}
+
+// Delivery sets the delivery method, either "progressive" or "streaming".
+func (b *MediaFileBuilder) Delivery(delivery Delivery) *MediaFileBuilder {
+	b.mf.Delivery = delivery
+	return b
+}
+
+// Codec sets the codec used to produce the media file.
+func (b *MediaFileBuilder) Codec(codec string) *MediaFileBuilder {
+	b.mf.Codec = codec
+	return b
+}
+
+// Bitrate sets the fixed bitrate of the media file in Kbps.
+func (b *MediaFileBuilder) Bitrate(kbps int) *MediaFileBuilder {
+	b.mf.Bitrate = kbps
+	return b
+}
+
+// BitrateRange sets the minimum and maximum bitrate of an adaptive stream in Kbps.
+func (b *MediaFileBuilder) BitrateRange(minKbps, maxKbps int) *MediaFileBuilder {
+	b.mf.MinBitrate = minKbps
+	b.mf.MaxBitrate = maxKbps
+	return b
+}
+
+// Size sets the pixel dimensions of the video.
+func (b *MediaFileBuilder) Size(width, height int) *MediaFileBuilder {
+	b.mf.Width = width
+	b.mf.Height = height
+	return b
+}
+
+// Scalable sets whether it is acceptable to scale the video.
+func (b *MediaFileBuilder) Scalable(scalable bool) *MediaFileBuilder {
+	b.mf.Scalable = scalable
+	return b
+}
+
+// MaintainAspectRatio sets whether the aspect ratio must be kept when scaling.
+func (b *MediaFileBuilder) MaintainAspectRatio(maintain bool) *MediaFileBuilder {
+	b.mf.MaintainAspectRatio = maintain
+	return b
+}
+
+// APIFramework sets the API framework needed to communicate with an
+// interactive media file.
+func (b *MediaFileBuilder) APIFramework(framework string) *MediaFileBuilder {
+	b.mf.APIFramework = framework
+	return b
+}
+
+// FileSize sets the size of the media file in bytes.
+func (b *MediaFileBuilder) FileSize(size int) *MediaFileBuilder {
+	b.mf.FileSize = size
+	return b
+}
+
+// MediaType sets the type of media file (2D / 3D / 360 / etc).
+func (b *MediaFileBuilder) MediaType(mediaType string) *MediaFileBuilder {
+	b.mf.MediaType = mediaType
+	return b
+}
+
+// Build validates the media file and returns it. The returned error is a
+// ValidationErrors if the media file is invalid.
+func (b *MediaFileBuilder) Build() (MediaFile, error) {
+	mf := b.mf
+	return mf, mf.Validate()
+}
+
+// LinearBuilder builds a Linear creative.
+type LinearBuilder struct {
+	linear     Linear
+	mediaFiles []*MediaFileBuilder
+}
+
+// NewLinear returns a builder for a linear creative of the given duration.
+func NewLinear(duration time.Duration) *LinearBuilder {
+	return &LinearBuilder{linear: Linear{Duration: Duration(duration)}}
+}
+
+// MediaFile adds a media file to the linear creative. The media file is
+// validated when the linear creative is built.
+func (b *LinearBuilder) MediaFile(mf *MediaFileBuilder) *LinearBuilder {
+	b.mediaFiles = append(b.mediaFiles, mf)
+	return b
+}
+
+// SkipAfter makes the linear creative skippable after the given time.
+func (b *LinearBuilder) SkipAfter(d time.Duration) *LinearBuilder {
+	b.linear.SkipOffset = OffsetDuration(d)
+	return b
+}
+
+// SkipAfterPercent makes the linear creative skippable after the given
+// fraction (0 to 1) of its duration.
+func (b *LinearBuilder) SkipAfterPercent(percent float32) *LinearBuilder {
+	b.linear.SkipOffset = OffsetPercent(percent)
+	return b
+}
+
+// ClickThrough sets the URI to open when the user clicks on the video.
+func (b *LinearBuilder) ClickThrough(uri string) *LinearBuilder {
+	clicks := b.videoClicks()
+	clicks.ClickThroughs = []VideoClick{{URI: URI(uri)}}
+	return b
+}
+
+// ClickTracking adds a URI to ping when the user clicks on the video.
+func (b *LinearBuilder) ClickTracking(uri string) *LinearBuilder {
+	clicks := b.videoClicks()
+	clicks.ClickTrackings = append(clicks.ClickTrackings, VideoClick{URI: URI(uri)})
+	return b
+}
+
+// Tracking adds a tracking URI for the given event.
+func (b *LinearBuilder) Tracking(event EventType, uri string) *LinearBuilder {
+	b.linear.TrackingEvents = append(b.linear.TrackingEvents, Tracking{Event: string(event), URI: URI(uri)})
+	return b
+}
+
+// Progress adds a progress tracking URI pinged once the creative played
+// for the given time.
+func (b *LinearBuilder) Progress(at time.Duration, uri string) *LinearBuilder {
+	b.linear.TrackingEvents = append(b.linear.TrackingEvents, Tracking{
+		Event:  string(EventProgress),
+		Offset: OffsetDuration(at),
+		URI:    URI(uri),
+	})
+	return b
+}
+
+func (b *LinearBuilder) videoClicks() *VideoClicks {
+	if b.linear.VideoClicks == nil {
+		b.linear.VideoClicks = &VideoClicks{}
+	}
+	return b.linear.VideoClicks
+}
+
+// Build validates the linear creative and its media files and returns it.
+// The returned error is a ValidationErrors if the creative is incomplete.
+func (b *LinearBuilder) Build() (*Linear, error) {
+	linear := b.linear
+	linear.MediaFiles = make([]MediaFile, len(b.mediaFiles))
+	for i, mb := range b.mediaFiles {
+		linear.MediaFiles[i] = mb.mf
+	}
+	if len(linear.MediaFiles) == 0 {
+		linear.MediaFiles = nil
+	}
+	if err := linear.Validate(); err != nil {
+		return nil, err
+	}
+	linear.TrackingEvents = append([]Tracking(nil), b.linear.TrackingEvents...)
+	if b.linear.VideoClicks != nil {
+		clicks := *b.linear.VideoClicks
+		clicks.ClickTrackings = append([]VideoClick(nil), clicks.ClickTrackings...)
+		clicks.ClickThroughs = append([]VideoClick(nil), clicks.ClickThroughs...)
+		linear.VideoClicks = &clicks
+	}
+	return &linear, nil
+}
+
+// resourceCount returns how many of the static, iframe and HTML resources are
+// set. Companion, NonLinear and Icon elements must provide exactly one.
+func resourceCount(static *StaticResource, iframe *CDATAURI, html *HTMLResource) int {
+	n := 0
+	if static != nil {
+		n++
+	}
+	if iframe != nil {
+		n++
+	}
+	if html != nil {
+		n++
+	}
+	return n
+}
+
+// CompanionBuilder builds a Companion creative.
+type CompanionBuilder struct {
+	companion Companion
+}
+
+// NewCompanion returns a builder for a companion of the given slot dimensions.
+func NewCompanion(width, height int) *CompanionBuilder {
+	return &CompanionBuilder{companion: Companion{Width: width, Height: height}}
+}
+
+// ID sets the optional identifier of the companion.
+func (b *CompanionBuilder) ID(id string) *CompanionBuilder {
+	b.companion.ID = id
+	return b
+}
+
+// AdSlotID sets the identifier used to match the companion to a publisher
+// placement area.
+func (b *CompanionBuilder) AdSlotID(id string) *CompanionBuilder {
+	b.companion.AdSlotID = id
+	return b
+}
+
+// AssetSize sets the pixel dimensions of the companion asset.
+func (b *CompanionBuilder) AssetSize(width, height int) *CompanionBuilder {
+	b.companion.AssetWidth = width
+	b.companion.AssetHeight = height
+	return b
+}
+
+// ExpandedSize sets the pixel dimensions of the companion in expanded state.
+func (b *CompanionBuilder) ExpandedSize(width, height int) *CompanionBuilder {
+	b.companion.ExpandedWidth = width
+	b.companion.ExpandedHeight = height
+	return b
+}
+
+// APIFramework sets the method to use for communication with the companion.
+func (b *CompanionBuilder) APIFramework(framework string) *CompanionBuilder {
+	b.companion.APIFramework = framework
+	return b
+}
+
+// StaticResource sets a static file, such as an image, as the companion resource.
+func (b *CompanionBuilder) StaticResource(uri, creativeType string) *CompanionBuilder {
+	b.companion.StaticResource = &StaticResource{CreativeType: creativeType, URI: URI(uri)}
+	return b
+}
+
+// IFrameResource sets the URI of an iframe as the companion resource.
+func (b *CompanionBuilder) IFrameResource(uri string) *CompanionBuilder {
+	b.companion.IFrameResource = &CDATAURI{URI: URI(uri)}
+	return b
+}
+
+// HTMLResource sets an HTML snippet as the companion resource.
+func (b *CompanionBuilder) HTMLResource(html string) *CompanionBuilder {
+	b.companion.HTMLResource = &HTMLResource{HTML: html}
+	return b
+}
+
+// AltText sets the text displayed when the companion is rendered in HTML.
+func (b *CompanionBuilder) AltText(text string) *CompanionBuilder {
+	b.companion.AltText = text
+	return b
+}
+
+// ClickThrough sets the URI to open when the user clicks on the companion.
+func (b *CompanionBuilder) ClickThrough(uri string) *CompanionBuilder {
+	b.companion.CompanionClickThrough = &CDATAURI{URI: URI(uri)}
+	return b
+}
+
+// ClickTracking adds a URI to ping when the user clicks on the companion.
+func (b *CompanionBuilder) ClickTracking(uri string) *CompanionBuilder {
+	b.companion.CompanionClickTrackings = append(b.companion.CompanionClickTrackings, CompanionClickTracking{URI: URI(uri)})
+	return b
+}
+
+// CreativeView adds a URI to ping when the companion is displayed.
+func (b *CompanionBuilder) CreativeView(uri string) *CompanionBuilder {
+	b.companion.TrackingEvents = append(b.companion.TrackingEvents, Tracking{Event: string(EventCreativeView), URI: URI(uri)})
+	return b
+}
+
+// Build validates the companion and returns it. The returned error is a
+// ValidationErrors if the companion is incomplete.
+func (b *CompanionBuilder) Build() (*Companion, error) {
+	c := b.companion
+	vd := &validator{}
+	vd.companion("Companion", &c)
+	if resourceCount(c.StaticResource, c.IFrameResource, c.HTMLResource) > 1 {
+		vd.fail("Companion", "only one of StaticResource, IFrameResource or HTMLResource is allowed")
+	}
+	if err := vd.result(); err != nil {
+		return nil, err
+	}
+	c.CompanionClickTrackings = append([]CompanionClickTracking(nil), c.CompanionClickTrackings...)
+	c.TrackingEvents = append([]Tracking(nil), c.TrackingEvents...)
+	return &c, nil
+}
+
+// NonLinearBuilder builds a NonLinear creative.
+type NonLinearBuilder struct {
+	nonLinear NonLinear
+}
+
+// NewNonLinear returns a builder for a non-linear creative of the given dimensions.
+func NewNonLinear(width, height int) *NonLinearBuilder {
+	return &NonLinearBuilder{nonLinear: NonLinear{Width: width, Height: height}}
+}
+
+// ID sets the optional identifier of the non-linear creative.
+func (b *NonLinearBuilder) ID(id string) *NonLinearBuilder {
+	b.nonLinear.ID = id
+	return b
+}
+
+// ExpandedSize sets the pixel dimensions of the creative in expanded state.
+func (b *NonLinearBuilder) ExpandedSize(width, height int) *NonLinearBuilder {
+	b.nonLinear.ExpandedWidth = width
+	b.nonLinear.ExpandedHeight = height
+	return b
+}
+
+// Scalable sets whether it is acceptable to scale the creative.
+func (b *NonLinearBuilder) Scalable(scalable bool) *NonLinearBuilder {
+	b.nonLinear.Scalable = scalable
+	return b
+}
+
+// MaintainAspectRatio sets whether the aspect ratio must be kept when scaling.
+func (b *NonLinearBuilder) MaintainAspectRatio(maintain bool) *NonLinearBuilder {
+	b.nonLinear.MaintainAspectRatio = maintain
+	return b
+}
+
+// MinSuggestedDuration sets the suggested display duration of the creative.
+func (b *NonLinearBuilder) MinSuggestedDuration(d time.Duration) *NonLinearBuilder {
+	dur := Duration(d)
+	b.nonLinear.MinSuggestedDuration = &dur
+	return b
+}
+
+// APIFramework sets the method to use for communication with the creative.
+func (b *NonLinearBuilder) APIFramework(framework string) *NonLinearBuilder {
+	b.nonLinear.APIFramework = framework
+	return b
+}
+
+// StaticResource sets a static file, such as an image, as the creative resource.
+func (b *NonLinearBuilder) StaticResource(uri, creativeType string) *NonLinearBuilder {
+	b.nonLinear.StaticResource = &StaticResource{CreativeType: creativeType, URI: URI(uri)}
+	return b
+}
+
+// IFrameResource sets the URI of an iframe as the creative resource.
+func (b *NonLinearBuilder) IFrameResource(uri string) *NonLinearBuilder {
+	b.nonLinear.IFrameResource = &CDATAURI{URI: URI(uri)}
+	return b
+}
+
+// HTMLResource sets an HTML snippet as the creative resource.
+func (b *NonLinearBuilder) HTMLResource(html string) *NonLinearBuilder {
+	b.nonLinear.HTMLResource = &HTMLResource{HTML: html}
+	return b
+}
+
+// ClickThrough sets the URI to open when the user clicks on the creative.
+func (b *NonLinearBuilder) ClickThrough(uri string) *NonLinearBuilder {
+	b.nonLinear.NonLinearClickThrough = &CDATAURI{URI: URI(uri)}
+	return b
+}
+
+// ClickTracking adds a URI to ping when the user clicks on the creative.
+func (b *NonLinearBuilder) ClickTracking(uri string) *NonLinearBuilder {
+	b.nonLinear.NonLinearClickTrackings = append(b.nonLinear.NonLinearClickTrackings, NonLinearClickTracking{URI: URI(uri)})
+	return b
+}
+
+// Build validates the non-linear creative and returns it. The returned error
+// is a ValidationErrors if the creative is incomplete.
+func (b *NonLinearBuilder) Build() (*NonLinear, error) {
+	nl := b.nonLinear
+	vd := &validator{}
+	vd.nonLinear("NonLinear", &nl)
+	if resourceCount(nl.StaticResource, nl.IFrameResource, nl.HTMLResource) > 1 {
+		vd.fail("NonLinear", "only one of StaticResource, IFrameResource or HTMLResource is allowed")
+	}
+	if err := vd.result(); err != nil {
+		return nil, err
+	}
+	nl.NonLinearClickTrackings = append([]NonLinearClickTracking(nil), nl.NonLinearClickTrackings...)
+	return &nl, nil
+}
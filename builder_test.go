@@ -0,0 +1,145 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearBuilder(t *testing.T) {
+	linear, err := NewLinear(15*time.Second).
+		SkipAfter(5*time.Second).
+		MediaFile(NewMediaFile("http://cdn.example.com/ad.mp4", "video/mp4").Size(1280, 720).Bitrate(2000).Codec("H.264")).
+		MediaFile(NewMediaFile("http://cdn.example.com/ad.m3u8", "application/x-mpegURL").Delivery("streaming").BitrateRange(500, 4000)).
+		ClickThrough("http://advertiser.example.com").
+		ClickTracking("http://track.example.com/click").
+		Tracking(Event_type_start, "http://track.example.com/start").
+		Progress(10*time.Second, "http://track.example.com/10s").
+		Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Duration(15*time.Second), linear.Duration)
+	if assert.NotNil(t, linear.SkipOffset) && assert.NotNil(t, linear.SkipOffset.Duration) {
+		assert.Equal(t, Duration(5*time.Second), *linear.SkipOffset.Duration)
+	}
+	if assert.Len(t, linear.MediaFiles, 2) {
+		assert.Equal(t, DeliveryProgressive, linear.MediaFiles[0].Delivery)
+		assert.Equal(t, 1280, linear.MediaFiles[0].Width)
+		assert.Equal(t, 2000, linear.MediaFiles[0].Bitrate)
+		assert.Equal(t, DeliveryStreaming, linear.MediaFiles[1].Delivery)
+		assert.Equal(t, 4000, linear.MediaFiles[1].MaxBitrate)
+	}
+	if assert.NotNil(t, linear.VideoClicks) {
+		assert.Equal(t, []VideoClick{{URI: "http://advertiser.example.com"}}, linear.VideoClicks.ClickThroughs)
+		assert.Equal(t, []VideoClick{{URI: "http://track.example.com/click"}}, linear.VideoClicks.ClickTrackings)
+	}
+	if assert.Len(t, linear.TrackingEvents, 2) {
+		assert.Equal(t, "start", linear.TrackingEvents[0].Event)
+		assert.Equal(t, "progress", linear.TrackingEvents[1].Event)
+	}
+
+	b, err := xml.Marshal(linear)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `<Linear skipoffset="00:00:05"><TrackingEvents><Tracking event="start"><![CDATA[http://track.example.com/start]]></Tracking><Tracking event="progress" offset="00:00:10"><![CDATA[http://track.example.com/10s]]></Tracking></TrackingEvents><Duration>00:00:15</Duration><MediaFiles><MediaFile delivery="progressive" type="video/mp4" codec="H.264" bitrate="2000" width="1280" height="720"><![CDATA[http://cdn.example.com/ad.mp4]]></MediaFile><MediaFile delivery="streaming" type="application/x-mpegURL" minBitrate="500" maxBitrate="4000" width="0" height="0"><![CDATA[http://cdn.example.com/ad.m3u8]]></MediaFile></MediaFiles><VideoClicks><ClickTracking><![CDATA[http://track.example.com/click]]></ClickTracking><ClickThrough><![CDATA[http://advertiser.example.com]]></ClickThrough></VideoClicks></Linear>`, string(b))
+	}
+}
+
+func TestLinearBuilderErrors(t *testing.T) {
+	mp4 := func() *MediaFileBuilder { return NewMediaFile("http://cdn.example.com/ad.mp4", "video/mp4") }
+
+	_, err := NewLinear(0).MediaFile(mp4()).Build()
+	assert.EqualError(t, err, "invalid Linear.Duration: must be positive")
+	_, err = NewLinear(time.Second).Build()
+	assert.EqualError(t, err, "invalid Linear.MediaFiles: missing")
+	_, err = NewLinear(0).Build()
+	if assert.IsType(t, ValidationErrors{}, err) {
+		errs := err.(ValidationErrors)
+		if assert.Len(t, errs, 2) {
+			assert.Equal(t, &ValidationError{Path: "Linear.Duration", Element: "Duration", Reason: "must be positive"}, errs[0])
+			assert.Equal(t, &ValidationError{Path: "Linear.MediaFiles", Element: "MediaFiles", Reason: "missing"}, errs[1])
+		}
+	}
+	_, err = NewMediaFile("http://cdn.example.com/ad.mp4", "").Build()
+	assert.EqualError(t, err, `invalid MediaFile: invalid MIME type ""`)
+
+	_, err = NewLinear(time.Second).MediaFile(NewMediaFile("", "video/mp4")).Build()
+	assert.EqualError(t, err, "invalid Linear.MediaFile[0]: missing URI")
+	_, err = NewLinear(time.Second).MediaFile(NewMediaFile("http://cdn.example.com/ad", "mp4")).Build()
+	assert.EqualError(t, err, `invalid Linear.MediaFile[0]: invalid MIME type "mp4"`)
+	_, err = NewLinear(time.Second).MediaFile(mp4().Delivery("download")).Build()
+	assert.EqualError(t, err, `invalid Linear.MediaFile[0]: invalid delivery "download"`)
+	_, err = NewLinear(time.Second).MediaFile(mp4().Bitrate(-1)).Build()
+	assert.EqualError(t, err, "invalid Linear.MediaFile[0]: negative bitrate")
+	_, err = NewLinear(time.Second).MediaFile(mp4().Bitrate(1000).BitrateRange(500, 1500)).Build()
+	assert.EqualError(t, err, "invalid Linear.MediaFile[0]: bitrate and minBitrate/maxBitrate are mutually exclusive")
+	_, err = NewLinear(time.Second).MediaFile(mp4().BitrateRange(500, 0)).Build()
+	assert.EqualError(t, err, "invalid Linear.MediaFile[0]: minBitrate and maxBitrate must be supplied together")
+	_, err = NewLinear(time.Second).MediaFile(mp4().BitrateRange(1500, 500)).Build()
+	assert.EqualError(t, err, "invalid Linear.MediaFile[0]: minBitrate is greater than maxBitrate")
+
+	hls := NewMediaFile("http://cdn.example.com/ad.m3u8", "application/x-mpegURL")
+	_, err = NewLinear(time.Second).MediaFile(hls).Build()
+	assert.EqualError(t, err, "invalid Linear.MediaFile[0]: application/x-mpegURL requires streaming delivery")
+	_, err = NewLinear(time.Second).MediaFile(hls.Delivery("streaming").Bitrate(1000)).Build()
+	assert.EqualError(t, err, "invalid Linear.MediaFile[0]: application/x-mpegURL must use minBitrate/maxBitrate instead of bitrate")
+}
+
+func TestCompanionBuilder(t *testing.T) {
+	c, err := NewCompanion(300, 250).
+		ID("c1").
+		AdSlotID("sidebar").
+		StaticResource("http://cdn.example.com/banner.png", "image/png").
+		AltText("Banner").
+		ClickThrough("http://advertiser.example.com?a=1&b=2").
+		ClickTracking("http://track.example.com/cclick").
+		CreativeView("http://track.example.com/cview").
+		Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 300, c.Width)
+	assert.Equal(t, 250, c.Height)
+	assert.Equal(t, "sidebar", c.AdSlotID)
+	if assert.NotNil(t, c.StaticResource) {
+		assert.Equal(t, "image/png", c.StaticResource.CreativeType)
+	}
+	assert.Equal(t, "http://advertiser.example.com?a=1&b=2", string(c.CompanionClickThrough.URI))
+	assert.Equal(t, []CompanionClickTracking{{URI: "http://track.example.com/cclick"}}, c.CompanionClickTrackings)
+	assert.Equal(t, []Tracking{{Event: "creativeView", URI: "http://track.example.com/cview"}}, c.TrackingEvents)
+
+	_, err = NewCompanion(0, 250).HTMLResource("<p>ad</p>").Build()
+	assert.EqualError(t, err, "invalid Companion: width and height must be positive")
+	_, err = NewCompanion(300, 250).Build()
+	assert.EqualError(t, err, "invalid Companion: one of StaticResource, IFrameResource or HTMLResource is required")
+	_, err = NewCompanion(300, 250).HTMLResource("<p>ad</p>").IFrameResource("http://cdn.example.com/ad.html").Build()
+	assert.EqualError(t, err, "invalid Companion: only one of StaticResource, IFrameResource or HTMLResource is allowed")
+}
+
+func TestNonLinearBuilder(t *testing.T) {
+	nl, err := NewNonLinear(480, 70).
+		MinSuggestedDuration(10 * time.Second).
+		Scalable(true).
+		IFrameResource("http://cdn.example.com/overlay.html").
+		ClickThrough("http://advertiser.example.com").
+		ClickTracking("http://track.example.com/nlclick").
+		Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 480, nl.Width)
+	assert.Equal(t, 70, nl.Height)
+	assert.True(t, nl.Scalable)
+	if assert.NotNil(t, nl.MinSuggestedDuration) {
+		assert.Equal(t, Duration(10*time.Second), *nl.MinSuggestedDuration)
+	}
+	assert.Equal(t, "http://cdn.example.com/overlay.html", string(nl.IFrameResource.URI))
+	assert.Equal(t, []NonLinearClickTracking{{URI: "http://track.example.com/nlclick"}}, nl.NonLinearClickTrackings)
+
+	_, err = NewNonLinear(480, 0).IFrameResource("http://cdn.example.com/overlay.html").Build()
+	assert.EqualError(t, err, "invalid NonLinear: width and height must be positive")
+	_, err = NewNonLinear(480, 70).Build()
+	assert.EqualError(t, err, "invalid NonLinear: one of StaticResource, IFrameResource or HTMLResource is required")
+}
@@ -0,0 +1,89 @@
+package vast
+
+import "reflect"
+
+// Clone returns a deep copy of the document. The copy shares no memory with
+// v, so it can be mutated (e.g. macro expansion, tracker injection) while v
+// is being read concurrently, for instance when v is a cached template.
+func (v *VAST) Clone() *VAST {
+	if v == nil {
+		return nil
+	}
+	var c VAST
+	deepCopy(reflect.ValueOf(&c).Elem(), reflect.ValueOf(v).Elem())
+	return &c
+}
+
+// Clone returns a deep copy of the ad.
+func (a *Ad) Clone() *Ad {
+	if a == nil {
+		return nil
+	}
+	var c Ad
+	deepCopy(reflect.ValueOf(&c).Elem(), reflect.ValueOf(a).Elem())
+	return &c
+}
+
+// Clone returns a deep copy of the creative.
+func (c *Creative) Clone() *Creative {
+	if c == nil {
+		return nil
+	}
+	var cc Creative
+	deepCopy(reflect.ValueOf(&cc).Elem(), reflect.ValueOf(c).Elem())
+	return &cc
+}
+
+// deepCopy copies src into dst, allocating new pointers, slices and maps all
+// the way down. Unexported struct fields are copied shallowly.
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		deepCopy(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		e := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(e, src.Elem())
+		dst.Set(e)
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if f := dst.Field(i); f.CanSet() {
+				f.Set(reflect.Zero(f.Type()))
+				deepCopy(f, src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(src.Type().Key()).Elem()
+			deepCopy(k, iter.Key())
+			e := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(e, iter.Value())
+			m.SetMapIndex(k, e)
+		}
+		dst.Set(m)
+	default:
+		dst.Set(src)
+	}
+}
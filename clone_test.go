@@ -0,0 +1,65 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	c := v.Clone()
+	assert.Equal(t, v, c)
+
+	c.Ads[0].ID = "changed"
+	c.Ads[0].InLine.AdSystem.Name = "changed"
+	c.Ads[0].InLine.Impressions[0].URI = "changed"
+	c.Ads[0].InLine.Impressions = append(c.Ads[0].InLine.Impressions, Impression{URI: "added"})
+	c.Ads[0].InLine.Creatives[0].Linear.TrackingEvents[0].URI = "changed"
+	c.Ads[0].InLine.Creatives[0].Linear.MediaFiles[0].URI = "changed"
+	c.Ads[0].InLine.Creatives[0].Linear.VideoClicks.ClickThroughs[0].URI = "changed"
+	*c.Ads[0].InLine.Description = CDATAString{CDATA: "changed"}
+
+	assert.Equal(t, "601364", v.Ads[0].ID)
+	assert.Equal(t, "Acudeo Compatible", v.Ads[0].InLine.AdSystem.Name)
+	assert.Len(t, v.Ads[0].InLine.Impressions, 2)
+	assert.Equal(t, URI("http://myTrackingURL/impression"), v.Ads[0].InLine.Impressions[0].URI)
+	assert.Equal(t, URI("http://myTrackingURL/creativeView"), v.Ads[0].InLine.Creatives[0].Linear.TrackingEvents[0].URI)
+	assert.NotEqual(t, URI("changed"), v.Ads[0].InLine.Creatives[0].Linear.MediaFiles[0].URI)
+	assert.Equal(t, URI("http://www.tremormedia.com"), v.Ads[0].InLine.Creatives[0].Linear.VideoClicks.ClickThroughs[0].URI)
+	assert.Equal(t, "VAST 2.0 Instream Test 1", v.Ads[0].InLine.Description.CDATA)
+}
+
+func TestClonePointerSlices(t *testing.T) {
+	d := Duration(5)
+	exts := []Extension{{Type: "a", Data: "<A/>"}}
+	v := &VAST{Ads: []Ad{{InLine: &InLine{
+		Extensions: &exts,
+		Creatives: []Creative{{
+			CreativeExtensions: &exts,
+			Linear:             &Linear{SkipOffset: &Offset{Duration: &d}},
+		}},
+	}}}}
+	c := v.Clone()
+	(*c.Ads[0].InLine.Extensions)[0].Type = "changed"
+	(*c.Ads[0].InLine.Creatives[0].CreativeExtensions)[0].Data = "changed"
+	*c.Ads[0].InLine.Creatives[0].Linear.SkipOffset.Duration = 10
+
+	assert.Equal(t, "a", exts[0].Type)
+	assert.Equal(t, "<A/>", exts[0].Data)
+	assert.Equal(t, Duration(5), d)
+}
+
+func TestCloneNil(t *testing.T) {
+	var v *VAST
+	assert.Nil(t, v.Clone())
+	var a *Ad
+	assert.Nil(t, a.Clone())
+	ad := &Ad{ID: "1", Wrapper: &Wrapper{VASTAdTagURI: CDATAURI{URI: "http://ads.example.com"}}}
+	assert.Equal(t, ad, ad.Clone())
+	cr := &Creative{ID: "1", Linear: &Linear{}}
+	assert.Equal(t, cr, cr.Clone())
+}
@@ -0,0 +1,96 @@
+package vast
+
+import "strings"
+
+// CompanionFor returns the first companion of the ad matching the publisher
+// slot, by its adSlotId attribute, or nil if there's none. Only the
+// companions of InLine ads can be displayed.
+func (a *Ad) CompanionFor(slotID string) *Companion {
+	var found *Companion
+	a.eachCompanion(func(c *Companion) bool {
+		if c.AdSlotID == slotID {
+			found = c
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// CompanionBySize returns the companion of the ad fitting in a w×h slot: the
+// first one of that exact size, or else the largest one smaller than the
+// slot. It returns nil if none fits.
+func (a *Ad) CompanionBySize(w, h int) *Companion {
+	var best *Companion
+	a.eachCompanion(func(c *Companion) bool {
+		if c.Width == w && c.Height == h {
+			best = c
+			return false
+		}
+		if c.Width <= 0 || c.Height <= 0 || c.Width > w || c.Height > h {
+			return true
+		}
+		if best == nil || c.Width*c.Height > best.Width*best.Height {
+			best = c
+		}
+		return true
+	})
+	return best
+}
+
+// CompanionsSatisfied reports whether displaying the given companions of the
+// ad, as returned by CompanionFor or CompanionBySize, meets the required
+// attribute of their CompanionAds: every companion must be displayed for
+// "all", at least one for "any", and none otherwise. When it's not, the ad
+// should not be played and the ErrorCompanionRequired error reported.
+func (a *Ad) CompanionsSatisfied(displayed []*Companion) bool {
+	shown := make(map[*Companion]bool, len(displayed))
+	for _, c := range displayed {
+		shown[c] = true
+	}
+	if a.InLine == nil {
+		return true
+	}
+	for i := range a.InLine.Creatives {
+		ca := a.InLine.Creatives[i].CompanionAds
+		if ca == nil || len(ca.Companions) == 0 {
+			continue
+		}
+		n := 0
+		for j := range ca.Companions {
+			if shown[&ca.Companions[j]] {
+				n++
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(ca.Required)) {
+		case "all":
+			if n < len(ca.Companions) {
+				return false
+			}
+		case "any":
+			if n == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// eachCompanion calls fn for every companion of an InLine ad, in document
+// order, until it returns false.
+func (a *Ad) eachCompanion(fn func(*Companion) bool) {
+	if a.InLine == nil {
+		return
+	}
+	for i := range a.InLine.Creatives {
+		ca := a.InLine.Creatives[i].CompanionAds
+		if ca == nil {
+			continue
+		}
+		for j := range ca.Companions {
+			if !fn(&ca.Companions[j]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompanionLookup(t *testing.T) {
+	ad := Ad{InLine: &InLine{Creatives: []Creative{
+		{Linear: &Linear{}},
+		{CompanionAds: &CompanionAds{Required: "any", Companions: []Companion{
+			{ID: "banner", Width: 300, Height: 250, AdSlotID: "side"},
+			{ID: "leader", Width: 728, Height: 90, AdSlotID: "top"},
+			{ID: "small", Width: 300, Height: 60},
+		}}},
+	}}}
+	assert.Equal(t, "leader", ad.CompanionFor("top").ID)
+	assert.Nil(t, ad.CompanionFor("bottom"))
+
+	assert.Equal(t, "small", ad.CompanionBySize(300, 60).ID)
+	assert.Equal(t, "banner", ad.CompanionBySize(320, 480).ID)
+	assert.Nil(t, ad.CompanionBySize(100, 100))
+
+	assert.False(t, ad.CompanionsSatisfied(nil))
+	assert.True(t, ad.CompanionsSatisfied([]*Companion{ad.CompanionFor("side")}))
+
+	ad.InLine.Creatives[1].CompanionAds.Required = "All"
+	assert.False(t, ad.CompanionsSatisfied([]*Companion{ad.CompanionFor("side")}))
+	assert.True(t, ad.CompanionsSatisfied([]*Companion{ad.CompanionFor("side"), ad.CompanionFor("top"), ad.CompanionBySize(300, 60)}))
+
+	ad.InLine.Creatives[1].CompanionAds.Required = "none"
+	assert.True(t, ad.CompanionsSatisfied(nil))
+
+	wrapper := Ad{Wrapper: &Wrapper{}}
+	assert.Nil(t, wrapper.CompanionFor("side"))
+	assert.Nil(t, wrapper.CompanionBySize(300, 250))
+	assert.True(t, wrapper.CompanionsSatisfied(nil))
+}
@@ -0,0 +1,177 @@
+package vast
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CompatAction is what Downgrade did with an element unsupported by the
+// target version.
+type CompatAction int
+
+// Actions of Downgrade
+const (
+	// The element was removed
+	CompatDropped CompatAction = iota
+	// The element was replaced by an equivalent supported by the target
+	// version
+	CompatTransformed
+)
+
+// String implements the fmt.Stringer interface.
+func (a CompatAction) String() string {
+	switch a {
+	case CompatDropped:
+		return "dropped"
+	case CompatTransformed:
+		return "transformed"
+	}
+	return fmt.Sprintf("CompatAction(%d)", int(a))
+}
+
+// CompatChange is an element, or an attribute of an element, changed by
+// Downgrade.
+type CompatChange struct {
+	// Path of the element, as in ValidationError, such as
+	// "VAST.Ad[0].InLine.Creative[1].UniversalAdId"
+	Path string
+	// Name of the attribute of the element, if the change is about one
+	Attr string
+	// Minimum version supporting the element or attribute
+	Version SpecVersion
+	Action  CompatAction
+	// Replacement of a transformed element, such as
+	// `Extension type="AdVerifications"`
+	Replacement string
+}
+
+// String returns a description of the change, such as
+// "VAST.Ad[0].InLine.Expires (VAST 4.1): dropped".
+func (c CompatChange) String() string {
+	s := c.Path
+	if c.Attr != "" {
+		s += "@" + c.Attr
+	}
+	s += " (VAST " + string(c.Version) + "): " + c.Action.String()
+	if c.Replacement != "" {
+		s += " to " + c.Replacement
+	}
+	return s
+}
+
+// CompatReport lists the changes made by Downgrade, in document order, so
+// that partners know what they lose with an older version.
+type CompatReport struct {
+	// Effective version of the downgraded document
+	From SpecVersion
+	// Version it was downgraded to
+	To      SpecVersion
+	Changes []CompatChange
+}
+
+// Lossless returns true if no element was dropped.
+func (r *CompatReport) Lossless() bool {
+	for _, c := range r.Changes {
+		if c.Action == CompatDropped {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the changes, one per line.
+func (r *CompatReport) String() string {
+	lines := make([]string, len(r.Changes))
+	for i, c := range r.Changes {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Downgrade returns a copy of doc converted to target, a version at most as
+// recent as the effective version of doc, along with the report of the
+// changes made: the elements and attributes target doesn't support are
+// dropped, except for
+//
+//   - the AdVerifications, transformed into the AdVerifications extension
+//     used before VAST 4.1
+//   - the playerExpand and playerCollapse events, renamed fullscreen and
+//     exitFullscreen before VAST 4.1
+func Downgrade(doc *VAST, target SpecVersion) (*VAST, *CompatReport, error) {
+	if doc == nil {
+		return nil, nil, errors.New("downgrade: nil document")
+	}
+	if !target.Valid() {
+		return nil, nil, fmt.Errorf("downgrade: unknown version %q", target)
+	}
+	from := doc.EffectiveVersion()
+	if from.Valid() && !from.AtLeast(target) {
+		return nil, nil, fmt.Errorf("downgrade: %s is newer than %s", target, from)
+	}
+	d := &downgrader{target: target, report: &CompatReport{From: from, To: target}}
+	v := doc.Clone()
+	v.Version = string(target)
+	if !target.AtLeast(Version4_0) {
+		v.XMLNS = ""
+	}
+	features(v, true, d.feature)
+	v.InferredVersion = inferVersion(v)
+	return v, d.report, nil
+}
+
+type downgrader struct {
+	target SpecVersion
+	report *CompatReport
+}
+
+// feature drops or transforms f if the target doesn't support it.
+func (d *downgrader) feature(f feature) {
+	switch {
+	case d.target.AtLeast(f.version):
+	case f.before != "":
+		d.change(f, CompatTransformed, `event="`+string(f.before)+`"`)
+		f.field.SetString(string(f.before))
+	case f.field.Type() == verificationsType:
+		d.verifications(f)
+	default:
+		d.change(f, CompatDropped, "")
+		f.field.Set(reflect.Zero(f.field.Type()))
+	}
+}
+
+// change records the change of f.
+func (d *downgrader) change(f feature, action CompatAction, replacement string) {
+	d.report.Changes = append(d.report.Changes, CompatChange{Path: f.path, Attr: f.attr, Version: f.version, Action: action, Replacement: replacement})
+}
+
+var verificationsType = reflect.TypeOf((*[]Verification)(nil))
+
+// verifications transforms the AdVerifications element of an InLine or
+// Wrapper ad into an extension of the ad.
+func (d *downgrader) verifications(f feature) {
+	ext, err := NewExtension(ExtensionAdVerifications, struct {
+		XMLName       xml.Name       `xml:"AdVerifications"`
+		Verifications []Verification `xml:"Verification"`
+	}{Verifications: *f.field.Interface().(*[]Verification)})
+	f.field.Set(reflect.Zero(f.field.Type()))
+	if err != nil {
+		// unencodable verifications are dropped
+		d.change(f, CompatDropped, "")
+		return
+	}
+	d.change(f, CompatTransformed, `Extension type="`+ExtensionAdVerifications+`"`)
+	switch ad := f.parent.Addr().Interface().(type) {
+	case *InLine:
+		var exts []Extension
+		if ad.Extensions != nil {
+			exts = *ad.Extensions
+		}
+		exts = append(exts, ext)
+		ad.Extensions = &exts
+	case *Wrapper:
+		ad.Extensions = append(ad.Extensions, ext)
+	}
+}
@@ -0,0 +1,106 @@
+package vast
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDowngrade(t *testing.T) {
+	doc := kitchenSink()
+	for _, target := range []SpecVersion{Version2_0, Version3_0, Version4_0, Version4_1, Version4_2} {
+		v, report, err := Downgrade(doc, target)
+		if !assert.NoError(t, err, target) {
+			continue
+		}
+		assert.Equal(t, string(target), v.Version)
+		assert.Equal(t, target, v.InferredVersion, "no element newer than the target is left")
+		assert.Equal(t, Version4_2, report.From)
+		assert.Equal(t, target, report.To)
+		for _, c := range report.Changes {
+			assert.False(t, target.AtLeast(c.Version), c.String())
+		}
+		// the verifications are kept, as an extension before 4.1
+		assert.Len(t, v.Ads[0].VerificationResources(), 1, target)
+	}
+	assert.Equal(t, "4.2", doc.Version, "doc is left untouched")
+	assert.NotNil(t, doc.Ads[0].InLine.AdVerifications)
+
+	v, report, err := Downgrade(doc, Version3_0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, report.Lossless())
+	assert.Contains(t, report.Changes, CompatChange{Path: "VAST.Ad[0]", Attr: "adType", Version: Version4_1, Action: CompatDropped})
+	assert.Contains(t, report.Changes, CompatChange{Path: "VAST.Ad[0].InLine.Creative[0].UniversalAdId", Version: Version4_0, Action: CompatDropped})
+	assert.Contains(t, report.Changes, CompatChange{Path: "VAST.Ad[0].InLine.AdVerifications", Version: Version4_1, Action: CompatTransformed, Replacement: `Extension type="AdVerifications"`})
+	assert.Contains(t, report.Changes, CompatChange{Path: "VAST.Ad[0].InLine.Advertiser", Version: Version4_0, Action: CompatDropped})
+	assert.Contains(t, report.Changes, CompatChange{Path: "VAST.Ad[0].InLine.Creative[0].Linear.MediaFile[0]", Attr: "mediaType", Version: Version4_1, Action: CompatDropped})
+	assert.Empty(t, v.Ads[0].InLine.Advertiser)
+	assert.Empty(t, v.Ads[0].InLine.Creatives[0].Linear.MediaFiles[0].MediaType)
+	assert.NotContains(t, report.Changes, CompatChange{Path: "VAST.Ad[0]", Attr: "sequence", Version: Version3_0, Action: CompatDropped})
+	assert.Equal(t, 1, v.Ads[0].Sequence)
+	assert.Nil(t, v.Ads[0].InLine.AdVerifications)
+	assert.NotNil(t, v.Ads[0].InLine.Creatives[0].Linear.Icons)
+	assert.Nil(t, v.Ads[0].InLine.Creatives[0].Linear.Icons.Icon[0].IconClickFallbackImages)
+
+	_, report, err = Downgrade(doc, Version2_0)
+	if assert.NoError(t, err) {
+		assert.Contains(t, report.Changes, CompatChange{Path: "VAST.Ad[0]", Attr: "sequence", Version: Version3_0, Action: CompatDropped})
+		assert.Contains(t, report.Changes, CompatChange{Path: "VAST.Ad[1].Wrapper.Creative[0].Linear.Icons", Version: Version3_0, Action: CompatDropped})
+	}
+
+	_, report, err = Downgrade(doc, Version4_2)
+	if assert.NoError(t, err) {
+		assert.Empty(t, report.Changes)
+		assert.True(t, report.Lossless())
+	}
+
+	_, _, err = Downgrade(doc, "1.0")
+	assert.EqualError(t, err, `downgrade: unknown version "1.0"`)
+	_, _, err = Downgrade(nil, Version3_0)
+	assert.EqualError(t, err, "downgrade: nil document")
+	_, _, err = Downgrade(&VAST{Version: "3.0"}, Version4_0)
+	assert.EqualError(t, err, "downgrade: 4.0 is newer than 3.0")
+}
+
+func TestDowngradeRequiredVersion(t *testing.T) {
+	names, err := filepath.Glob("testdata/*.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	docs := map[string]*VAST{"kitchen sink": kitchenSink()}
+	for _, name := range names {
+		if v, _, _, err := loadFixture(name); err == nil {
+			docs[name] = v
+		}
+	}
+	for name, doc := range docs {
+		for _, target := range specVersions {
+			if !doc.EffectiveVersion().AtLeast(target) {
+				continue
+			}
+			v, _, err := Downgrade(doc, target)
+			if assert.NoError(t, err, "%s to %s", name, target) {
+				assert.True(t, target.AtLeast(RequiredVersion(v)), "%s to %s requires %s", name, target, RequiredVersion(v))
+			}
+		}
+	}
+}
+
+func TestDowngradeEvents(t *testing.T) {
+	doc := &VAST{Version: "4.1", Ads: []Ad{{InLine: &InLine{Creatives: []Creative{{Linear: &Linear{
+		TrackingEvents: []Tracking{{Event: "start"}, {Event: "playerExpand"}, {Event: "playerCollapse"}},
+	}}}}}}}
+	v, report, err := Downgrade(doc, Version3_0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, report.Lossless())
+	assert.Equal(t, []Tracking{{Event: "start"}, {Event: "fullscreen"}, {Event: "exitFullscreen"}}, v.Ads[0].InLine.Creatives[0].Linear.TrackingEvents)
+	assert.Equal(t, `VAST.Ad[0].InLine.Creative[0].Linear.Tracking[1]@event (VAST 4.1): transformed to event="fullscreen"
+VAST.Ad[0].InLine.Creative[0].Linear.Tracking[2]@event (VAST 4.1): transformed to event="exitFullscreen"`, report.String())
+	assert.Equal(t, "dropped", CompatDropped.String())
+	assert.Equal(t, "CompatAction(5)", CompatAction(5).String())
+}
@@ -0,0 +1,27 @@
+package vast
+
+// IsConditional returns true if the ad is flagged with conditionalAd="true",
+// meaning it is a programmatic unit which may decide not to play once
+// executed.
+func (a *Ad) IsConditional() bool {
+	return a.ConditionalAd != nil && bool(*a.ConditionalAd)
+}
+
+// RemoveConditionalAds removes the conditional ads from the document and
+// returns them. Server-side ad insertion can't execute such ads, so they
+// should be dropped, and eventually reported with
+// ErrorConditionalAdRejected, before stitching.
+func (v *VAST) RemoveConditionalAds() []Ad {
+	var kept, removed []Ad
+	for _, ad := range v.Ads {
+		if ad.IsConditional() {
+			removed = append(removed, ad)
+		} else {
+			kept = append(kept, ad)
+		}
+	}
+	if len(removed) > 0 {
+		v.Ads = kept
+	}
+	return removed
+}
@@ -0,0 +1,38 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionalAd(t *testing.T) {
+	var v VAST
+	raw := `<VAST version="3.0"><Ad id="1" conditionalAd="true"></Ad><Ad id="2" conditionalAd="false"></Ad><Ad id="3"></Ad></VAST>`
+	if !assert.NoError(t, xml.Unmarshal([]byte(raw), &v)) {
+		return
+	}
+	assert.True(t, v.Ads[0].IsConditional())
+	assert.False(t, v.Ads[1].IsConditional())
+	if assert.NotNil(t, v.Ads[1].ConditionalAd) {
+		assert.Equal(t, Bool(false), *v.Ads[1].ConditionalAd)
+	}
+	assert.False(t, v.Ads[2].IsConditional())
+
+	b, err := xml.Marshal(v.Ads[1])
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(b), `conditionalAd="false"`)
+	}
+
+	removed := v.RemoveConditionalAds()
+	if assert.Len(t, removed, 1) {
+		assert.Equal(t, "1", removed[0].ID)
+	}
+	if assert.Len(t, v.Ads, 2) {
+		assert.Equal(t, "2", v.Ads[0].ID)
+		assert.Equal(t, "3", v.Ads[1].ID)
+	}
+	assert.Empty(t, v.RemoveConditionalAds())
+	assert.Len(t, v.Ads, 2)
+}
@@ -0,0 +1,230 @@
+// Package dash converts VAST ads into MPEG-DASH periods, for server-side
+// stitching of ads into DASH presentations.
+package dash
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// AssetIDScheme is the scheme of the AssetIdentifier of the ad periods,
+// holding the UniversalAdId of the ad.
+const AssetIDScheme = "urn:org:dashif:asset-id:2013"
+
+// ErrNoPeriods is returned when none of the ads has a usable media file.
+var ErrNoPeriods = errors.New("dash: no ad with a usable media file and a duration")
+
+// Period is an MPD Period playing a single ad.
+type Period struct {
+	XMLName xml.Name `xml:"Period"`
+	ID      string   `xml:"id,attr"`
+	// The start of the period within the presentation, as an ISO 8601
+	// duration
+	Start string `xml:"start,attr"`
+	// The duration of the ad, as an ISO 8601 duration
+	Duration        string          `xml:"duration,attr"`
+	AssetIdentifier *Descriptor     `xml:"AssetIdentifier,omitempty"`
+	AdaptationSets  []AdaptationSet `xml:"AdaptationSet"`
+}
+
+// Descriptor is a DASH descriptor element.
+type Descriptor struct {
+	SchemeIDURI string `xml:"schemeIdUri,attr"`
+	Value       string `xml:"value,attr,omitempty"`
+}
+
+// AdaptationSet groups the renditions of an ad sharing a MIME type.
+type AdaptationSet struct {
+	MimeType         string           `xml:"mimeType,attr"`
+	ContentType      string           `xml:"contentType,attr,omitempty"`
+	SegmentAlignment bool             `xml:"segmentAlignment,attr"`
+	Representations  []Representation `xml:"Representation"`
+}
+
+// Representation is a rendition of an ad, a single file at BaseURL.
+type Representation struct {
+	ID string `xml:"id,attr"`
+	// Bitrate in bits per second
+	Bandwidth int    `xml:"bandwidth,attr"`
+	Width     int    `xml:"width,attr,omitempty"`
+	Height    int    `xml:"height,attr,omitempty"`
+	Codecs    string `xml:"codecs,attr,omitempty"`
+	BaseURL   string `xml:"BaseURL"`
+}
+
+// Options configures the periods built by FromPod.
+type Options struct {
+	// The start of the first period within the presentation
+	Start time.Duration
+	// The prefix of the period IDs, followed by the position of the ad from 1
+	IDPrefix string
+	// If true, the Mezzanine files of the ads are used rather than their
+	// media files, for stitchers transcoding the ads themselves
+	UseMezzanine bool
+	// MIME types of the progressive media files to use, video/mp4 if empty
+	MIMETypes []string
+}
+
+// FromVAST returns the periods playing the pod of doc, or its first
+// stand-alone ad if it has no pod.
+func FromVAST(doc *vast.VAST, opts Options) ([]Period, error) {
+	pod, standalone := doc.SplitPod()
+	if len(pod) == 0 && len(standalone) > 0 {
+		pod = standalone[:1]
+	}
+	return FromPod(pod, opts)
+}
+
+// FromPod returns a period per ad, back to back from opts.Start. An ad plays
+// the progressive media files of the first linear creative having some of
+// the requested MIME types, or its mezzanine files with opts.UseMezzanine.
+// The ads without such files or without duration are skipped, and
+// ErrNoPeriods is returned if none remains.
+func FromPod(ads []*vast.Ad, opts Options) ([]Period, error) {
+	mimeTypes := opts.MIMETypes
+	if len(mimeTypes) == 0 {
+		mimeTypes = []string{vast.MIMEVideoMP4}
+	}
+	var periods []Period
+	start := opts.Start
+	for i, ad := range ads {
+		d, ok := ad.Duration()
+		if !ok || d <= 0 || ad.InLine == nil {
+			continue
+		}
+		var sets []AdaptationSet
+		for _, c := range ad.InLine.Creatives {
+			if c.Linear == nil {
+				continue
+			}
+			if opts.UseMezzanine {
+				sets = mezzanineSets(c.Linear.Mezzanines, d)
+			} else {
+				files := vast.FilterMediaFiles(c.Linear.MediaFiles, vast.ByMIME(mimeTypes...), vast.ProgressiveOnly, vast.ExcludeInteractive)
+				sets = mediaFileSets(files, d)
+			}
+			if len(sets) > 0 {
+				break
+			}
+		}
+		if len(sets) == 0 {
+			continue
+		}
+		p := Period{
+			ID:             opts.IDPrefix + strconv.Itoa(i+1),
+			Start:          formatDuration(start),
+			Duration:       formatDuration(d),
+			AdaptationSets: sets,
+		}
+		if ids := ad.UniversalAdIDs(); len(ids) > 0 {
+			p.AssetIdentifier = &Descriptor{SchemeIDURI: AssetIDScheme, Value: ids[0].IDRegistry + ":" + ids[0].ID}
+		}
+		periods = append(periods, p)
+		start += d
+	}
+	if len(periods) == 0 {
+		return nil, ErrNoPeriods
+	}
+	return periods, nil
+}
+
+func mediaFileSets(files []vast.MediaFile, d time.Duration) []AdaptationSet {
+	var sets []AdaptationSet
+	for i, m := range files {
+		bandwidth := m.Bitrate * 1000
+		if bandwidth <= 0 {
+			bandwidth = m.MaxBitrate * 1000
+		}
+		if bandwidth <= 0 {
+			bandwidth = estimateBandwidth(m.FileSize, d)
+		}
+		sets = addRepresentation(sets, m.Type, Representation{
+			ID:        representationID(m.ID, i),
+			Bandwidth: bandwidth,
+			Width:     m.Width,
+			Height:    m.Height,
+			Codecs:    m.Codec,
+			BaseURL:   strings.TrimSpace(string(m.URI)),
+		})
+	}
+	return sets
+}
+
+func mezzanineSets(files []vast.Mezzanine, d time.Duration) []AdaptationSet {
+	var sets []AdaptationSet
+	for i, m := range files {
+		sets = addRepresentation(sets, m.Type, Representation{
+			ID:        representationID(m.ID, i),
+			Bandwidth: estimateBandwidth(m.FileSize, d),
+			Width:     m.Width,
+			Height:    m.Height,
+			Codecs:    m.Codec,
+			BaseURL:   strings.TrimSpace(string(m.URI)),
+		})
+	}
+	return sets
+}
+
+// addRepresentation adds r to the adaptation set of its MIME type, creating
+// it if needed.
+func addRepresentation(sets []AdaptationSet, mimeType string, r Representation) []AdaptationSet {
+	if r.BaseURL == "" {
+		return sets
+	}
+	for i := range sets {
+		if strings.EqualFold(sets[i].MimeType, mimeType) {
+			sets[i].Representations = append(sets[i].Representations, r)
+			return sets
+		}
+	}
+	contentType := ""
+	if i := strings.IndexByte(mimeType, '/'); i > 0 {
+		contentType = strings.ToLower(mimeType[:i])
+	}
+	return append(sets, AdaptationSet{
+		MimeType:         mimeType,
+		ContentType:      contentType,
+		SegmentAlignment: true,
+		Representations:  []Representation{r},
+	})
+}
+
+func representationID(id string, i int) string {
+	if id != "" {
+		return id
+	}
+	return strconv.Itoa(i + 1)
+}
+
+// estimateBandwidth returns the average bitrate in bits per second of a file
+// of the given size in bytes and duration, or 0 if the size is unknown.
+func estimateBandwidth(size int, d time.Duration) int {
+	if size <= 0 || d <= 0 {
+		return 0
+	}
+	return int(float64(size) * 8 / d.Seconds())
+}
+
+// formatDuration formats d as an ISO 8601 duration, as used by MPDs, such as
+// "PT1M30.5S".
+func formatDuration(d time.Duration) string {
+	var sb strings.Builder
+	sb.WriteString("PT")
+	if h := d / time.Hour; h > 0 {
+		sb.WriteString(strconv.Itoa(int(h)) + "H")
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		sb.WriteString(strconv.Itoa(int(m)) + "M")
+		d -= m * time.Minute
+	}
+	if d > 0 || sb.Len() == 2 {
+		sb.WriteString(strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S")
+	}
+	return sb.String()
+}
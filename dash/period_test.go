@@ -0,0 +1,71 @@
+package dash
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func ad(seq int, d time.Duration, linear vast.Linear) vast.Ad {
+	linear.Duration = vast.Duration(d)
+	return vast.Ad{Sequence: seq, InLine: &vast.InLine{Creatives: []vast.Creative{
+		{UniversalAdID: &vast.UniversalAdID{IDRegistry: "ad-id.org", ID: "CNPA0484000H"}, Linear: &linear},
+	}}}
+}
+
+func TestFromVAST(t *testing.T) {
+	doc := &vast.VAST{Ads: []vast.Ad{
+		ad(2, 15*time.Second, vast.Linear{MediaFiles: []vast.MediaFile{
+			{Delivery: vast.DeliveryProgressive, Type: vast.MIMEVideoMP4, Width: 1280, Height: 720, Bitrate: 2000, Codec: "avc1.64001f", URI: " https://cdn/b-720.mp4 "},
+			{Delivery: vast.DeliveryProgressive, Type: vast.MIMEVideoMP4, Width: 640, Height: 360, FileSize: 1500000, URI: "https://cdn/b-360.mp4"},
+			{Delivery: vast.DeliveryStreaming, Type: vast.MIMEHLS, URI: "https://cdn/b.m3u8"},
+		}}),
+		ad(1, 90500*time.Millisecond, vast.Linear{MediaFiles: []vast.MediaFile{
+			{ID: "a", Delivery: vast.DeliveryProgressive, Type: vast.MIMEVideoMP4, Bitrate: 500, URI: "https://cdn/a.mp4"},
+		}}),
+		ad(3, 10*time.Second, vast.Linear{MediaFiles: []vast.MediaFile{
+			{Delivery: vast.DeliveryStreaming, Type: vast.MIMEHLS, URI: "https://cdn/c.m3u8"},
+		}}),
+	}}
+	periods, err := FromVAST(doc, Options{Start: time.Hour, IDPrefix: "ad-"})
+	if !assert.NoError(t, err) || !assert.Len(t, periods, 2) {
+		return
+	}
+	assert.Equal(t, "PT1H", periods[0].Start)
+	assert.Equal(t, "PT1M30.5S", periods[0].Duration)
+	assert.Equal(t, "PT1H1M30.5S", periods[1].Start)
+	assert.Equal(t, "ad-2", periods[1].ID)
+	assert.Equal(t, 800000, periods[1].AdaptationSets[0].Representations[1].Bandwidth)
+
+	b, err := xml.Marshal(periods[1])
+	assert.NoError(t, err)
+	assert.Equal(t, `<Period id="ad-2" start="PT1H1M30.5S" duration="PT15S">`+
+		`<AssetIdentifier schemeIdUri="urn:org:dashif:asset-id:2013" value="ad-id.org:CNPA0484000H"></AssetIdentifier>`+
+		`<AdaptationSet mimeType="video/mp4" contentType="video" segmentAlignment="true">`+
+		`<Representation id="1" bandwidth="2000000" width="1280" height="720" codecs="avc1.64001f"><BaseURL>https://cdn/b-720.mp4</BaseURL></Representation>`+
+		`<Representation id="2" bandwidth="800000" width="640" height="360"><BaseURL>https://cdn/b-360.mp4</BaseURL></Representation>`+
+		`</AdaptationSet></Period>`, string(b))
+}
+
+func TestFromPodMezzanine(t *testing.T) {
+	a := ad(0, 30*time.Second, vast.Linear{
+		MediaFiles: []vast.MediaFile{{Delivery: vast.DeliveryProgressive, Type: vast.MIMEVideoMP4, URI: "https://cdn/a.mp4"}},
+		Mezzanines: []vast.Mezzanine{{Delivery: vast.DeliveryProgressive, Type: "video/quicktime", Width: 1920, Height: 1080, FileSize: 150000000, URI: "https://cdn/a.mov"}},
+	})
+	periods, err := FromPod([]*vast.Ad{&a}, Options{UseMezzanine: true})
+	if assert.NoError(t, err) && assert.Len(t, periods, 1) {
+		assert.Equal(t, "PT0S", periods[0].Start)
+		assert.Equal(t, []AdaptationSet{{MimeType: "video/quicktime", ContentType: "video", SegmentAlignment: true, Representations: []Representation{
+			{ID: "1", Bandwidth: 40000000, Width: 1920, Height: 1080, BaseURL: "https://cdn/a.mov"},
+		}}}, periods[0].AdaptationSets)
+	}
+
+	noDuration := ad(0, 0, vast.Linear{MediaFiles: []vast.MediaFile{{Delivery: vast.DeliveryProgressive, Type: vast.MIMEVideoMP4, URI: "https://cdn/a.mp4"}}})
+	_, err = FromPod([]*vast.Ad{&a, &noDuration}, Options{MIMETypes: []string{"video/webm"}})
+	assert.Equal(t, ErrNoPeriods, err)
+	_, err = FromVAST(&vast.VAST{}, Options{})
+	assert.Equal(t, ErrNoPeriods, err)
+}
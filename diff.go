@@ -0,0 +1,324 @@
+package vast
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind is the kind of difference between two documents.
+type ChangeKind int
+
+// Change kinds reported by Diff
+const (
+	// An element or attribute only found in the second document
+	ChangeAdded ChangeKind = iota + 1
+	// An element or attribute only found in the first document
+	ChangeRemoved
+	// A value differing between both documents
+	ChangeModified
+)
+
+// String implements the fmt.Stringer interface.
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is a difference between two documents.
+type Change struct {
+	Kind ChangeKind
+	// Path of the element using XML element names, container elements being
+	// omitted, such as "VAST.Ad[0].InLine.Impression[1]". Attributes are
+	// prefixed with @, such as "VAST.Ad[0]@sequence". For added elements, the
+	// index is the one in the second document.
+	Path string
+	// The value in the first document, empty for added elements and for
+	// elements without text content
+	Old string `json:",omitempty"`
+	// The value in the second document, empty for removed elements and for
+	// elements without text content
+	New string `json:",omitempty"`
+}
+
+// String implements the fmt.Stringer interface.
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s %s", c.Path, strconv.Quote(c.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s %s", c.Path, strconv.Quote(c.Old))
+	}
+	return fmt.Sprintf("~ %s %s -> %s", c.Path, strconv.Quote(c.Old), strconv.Quote(c.New))
+}
+
+// Diff returns the differences between a and b, in document order. The order
+// of sibling trackers, impressions and error URIs is not significant, nor is
+// the whitespace surrounding text content.
+func Diff(a, b *VAST) []Change {
+	c := comparer{unordered: true, trim: true}
+	c.compare("VAST", reflect.ValueOf(a), reflect.ValueOf(b))
+	return c.changes
+}
+
+// unorderedTypes are the types of the elements whose order among their
+// siblings is not significant.
+var unorderedTypes = map[reflect.Type]bool{
+	reflect.TypeOf(Impression{}):             true,
+	reflect.TypeOf(Tracking{}):               true,
+	reflect.TypeOf(VideoClick{}):             true,
+	reflect.TypeOf(CDATAString{}):            true,
+	reflect.TypeOf(CompanionClickTracking{}): true,
+	reflect.TypeOf(NonLinearClickTracking{}): true,
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// comparer compares two values of the same type.
+type comparer struct {
+	// whether the order of unorderedTypes elements is not significant
+	unordered bool
+	// whether whitespace surrounding text is not significant
+	trim bool
+	// whether absent values equal default and zero values
+	defaults bool
+	changes  []Change
+}
+
+func (c *comparer) add(kind ChangeKind, path, old, new string) {
+	c.changes = append(c.changes, Change{Kind: kind, Path: path, Old: old, New: new})
+}
+
+func (c *comparer) compare(path string, a, b reflect.Value) {
+	if a.Kind() == reflect.Ptr || a.Kind() == reflect.Interface {
+		switch {
+		case a.IsNil() && b.IsNil():
+		case c.defaults && (a.IsNil() || b.IsNil()):
+			if a.IsNil() {
+				a = reflect.New(b.Type().Elem())
+			} else {
+				b = reflect.New(a.Type().Elem())
+			}
+			c.compare(path, a.Elem(), b.Elem())
+		case a.IsNil():
+			c.add(ChangeAdded, path, "", c.text(b.Elem()))
+		case b.IsNil():
+			c.add(ChangeRemoved, path, c.text(a.Elem()), "")
+		default:
+			c.compare(path, a.Elem(), b.Elem())
+		}
+		return
+	}
+	if leaf, ok := c.leaf(a); ok {
+		if other, _ := c.leaf(b); leaf != other {
+			c.add(ChangeModified, path, leaf, other)
+		}
+		return
+	}
+	switch a.Kind() {
+	case reflect.Slice:
+		if c.unordered && unorderedTypes[a.Type().Elem()] {
+			c.compareUnordered(path, a, b)
+			return
+		}
+		n := a.Len()
+		if b.Len() > n {
+			n = b.Len()
+		}
+		for i := 0; i < n; i++ {
+			p := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= a.Len():
+				c.add(ChangeAdded, p, "", c.text(b.Index(i)))
+			case i >= b.Len():
+				c.add(ChangeRemoved, p, c.text(a.Index(i)), "")
+			default:
+				c.compare(p, a.Index(i), b.Index(i))
+			}
+		}
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			p, ok := fieldPath(path, f)
+			if !ok {
+				continue
+			}
+			if d, ok := attrDefaults[t][f.Name]; ok && c.defaults {
+				c.compareDefault(p, a.Field(i), b.Field(i), d)
+				continue
+			}
+			c.compare(p, a.Field(i), b.Field(i))
+		}
+	}
+}
+
+// compareDefault compares leaf values, or pointers to leaf values, an empty
+// or nil one being replaced by d.
+func (c *comparer) compareDefault(path string, a, b reflect.Value, d string) {
+	leaf, other := c.defaultLeaf(a), c.defaultLeaf(b)
+	if leaf == "" {
+		leaf = d
+	}
+	if other == "" {
+		other = d
+	}
+	if leaf != other {
+		c.add(ChangeModified, path, leaf, other)
+	}
+}
+
+// defaultLeaf returns the text of a leaf value, or of the leaf value it
+// points to, empty if nil.
+func (c *comparer) defaultLeaf(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	leaf, _ := c.leaf(v)
+	return leaf
+}
+
+// compareUnordered compares slices whose elements are matched regardless of
+// their position.
+func (c *comparer) compareUnordered(path string, a, b reflect.Value) {
+	remaining := map[string][]int{}
+	for i := 0; i < b.Len(); i++ {
+		k := c.key(b.Index(i))
+		remaining[k] = append(remaining[k], i)
+	}
+	for i := 0; i < a.Len(); i++ {
+		k := c.key(a.Index(i))
+		if idx := remaining[k]; len(idx) > 0 {
+			remaining[k] = idx[1:]
+			continue
+		}
+		c.add(ChangeRemoved, path+"["+strconv.Itoa(i)+"]", c.text(a.Index(i)), "")
+	}
+	added := map[int]bool{}
+	for _, idx := range remaining {
+		for _, i := range idx {
+			added[i] = true
+		}
+	}
+	for i := 0; i < b.Len(); i++ {
+		if added[i] {
+			c.add(ChangeAdded, path+"["+strconv.Itoa(i)+"]", "", c.text(b.Index(i)))
+		}
+	}
+}
+
+// leaf returns the text of a value compared as a whole: scalars and types
+// implementing encoding.TextMarshaler.
+func (c *comparer) leaf(v reflect.Value) (string, bool) {
+	var s string
+	switch {
+	case v.Type().Implements(textMarshalerType):
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return fmt.Sprint(v.Interface()), true
+		}
+		s = string(text)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		s = string(v.Bytes())
+	case v.Kind() == reflect.String:
+		s = v.String()
+	case v.Kind() == reflect.Bool, v.Kind() >= reflect.Int && v.Kind() <= reflect.Float64:
+		return fmt.Sprint(v.Interface()), true
+	default:
+		return "", false
+	}
+	if c.trim {
+		s = strings.TrimSpace(s)
+	}
+	return s, true
+}
+
+// key returns a canonical representation of v, equal for values the comparer
+// considers equal.
+func (c *comparer) key(v reflect.Value) string {
+	var sb strings.Builder
+	c.writeKey(&sb, v)
+	return sb.String()
+}
+
+func (c *comparer) writeKey(sb *strings.Builder, v reflect.Value) {
+	switch {
+	case v.Kind() == reflect.Ptr && v.IsNil() && c.defaults:
+		v = reflect.New(v.Type().Elem()).Elem()
+	case (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil():
+		sb.WriteString("nil;")
+		return
+	case v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface:
+		v = v.Elem()
+	}
+	if leaf, ok := c.leaf(v); ok {
+		sb.WriteString(strconv.Quote(leaf))
+		sb.WriteByte(';')
+		return
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		sb.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			c.writeKey(sb, v.Index(i))
+		}
+		sb.WriteByte(']')
+	case reflect.Struct:
+		sb.WriteByte('{')
+		for i := 0; i < v.NumField(); i++ {
+			if _, ok := fieldPath("", v.Type().Field(i)); ok {
+				c.writeKey(sb, v.Field(i))
+			}
+		}
+		sb.WriteByte('}')
+	}
+}
+
+// text returns the text content of an element, if any.
+func (c *comparer) text(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if leaf, ok := c.leaf(v); ok {
+		return leaf
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if tag, ok := ParseXMLField(v.Type().Field(i)); ok && tag.CharData {
+			return c.text(v.Field(i))
+		}
+	}
+	return ""
+}
+
+// fieldPath returns the path of the field f of the element at path, and
+// false for the fields which are not part of the document.
+func fieldPath(path string, f reflect.StructField) (string, bool) {
+	tag, ok := ParseXMLField(f)
+	switch {
+	case !ok, tag.Comment:
+		return "", false
+	case tag.CharData, tag.InnerXML:
+		return path, true
+	case tag.Attr:
+		return path + "@" + tag.Name(), true
+	}
+	return path + "." + tag.Name(), true
+}
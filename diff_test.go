@@ -0,0 +1,53 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	a, _, _, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, Diff(a, a.Clone()))
+
+	b := a.Clone()
+	in := b.Ads[0].InLine
+	// reordering trackers and surrounding whitespace are not changes
+	in.Impressions[0], in.Impressions[1] = in.Impressions[1], in.Impressions[0]
+	in.Impressions[0].URI = " " + in.Impressions[0].URI + "\n"
+	b.Ads[0].AddImpression("http://t/added")
+	b.Ads[0].Sequence = 3
+	linear := in.Creatives[0].Linear
+	linear.Duration = Duration(42 * time.Second)
+	linear.MediaFiles[0].Bitrate = 800
+	linear.SkipOffset = OffsetDuration(5 * time.Second)
+	linear.TrackingEvents = linear.TrackingEvents[1:]
+
+	var changes []string
+	for _, c := range Diff(a, b) {
+		changes = append(changes, c.String())
+	}
+	assert.Equal(t, []string{
+		`+ VAST.Ad[0].InLine.Impression[2] "http://t/added"`,
+		`+ VAST.Ad[0].InLine.Creative[0].Linear@skipoffset "00:00:05"`,
+		`- VAST.Ad[0].InLine.Creative[0].Linear.Tracking[0] "http://myTrackingURL/creativeView"`,
+		`~ VAST.Ad[0].InLine.Creative[0].Linear.Duration "00:00:30" -> "00:00:42"`,
+		`~ VAST.Ad[0].InLine.Creative[0].Linear.MediaFile[0]@bitrate "500" -> "800"`,
+		`~ VAST.Ad[0]@sequence "0" -> "3"`,
+	}, changes)
+}
+
+func TestDiffDuplicates(t *testing.T) {
+	a := &VAST{Ads: []Ad{{InLine: &InLine{Impressions: []Impression{{URI: "http://t/1"}, {URI: "http://t/1"}}}}}}
+	b := &VAST{Ads: []Ad{{InLine: &InLine{Impressions: []Impression{{URI: "http://t/1"}}}}, {}}}
+	assert.Equal(t, []Change{
+		{Kind: ChangeRemoved, Path: "VAST.Ad[0].InLine.Impression[1]", Old: "http://t/1"},
+		{Kind: ChangeAdded, Path: "VAST.Ad[1]"},
+	}, Diff(a, b))
+	assert.Equal(t, []Change{{Kind: ChangeAdded, Path: "VAST"}}, Diff(nil, b))
+	assert.Equal(t, "removed", ChangeRemoved.String())
+}
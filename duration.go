@@ -1,55 +1,175 @@
 package vast
 
 import (
+	"encoding/xml"
 	"fmt"
 	"strconv"
-	"strings"
 	"time"
 )
 
 // Duration is a VAST duration expressed a hh:mm:ss
 type Duration time.Duration
 
+// FromDuration returns d as a Duration, rounded to the millisecond which is
+// the precision of VAST durations.
+func FromDuration(d time.Duration) Duration {
+	return Duration(d.Round(time.Millisecond))
+}
+
+// ToDuration returns the duration as a time.Duration.
+func (dur Duration) ToDuration() time.Duration {
+	return time.Duration(dur)
+}
+
+// Seconds returns the duration as a floating point number of seconds.
+func (dur Duration) Seconds() float64 {
+	return time.Duration(dur).Seconds()
+}
+
+// Milliseconds returns the duration as an integer millisecond count.
+func (dur Duration) Milliseconds() int64 {
+	return int64(time.Duration(dur) / time.Millisecond)
+}
+
+// Add returns the sum of dur and d.
+func (dur Duration) Add(d Duration) Duration {
+	return dur + d
+}
+
+// Sub returns dur minus d.
+func (dur Duration) Sub(d Duration) Duration {
+	return dur - d
+}
+
+// String returns the duration in the VAST hh:mm:ss(.mmm) format.
+func (dur Duration) String() string {
+	var buf [16]byte
+	return string(dur.appendText(buf[:0]))
+}
+
+// MarshalXMLAttr implements the xml.MarshalerAttr interface, so durations are
+// formatted the same way in attribute (e.g. Icon duration) and element
+// (e.g. Linear Duration) positions.
+func (dur Duration) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	if dur < 0 {
+		return xml.Attr{}, fmt.Errorf("invalid duration: %v", time.Duration(dur))
+	}
+	var buf [16]byte
+	return xml.Attr{Name: name, Value: string(dur.appendText(buf[:0]))}, nil
+}
+
+// UnmarshalXMLAttr implements the xml.UnmarshalerAttr interface.
+func (dur *Duration) UnmarshalXMLAttr(attr xml.Attr) error {
+	d, ok := parseDuration(attr.Value)
+	if !ok {
+		*dur = 0
+		return fmt.Errorf("invalid duration: %s", attr.Value)
+	}
+	*dur = d
+	return nil
+}
+
 // MarshalText implements the encoding.TextMarshaler interface.
+// The duration is rounded to the millisecond.
 func (dur Duration) MarshalText() ([]byte, error) {
-	h := dur / Duration(time.Hour)
-	m := dur % Duration(time.Hour) / Duration(time.Minute)
-	s := dur % Duration(time.Minute) / Duration(time.Second)
-	ms := dur % Duration(time.Second) / Duration(time.Millisecond)
-	if ms == 0 {
-		return []byte(fmt.Sprintf("%02d:%02d:%02d", h, m, s)), nil
+	if dur < 0 {
+		return nil, fmt.Errorf("invalid duration: %v", time.Duration(dur))
+	}
+	return dur.appendText(make([]byte, 0, 12)), nil
+}
+
+// appendText appends the hh:mm:ss(.mmm) form of the non-negative duration to
+// b.
+func (dur Duration) appendText(b []byte) []byte {
+	dur = FromDuration(time.Duration(dur))
+	h := int64(dur / Duration(time.Hour))
+	m := int64(dur % Duration(time.Hour) / Duration(time.Minute))
+	s := int64(dur % Duration(time.Minute) / Duration(time.Second))
+	ms := int64(dur % Duration(time.Second) / Duration(time.Millisecond))
+	if h < 10 {
+		b = append(b, '0')
+	}
+	b = strconv.AppendInt(b, h, 10)
+	b = append(b, ':', byte('0'+m/10), byte('0'+m%10), ':', byte('0'+s/10), byte('0'+s%10))
+	if ms != 0 {
+		b = append(b, '.', byte('0'+ms/100), byte('0'+ms/10%10), byte('0'+ms%10))
 	}
-	return []byte(fmt.Sprintf("%02d:%02d:%02d.%03d", h, m, s, ms)), nil
+	return b
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
-func (dur *Duration) UnmarshalText(data []byte) (err error) {
-	s := string(data)
-	s = strings.TrimSpace(s)
-	if s == "" || strings.ToLower(s) == "undefined" {
+func (dur *Duration) UnmarshalText(data []byte) error {
+	d, ok := parseDuration(string(data))
+	if !ok {
 		*dur = 0
-		return nil
-	}
-	parts := strings.SplitN(s, ":", 3)
-	if len(parts) != 3 {
 		return fmt.Errorf("invalid duration: %s", data)
 	}
-	if i := strings.IndexByte(parts[2], '.'); i > 0 {
-		ms, err := strconv.ParseInt(parts[2][i+1:], 10, 32)
-		if err != nil || ms < 0 || ms > 999 {
-			return fmt.Errorf("invalid duration: %s", data)
+	*dur = d
+	return nil
+}
+
+// parseDuration parses a duration in the hh:mm:ss(.mmm) format, surrounded
+// by optional white space. An empty or "undefined" duration is 0.
+//
+// It is called for every duration and offset of the documents, so it
+// doesn't allocate: s is never retained, letting the callers convert byte
+// slices on the stack.
+func parseDuration(s string) (Duration, bool) {
+	for len(s) > 0 && isSpace(s[0]) {
+		s = s[1:]
+	}
+	for len(s) > 0 && isSpace(s[len(s)-1]) {
+		s = s[:len(s)-1]
+	}
+	if s == "" || isUndefined(s) {
+		return 0, true
+	}
+	// hours, minutes, seconds and milliseconds
+	var fields [4]int64
+	field, digits := 0, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			if fields[field] > 999 {
+				return 0, false
+			}
+			fields[field] = fields[field]*10 + int64(c-'0')
+			digits++
+		case c == ':' && field < 2 && digits > 0:
+			field, digits = field+1, 0
+		case c == '.' && field == 2 && digits > 0:
+			field, digits = field+1, 0
+		default:
+			return 0, false
 		}
-		parts[2] = parts[2][:i]
-		*dur += Duration(ms) * Duration(time.Millisecond)
-	}
-	f := Duration(time.Second)
-	for i := 2; i >= 0; i-- {
-		n, err := strconv.ParseInt(parts[i], 10, 32)
-		if err != nil || n < 0 || n > 59 {
-			return fmt.Errorf("invalid duration: %s", data)
+	}
+	if field < 2 || digits == 0 {
+		return 0, false
+	}
+	if fields[0] > 59 || fields[1] > 59 || fields[2] > 59 || fields[3] > 999 {
+		return 0, false
+	}
+	return Duration(fields[0])*Duration(time.Hour) +
+		Duration(fields[1])*Duration(time.Minute) +
+		Duration(fields[2])*Duration(time.Second) +
+		Duration(fields[3])*Duration(time.Millisecond), true
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// isUndefined returns true if s is "undefined", in any case.
+func isUndefined(s string) bool {
+	const undefined = "undefined"
+	if len(s) != len(undefined) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i]|0x20 != undefined[i] {
+			return false
 		}
-		*dur += Duration(n) * f
-		f *= 60
 	}
-	return nil
+	return true
 }
@@ -1,6 +1,7 @@
 package vast
 
 import (
+	"encoding/xml"
 	"testing"
 	"time"
 
@@ -69,3 +70,96 @@ func TestDurationUnmarshal(t *testing.T) {
 	assert.EqualError(t, d.UnmarshalText([]byte("00:00:00.1000")), "invalid duration: 00:00:00.1000")
 	assert.EqualError(t, d.UnmarshalText([]byte("00h01m")), "invalid duration: 00h01m")
 }
+
+func TestDurationInterop(t *testing.T) {
+	d := FromDuration(1500*time.Millisecond + 600*time.Microsecond)
+	assert.Equal(t, Duration(1501*time.Millisecond), d)
+	assert.Equal(t, 1501*time.Millisecond, d.ToDuration())
+	assert.Equal(t, int64(1501), d.Milliseconds())
+	assert.InDelta(t, 1.501, d.Seconds(), 1e-9)
+	assert.Equal(t, Duration(2*time.Second), d.Add(Duration(499*time.Millisecond)))
+	assert.Equal(t, Duration(time.Second), d.Sub(Duration(501*time.Millisecond)))
+	assert.Equal(t, "00:00:01.501", d.String())
+
+	// marshaling rounds to the millisecond
+	b, err := Duration(1999600 * time.Microsecond).MarshalText()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "00:00:02", string(b))
+	}
+	_, err = Duration(-time.Second).MarshalText()
+	assert.EqualError(t, err, "invalid duration: -1s")
+
+	// unmarshaling overwrites the previous value
+	if assert.NoError(t, d.UnmarshalText([]byte("00:00:03"))) {
+		assert.Equal(t, Duration(3*time.Second), d)
+	}
+}
+
+func TestDurationXMLPositions(t *testing.T) {
+	icon := Icon{Duration: Duration(5500 * time.Millisecond)}
+	linear := Linear{Duration: Duration(90 * time.Second)}
+
+	b, err := xml.Marshal(icon)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(b), `duration="00:00:05.500"`)
+	}
+	b, err = xml.Marshal(linear)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(b), `<Duration>00:01:30</Duration>`)
+	}
+
+	var parsed Icon
+	if assert.NoError(t, xml.Unmarshal([]byte(`<Icon duration="00:00:05.500"></Icon>`), &parsed)) {
+		assert.Equal(t, icon.Duration, parsed.Duration)
+	}
+	assert.Error(t, xml.Unmarshal([]byte(`<Icon duration="5s"></Icon>`), &parsed))
+}
+
+func TestDurationUnmarshalEdgeCases(t *testing.T) {
+	var d Duration
+	for s, want := range map[string]Duration{
+		"1:2:3":            Duration(time.Hour + 2*time.Minute + 3*time.Second),
+		"00:00:01.5":       Duration(time.Second + 5*time.Millisecond),
+		"\t00:00:01.500\n": Duration(1500 * time.Millisecond),
+		"UNDEFINED":        0,
+		"00:00:00.0000005": Duration(5 * time.Millisecond),
+	} {
+		if assert.NoError(t, d.UnmarshalText([]byte(s)), s) {
+			assert.Equal(t, want, d, s)
+		}
+	}
+	for _, s := range []string{"00:00", "00:00:00:00", "00:00:00.", ":00:00", "00::00", "00:00:.5", "60:00:00", "+1:00:00", "00:00:00.99999999999999999999", "undefine"} {
+		assert.EqualError(t, d.UnmarshalText([]byte(s)), "invalid duration: "+s)
+		assert.Equal(t, Duration(0), d)
+	}
+	assert.Equal(t, "100:00:00", Duration(100*time.Hour).String())
+}
+
+func TestDurationAllocs(t *testing.T) {
+	var d Duration
+	text := []byte("00:01:30.250")
+	attr := xml.Attr{Value: "00:01:30.250"}
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() { _ = d.UnmarshalText(text) }))
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() { _ = d.UnmarshalXMLAttr(attr) }))
+}
+
+func BenchmarkDurationUnmarshalText(b *testing.B) {
+	text := []byte("00:01:30.250")
+	var d Duration
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := d.UnmarshalText(text); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDurationMarshalText(b *testing.B) {
+	d := Duration(90250 * time.Millisecond)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := d.MarshalText(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package vast
+
+import "strings"
+
+// EmptyResponse returns the "no ad" document of the given version, served
+// when no ad is available. The error tracking URIs are set as document level
+// Error elements, which players fire with ErrorWrapperNoAd (303) as no ad
+// was found, such as with FireNoAd of the track package.
+func EmptyResponse(version SpecVersion, errorURLs ...string) *VAST {
+	v := &VAST{Version: string(version)}
+	for _, u := range errorURLs {
+		if u = strings.TrimSpace(u); u != "" {
+			v.Errors = append(v.Errors, CDATAURI{URI: URI(u)})
+		}
+	}
+	return v
+}
+
+// IsEmpty returns true if the document has no ad.
+func (v *VAST) IsEmpty() bool {
+	return len(v.Ads) == 0
+}
@@ -0,0 +1,21 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyResponse(t *testing.T) {
+	v := EmptyResponse(Version4_1, "https://pub.example.com/err?code=[ERRORCODE]", " ")
+	assert.True(t, v.IsEmpty())
+	assert.Equal(t, []string{"https://pub.example.com/err?code=303"}, v.ErrorURLs(ErrorWrapperNoAd))
+	b, err := xml.Marshal(v)
+	assert.NoError(t, err)
+	assert.Equal(t, `<VAST version="4.1"><Error><![CDATA[https://pub.example.com/err?code=[ERRORCODE]]]></Error></VAST>`, string(b))
+
+	b, err = xml.Marshal(EmptyResponse(Version2_0))
+	assert.NoError(t, err)
+	assert.Equal(t, `<VAST version="2.0"></VAST>`, string(b))
+}
@@ -0,0 +1,67 @@
+package vast
+
+import "strings"
+
+// Delivery is the method of delivery of a media file.
+type Delivery string
+
+// Delivery methods
+const (
+	DeliveryProgressive Delivery = "progressive"
+	DeliveryStreaming   Delivery = "streaming"
+)
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// Known values are matched case-insensitively.
+func (d *Delivery) UnmarshalText(data []byte) error {
+	*d = Delivery(normalizeEnum(string(data), string(DeliveryProgressive), string(DeliveryStreaming)))
+	return nil
+}
+
+// AdType identifies the type of ad, as found in the adType attribute of an Ad
+// element (VAST 4.1+).
+type AdType string
+
+// Ad types
+const (
+	AdTypeVideo  AdType = "video"
+	AdTypeAudio  AdType = "audio"
+	AdTypeHybrid AdType = "hybrid"
+)
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// Known values are matched case-insensitively.
+func (t *AdType) UnmarshalText(data []byte) error {
+	*t = AdType(normalizeEnum(string(data), string(AdTypeVideo), string(AdTypeAudio), string(AdTypeHybrid)))
+	return nil
+}
+
+// PricingModel is the pricing model of a Pricing element.
+type PricingModel string
+
+// Pricing models
+const (
+	PricingCPM PricingModel = "cpm"
+	PricingCPC PricingModel = "cpc"
+	PricingCPE PricingModel = "cpe"
+	PricingCPV PricingModel = "cpv"
+)
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// Known values are matched case-insensitively.
+func (m *PricingModel) UnmarshalText(data []byte) error {
+	*m = PricingModel(normalizeEnum(string(data), string(PricingCPM), string(PricingCPC), string(PricingCPE), string(PricingCPV)))
+	return nil
+}
+
+// normalizeEnum returns the known value matching s case-insensitively, or s
+// unchanged if there's none.
+func normalizeEnum(s string, known ...string) string {
+	t := strings.TrimSpace(s)
+	for _, k := range known {
+		if strings.EqualFold(t, k) {
+			return k
+		}
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnumsUnmarshal(t *testing.T) {
+	var ad Ad
+	if assert.NoError(t, xml.Unmarshal([]byte(`<Ad adType="Audio"></Ad>`), &ad)) {
+		assert.Equal(t, AdTypeAudio, ad.AdType)
+	}
+
+	var mf MediaFile
+	if assert.NoError(t, xml.Unmarshal([]byte(`<MediaFile delivery=" Streaming " type="video/mp4"></MediaFile>`), &mf)) {
+		assert.Equal(t, DeliveryStreaming, mf.Delivery)
+	}
+
+	var p Pricing
+	if assert.NoError(t, xml.Unmarshal([]byte(`<Pricing model="CPM" currency="USD">1.5</Pricing>`), &p)) {
+		assert.Equal(t, PricingCPM, p.Model)
+	}
+
+	// unknown values are kept as is
+	if assert.NoError(t, xml.Unmarshal([]byte(`<Pricing model="Flat" currency="USD">1</Pricing>`), &p)) {
+		assert.Equal(t, PricingModel("Flat"), p.Model)
+	}
+}
+
+func TestEnumsMarshal(t *testing.T) {
+	b, err := xml.Marshal(Pricing{Model: PricingCPV, Currency: "EUR", Value: "2"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `<Pricing model="cpv" currency="EUR"><![CDATA[2]]></Pricing>`, string(b))
+	}
+}
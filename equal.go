@@ -0,0 +1,47 @@
+package vast
+
+import "reflect"
+
+// EqualOption changes what Equal considers significant.
+type EqualOption func(*comparer)
+
+// StrictOrder makes the order of sibling trackers, impressions and error URIs
+// significant.
+func StrictOrder() EqualOption {
+	return func(c *comparer) { c.unordered = false }
+}
+
+// StrictWhitespace makes the whitespace surrounding text content significant.
+func StrictWhitespace() EqualOption {
+	return func(c *comparer) { c.trim = false }
+}
+
+// StrictDefaults makes an absent attribute or element different from one set
+// to its default value.
+func StrictDefaults() EqualOption {
+	return func(c *comparer) { c.defaults = false }
+}
+
+// attrDefaults are the values of the attributes defined by the spec when
+// absent, by type and field name.
+var attrDefaults = map[reflect.Type]map[string]string{
+	reflect.TypeOf(Ad{}):                  {"AdType": string(AdTypeVideo), "ConditionalAd": "false"},
+	reflect.TypeOf(Wrapper{}):             {"FollowAdditionalWrappers": "true", "AllowMultipleAds": "false", "FallbackOnNoAd": "false"},
+	reflect.TypeOf(CompanionAds{}):        {"Required": "none"},
+	reflect.TypeOf(CompanionAdsWrapper{}): {"Required": "none"},
+	reflect.TypeOf(MediaFile{}):           {"MediaType": "2D"},
+	reflect.TypeOf(Mezzanine{}):           {"MediaType": "2D"},
+}
+
+// Equal reports whether a and b are semantically equal. Unless changed by
+// opts, the order of sibling trackers, impressions and error URIs, the
+// whitespace surrounding text content, and absent attributes or elements
+// compared to ones set to their default or zero value are not significant.
+func Equal(a, b *VAST, opts ...EqualOption) bool {
+	c := comparer{unordered: true, trim: true, defaults: true}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	c.compare("VAST", reflect.ValueOf(a), reflect.ValueOf(b))
+	return len(c.changes) == 0
+}
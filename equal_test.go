@@ -0,0 +1,80 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqual(t *testing.T) {
+	a, _, _, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, Equal(a, a.Clone()))
+	assert.True(t, Equal(nil, nil))
+	assert.False(t, Equal(a, nil))
+
+	b := a.Clone()
+	in := b.Ads[0].InLine
+	in.Impressions[0], in.Impressions[1] = in.Impressions[1], in.Impressions[0]
+	assert.True(t, Equal(a, b))
+	assert.False(t, Equal(a, b, StrictOrder()))
+
+	b = a.Clone()
+	b.Ads[0].InLine.Errors[0].URI = "\n  " + b.Ads[0].InLine.Errors[0].URI + "  \n"
+	assert.True(t, Equal(a, b))
+	assert.False(t, Equal(a, b, StrictWhitespace()))
+
+	b = a.Clone()
+	b.Ads[0].InLine.Creatives[0].Linear.VideoClicks.ClickTrackings[0].URI = "http://t/other"
+	assert.False(t, Equal(a, b))
+}
+
+func TestEqualDefaults(t *testing.T) {
+	a := &VAST{Ads: []Ad{{InLine: &InLine{Creatives: []Creative{
+		{CompanionAds: &CompanionAds{}},
+		{Linear: &Linear{}},
+	}}}}}
+	b := a.Clone()
+	b.Ads[0].InLine.Creatives[0].CompanionAds.Required = "none"
+	b.Ads[0].InLine.Creatives[1].Linear.VideoClicks = &VideoClicks{}
+	assert.True(t, Equal(a, b))
+	assert.False(t, Equal(a, b, StrictDefaults()))
+
+	b.Ads[0].InLine.Creatives[0].CompanionAds.Required = "all"
+	assert.False(t, Equal(a, b))
+}
+
+func TestEqualAttrDefaults(t *testing.T) {
+	wrapper := func(w *Wrapper) *VAST { return &VAST{Ads: []Ad{{Wrapper: w}}} }
+	absent := wrapper(&Wrapper{})
+	tests := []struct {
+		doc   *VAST
+		equal bool
+	}{
+		{wrapper(&Wrapper{FollowAdditionalWrappers: NewBool(true)}), true},
+		{wrapper(&Wrapper{FollowAdditionalWrappers: NewBool(false)}), false},
+		{wrapper(&Wrapper{AllowMultipleAds: NewBool(false)}), true},
+		{wrapper(&Wrapper{AllowMultipleAds: NewBool(true)}), false},
+		{wrapper(&Wrapper{FallbackOnNoAd: NewBool(false)}), true},
+		{wrapper(&Wrapper{FallbackOnNoAd: NewBool(true)}), false},
+	}
+	for i, tt := range tests {
+		assert.Equal(t, tt.equal, Equal(absent, tt.doc), "case %d", i)
+		assert.Equal(t, tt.equal, Equal(tt.doc, absent), "case %d", i)
+		assert.False(t, Equal(absent, tt.doc, StrictDefaults()), "case %d", i)
+	}
+
+	ad := func(typ AdType, conditional *Bool, mediaType string) *VAST {
+		return &VAST{Ads: []Ad{{AdType: typ, ConditionalAd: conditional, InLine: &InLine{Creatives: []Creative{{Linear: &Linear{
+			MediaFiles: []MediaFile{{MediaType: mediaType}},
+			Mezzanines: []Mezzanine{{MediaType: mediaType}},
+		}}}}}}}
+	}
+	assert.True(t, Equal(ad("", nil, ""), ad(AdTypeVideo, NewBool(false), "2D")))
+	assert.False(t, Equal(ad("", nil, ""), ad(AdTypeAudio, nil, "")))
+	assert.False(t, Equal(ad("", nil, ""), ad("", NewBool(true), "")))
+	assert.False(t, Equal(ad("", nil, ""), ad("", nil, "3D")))
+	assert.False(t, Equal(ad("", nil, ""), ad(AdTypeVideo, nil, ""), StrictDefaults()))
+}
@@ -0,0 +1,239 @@
+package vast
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ErrorCode is a VAST error code, reported to error tracking URIs through the
+// [ERRORCODE] macro.
+type ErrorCode int
+
+// Error codes defined by VAST 4.2
+const (
+	ErrorXMLParsing              ErrorCode = 100
+	ErrorSchemaValidation        ErrorCode = 101
+	ErrorVersionNotSupported     ErrorCode = 102
+	ErrorTrafficking             ErrorCode = 200
+	ErrorUnexpectedLinearity     ErrorCode = 201
+	ErrorUnexpectedDuration      ErrorCode = 202
+	ErrorUnexpectedSize          ErrorCode = 203
+	ErrorCategoryRequired        ErrorCode = 204
+	ErrorCategoryBlocked         ErrorCode = 205
+	ErrorAdBreakShortened        ErrorCode = 206
+	ErrorWrapper                 ErrorCode = 300
+	ErrorWrapperTimeout          ErrorCode = 301
+	ErrorWrapperLimit            ErrorCode = 302
+	ErrorWrapperNoAd             ErrorCode = 303
+	ErrorInLineTimeout           ErrorCode = 304
+	ErrorLinear                  ErrorCode = 400
+	ErrorMediaFileNotFound       ErrorCode = 401
+	ErrorMediaFileTimeout        ErrorCode = 402
+	ErrorMediaFileNotSupported   ErrorCode = 403
+	ErrorMediaFileDisplay        ErrorCode = 405
+	ErrorMezzanineRequired       ErrorCode = 406
+	ErrorMezzanineDownloading    ErrorCode = 407
+	ErrorConditionalAdRejected   ErrorCode = 408
+	ErrorInteractiveNotExecuted  ErrorCode = 409
+	ErrorVerificationNotExecuted ErrorCode = 410
+	ErrorMezzanineInvalid        ErrorCode = 411
+	ErrorNonLinear               ErrorCode = 500
+	ErrorNonLinearDimensions     ErrorCode = 501
+	ErrorNonLinearFetch          ErrorCode = 502
+	ErrorNonLinearNotSupported   ErrorCode = 503
+	ErrorCompanion               ErrorCode = 600
+	ErrorCompanionDimensions     ErrorCode = 601
+	ErrorCompanionRequired       ErrorCode = 602
+	ErrorCompanionFetch          ErrorCode = 603
+	ErrorCompanionNotSupported   ErrorCode = 604
+	ErrorUndefined               ErrorCode = 900
+	ErrorVPAID                   ErrorCode = 901
+	ErrorInteractiveCreativeFile ErrorCode = 902
+)
+
+var errorDescriptions = map[ErrorCode]string{
+	ErrorXMLParsing:              "XML parsing error",
+	ErrorSchemaValidation:        "VAST schema validation error",
+	ErrorVersionNotSupported:     "VAST version of response not supported",
+	ErrorTrafficking:             "Trafficking error, the ad type was not expected or cannot be played",
+	ErrorUnexpectedLinearity:     "Media player expecting different linearity",
+	ErrorUnexpectedDuration:      "Media player expecting different duration",
+	ErrorUnexpectedSize:          "Media player expecting different size",
+	ErrorCategoryRequired:        "Ad category was required but not provided",
+	ErrorCategoryBlocked:         "InLine category violates Wrapper BlockedAdCategories",
+	ErrorAdBreakShortened:        "Ad break shortened, ad was not served",
+	ErrorWrapper:                 "General Wrapper error",
+	ErrorWrapperTimeout:          "Timeout of VAST URI provided in Wrapper element",
+	ErrorWrapperLimit:            "Wrapper limit reached",
+	ErrorWrapperNoAd:             "No VAST response after one or more Wrappers",
+	ErrorInLineTimeout:           "InLine response failed to result in ad display within defined time limit",
+	ErrorLinear:                  "General Linear error",
+	ErrorMediaFileNotFound:       "File not found, unable to find Linear/MediaFile from URI",
+	ErrorMediaFileTimeout:        "Timeout of MediaFile URI",
+	ErrorMediaFileNotSupported:   "Couldn't find MediaFile that is supported by this media player",
+	ErrorMediaFileDisplay:        "Problem displaying MediaFile",
+	ErrorMezzanineRequired:       "Mezzanine was required but not provided, ad not served",
+	ErrorMezzanineDownloading:    "Mezzanine is in the process of being downloaded for the first time",
+	ErrorConditionalAdRejected:   "Conditional ad rejected",
+	ErrorInteractiveNotExecuted:  "Interactive unit in the InteractiveCreativeFile node was not executed",
+	ErrorVerificationNotExecuted: "Verification unit in the Verification node was not executed",
+	ErrorMezzanineInvalid:        "Mezzanine was provided as required, but file did not meet required specification",
+	ErrorNonLinear:               "General NonLinearAds error",
+	ErrorNonLinearDimensions:     "Unable to display NonLinear ad because creative dimensions do not align with creative display area",
+	ErrorNonLinearFetch:          "Unable to fetch NonLinearAds/NonLinear resource",
+	ErrorNonLinearNotSupported:   "Couldn't find NonLinear resource with supported type",
+	ErrorCompanion:               "General CompanionAds error",
+	ErrorCompanionDimensions:     "Unable to display Companion because creative dimensions do not fit within Companion display area",
+	ErrorCompanionRequired:       "Unable to display required Companion",
+	ErrorCompanionFetch:          "Unable to fetch CompanionAds/Companion resource",
+	ErrorCompanionNotSupported:   "Couldn't find Companion resource with supported type",
+	ErrorUndefined:               "Undefined error",
+	ErrorVPAID:                   "General VPAID error",
+	ErrorInteractiveCreativeFile: "General InteractiveCreativeFile error",
+}
+
+// String returns the description of the error code as given by the spec.
+func (e ErrorCode) String() string {
+	if d, ok := errorDescriptions[e]; ok {
+		return d
+	}
+	return "Unknown error " + strconv.Itoa(int(e))
+}
+
+// Known returns true if the error code is defined by the spec.
+func (e ErrorCode) Known() bool {
+	_, ok := errorDescriptions[e]
+	return ok
+}
+
+// ErrorCategory groups error codes by the part of the ad they relate to.
+type ErrorCategory int
+
+// Error categories, matching the hundreds of the error codes
+const (
+	ErrorCategoryUndefined ErrorCategory = iota
+	ErrorCategoryXML
+	ErrorCategoryTrafficking
+	ErrorCategoryWrapper
+	ErrorCategoryLinear
+	ErrorCategoryNonLinear
+	ErrorCategoryCompanion
+)
+
+// String implements the fmt.Stringer interface.
+func (c ErrorCategory) String() string {
+	switch c {
+	case ErrorCategoryXML:
+		return "XML"
+	case ErrorCategoryTrafficking:
+		return "Trafficking"
+	case ErrorCategoryWrapper:
+		return "Wrapper"
+	case ErrorCategoryLinear:
+		return "Linear"
+	case ErrorCategoryNonLinear:
+		return "NonLinear"
+	case ErrorCategoryCompanion:
+		return "Companion"
+	}
+	return "Undefined"
+}
+
+// Category returns the category of the error code.
+func (e ErrorCode) Category() ErrorCategory {
+	switch e / 100 {
+	case 1:
+		return ErrorCategoryXML
+	case 2:
+		return ErrorCategoryTrafficking
+	case 3:
+		return ErrorCategoryWrapper
+	case 4:
+		return ErrorCategoryLinear
+	case 5:
+		return ErrorCategoryNonLinear
+	case 6:
+		return ErrorCategoryCompanion
+	}
+	return ErrorCategoryUndefined
+}
+
+// errorCodeMacros are the spellings of the [ERRORCODE] macro found in error
+// tracking URIs, including the percent-encoded one, matched regardless of
+// case.
+var errorCodeMacros = []string{"[ERRORCODE]", "%5BERRORCODE%5D"}
+
+// ErrorURL returns uri with the [ERRORCODE] macro replaced by the code. The
+// macro is matched regardless of case, and so is its percent-encoded
+// spelling, such as "%5bErrorCode%5D".
+func (e ErrorCode) ErrorURL(uri string) string {
+	code := strconv.Itoa(int(e))
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(uri); i++ {
+		for _, m := range errorCodeMacros {
+			if len(uri)-i >= len(m) && strings.EqualFold(uri[i:i+len(m)], m) {
+				b.WriteString(uri[last:i])
+				b.WriteString(code)
+				i += len(m) - 1
+				last = i + 1
+				break
+			}
+		}
+	}
+	if last == 0 {
+		return uri
+	}
+	b.WriteString(uri[last:])
+	return b.String()
+}
+
+// ErrorURLs returns the error tracking URIs of the ad with the [ERRORCODE]
+// macro replaced by the code.
+func (a *Ad) ErrorURLs(code ErrorCode) []string {
+	return errorURLs(a.ErrorURIs(), code)
+}
+
+// ErrorURIs returns the error tracking URIs of the ad, macros unexpanded.
+func (a *Ad) ErrorURIs() []string {
+	switch {
+	case a.InLine != nil:
+		return errorURIs(a.InLine.Errors)
+	case a.Wrapper != nil:
+		return errorURIs(a.Wrapper.Errors)
+	}
+	return nil
+}
+
+// ErrorURLs returns the document level error tracking URIs, used for "no ad"
+// responses, with the [ERRORCODE] macro replaced by the code.
+func (v *VAST) ErrorURLs(code ErrorCode) []string {
+	return errorURLs(v.ErrorURIs(), code)
+}
+
+// ErrorURIs returns the document level error tracking URIs, macros
+// unexpanded.
+func (v *VAST) ErrorURIs() []string {
+	return errorURIs(v.Errors)
+}
+
+func errorURIs(errs []CDATAURI) []string {
+	var uris []string
+	for _, e := range errs {
+		if u := strings.TrimSpace(string(e.URI)); u != "" {
+			uris = append(uris, u)
+		}
+	}
+	return uris
+}
+
+func errorURLs(uris []string, code ErrorCode) []string {
+	if uris == nil {
+		return nil
+	}
+	urls := make([]string, len(uris))
+	for i, u := range uris {
+		urls[i] = code.ErrorURL(u)
+	}
+	return urls
+}
@@ -0,0 +1,42 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, "Wrapper limit reached", ErrorWrapperLimit.String())
+	assert.Equal(t, "Unknown error 404", ErrorCode(404).String())
+	assert.True(t, ErrorMezzanineInvalid.Known())
+	assert.False(t, ErrorCode(404).Known())
+
+	assert.Equal(t, ErrorCategoryXML, ErrorXMLParsing.Category())
+	assert.Equal(t, ErrorCategoryTrafficking, ErrorAdBreakShortened.Category())
+	assert.Equal(t, ErrorCategoryWrapper, ErrorWrapperNoAd.Category())
+	assert.Equal(t, ErrorCategoryLinear, ErrorMediaFileNotSupported.Category())
+	assert.Equal(t, ErrorCategoryNonLinear, ErrorNonLinearFetch.Category())
+	assert.Equal(t, ErrorCategoryCompanion, ErrorCompanionRequired.Category())
+	assert.Equal(t, ErrorCategoryUndefined, ErrorVPAID.Category())
+	assert.Equal(t, "Companion", ErrorCategoryCompanion.String())
+}
+
+func TestErrorURL(t *testing.T) {
+	assert.Equal(t, "http://e.example.com/?code=303", ErrorWrapperNoAd.ErrorURL("http://e.example.com/?code=[ERRORCODE]"))
+	assert.Equal(t, "http://e.example.com/?code=303", ErrorWrapperNoAd.ErrorURL("http://e.example.com/?code=%5BERRORCODE%5D"))
+	assert.Equal(t, "http://e.example.com/?code=303", ErrorWrapperNoAd.ErrorURL("http://e.example.com/?code=%5bERRORCODE%5d"))
+	assert.Equal(t, "http://e.example.com/?a=303&b=303", ErrorWrapperNoAd.ErrorURL("http://e.example.com/?a=[errorCode]&b=%5BErrorCode%5d"))
+	assert.Equal(t, "http://e.example.com/?code=[ERRORCODE", ErrorWrapperNoAd.ErrorURL("http://e.example.com/?code=[ERRORCODE"))
+	assert.Equal(t, "http://e.example.com/", ErrorWrapperNoAd.ErrorURL("http://e.example.com/"))
+
+	ad := Ad{InLine: &InLine{Errors: []CDATAURI{{"http://a/[ERRORCODE]"}, {" "}, {"http://b/"}}}}
+	assert.Equal(t, []string{"http://a/403", "http://b/"}, ad.ErrorURLs(ErrorMediaFileNotSupported))
+	ad = Ad{Wrapper: &Wrapper{Errors: []CDATAURI{{"http://w/[ERRORCODE]"}}}}
+	assert.Equal(t, []string{"http://w/301"}, ad.ErrorURLs(ErrorWrapperTimeout))
+
+	v := VAST{Errors: []CDATAURI{{"http://noad/[ERRORCODE]"}}}
+	assert.Equal(t, []string{"http://noad/303"}, v.ErrorURLs(ErrorWrapperNoAd))
+	assert.Equal(t, []string{"http://noad/[ERRORCODE]"}, v.ErrorURIs())
+	assert.Nil(t, (&Ad{}).ErrorURIs())
+}
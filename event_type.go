@@ -1,5 +1,7 @@
 package vast
 
+import "strconv"
+
 const (
 	/**
 	 * not to be confused with an impression, this event indicates that an individual creative
@@ -89,3 +91,126 @@ const (
 
 	Event_type_monitor = "monitor"
 )
+
+// EventType is the name of an event tracked by a <Tracking> element.
+type EventType string
+
+// Tracking events defined by VAST 4.2
+const (
+	EventCreativeView           EventType = "creativeView"
+	EventLoaded                 EventType = "loaded"
+	EventStart                  EventType = "start"
+	EventFirstQuartile          EventType = "firstQuartile"
+	EventMidpoint               EventType = "midpoint"
+	EventThirdQuartile          EventType = "thirdQuartile"
+	EventComplete               EventType = "complete"
+	EventProgress               EventType = "progress"
+	EventMute                   EventType = "mute"
+	EventUnmute                 EventType = "unmute"
+	EventPause                  EventType = "pause"
+	EventResume                 EventType = "resume"
+	EventRewind                 EventType = "rewind"
+	EventSkip                   EventType = "skip"
+	EventCloseLinear            EventType = "closeLinear"
+	EventFullscreen             EventType = "fullscreen"
+	EventExitFullscreen         EventType = "exitFullscreen"
+	EventPlayerExpand           EventType = "playerExpand"
+	EventPlayerCollapse         EventType = "playerCollapse"
+	EventExpand                 EventType = "expand"
+	EventCollapse               EventType = "collapse"
+	EventAcceptInvitationLinear EventType = "acceptInvitationLinear"
+	EventAcceptInvitation       EventType = "acceptInvitation"
+	EventAdExpand               EventType = "adExpand"
+	EventAdCollapse             EventType = "adCollapse"
+	EventMinimize               EventType = "minimize"
+	EventClose                  EventType = "close"
+	EventOverlayViewDuration    EventType = "overlayViewDuration"
+	EventTimeSpentViewing       EventType = "timeSpentViewing"
+	EventOtherAdInteraction     EventType = "otherAdInteraction"
+	EventInteractiveStart       EventType = "interactiveStart"
+	EventNotUsed                EventType = "notUsed"
+	// Fired when an AdVerifications script could not be executed
+	EventVerificationNotExecuted EventType = "verificationNotExecuted"
+)
+
+// EventContext is the kind of element an event is tracked for.
+type EventContext int
+
+// Contexts in which tracking events are defined
+const (
+	LinearContext EventContext = iota
+	NonLinearContext
+	CompanionContext
+	VerificationContext
+)
+
+// playerEvents are the player operation events tracked for both linear and
+// non-linear creatives.
+var playerEvents = []EventType{
+	EventMute, EventUnmute, EventPause, EventResume, EventRewind, EventSkip,
+	EventFullscreen, EventExitFullscreen, EventPlayerExpand, EventPlayerCollapse,
+	EventOtherAdInteraction, EventInteractiveStart, EventNotUsed,
+}
+
+var eventContexts = map[EventContext]map[EventType]bool{
+	LinearContext: eventSet(playerEvents,
+		EventCreativeView, EventLoaded, EventStart, EventFirstQuartile, EventMidpoint,
+		EventThirdQuartile, EventComplete, EventProgress, EventCloseLinear, EventExpand,
+		EventCollapse, EventAcceptInvitationLinear, EventTimeSpentViewing,
+	),
+	NonLinearContext: eventSet(playerEvents,
+		EventCreativeView, EventAcceptInvitation, EventAdExpand, EventAdCollapse,
+		EventMinimize, EventClose, EventOverlayViewDuration, EventExpand, EventCollapse,
+	),
+	CompanionContext:    eventSet(nil, EventCreativeView),
+	VerificationContext: eventSet(nil, EventVerificationNotExecuted),
+}
+
+func eventSet(base []EventType, events ...EventType) map[EventType]bool {
+	set := make(map[EventType]bool, len(base)+len(events))
+	for _, e := range base {
+		set[e] = true
+	}
+	for _, e := range events {
+		set[e] = true
+	}
+	return set
+}
+
+// IsValidFor returns true if the event can be tracked in the given context.
+func (e EventType) IsValidFor(ctx EventContext) bool {
+	return eventContexts[ctx][e]
+}
+
+// String implements the fmt.Stringer interface.
+func (e EventType) String() string {
+	return string(e)
+}
+
+// VerificationReason is the reason why a verification script was not
+// executed, reported to verificationNotExecuted tracking URIs through the
+// [REASON] macro.
+type VerificationReason int
+
+// Verification reasons defined by VAST 4.1, matching the OM SDK ones
+const (
+	// The verification resource was rejected by the player
+	VerificationResourceRejected VerificationReason = 1
+	// The API framework or version of the verification isn't supported
+	VerificationNotSupported VerificationReason = 2
+	// An error occurred while loading the verification resource
+	VerificationLoadError VerificationReason = 3
+)
+
+// String implements the fmt.Stringer interface.
+func (r VerificationReason) String() string {
+	switch r {
+	case VerificationResourceRejected:
+		return "Verification resource rejected"
+	case VerificationNotSupported:
+		return "Verification not supported"
+	case VerificationLoadError:
+		return "Error during resource load"
+	}
+	return "Unknown reason " + strconv.Itoa(int(r))
+}
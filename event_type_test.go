@@ -0,0 +1,37 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventTypeIsValidFor(t *testing.T) {
+	assert.True(t, EventStart.IsValidFor(LinearContext))
+	assert.True(t, EventProgress.IsValidFor(LinearContext))
+	assert.True(t, EventSkip.IsValidFor(LinearContext))
+	assert.True(t, EventSkip.IsValidFor(NonLinearContext))
+	assert.True(t, EventCreativeView.IsValidFor(CompanionContext))
+	assert.True(t, EventAdCollapse.IsValidFor(NonLinearContext))
+	assert.True(t, EventVerificationNotExecuted.IsValidFor(VerificationContext))
+
+	assert.False(t, EventStart.IsValidFor(NonLinearContext))
+	assert.False(t, EventStart.IsValidFor(CompanionContext))
+	assert.False(t, EventClose.IsValidFor(LinearContext))
+	assert.False(t, EventCloseLinear.IsValidFor(NonLinearContext))
+	assert.False(t, EventVerificationNotExecuted.IsValidFor(LinearContext))
+	assert.False(t, EventType("monitor").IsValidFor(LinearContext))
+	assert.False(t, EventStart.IsValidFor(EventContext(42)))
+}
+
+func TestEventTypeCompat(t *testing.T) {
+	// legacy untyped constants remain usable where an EventType is expected
+	var e EventType = Event_type_firstQuartile
+	assert.Equal(t, EventFirstQuartile, e)
+	assert.Equal(t, "firstQuartile", e.String())
+}
+
+func TestVerificationReason(t *testing.T) {
+	assert.Equal(t, "Verification not supported", VerificationNotSupported.String())
+	assert.Equal(t, "Unknown reason 42", VerificationReason(42).String())
+}
@@ -0,0 +1,76 @@
+package vast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expires is the number of seconds an InLine ad can be cached after the
+// response was received (VAST 4.1+).
+type Expires int
+
+// NewExpires returns a pointer to an Expires of d, truncated to the second.
+func NewExpires(d time.Duration) *Expires {
+	e := Expires(d / time.Second)
+	return &e
+}
+
+// Duration returns the caching period as a time.Duration.
+func (e Expires) Duration() time.Duration {
+	return time.Duration(e) * time.Second
+}
+
+// ExpiresAt returns the time the ad expires given the time the response was
+// received.
+func (e Expires) ExpiresAt(received time.Time) time.Time {
+	return received.Add(e.Duration())
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (e Expires) MarshalText() ([]byte, error) {
+	if e < 0 {
+		return nil, fmt.Errorf("invalid expires: %d", int(e))
+	}
+	return []byte(strconv.Itoa(int(e))), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (e *Expires) UnmarshalText(data []byte) error {
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid expires: %q", string(data))
+	}
+	*e = Expires(n)
+	return nil
+}
+
+// ExpiresAt returns the earliest expiry of the InLine ads of the document,
+// computed from ReceivedAt. It returns false if ReceivedAt is not set or no
+// ad carries an Expires element.
+func (v *VAST) ExpiresAt() (time.Time, bool) {
+	var at time.Time
+	found := false
+	if v.ReceivedAt.IsZero() {
+		return at, false
+	}
+	for _, ad := range v.Ads {
+		if ad.InLine == nil || ad.InLine.Expires == nil {
+			continue
+		}
+		t := ad.InLine.Expires.ExpiresAt(v.ReceivedAt)
+		if !found || t.Before(at) {
+			at, found = t, true
+		}
+	}
+	return at, found
+}
+
+// IsExpired returns true if any InLine ad of the document has expired at now,
+// in which case a cached copy of the response should be evicted. A document
+// without ReceivedAt or without any Expires element never expires.
+func (v *VAST) IsExpired(now time.Time) bool {
+	at, ok := v.ExpiresAt()
+	return ok && !now.Before(at)
+}
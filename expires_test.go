@@ -0,0 +1,61 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiresMarshal(t *testing.T) {
+	var e Expires
+	assert.NoError(t, e.UnmarshalText([]byte(" 3600 ")))
+	assert.Equal(t, Expires(3600), e)
+	assert.Equal(t, time.Hour, e.Duration())
+	assert.EqualError(t, e.UnmarshalText([]byte("-1")), `invalid expires: "-1"`)
+	assert.EqualError(t, e.UnmarshalText([]byte("1h")), `invalid expires: "1h"`)
+
+	b, err := Expires(60).MarshalText()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "60", string(b))
+	}
+	_, err = Expires(-1).MarshalText()
+	assert.EqualError(t, err, "invalid expires: -1")
+
+	assert.Equal(t, Expires(90), *NewExpires(90*time.Second + 500*time.Millisecond))
+}
+
+func TestIsExpired(t *testing.T) {
+	raw := `<VAST version="4.1"><Ad><InLine><AdSystem>DSP</AdSystem><AdTitle>a</AdTitle><Expires>3600</Expires></InLine></Ad>` +
+		`<Ad><InLine><AdSystem>DSP</AdSystem><AdTitle>b</AdTitle><Expires>600</Expires></InLine></Ad>` +
+		`<Ad><InLine><AdSystem>DSP</AdSystem><AdTitle>c</AdTitle></InLine></Ad></VAST>`
+	var v VAST
+	if !assert.NoError(t, xml.Unmarshal([]byte(raw), &v)) {
+		return
+	}
+	assert.Equal(t, Expires(3600), *v.Ads[0].InLine.Expires)
+	assert.Nil(t, v.Ads[2].InLine.Expires)
+
+	// unknown reception time
+	_, ok := v.ExpiresAt()
+	assert.False(t, ok)
+	assert.False(t, v.IsExpired(time.Now().Add(24*time.Hour)))
+
+	received := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	v.ReceivedAt = received
+	at, ok := v.ExpiresAt()
+	assert.True(t, ok)
+	assert.Equal(t, received.Add(10*time.Minute), at)
+	assert.Equal(t, received.Add(time.Hour), v.Ads[0].InLine.Expires.ExpiresAt(received))
+	assert.False(t, v.IsExpired(received.Add(9*time.Minute)))
+	assert.True(t, v.IsExpired(received.Add(10*time.Minute)))
+
+	v.Ads = v.Ads[2:]
+	assert.False(t, v.IsExpired(received.Add(24*time.Hour)))
+
+	b, err := xml.Marshal(VAST{Version: "4.1", Ads: []Ad{{InLine: &InLine{Expires: NewExpires(time.Minute)}}}})
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(b), "<Expires>60</Expires>")
+	}
+}
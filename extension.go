@@ -8,14 +8,68 @@ type Extension struct {
 	Type           string     `xml:"type,attr,omitempty"`
 	CustomTracking []Tracking `xml:"CustomTracking>Tracking,omitempty"  json:",omitempty"`
 	Data           string     `xml:",innerxml" json:",omitempty"`
+	// Any other attribute of the extension, kept as is
+	Attrs []xml.Attr `xml:",any,attr" json:",omitempty"`
+	// The content of an extension of a type registered by RegisterExtension
+	// or RegisterExtensionElement, a pointer to a value of that type. When
+	// set, it's encoded in place of Data.
+	Content interface{} `xml:"-" json:"-"`
+}
+
+// NewExtension returns an extension of the given type holding v encoded
+// as XML.
+func NewExtension(typ string, v interface{}) (Extension, error) {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		return Extension{}, err
+	}
+	return Extension{Type: typ, Data: string(b)}, nil
+}
+
+// Decode decodes the inner XML of the extension into v, the same way
+// xml.Unmarshal would decode the <Extension> element itself: the fields of v
+// are matched against the children of the extension. A well-known extension
+// is decoded by its own decoder when v points to its type, such as a *Geo
+// for a geo extension.
+func (e *Extension) Decode(v interface{}) error {
+	if ok, err := e.decodeTyped(v); ok {
+		return err
+	}
+	return e.decodeData(v)
+}
+
+// findExtension returns the first extension of the given type, or nil.
+func findExtension(exts []Extension, typ string) *Extension {
+	for i := range exts {
+		if exts[i].Type == typ {
+			return &exts[i]
+		}
+	}
+	return nil
+}
+
+// Extension returns the first extension of the given type, or nil if there
+// is none.
+func (in *InLine) Extension(typ string) *Extension {
+	if in.Extensions == nil {
+		return nil
+	}
+	return findExtension(*in.Extensions, typ)
+}
+
+// Extension returns the first extension of the given type, or nil if there
+// is none.
+func (w *Wrapper) Extension(typ string) *Extension {
+	return findExtension(w.Extensions, typ)
 }
 
 // the extension type as a middleware in the encoding process.
 type extension Extension
 
 type extensionNoCT struct {
-	Type string `xml:"type,attr,omitempty"`
-	Data string `xml:",innerxml" json:",omitempty"`
+	Type  string     `xml:"type,attr,omitempty"`
+	Data  string     `xml:",innerxml" json:",omitempty"`
+	Attrs []xml.Attr `xml:",any,attr" json:",omitempty"`
 }
 
 // MarshalXML implements xml.Marshaler interface.
@@ -25,15 +79,47 @@ func (e Extension) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
 	var e2 interface{}
 	// if we have custom trackers, we should ignore the data, if not, then we
 	// should consider only the data.
-	if len(e.CustomTracking) > 0 {
-		e2 = extension{Type: e.Type, CustomTracking: e.CustomTracking}
-	} else {
-		e2 = extensionNoCT{Type: e.Type, Data: e.Data}
+	attrs := encodableAttrs(e.Attrs)
+	switch {
+	case len(e.CustomTracking) > 0:
+		e2 = extension{Type: e.Type, CustomTracking: e.CustomTracking, Attrs: attrs}
+	case e.Content != nil:
+		typ, data, err := e.encodeContent()
+		if err != nil {
+			return err
+		}
+		e2 = extensionNoCT{Type: typ, Data: data, Attrs: attrs}
+	default:
+		e2 = extensionNoCT{Type: e.Type, Data: e.Data, Attrs: attrs}
 	}
 
 	return enc.EncodeElement(e2, start)
 }
 
+// encodableAttrs returns attrs with the namespace declarations, decoded with
+// the "xmlns" space, named as written in the document: encoding/xml would
+// otherwise declare a prefix for the "xmlns" space itself, leaving the
+// prefixes used by the inner XML unbound.
+func encodableAttrs(attrs []xml.Attr) []xml.Attr {
+	var out []xml.Attr
+	for i, a := range attrs {
+		if a.Name.Space != "xmlns" {
+			if out != nil {
+				out = append(out, a)
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]xml.Attr, 0, len(attrs)), attrs[:i]...)
+		}
+		out = append(out, xml.Attr{Name: xml.Name{Local: "xmlns:" + a.Name.Local}, Value: a.Value})
+	}
+	if out == nil {
+		return attrs
+	}
+	return out
+}
+
 // UnmarshalXML implements xml.Unmarshaler interface.
 func (e *Extension) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
 	// decode the extension into a temporary element from a wrapper Extension,
@@ -42,12 +128,14 @@ func (e *Extension) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error
 	if err := dec.DecodeElement(&e2, &start); err != nil {
 		return err
 	}
-	// copy the type and the customTracking
+	// copy the type, the other attributes and the customTracking
 	e.Type = e2.Type
+	e.Attrs = e2.Attrs
 	e.CustomTracking = e2.CustomTracking
 	// copy the data only of customTracking is empty
 	if len(e.CustomTracking) == 0 {
 		e.Data = e2.Data
+		e.decodeContent()
 	}
 	return nil
 }
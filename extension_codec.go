@@ -0,0 +1,180 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// extensionCodecs holds the extension types registered by RegisterExtension
+// and RegisterExtensionElement.
+var extensionCodecs = struct {
+	sync.RWMutex
+	// by lower case type attribute
+	types map[string]registeredExtension
+	// by root element name
+	elements map[string]reflect.Type
+}{types: map[string]registeredExtension{}, elements: map[string]reflect.Type{}}
+
+type registeredExtension struct {
+	// the type attribute as registered
+	name string
+	typ  reflect.Type
+}
+
+// RegisterExtension registers the struct type of prototype for the
+// extensions of the given type attribute, matched case-insensitively. The
+// content of such extensions is decoded into a new value of that type, set
+// as their Content, the fields of the struct being matched against the
+// children of the <Extension> element as done by Decode.
+//
+// Registration is meant to happen at init time, before any document is
+// decoded.
+func RegisterExtension(typ string, prototype interface{}) {
+	t := extensionStruct(prototype)
+	extensionCodecs.Lock()
+	extensionCodecs.types[strings.ToLower(typ)] = registeredExtension{name: typ, typ: t}
+	extensionCodecs.Unlock()
+}
+
+// RegisterExtensionElement registers the struct type of prototype for the
+// extensions whose first child element has the given name, such as
+// "AdVerifications". The child element is decoded into a new value of that
+// type, set as the Content of the extension, and encoded back as an element
+// of that name whatever the XMLName of the type.
+//
+// Registration is meant to happen at init time, before any document is
+// decoded. Extensions matching both a registered type and a registered
+// element are decoded by type.
+func RegisterExtensionElement(name string, prototype interface{}) {
+	t := extensionStruct(prototype)
+	extensionCodecs.Lock()
+	extensionCodecs.elements[name] = t
+	extensionCodecs.Unlock()
+}
+
+// extensionStruct returns the struct type of prototype, or of what it points
+// to, and panics if it's not a struct.
+func extensionStruct(prototype interface{}) reflect.Type {
+	t := reflect.TypeOf(prototype)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic("vast: extension prototype must be a struct")
+	}
+	return t
+}
+
+// decodeContent decodes the data of the extension into a new value of the
+// type registered for it, if any, and sets it as its Content. Content is
+// left unset if the data can't be decoded, so that documents holding such
+// extensions can still be read; decoding them with Decode reports the error.
+func (e *Extension) decodeContent() {
+	extensionCodecs.RLock()
+	registered, byType := extensionCodecs.types[strings.ToLower(e.Type)]
+	t := registered.typ
+	if !byType {
+		t = extensionCodecs.elements[rootElement(e.Data)]
+	}
+	extensionCodecs.RUnlock()
+	if t == nil {
+		return
+	}
+	v := reflect.New(t).Interface()
+	var err error
+	if byType {
+		err = e.decodeData(v)
+	} else {
+		err = xml.Unmarshal([]byte(e.Data), v)
+	}
+	if err == nil {
+		e.Content = v
+	}
+}
+
+// encodeContent returns the type and the inner XML of the extension from its
+// Content.
+func (e *Extension) encodeContent() (string, string, error) {
+	typ, element, byType := e.contentType()
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	if !byType {
+		// encode the content as the registered element, whatever the name
+		// of its Go type
+		var err error
+		if element != "" {
+			err = enc.EncodeElement(e.Content, xml.StartElement{Name: xml.Name{Local: element}})
+		} else {
+			err = enc.Encode(e.Content)
+		}
+		if err == nil {
+			err = enc.Flush()
+		}
+		return typ, buf.String(), err
+	}
+	// encode the content as the extension and keep its children
+	if err := enc.EncodeElement(e.Content, xml.StartElement{Name: xml.Name{Local: "Extension"}}); err != nil {
+		return "", "", err
+	}
+	if err := enc.Flush(); err != nil {
+		return "", "", err
+	}
+	s := buf.String()
+	start, end := strings.IndexByte(s, '>'), strings.LastIndexByte(s, '<')
+	if start < 0 || end < start {
+		return "", "", errors.New("vast: invalid extension content encoding")
+	}
+	return typ, s[start+1 : end], nil
+}
+
+// contentType returns the type attribute of the extension, the registered
+// one if the extension has none, whether its content is registered by type,
+// and otherwise the name of the element its content is registered for, if
+// any.
+func (e *Extension) contentType() (string, string, bool) {
+	t := reflect.TypeOf(e.Content)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	extensionCodecs.RLock()
+	defer extensionCodecs.RUnlock()
+	if e.Type != "" && extensionCodecs.types[strings.ToLower(e.Type)].typ == t {
+		return e.Type, "", true
+	}
+	if e.Type == "" {
+		for _, registered := range extensionCodecs.types {
+			if registered.typ == t {
+				return registered.name, "", true
+			}
+		}
+	}
+	if name := rootElement(e.Data); extensionCodecs.elements[name] == t {
+		return e.Type, name, false
+	}
+	var element string
+	for name, registered := range extensionCodecs.elements {
+		// the lowest name for stable encodings of types registered twice
+		if registered == t && (element == "" || name < element) {
+			element = name
+		}
+	}
+	return e.Type, element, false
+}
+
+// rootElement returns the name of the first element of data, or "".
+func rootElement(data string) string {
+	dec := xml.NewDecoder(strings.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name.Local
+		}
+	}
+}
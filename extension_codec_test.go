@@ -0,0 +1,92 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type acmeExtension struct {
+	Campaign string
+	Flights  []int `xml:"Flight"`
+}
+
+type brandExtension struct {
+	XMLName xml.Name `xml:"Brand"`
+	Name    string   `xml:"name,attr"`
+	Logo    string
+}
+
+// promoExtension has no XMLName, its element name coming from the
+// registration only.
+type promoExtension struct {
+	Code string `xml:"code,attr"`
+}
+
+func init() {
+	RegisterExtension("Acme", acmeExtension{})
+	RegisterExtensionElement("Brand", &brandExtension{})
+	RegisterExtensionElement("Promo", promoExtension{})
+}
+
+func TestExtensionCodec(t *testing.T) {
+	var exts struct {
+		Extensions []Extension `xml:"Extension"`
+	}
+	assert.NoError(t, xml.Unmarshal([]byte(`<Extensions>`+
+		`<Extension type="acme"><Campaign>spring</Campaign><Flight>1</Flight><Flight>2</Flight></Extension>`+
+		`<Extension type="branding"> <Brand name="ACME"><Logo>https://cdn/logo.png</Logo></Brand></Extension>`+
+		`<Extension type="acme"><Flight>x</Flight></Extension>`+
+		`<Extension type="other"><Foo/></Extension>`+
+		`</Extensions>`), &exts))
+	if !assert.Len(t, exts.Extensions, 4) {
+		return
+	}
+	acme := exts.Extensions[0].Content.(*acmeExtension)
+	assert.Equal(t, &acmeExtension{Campaign: "spring", Flights: []int{1, 2}}, acme)
+	brand := exts.Extensions[1].Content.(*brandExtension)
+	assert.Equal(t, "ACME", brand.Name)
+	assert.Equal(t, "https://cdn/logo.png", brand.Logo)
+	// undecodable and unregistered extensions keep their data only
+	assert.Nil(t, exts.Extensions[2].Content)
+	assert.Error(t, exts.Extensions[2].Decode(&acmeExtension{}))
+	assert.Nil(t, exts.Extensions[3].Content)
+
+	var decoded acmeExtension
+	assert.NoError(t, exts.Extensions[0].Decode(&decoded))
+	assert.Equal(t, *acme, decoded)
+	v, err := exts.Extensions[1].Value()
+	assert.NoError(t, err)
+	assert.Equal(t, brand, v)
+
+	// the content is encoded in place of the data
+	acme.Flights = append(acme.Flights, 3)
+	brand.Logo = "https://cdn/logo2.png"
+	b, err := xml.Marshal(exts.Extensions[:2])
+	assert.NoError(t, err)
+	assert.Equal(t, `<Extension type="acme"><Campaign>spring</Campaign><Flight>1</Flight><Flight>2</Flight><Flight>3</Flight></Extension>`+
+		`<Extension type="branding"><Brand name="ACME"><Logo>https://cdn/logo2.png</Logo></Brand></Extension>`, string(b))
+
+	// the registered type is used for new extensions without type
+	b, err = xml.Marshal(Extension{Content: &acmeExtension{Campaign: "fall"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `<Extension type="Acme"><Campaign>fall</Campaign></Extension>`, string(b))
+
+	assert.Panics(t, func() { RegisterExtension("bogus", "not a struct") })
+}
+
+func TestExtensionCodecElementName(t *testing.T) {
+	var e Extension
+	if !assert.NoError(t, xml.Unmarshal([]byte(`<Extension type="promo"><Promo code="X"/></Extension>`), &e)) {
+		return
+	}
+	assert.Equal(t, &promoExtension{Code: "X"}, e.Content)
+	b, err := xml.Marshal(e)
+	assert.NoError(t, err)
+	assert.Equal(t, `<Extension type="promo"><Promo code="X"></Promo></Extension>`, string(b))
+
+	b, err = xml.Marshal(Extension{Content: &promoExtension{Code: "Y"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `<Extension><Promo code="Y"></Promo></Extension>`, string(b))
+}
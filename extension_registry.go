@@ -0,0 +1,164 @@
+package vast
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Types of the well-known extensions decoded by Extension.Value
+const (
+	// Google Ad Manager extension giving the position of the ad in the
+	// waterfall of fallback ads
+	ExtensionWaterfall = "waterfall"
+	// Google Ad Manager extension describing the location and the network of
+	// the viewer
+	ExtensionGeo = "geo"
+	// FreeWheel extension carrying the creative parameters set in the ad
+	// server
+	ExtensionFreeWheel = "FreeWheel"
+)
+
+// Waterfall is the content of a waterfall extension, such as
+// <Extension type="waterfall" fallback_index="1"/>.
+type Waterfall struct {
+	// The position of the ad in the waterfall, from 0
+	FallbackIndex int
+}
+
+// Geo is the content of a geo extension.
+type Geo struct {
+	// ISO 3166-1 alpha-2 country code
+	Country string `xml:",omitempty"`
+	// The bandwidth class of the connection of the viewer, from 0 (unknown)
+	// to 4 (broadband)
+	Bandwidth int `xml:",omitempty"`
+	// The bandwidth of the connection of the viewer, in kbps
+	BandwidthKbps int `xml:",omitempty"`
+}
+
+// FreeWheelParameters is the content of a FreeWheel extension.
+type FreeWheelParameters struct {
+	Parameters []FreeWheelParameter `xml:"CreativeParameters>CreativeParameter"`
+}
+
+// FreeWheelParameter is a parameter of a FreeWheel creative.
+type FreeWheelParameter struct {
+	CreativeID string `xml:"creativeId,attr,omitempty"`
+	Name       string `xml:"name,attr"`
+	// The kind of creative the parameter applies to, e.g. "Linear"
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",cdata"`
+}
+
+// Get returns the value of the first parameter of the given name, and false
+// if there is none.
+func (p *FreeWheelParameters) Get(name string) (string, bool) {
+	for _, param := range p.Parameters {
+		if param.Name == name {
+			return strings.TrimSpace(param.Value), true
+		}
+	}
+	return "", false
+}
+
+// LegacyVerifications is the content of an AdVerifications extension, where
+// the Verification elements are either wrapped in an AdVerifications element
+// or not.
+type LegacyVerifications struct {
+	Verifications []Verification
+}
+
+// extensionDecoder decodes the extensions of a type into a pointer to a
+// value of type typ.
+type extensionDecoder struct {
+	typ    reflect.Type
+	decode func(e *Extension) (interface{}, error)
+}
+
+// extensionDecoders holds the decoders of the well-known extensions, keyed
+// by lower case type.
+var extensionDecoders = map[string]extensionDecoder{
+	strings.ToLower(ExtensionWaterfall): {reflect.TypeOf(Waterfall{}), decodeWaterfall},
+	strings.ToLower(ExtensionGeo): {reflect.TypeOf(Geo{}), func(e *Extension) (interface{}, error) {
+		var g Geo
+		return &g, e.decodeData(&g)
+	}},
+	strings.ToLower(ExtensionFreeWheel): {reflect.TypeOf(FreeWheelParameters{}), func(e *Extension) (interface{}, error) {
+		var p FreeWheelParameters
+		return &p, e.decodeData(&p)
+	}},
+	strings.ToLower(ExtensionAdVerifications): {reflect.TypeOf(LegacyVerifications{}), decodeLegacyVerifications},
+}
+
+func decodeWaterfall(e *Extension) (interface{}, error) {
+	var w Waterfall
+	for _, attr := range e.Attrs {
+		if attr.Name.Local != "fallback_index" {
+			continue
+		}
+		i, err := strconv.Atoi(strings.TrimSpace(attr.Value))
+		if err != nil {
+			return nil, err
+		}
+		w.FallbackIndex = i
+	}
+	return &w, nil
+}
+
+func decodeLegacyVerifications(e *Extension) (interface{}, error) {
+	var content struct {
+		Wrapped []Verification `xml:"AdVerifications>Verification"`
+		Bare    []Verification `xml:"Verification"`
+	}
+	if err := e.decodeData(&content); err != nil {
+		return nil, err
+	}
+	return &LegacyVerifications{Verifications: append(content.Wrapped, content.Bare...)}, nil
+}
+
+// Value returns the content of a well-known extension decoded into its
+// type, such as a *Geo for a geo extension, the Content of an extension of
+// a registered type, and nil for other extensions.
+func (e *Extension) Value() (interface{}, error) {
+	if e.Content != nil {
+		return e.Content, nil
+	}
+	dec, ok := extensionDecoders[strings.ToLower(e.Type)]
+	if !ok {
+		return nil, nil
+	}
+	return dec.decode(e)
+}
+
+// decodeTyped decodes the extension into v if it's a pointer to the type of
+// its content or of the well-known extension, and returns false otherwise.
+func (e *Extension) decodeTyped(v interface{}) (bool, error) {
+	if e.Content != nil {
+		rv, content := reflect.ValueOf(v), reflect.ValueOf(e.Content)
+		if rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Type() == content.Type() {
+			rv.Elem().Set(content.Elem())
+			return true, nil
+		}
+	}
+	dec, ok := extensionDecoders[strings.ToLower(e.Type)]
+	if !ok {
+		return false, nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Type() != dec.typ {
+		return false, nil
+	}
+	value, err := dec.decode(e)
+	if err != nil {
+		return true, err
+	}
+	rv.Elem().Set(reflect.ValueOf(value).Elem())
+	return true, nil
+}
+
+// decodeData decodes the inner XML of the extension into v.
+func (e *Extension) decodeData(v interface{}) error {
+	return xml.Unmarshal([]byte("<Extension>"+e.Data+"</Extension>"), v)
+}
@@ -0,0 +1,61 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensionValue(t *testing.T) {
+	var exts struct {
+		Extensions []Extension `xml:"Extension"`
+	}
+	assert.NoError(t, xml.Unmarshal([]byte(`<Extensions>`+
+		`<Extension type="waterfall" fallback_index="2"/>`+
+		`<Extension type="geo"><Country>US</Country><Bandwidth>4</Bandwidth><BandwidthKbps>20000</BandwidthKbps></Extension>`+
+		`<Extension type="FreeWheel"><CreativeParameters>`+
+		`<CreativeParameter creativeId="123" name="moat" type="Linear"><![CDATA[ enabled ]]></CreativeParameter>`+
+		`</CreativeParameters></Extension>`+
+		`<Extension type="AdVerifications"><Verification vendor="a"/><AdVerifications><Verification vendor="b"/></AdVerifications></Extension>`+
+		`<Extension type="other"><Foo/></Extension>`+
+		`</Extensions>`), &exts))
+	if !assert.Len(t, exts.Extensions, 5) {
+		return
+	}
+
+	v, err := exts.Extensions[0].Value()
+	assert.NoError(t, err)
+	assert.Equal(t, &Waterfall{FallbackIndex: 2}, v)
+	v, err = exts.Extensions[1].Value()
+	assert.NoError(t, err)
+	assert.Equal(t, &Geo{Country: "US", Bandwidth: 4, BandwidthKbps: 20000}, v)
+	v, err = exts.Extensions[2].Value()
+	if assert.NoError(t, err) {
+		params := v.(*FreeWheelParameters)
+		value, ok := params.Get("moat")
+		assert.True(t, ok)
+		assert.Equal(t, "enabled", value)
+		assert.Equal(t, "123", params.Parameters[0].CreativeID)
+	}
+	v, err = exts.Extensions[3].Value()
+	if assert.NoError(t, err) && assert.Len(t, v.(*LegacyVerifications).Verifications, 2) {
+		assert.Equal(t, "b", v.(*LegacyVerifications).Verifications[0].Vendor)
+	}
+	v, err = exts.Extensions[4].Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	// Decode uses the decoder of the extension when given its type
+	var w Waterfall
+	assert.NoError(t, exts.Extensions[0].Decode(&w))
+	assert.Equal(t, 2, w.FallbackIndex)
+	var raw struct {
+		Country string
+	}
+	assert.NoError(t, exts.Extensions[1].Decode(&raw))
+	assert.Equal(t, "US", raw.Country)
+
+	bad := Extension{Type: "Waterfall", Attrs: []xml.Attr{{Name: xml.Name{Local: "fallback_index"}, Value: "x"}}}
+	assert.Error(t, bad.Decode(&w))
+}
@@ -46,10 +46,10 @@ func TestExtensionCustomTracking(t *testing.T) {
 	if assert.Len(t, e.CustomTracking, 2) {
 		// first event
 		assert.Equal(t, "event.1", e.CustomTracking[0].Event)
-		assert.Equal(t, "http://event.1", e.CustomTracking[0].URI)
+		assert.Equal(t, URI("http://event.1"), e.CustomTracking[0].URI)
 		// second event
 		assert.Equal(t, "event.2", e.CustomTracking[1].Event)
-		assert.Equal(t, "http://event.2", e.CustomTracking[1].URI)
+		assert.Equal(t, URI("http://event.2"), e.CustomTracking[1].URI)
 	}
 
 	// marshal the extension
@@ -77,3 +77,66 @@ func TestExtensionGeneric(t *testing.T) {
 	// assert the resulting marshaled extension
 	assert.Equal(t, string(extensionData), string(xmlExtensionOutput))
 }
+
+type skippableAdType struct {
+	Value string `xml:"SkippableAdType"`
+}
+
+func TestExtensionDecode(t *testing.T) {
+	var e Extension
+	if !assert.NoError(t, xml.Unmarshal(extensionData, &e)) {
+		return
+	}
+	var payload skippableAdType
+	if assert.NoError(t, e.Decode(&payload)) {
+		assert.Equal(t, "Generic", payload.Value)
+	}
+
+	e, err := NewExtension("testCustomTracking", skippableAdType{Value: "Generic"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "<skippableAdType><SkippableAdType>Generic</SkippableAdType></skippableAdType>", e.Data)
+	}
+
+	e = Extension{Data: "<broken>"}
+	assert.Error(t, e.Decode(&payload))
+}
+
+func TestExtensionAttrsRoundTrip(t *testing.T) {
+	raw := `<Extension type="geo" xmlns:g="http://geo.example.com" source="dsp"><g:Country>US</g:Country><Bandwidth>4</Bandwidth></Extension>`
+	var e Extension
+	if !assert.NoError(t, xml.Unmarshal([]byte(raw), &e)) {
+		return
+	}
+	assert.Equal(t, "geo", e.Type)
+	assert.Len(t, e.Attrs, 2)
+	b, err := xml.Marshal(e)
+	if assert.NoError(t, err) {
+		assert.Equal(t, raw, string(b))
+		var again Extension
+		if assert.NoError(t, xml.Unmarshal(b, &again)) {
+			assert.Equal(t, e, again)
+		}
+	}
+
+	// the default namespace and custom trackers
+	raw = `<Extension type="t" xmlns="http://t.example.com" xmlns:g="http://g.example.com"><CustomTracking><Tracking event="start"><![CDATA[https://t.example.com/start]]></Tracking></CustomTracking></Extension>`
+	e = Extension{}
+	if assert.NoError(t, xml.Unmarshal([]byte(raw), &e)) {
+		b, err := xml.Marshal(e)
+		if assert.NoError(t, err) {
+			assert.Equal(t, raw, string(b))
+		}
+	}
+}
+
+func TestFindExtension(t *testing.T) {
+	in := InLine{}
+	assert.Nil(t, in.Extension("geo"))
+	in.Extensions = &[]Extension{{Type: "a"}, {Type: "geo", Data: "1"}, {Type: "geo", Data: "2"}}
+	if e := in.Extension("geo"); assert.NotNil(t, e) {
+		assert.Equal(t, "1", e.Data)
+	}
+	w := Wrapper{Extensions: []Extension{{Type: "a"}}}
+	assert.NotNil(t, w.Extension("a"))
+	assert.Nil(t, w.Extension("b"))
+}
@@ -0,0 +1,879 @@
+package vast
+
+import (
+	"encoding/xml"
+	"strconv"
+	"unicode/utf8"
+)
+
+// FastMarshal returns the XML encoding of v, byte for byte the same as
+// xml.Marshal's, without going through reflection. Extensions, whose content
+// is arbitrary, are the only elements still encoded by encoding/xml.
+func FastMarshal(v *VAST) ([]byte, error) {
+	return AppendFastMarshal(make([]byte, 0, 4096), v)
+}
+
+// AppendFastMarshal appends the XML encoding of v to dst, as FastMarshal
+// does, so that buffers can be reused across documents.
+func AppendFastMarshal(dst []byte, v *VAST) ([]byte, error) {
+	if v == nil {
+		return dst, nil
+	}
+	e := fastEncoder{buf: dst}
+	e.vast(v)
+	if e.err != nil {
+		return dst, e.err
+	}
+	return e.buf, nil
+}
+
+// fastEncoder appends the XML encoding of the VAST elements to buf. The
+// first error is kept in err, the following writes are then ignored by the
+// callers checking it.
+//
+// It mirrors the quirks of encoding/xml, such as writing the parent of the
+// fields tagged "Parent>Child", e.g. TrackingEvents, even when the field is
+// an empty slice.
+type fastEncoder struct {
+	buf []byte
+	err error
+	// The encoder of the extensions, shared by the document so that the
+	// prefixes of the namespaces of their attributes are numbered as by
+	// xml.Marshal
+	xml *xml.Encoder
+}
+
+// fail keeps err unless an error was already met.
+func (e *fastEncoder) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+// Write implements the io.Writer interface, for the extensions encoded by
+// an xml.Encoder.
+func (e *fastEncoder) Write(p []byte) (int, error) {
+	e.buf = append(e.buf, p...)
+	return len(p), nil
+}
+
+func (e *fastEncoder) open(name string) {
+	e.buf = append(e.buf, '<')
+	e.buf = append(e.buf, name...)
+}
+
+func (e *fastEncoder) openEnd() {
+	e.buf = append(e.buf, '>')
+}
+
+func (e *fastEncoder) start(name string) {
+	e.open(name)
+	e.openEnd()
+}
+
+func (e *fastEncoder) end(name string) {
+	e.buf = append(e.buf, '<', '/')
+	e.buf = append(e.buf, name...)
+	e.buf = append(e.buf, '>')
+}
+
+func (e *fastEncoder) attr(name, value string) {
+	e.buf = append(e.buf, ' ')
+	e.buf = append(e.buf, name...)
+	e.buf = append(e.buf, '=', '"')
+	e.text(value)
+	e.buf = append(e.buf, '"')
+}
+
+func (e *fastEncoder) attrOmitEmpty(name, value string) {
+	if value != "" {
+		e.attr(name, value)
+	}
+}
+
+func (e *fastEncoder) attrInt(name string, n int) {
+	e.buf = append(e.buf, ' ')
+	e.buf = append(e.buf, name...)
+	e.buf = append(e.buf, '=', '"')
+	e.buf = strconv.AppendInt(e.buf, int64(n), 10)
+	e.buf = append(e.buf, '"')
+}
+
+func (e *fastEncoder) attrIntOmitEmpty(name string, n int) {
+	if n != 0 {
+		e.attrInt(name, n)
+	}
+}
+
+func (e *fastEncoder) attrBool(name string, b bool) {
+	if b {
+		e.attr(name, "true")
+	} else {
+		e.attr(name, "false")
+	}
+}
+
+func (e *fastEncoder) attrDuration(name string, d Duration) {
+	if d < 0 {
+		_, err := d.MarshalText()
+		e.fail(err)
+		return
+	}
+	e.buf = append(e.buf, ' ')
+	e.buf = append(e.buf, name...)
+	e.buf = append(e.buf, '=', '"')
+	e.buf = d.appendText(e.buf)
+	e.buf = append(e.buf, '"')
+}
+
+func (e *fastEncoder) attrOffset(name string, o Offset) {
+	if o.Duration != nil {
+		e.attrDuration(name, *o.Duration)
+		return
+	}
+	e.buf = append(e.buf, ' ')
+	e.buf = append(e.buf, name...)
+	e.buf = append(e.buf, '=', '"')
+	e.buf = strconv.AppendInt(e.buf, int64(o.Percent*100), 10)
+	e.buf = append(e.buf, '%', '"')
+}
+
+// text appends s escaped as xml.EscapeText does.
+func (e *fastEncoder) text(s string) {
+	last := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c < utf8.RuneSelf && c != '"' && c != '\'' && c != '&' && c != '<' && c != '>' {
+			i++
+			continue
+		}
+		r, width := rune(c), 1
+		if c >= utf8.RuneSelf {
+			r, width = utf8.DecodeRuneInString(s[i:])
+		}
+		var esc string
+		switch r {
+		case '"':
+			esc = "&#34;"
+		case '\'':
+			esc = "&#39;"
+		case '&':
+			esc = "&amp;"
+		case '<':
+			esc = "&lt;"
+		case '>':
+			esc = "&gt;"
+		case '\t':
+			esc = "&#x9;"
+		case '\n':
+			esc = "&#xA;"
+		case '\r':
+			esc = "&#xD;"
+		default:
+			if !inCharacterRange(r) || (r == utf8.RuneError && width == 1) {
+				esc = "\uFFFD"
+				break
+			}
+			i += width
+			continue
+		}
+		e.buf = append(e.buf, s[last:i]...)
+		e.buf = append(e.buf, esc...)
+		i += width
+		last = i
+	}
+	e.buf = append(e.buf, s[last:]...)
+}
+
+// inCharacterRange reports whether r is a valid XML character.
+func inCharacterRange(r rune) bool {
+	return r == 0x09 ||
+		r == 0x0A ||
+		r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
+// cdata appends s as a CDATA section, splitting the "]]>" it contains. An
+// empty s appends nothing.
+func (e *fastEncoder) cdata(s string) {
+	if s == "" {
+		return
+	}
+	e.buf = append(e.buf, "<![CDATA["...)
+	for {
+		i := indexCDATAEnd(s)
+		if i < 0 {
+			break
+		}
+		e.buf = append(e.buf, s[:i]...)
+		e.buf = append(e.buf, "]]]]><![CDATA[>"...)
+		s = s[i+3:]
+	}
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, "]]>"...)
+}
+
+func indexCDATAEnd(s string) int {
+	for i := 0; i+2 < len(s); i++ {
+		if s[i] == ']' && s[i+1] == ']' && s[i+2] == '>' {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *fastEncoder) cdataElement(name, s string) {
+	e.start(name)
+	e.cdata(s)
+	e.end(name)
+}
+
+func (e *fastEncoder) textElement(name, s string) {
+	e.start(name)
+	e.text(s)
+	e.end(name)
+}
+
+func (e *fastEncoder) cdataURIs(name string, list []CDATAURI) {
+	for _, u := range list {
+		e.cdataElement(name, string(u.URI))
+	}
+}
+
+func (e *fastEncoder) vast(v *VAST) {
+	e.open("VAST")
+	e.attr("version", v.Version)
+	e.attrOmitEmpty("xmlns", v.XMLNS)
+	if v.Mute {
+		e.attr("mute", "true")
+	}
+	e.openEnd()
+	for i := range v.Ads {
+		e.ad(&v.Ads[i])
+		if e.err != nil {
+			return
+		}
+	}
+	e.cdataURIs("Error", v.Errors)
+	e.end("VAST")
+}
+
+func (e *fastEncoder) ad(ad *Ad) {
+	e.open("Ad")
+	e.attrOmitEmpty("id", ad.ID)
+	e.attrIntOmitEmpty("sequence", ad.Sequence)
+	e.attrOmitEmpty("adType", string(ad.AdType))
+	if ad.ConditionalAd != nil {
+		e.attrBool("conditionalAd", bool(*ad.ConditionalAd))
+	}
+	e.openEnd()
+	if ad.InLine != nil {
+		e.inline(ad.InLine)
+	}
+	if ad.Wrapper != nil {
+		e.wrapper(ad.Wrapper)
+	}
+	e.end("Ad")
+}
+
+func (e *fastEncoder) adSystem(s *AdSystem) {
+	if s == nil {
+		return
+	}
+	e.open("AdSystem")
+	e.attrOmitEmpty("version", s.Version)
+	e.openEnd()
+	e.cdata(s.Name)
+	e.end("AdSystem")
+}
+
+func (e *fastEncoder) extensions(parent, name string, exts []Extension) {
+	e.start(parent)
+	if len(exts) > 0 {
+		if e.xml == nil {
+			e.xml = xml.NewEncoder(e)
+		}
+		for i := range exts {
+			if err := e.xml.EncodeElement(&exts[i], xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+				e.fail(err)
+				return
+			}
+		}
+		if err := e.xml.Flush(); err != nil {
+			e.fail(err)
+			return
+		}
+	}
+	e.end(parent)
+}
+
+func (e *fastEncoder) impressions(imps []Impression) {
+	for _, imp := range imps {
+		e.open("Impression")
+		e.attrOmitEmpty("id", imp.ID)
+		e.openEnd()
+		e.cdata(string(imp.URI))
+		e.end("Impression")
+	}
+}
+
+func (e *fastEncoder) viewableImpression(vi *ViewableImpression) {
+	if vi == nil {
+		return
+	}
+	e.open("ViewableImpression")
+	e.attrOmitEmpty("id", vi.ID)
+	e.openEnd()
+	e.cdataURIs("Viewable", vi.Viewable)
+	e.cdataURIs("NotViewable", vi.NotViewable)
+	e.cdataURIs("ViewUndetermined", vi.ViewUndetermined)
+	e.end("ViewableImpression")
+}
+
+func (e *fastEncoder) verifications(list *[]Verification) {
+	if list == nil {
+		return
+	}
+	e.start("AdVerifications")
+	for _, v := range *list {
+		e.open("Verification")
+		e.attrOmitEmpty("vendor", v.Vendor)
+		e.openEnd()
+		for _, r := range v.JavaScriptResources {
+			e.open("JavaScriptResource")
+			e.attrOmitEmpty("apiFramework", r.APIFramework)
+			if r.BrowserOptional {
+				e.attr("browserOptional", "true")
+			}
+			e.openEnd()
+			e.cdata(string(r.URI))
+			e.end("JavaScriptResource")
+		}
+		for _, r := range v.ExecutableResources {
+			e.open("ExecutableResource")
+			e.attrOmitEmpty("apiFramework", r.APIFramework)
+			e.attrOmitEmpty("type", r.Type)
+			e.openEnd()
+			e.cdata(string(r.URI))
+			e.end("ExecutableResource")
+		}
+		e.trackingEvents(v.TrackingEvents)
+		if v.VerificationParameters != nil {
+			e.cdataElement("VerificationParameters", v.VerificationParameters.CDATA)
+		}
+		e.end("Verification")
+	}
+	e.end("AdVerifications")
+}
+
+func (e *fastEncoder) inline(in *InLine) {
+	e.start("InLine")
+	e.adSystem(in.AdSystem)
+	e.cdataURIs("Error", in.Errors)
+	if in.Extensions != nil {
+		e.extensions("Extensions", "Extension", *in.Extensions)
+		if e.err != nil {
+			return
+		}
+	}
+	e.impressions(in.Impressions)
+	e.viewableImpression(in.ViewableImpression)
+	if p := in.Pricing; p != nil {
+		e.open("Pricing")
+		e.attr("model", string(p.Model))
+		e.attr("currency", p.Currency)
+		e.openEnd()
+		e.cdata(p.Value)
+		e.end("Pricing")
+	}
+	if in.AdServingId != "" {
+		e.textElement("AdServingId", in.AdServingId)
+	}
+	e.cdataElement("AdTitle", in.AdTitle.CDATA)
+	if in.Advertiser != "" {
+		e.textElement("Advertiser", in.Advertiser)
+	}
+	for _, c := range in.Categories {
+		e.open("Category")
+		e.attrOmitEmpty("authority", c.Authority)
+		e.openEnd()
+		e.text(c.Code)
+		e.end("Category")
+	}
+	e.start("Creatives")
+	for i := range in.Creatives {
+		e.creative(&in.Creatives[i])
+		if e.err != nil {
+			return
+		}
+	}
+	e.end("Creatives")
+	if in.Description != nil {
+		e.cdataElement("Description", in.Description.CDATA)
+	}
+	if in.Expires != nil {
+		b, err := in.Expires.MarshalText()
+		if err != nil {
+			e.fail(err)
+			return
+		}
+		e.textElement("Expires", string(b))
+	}
+	for _, s := range in.Surveys {
+		e.open("Survey")
+		e.attrOmitEmpty("type", s.Type)
+		e.openEnd()
+		e.cdata(string(s.URI))
+		e.end("Survey")
+	}
+	e.verifications(in.AdVerifications)
+	e.end("InLine")
+}
+
+func (e *fastEncoder) wrapper(w *Wrapper) {
+	e.open("Wrapper")
+	if w.FallbackOnNoAd != nil {
+		e.attrBool("fallbackOnNoAd", bool(*w.FallbackOnNoAd))
+	}
+	if w.AllowMultipleAds != nil {
+		e.attrBool("allowMultipleAds", bool(*w.AllowMultipleAds))
+	}
+	if w.FollowAdditionalWrappers != nil {
+		e.attrBool("followAdditionalWrappers", bool(*w.FollowAdditionalWrappers))
+	}
+	e.openEnd()
+	e.adSystem(w.AdSystem)
+	e.cdataURIs("Error", w.Errors)
+	e.extensions("Extensions", "Extension", w.Extensions)
+	if e.err != nil {
+		return
+	}
+	e.impressions(w.Impressions)
+	e.viewableImpression(w.ViewableImpression)
+	e.start("Creatives")
+	for i := range w.Creatives {
+		e.creativeWrapper(&w.Creatives[i])
+		if e.err != nil {
+			return
+		}
+	}
+	e.end("Creatives")
+	e.cdataElement("VASTAdTagURI", string(w.VASTAdTagURI.URI))
+	e.verifications(w.AdVerifications)
+	e.end("Wrapper")
+}
+
+func (e *fastEncoder) creative(c *Creative) {
+	e.open("Creative")
+	e.attrOmitEmpty("id", c.ID)
+	e.attrIntOmitEmpty("sequence", c.Sequence)
+	e.attrOmitEmpty("adId", c.AdID)
+	e.attrOmitEmpty("apiFramework", c.APIFramework)
+	e.openEnd()
+	if id := c.UniversalAdID; id != nil {
+		e.open("UniversalAdId")
+		e.attr("idRegistry", id.IDRegistry)
+		e.openEnd()
+		e.cdata(id.ID)
+		e.end("UniversalAdId")
+	}
+	if c.Linear != nil {
+		e.linear(c.Linear)
+	}
+	if ca := c.CompanionAds; ca != nil {
+		e.open("CompanionAds")
+		e.attrOmitEmpty("required", ca.Required)
+		e.openEnd()
+		for i := range ca.Companions {
+			e.companion(&ca.Companions[i])
+		}
+		e.end("CompanionAds")
+	}
+	if nl := c.NonLinearAds; nl != nil {
+		e.start("NonLinearAds")
+		e.trackingEvents(nl.TrackingEvents)
+		for i := range nl.NonLinears {
+			e.nonLinear(&nl.NonLinears[i])
+		}
+		e.end("NonLinearAds")
+	}
+	if c.CreativeExtensions != nil {
+		e.extensions("CreativeExtensions", "CreativeExtension", *c.CreativeExtensions)
+	}
+	e.end("Creative")
+}
+
+func (e *fastEncoder) creativeWrapper(c *CreativeWrapper) {
+	e.open("Creative")
+	e.attrOmitEmpty("id", c.ID)
+	e.attrIntOmitEmpty("sequence", c.Sequence)
+	e.attrOmitEmpty("adId", c.AdID)
+	e.openEnd()
+	if l := c.Linear; l != nil {
+		e.start("Linear")
+		e.icons(l.Icons)
+		e.trackingEvents(l.TrackingEvents)
+		e.videoClicks(l.VideoClicks)
+		e.end("Linear")
+	}
+	if ca := c.CompanionAds; ca != nil {
+		e.open("CompanionAds")
+		e.attrOmitEmpty("required", ca.Required)
+		e.openEnd()
+		for i := range ca.Companions {
+			e.companionWrapper(&ca.Companions[i])
+		}
+		e.end("CompanionAds")
+	}
+	if nl := c.NonLinearAds; nl != nil {
+		e.start("NonLinearAds")
+		e.trackingEvents(nl.TrackingEvents)
+		for i := range nl.NonLinears {
+			e.nonLinearWrapper(&nl.NonLinears[i])
+		}
+		e.end("NonLinearAds")
+	}
+	e.end("Creative")
+}
+
+func (e *fastEncoder) linear(l *Linear) {
+	e.open("Linear")
+	if l.SkipOffset != nil {
+		e.attrOffset("skipoffset", *l.SkipOffset)
+	}
+	e.openEnd()
+	e.icons(l.Icons)
+	e.trackingEvents(l.TrackingEvents)
+	e.adParameters(l.AdParameters)
+	if l.Duration != 0 {
+		if l.Duration < 0 {
+			_, err := l.Duration.MarshalText()
+			e.fail(err)
+			return
+		}
+		e.start("Duration")
+		e.buf = l.Duration.appendText(e.buf)
+		e.end("Duration")
+	}
+	e.start("MediaFiles")
+	for i := range l.MediaFiles {
+		e.mediaFile(&l.MediaFiles[i])
+	}
+	for _, m := range l.Mezzanines {
+		e.open("Mezzanine")
+		e.attrOmitEmpty("id", m.ID)
+		e.attr("delivery", string(m.Delivery))
+		e.attr("type", m.Type)
+		e.attrInt("width", m.Width)
+		e.attrInt("height", m.Height)
+		e.attrOmitEmpty("codec", m.Codec)
+		e.attrIntOmitEmpty("fileSize", m.FileSize)
+		e.attrOmitEmpty("mediaType", m.MediaType)
+		e.openEnd()
+		e.cdata(string(m.URI))
+		e.end("Mezzanine")
+	}
+	for _, f := range l.InteractiveCreativeFiles {
+		e.open("InteractiveCreativeFile")
+		e.attrOmitEmpty("type", f.Type)
+		e.attrOmitEmpty("apiFramework", f.APIFramework)
+		if f.VariableDuration {
+			e.attr("variableDuration", "true")
+		}
+		e.openEnd()
+		e.cdata(string(f.URI))
+		e.end("InteractiveCreativeFile")
+	}
+	if cc := l.ClosedCaptionFiles; cc != nil {
+		e.start("ClosedCaptionFiles")
+		for _, f := range cc.ClosedCaptionFile {
+			e.open("ClosedCaptionFile")
+			e.attrOmitEmpty("type", f.Type)
+			e.attrOmitEmpty("language", f.Language)
+			e.openEnd()
+			e.cdata(string(f.URI))
+			e.end("ClosedCaptionFile")
+		}
+		e.end("ClosedCaptionFiles")
+	}
+	e.end("MediaFiles")
+	e.videoClicks(l.VideoClicks)
+	e.end("Linear")
+}
+
+func (e *fastEncoder) mediaFile(m *MediaFile) {
+	e.open("MediaFile")
+	e.attrOmitEmpty("id", m.ID)
+	e.attr("delivery", string(m.Delivery))
+	e.attr("type", m.Type)
+	e.attrOmitEmpty("codec", m.Codec)
+	e.attrIntOmitEmpty("bitrate", m.Bitrate)
+	e.attrIntOmitEmpty("minBitrate", m.MinBitrate)
+	e.attrIntOmitEmpty("maxBitrate", m.MaxBitrate)
+	e.attrInt("width", m.Width)
+	e.attrInt("height", m.Height)
+	if m.Scalable {
+		e.attr("scalable", "true")
+	}
+	if m.MaintainAspectRatio {
+		e.attr("maintainAspectRatio", "true")
+	}
+	e.attrOmitEmpty("apiFramework", m.APIFramework)
+	e.attrIntOmitEmpty("fileSize", m.FileSize)
+	e.attrOmitEmpty("mediaType", m.MediaType)
+	e.openEnd()
+	e.cdata(string(m.URI))
+	e.end("MediaFile")
+}
+
+func (e *fastEncoder) trackingEvents(events []Tracking) {
+	e.start("TrackingEvents")
+	for _, t := range events {
+		e.open("Tracking")
+		e.attr("event", t.Event)
+		if t.Offset != nil {
+			e.attrOffset("offset", *t.Offset)
+		}
+		e.attrOmitEmpty("ua", t.UA)
+		e.openEnd()
+		e.cdata(string(t.URI))
+		e.end("Tracking")
+	}
+	e.end("TrackingEvents")
+}
+
+func (e *fastEncoder) videoClicks(vc *VideoClicks) {
+	if vc == nil {
+		return
+	}
+	e.start("VideoClicks")
+	e.videoClickList("ClickTracking", vc.ClickTrackings)
+	e.videoClickList("CustomClick", vc.CustomClicks)
+	e.videoClickList("ClickThrough", vc.ClickThroughs)
+	e.end("VideoClicks")
+}
+
+func (e *fastEncoder) videoClickList(name string, clicks []VideoClick) {
+	for _, c := range clicks {
+		e.open(name)
+		e.attrOmitEmpty("id", c.ID)
+		e.openEnd()
+		e.cdata(string(c.URI))
+		e.end(name)
+	}
+}
+
+func (e *fastEncoder) adParameters(p *AdParameters) {
+	if p == nil {
+		return
+	}
+	e.open("AdParameters")
+	if p.XMLEncoded {
+		e.attr("xmlEncoded", "true")
+	}
+	e.openEnd()
+	e.cdata(p.Parameters)
+	e.end("AdParameters")
+}
+
+func (e *fastEncoder) htmlResource(r *HTMLResource) {
+	if r == nil {
+		return
+	}
+	e.open("HTMLResource")
+	if r.XMLEncoded {
+		e.attr("xmlEncoded", "true")
+	}
+	e.openEnd()
+	e.cdata(r.HTML)
+	e.end("HTMLResource")
+}
+
+func (e *fastEncoder) iframeResource(r *CDATAURI) {
+	if r != nil {
+		e.cdataElement("IFrameResource", string(r.URI))
+	}
+}
+
+func (e *fastEncoder) staticResource(r *StaticResource) {
+	if r == nil {
+		return
+	}
+	e.open("StaticResource")
+	e.attrOmitEmpty("creativeType", r.CreativeType)
+	e.openEnd()
+	e.cdata(string(r.URI))
+	e.end("StaticResource")
+}
+
+func (e *fastEncoder) companionClickTrackings(list []CompanionClickTracking) {
+	for _, c := range list {
+		e.open("CompanionClickTracking")
+		e.attrOmitEmpty("id", c.ID)
+		e.openEnd()
+		e.cdata(string(c.URI))
+		e.end("CompanionClickTracking")
+	}
+}
+
+func (e *fastEncoder) nonLinearClickTrackings(list []NonLinearClickTracking) {
+	for _, c := range list {
+		e.open("NonLinearClickTracking")
+		e.attrOmitEmpty("id", c.ID)
+		e.openEnd()
+		e.cdata(string(c.URI))
+		e.end("NonLinearClickTracking")
+	}
+}
+
+func (e *fastEncoder) companion(c *Companion) {
+	e.open("Companion")
+	e.attrOmitEmpty("id", c.ID)
+	e.attrIntOmitEmpty("width", c.Width)
+	e.attrIntOmitEmpty("height", c.Height)
+	e.attrIntOmitEmpty("assetWidth", c.AssetWidth)
+	e.attrIntOmitEmpty("assetHeight", c.AssetHeight)
+	e.attrIntOmitEmpty("expandedWidth", c.ExpandedWidth)
+	e.attrIntOmitEmpty("expandedHeight", c.ExpandedHeight)
+	e.attrOmitEmpty("apiFramework", c.APIFramework)
+	e.attrOmitEmpty("adSlotId", c.AdSlotID)
+	e.openEnd()
+	e.htmlResource(c.HTMLResource)
+	e.iframeResource(c.IFrameResource)
+	e.staticResource(c.StaticResource)
+	e.adParameters(c.AdParameters)
+	if c.AltText != "" {
+		e.textElement("AltText", c.AltText)
+	}
+	if c.CompanionClickThrough != nil {
+		e.cdataElement("CompanionClickThrough", string(c.CompanionClickThrough.URI))
+	}
+	e.companionClickTrackings(c.CompanionClickTrackings)
+	e.trackingEvents(c.TrackingEvents)
+	e.end("Companion")
+}
+
+func (e *fastEncoder) companionWrapper(c *CompanionWrapper) {
+	e.open("Companion")
+	e.attrOmitEmpty("id", c.ID)
+	e.attrInt("width", c.Width)
+	e.attrInt("height", c.Height)
+	e.attrInt("assetWidth", c.AssetWidth)
+	e.attrInt("assetHeight", c.AssetHeight)
+	e.attrInt("expandedWidth", c.ExpandedWidth)
+	e.attrInt("expandedHeight", c.ExpandedHeight)
+	e.attrOmitEmpty("apiFramework", c.APIFramework)
+	e.attrOmitEmpty("adSlotId", c.AdSlotID)
+	e.openEnd()
+	if c.CompanionClickThrough != nil {
+		e.cdataElement("CompanionClickThrough", string(c.CompanionClickThrough.URI))
+	}
+	e.companionClickTrackings(c.CompanionClickTrackings)
+	if c.AltText != "" {
+		e.textElement("AltText", c.AltText)
+	}
+	e.trackingEvents(c.TrackingEvents)
+	e.adParameters(c.AdParameters)
+	e.staticResource(c.StaticResource)
+	e.iframeResource(c.IFrameResource)
+	e.htmlResource(c.HTMLResource)
+	e.end("Companion")
+}
+
+// nonLinearAttrs appends the attributes shared by NonLinear and
+// NonLinearWrapper.
+func (e *fastEncoder) nonLinearAttrs(id string, width, height, expandedWidth, expandedHeight int, scalable, maintainAspectRatio bool, minSuggestedDuration *Duration, apiFramework string) {
+	e.attrOmitEmpty("id", id)
+	e.attrInt("width", width)
+	e.attrInt("height", height)
+	e.attrInt("expandedWidth", expandedWidth)
+	e.attrInt("expandedHeight", expandedHeight)
+	if scalable {
+		e.attr("scalable", "true")
+	}
+	if maintainAspectRatio {
+		e.attr("maintainAspectRatio", "true")
+	}
+	if minSuggestedDuration != nil {
+		e.attrDuration("minSuggestedDuration", *minSuggestedDuration)
+	}
+	e.attrOmitEmpty("apiFramework", apiFramework)
+}
+
+func (e *fastEncoder) nonLinear(nl *NonLinear) {
+	e.open("NonLinear")
+	e.nonLinearAttrs(nl.ID, nl.Width, nl.Height, nl.ExpandedWidth, nl.ExpandedHeight, nl.Scalable, nl.MaintainAspectRatio, nl.MinSuggestedDuration, nl.APIFramework)
+	e.openEnd()
+	e.htmlResource(nl.HTMLResource)
+	e.iframeResource(nl.IFrameResource)
+	e.staticResource(nl.StaticResource)
+	e.adParameters(nl.AdParameters)
+	if nl.NonLinearClickThrough != nil {
+		e.cdataElement("NonLinearClickThrough", string(nl.NonLinearClickThrough.URI))
+	}
+	e.nonLinearClickTrackings(nl.NonLinearClickTrackings)
+	e.end("NonLinear")
+}
+
+func (e *fastEncoder) nonLinearWrapper(nl *NonLinearWrapper) {
+	e.open("NonLinear")
+	e.nonLinearAttrs(nl.ID, nl.Width, nl.Height, nl.ExpandedWidth, nl.ExpandedHeight, nl.Scalable, nl.MaintainAspectRatio, nl.MinSuggestedDuration, nl.APIFramework)
+	e.openEnd()
+	e.trackingEvents(nl.TrackingEvents)
+	e.nonLinearClickTrackings(nl.NonLinearClickTrackings)
+	e.end("NonLinear")
+}
+
+func (e *fastEncoder) icons(icons *Icons) {
+	if icons == nil {
+		return
+	}
+	e.start("Icons")
+	for i := range icons.Icon {
+		e.icon(&icons.Icon[i])
+	}
+	e.end("Icons")
+}
+
+func (e *fastEncoder) icon(icon *Icon) {
+	e.open("Icon")
+	e.attr("program", icon.Program)
+	e.attrInt("width", icon.Width)
+	e.attrInt("height", icon.Height)
+	e.attr("xPosition", string(icon.XPosition))
+	e.attr("yPosition", string(icon.YPosition))
+	e.attrOffset("offset", icon.Offset)
+	e.attrDuration("duration", icon.Duration)
+	e.attrOmitEmpty("apiFramework", icon.APIFramework)
+	e.openEnd()
+	e.htmlResource(icon.HTMLResource)
+	e.iframeResource(icon.IFrameResource)
+	e.staticResource(icon.StaticResource)
+	e.start("IconClicks")
+	if icon.IconClickThrough != nil {
+		e.cdataElement("IconClickThrough", string(icon.IconClickThrough.URI))
+	}
+	e.cdataURIs("IconClickTracking", icon.IconClickTrackings)
+	if images := icon.IconClickFallbackImages; images != nil {
+		e.start("IconClickFallbackImages")
+		for _, img := range *images {
+			e.open("IconClickFallbackImage")
+			e.attrIntOmitEmpty("width", img.Width)
+			e.attrIntOmitEmpty("height", img.Height)
+			e.openEnd()
+			if img.AltText != "" {
+				e.textElement("AltText", img.AltText)
+			}
+			e.staticResource(img.StaticResource)
+			e.end("IconClickFallbackImage")
+		}
+		e.end("IconClickFallbackImages")
+	}
+	e.end("IconClicks")
+	if icon.IconViewTracking != nil {
+		e.cdataElement("IconViewTracking", string(icon.IconViewTracking.URI))
+	}
+	e.end("Icon")
+}
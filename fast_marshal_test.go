@@ -0,0 +1,238 @@
+package vast
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// kitchenSink returns a document setting every field of the VAST tree.
+func kitchenSink() *VAST {
+	d := Duration(2500 * time.Millisecond)
+	tracking := []Tracking{
+		{Event: "start", URI: "https://t.example.com/start?a=1&b=2"},
+		{Event: "progress", Offset: OffsetDuration(5 * time.Second), URI: "https://t.example.com/p", UA: "ua"},
+		{Event: "progress", Offset: OffsetPercent(.5), URI: "https://t.example.com/half"},
+	}
+	exts := []Extension{
+		{Type: "waterfall", Data: `<Waterfall fallbackIndex="1"/>`},
+		{Type: "custom", CustomTracking: []Tracking{{Event: "ping", URI: "https://t.example.com/ping"}}},
+		{Attrs: []xml.Attr{{Name: xml.Name{Local: "source"}, Value: "x&y"}}, Data: "<Foo>bar</Foo>"},
+	}
+	verifications := []Verification{{
+		Vendor:                 "vendor.com-omid",
+		JavaScriptResources:    []JavaScriptResource{{APIFramework: "omid", BrowserOptional: true, URI: "https://v.example.com/omid.js"}},
+		ExecutableResources:    []ExecutableResource{{APIFramework: "omid", Type: "application/x", URI: "https://v.example.com/x"}},
+		TrackingEvents:         []Tracking{{Event: "verificationNotExecuted", URI: "https://v.example.com/ne?r=[REASON]"}},
+		VerificationParameters: &CDATAString{CDATA: `{"a":"]]>"}`},
+	}}
+	icons := &Icons{Icon: []Icon{{
+		Program: "AdChoices", Width: 20, Height: 20, XPosition: "right", YPosition: "top",
+		Offset: *OffsetDuration(time.Second), Duration: d, APIFramework: "none",
+		StaticResource:          &StaticResource{CreativeType: "image/png", URI: "https://i.example.com/icon.png"},
+		IconClickThrough:        &CDATAURI{URI: "https://i.example.com/click"},
+		IconClickTrackings:      []CDATAURI{{URI: "https://i.example.com/ct"}},
+		IconClickFallbackImages: &[]IconClickFallbackImage{{Width: 10, Height: 10, AltText: "alt <text>", StaticResource: &StaticResource{URI: "https://i.example.com/fb.png"}}},
+		IconViewTracking:        &CDATAURI{URI: "https://i.example.com/view"},
+	}, {
+		Program: "other", HTMLResource: &HTMLResource{XMLEncoded: true, HTML: "&lt;p&gt;"}, IFrameResource: &CDATAURI{URI: "https://i.example.com/frame"},
+		IconClickTrackings: []CDATAURI{{URI: "https://i.example.com/ct2"}},
+	}}}
+	clicks := &VideoClicks{
+		ClickTrackings: []VideoClick{{ID: "c1", URI: "https://c.example.com/track"}},
+		CustomClicks:   []VideoClick{{URI: "https://c.example.com/custom"}},
+		ClickThroughs:  []VideoClick{{URI: "https://c.example.com/through"}},
+	}
+	cats := []Category{{Authority: IABCategoryAuthority, Code: "IAB1-6"}, {Code: "a\"b'c"}}
+	return &VAST{
+		Version: "4.2",
+		XMLNS:   "http://www.iab.com/VAST",
+		Mute:    true,
+		Errors:  []CDATAURI{{URI: "https://e.example.com/[ERRORCODE]"}},
+		Ads: []Ad{{
+			ID: "inline", Sequence: 1, AdType: AdTypeVideo, ConditionalAd: NewBool(false),
+			InLine: &InLine{
+				AdSystem:           &AdSystem{Version: "1.0", Name: "Sys & Co"},
+				Errors:             []CDATAURI{{URI: "https://e.example.com/inline"}, {}},
+				Extensions:         &exts,
+				Impressions:        []Impression{{ID: "imp", URI: "https://i.example.com/imp"}},
+				ViewableImpression: &ViewableImpression{ID: "vi", Viewable: []CDATAURI{{URI: "v"}}, NotViewable: []CDATAURI{{URI: "nv"}}, ViewUndetermined: []CDATAURI{{URI: "vu"}}},
+				Pricing:            &Pricing{Model: PricingCPM, Currency: "USD", Value: "1.50"},
+				AdServingId:        "srv-1\tx",
+				AdTitle:            CDATAString{CDATA: "Title ]]> end"},
+				Advertiser:         "Acme\n& Sons",
+				Categories:         cats,
+				Creatives: []Creative{{
+					ID: "cr", Sequence: 1, AdID: "ad", APIFramework: "SIMID",
+					UniversalAdID: &UniversalAdID{IDRegistry: "ad-id.org", ID: "CNPA0484000H"},
+					Linear: &Linear{
+						SkipOffset:     OffsetPercent(.25),
+						Icons:          icons,
+						TrackingEvents: tracking,
+						AdParameters:   &AdParameters{XMLEncoded: true, Parameters: `{"k":1}`},
+						Duration:       Duration(30500 * time.Millisecond),
+						MediaFiles: []MediaFile{{
+							ID: "m", Delivery: DeliveryProgressive, Type: "video/mp4", Codec: "avc1", Bitrate: 800, MinBitrate: 500, MaxBitrate: 1000,
+							Width: 640, Height: 360, Scalable: true, MaintainAspectRatio: true, APIFramework: "VPAID", URI: "https://m.example.com/a.mp4", FileSize: 1234, MediaType: "2D",
+						}, {Delivery: DeliveryStreaming, Type: "application/x-mpegURL"}},
+						Mezzanines:               []Mezzanine{{ID: "mz", Delivery: DeliveryProgressive, Type: "video/mp4", Width: 1920, Height: 1080, Codec: "h264", FileSize: 99, MediaType: "2D", URI: "https://m.example.com/mezz.mp4"}},
+						InteractiveCreativeFiles: []InteractiveCreativeFile{{Type: "text/html", APIFramework: "SIMID", VariableDuration: true, URI: "https://m.example.com/simid.html"}},
+						ClosedCaptionFiles:       &ClosedCaptionFiles{ClosedCaptionFile: []ClosedCaptionFile{{Type: "text/vtt", Language: "en", URI: "https://m.example.com/en.vtt"}}},
+						VideoClicks:              clicks,
+					},
+					CreativeExtensions: &exts,
+				}, {
+					CompanionAds: &CompanionAds{Required: "any", Companions: []Companion{{
+						ID: "co", Width: 300, Height: 250, AssetWidth: 300, AssetHeight: 250, ExpandedWidth: 600, ExpandedHeight: 500, APIFramework: "x", AdSlotID: "slot",
+						HTMLResource: &HTMLResource{HTML: "<b>html</b>"}, IFrameResource: &CDATAURI{URI: "https://co.example.com/frame"},
+						StaticResource: &StaticResource{CreativeType: "image/jpeg", URI: "https://co.example.com/a.jpg"},
+						AdParameters:   &AdParameters{Parameters: "p"}, AltText: "alt", CompanionClickThrough: &CDATAURI{URI: "https://co.example.com/click"},
+						CompanionClickTrackings: []CompanionClickTracking{{ID: "cct", URI: "https://co.example.com/cct"}},
+						TrackingEvents:          []Tracking{{Event: "creativeView", URI: "https://co.example.com/view"}},
+					}, {}}},
+				}, {
+					NonLinearAds: &NonLinearAds{TrackingEvents: tracking, NonLinears: []NonLinear{{
+						ID: "nl", Width: 300, Height: 50, ExpandedWidth: 600, ExpandedHeight: 100, Scalable: true, MaintainAspectRatio: true, MinSuggestedDuration: &d, APIFramework: "y",
+						HTMLResource: &HTMLResource{HTML: "h"}, IFrameResource: &CDATAURI{URI: "f"}, StaticResource: &StaticResource{URI: "s"},
+						AdParameters: &AdParameters{Parameters: "p"}, NonLinearClickThrough: &CDATAURI{URI: "ct"},
+						NonLinearClickTrackings: []NonLinearClickTracking{{ID: "nlct", URI: "https://nl.example.com/ct"}},
+					}, {}}},
+				}, {
+					Linear: &Linear{Mezzanines: []Mezzanine{{URI: "https://m.example.com/only.mp4"}}, Icons: &Icons{}},
+				}, {
+					Linear: &Linear{ClosedCaptionFiles: &ClosedCaptionFiles{}},
+				}},
+				Description:     &CDATAString{CDATA: "desc"},
+				Expires:         NewExpires(time.Hour),
+				Surveys:         []Survey{{Type: "text/javascript", URI: "https://s.example.com/survey"}},
+				AdVerifications: &verifications,
+			},
+		}, {
+			ID: "wrapper",
+			Wrapper: &Wrapper{
+				AdSystem:           &AdSystem{Name: "SSP"},
+				Errors:             []CDATAURI{{URI: "https://e.example.com/wrapper"}},
+				Extensions:         exts,
+				Impressions:        []Impression{{URI: "https://i.example.com/wimp"}},
+				ViewableImpression: &ViewableImpression{},
+				Creatives: []CreativeWrapper{{
+					ID: "cw", Sequence: 2, AdID: "ad",
+					Linear: &LinearWrapper{Icons: icons, TrackingEvents: tracking, VideoClicks: clicks},
+				}, {
+					CompanionAds: &CompanionAdsWrapper{Required: "all", Companions: []CompanionWrapper{{
+						ID: "cw", Width: 1, Height: 2, APIFramework: "a", AdSlotID: "s",
+						CompanionClickThrough:   &CDATAURI{URI: "ct"},
+						CompanionClickTrackings: []CompanionClickTracking{{URI: "cct"}},
+						AltText:                 "alt", TrackingEvents: tracking, AdParameters: &AdParameters{Parameters: "p"},
+						StaticResource: &StaticResource{URI: "s"}, IFrameResource: &CDATAURI{URI: "f"}, HTMLResource: &HTMLResource{HTML: "h"},
+					}, {}}},
+					NonLinearAds: &NonLinearAdsWrapper{TrackingEvents: tracking, NonLinears: []NonLinearWrapper{{
+						ID: "nlw", Width: 1, Height: 2, Scalable: true, MinSuggestedDuration: &d, APIFramework: "a",
+						TrackingEvents:          tracking,
+						NonLinearClickTrackings: []NonLinearClickTracking{{URI: "ct"}},
+					}}},
+				}},
+				VASTAdTagURI:             CDATAURI{URI: "https://w.example.com/vast.xml"},
+				AdVerifications:          &[]Verification{},
+				FallbackOnNoAd:           NewBool(true),
+				AllowMultipleAds:         NewBool(false),
+				FollowAdditionalWrappers: NewBool(true),
+			},
+		}, {
+			ID:     "empty",
+			InLine: &InLine{Extensions: &[]Extension{}},
+		}, {
+			Wrapper: &Wrapper{},
+		}},
+	}
+}
+
+func TestFastMarshal(t *testing.T) {
+	docs := map[string]*VAST{"kitchen sink": kitchenSink(), "empty": {}}
+	for _, kind := range []AdKind{AdKindInLineLinear, AdKindWrapper, AdKindNonLinear, AdKindAudio} {
+		doc, err := Skeleton(Version4_2, kind)
+		if assert.NoError(t, err) {
+			docs[kind.String()] = doc
+		}
+	}
+	files, _ := filepath.Glob("testdata/*.xml")
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		var doc VAST
+		if assert.NoError(t, xml.Unmarshal(b, &doc), file) {
+			docs[file] = &doc
+		}
+	}
+	// invalid XML characters are replaced, as done by encoding/xml
+	docs["invalid characters"] = &VAST{Version: "a\x00b\xffcé\U0001F600", Errors: []CDATAURI{{URI: "\x01"}}}
+	// the prefixes of colliding namespaces are numbered across the document
+	ns := []xml.Attr{
+		{Name: xml.Name{Space: "http://a.example.com/ns", Local: "attr"}, Value: "a"},
+		{Name: xml.Name{Space: "http://b.example.com/ns", Local: "attr"}, Value: "b"},
+	}
+	namespaced := kitchenSink()
+	namespaced.Ads[0].InLine.Extensions = &[]Extension{{Type: "t", Attrs: ns}}
+	namespaced.Ads[0].InLine.Creatives[0].CreativeExtensions = &[]Extension{{Type: "t", Attrs: ns}}
+	docs["namespaced"] = namespaced
+
+	for name, doc := range docs {
+		want, err := xml.Marshal(doc)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		got, err := FastMarshal(doc)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, string(want), string(got), name)
+		}
+	}
+}
+
+func TestFastMarshalErrors(t *testing.T) {
+	doc := kitchenSink()
+	doc.Ads[0].InLine.Creatives[0].Linear.Duration = Duration(-time.Second)
+	_, err := xml.Marshal(doc)
+	assert.Error(t, err)
+	_, err = FastMarshal(doc)
+	assert.EqualError(t, err, "invalid duration: -1s")
+
+	doc = kitchenSink()
+	*doc.Ads[0].InLine.Expires = -1
+	_, err = FastMarshal(doc)
+	assert.EqualError(t, err, "invalid expires: -1")
+
+	b, err := FastMarshal(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, b)
+
+	dst := []byte("prefix")
+	dst, err = AppendFastMarshal(dst, &VAST{Version: "4.2"})
+	assert.NoError(t, err)
+	assert.Equal(t, `prefix<VAST version="4.2"></VAST>`, string(dst))
+}
+
+func BenchmarkFastMarshal(b *testing.B) {
+	doc := kitchenSink()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := FastMarshal(doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkXMLMarshal(b *testing.B) {
+	doc := kitchenSink()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := xml.Marshal(doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package vast
+
+import "fmt"
+
+// Flatten merges a resolved chain of Wrapper ads and the terminal InLine ad
+// into a single InLine ad, following the VAST wrapper rules:
+//
+//   - impressions, viewable impressions, error URIs, extensions and
+//     verifications of every wrapper are added;
+//   - linear tracking events, click trackings and icons of the wrappers are
+//     added to every linear creative;
+//   - non-linear tracking events and click trackings are added to every
+//     non-linear creative;
+//   - companion creativeView and click trackings are added to every companion;
+//   - click-throughs of the wrappers are ignored, only the InLine one is kept.
+//
+// wrappers is ordered from the outermost wrapper to the innermost one. The
+// flattened ad keeps the ID of the InLine ad and the sequence of the outermost
+// wrapper. Neither wrappers nor inline are modified.
+func Flatten(wrappers []*Ad, inline *Ad) (*Ad, error) {
+	if inline == nil || inline.InLine == nil {
+		return nil, fmt.Errorf("flatten: terminal ad is not an InLine ad")
+	}
+	ad := inline.Clone()
+	for i, w := range wrappers {
+		if w == nil || w.Wrapper == nil {
+			return nil, fmt.Errorf("flatten: ad %d of the chain is not a Wrapper ad", i)
+		}
+		mergeWrapper(ad.InLine, w.Clone().Wrapper)
+	}
+	if len(wrappers) > 0 {
+		ad.Sequence = wrappers[0].Sequence
+	}
+	return ad, nil
+}
+
+// mergeWrapper adds the trackers of w to in. w must not be used afterward.
+func mergeWrapper(in *InLine, w *Wrapper) {
+	in.Impressions = append(in.Impressions, w.Impressions...)
+	if vi := w.ViewableImpression; vi != nil {
+		if in.ViewableImpression == nil {
+			in.ViewableImpression = &ViewableImpression{ID: vi.ID}
+		}
+		in.ViewableImpression.Viewable = append(in.ViewableImpression.Viewable, vi.Viewable...)
+		in.ViewableImpression.NotViewable = append(in.ViewableImpression.NotViewable, vi.NotViewable...)
+		in.ViewableImpression.ViewUndetermined = append(in.ViewableImpression.ViewUndetermined, vi.ViewUndetermined...)
+	}
+	in.Errors = append(in.Errors, w.Errors...)
+	if len(w.Extensions) > 0 {
+		if in.Extensions == nil {
+			in.Extensions = &[]Extension{}
+		}
+		*in.Extensions = append(*in.Extensions, w.Extensions...)
+	}
+	if w.AdVerifications != nil && len(*w.AdVerifications) > 0 {
+		if in.AdVerifications == nil {
+			in.AdVerifications = &[]Verification{}
+		}
+		*in.AdVerifications = append(*in.AdVerifications, *w.AdVerifications...)
+	}
+	for _, wc := range w.Creatives {
+		if wc.Linear != nil {
+			for i := range in.Creatives {
+				if l := in.Creatives[i].Linear; l != nil {
+					mergeLinearWrapper(l, wc.Linear)
+				}
+			}
+		}
+		if wc.NonLinearAds != nil {
+			for i := range in.Creatives {
+				if nl := in.Creatives[i].NonLinearAds; nl != nil {
+					mergeNonLinearAdsWrapper(nl, wc.NonLinearAds)
+				}
+			}
+		}
+		if wc.CompanionAds != nil {
+			for i := range in.Creatives {
+				if ca := in.Creatives[i].CompanionAds; ca != nil {
+					mergeCompanionAdsWrapper(ca, wc.CompanionAds)
+				}
+			}
+		}
+	}
+}
+
+func mergeLinearWrapper(l *Linear, w *LinearWrapper) {
+	l.TrackingEvents = append(l.TrackingEvents, w.TrackingEvents...)
+	if w.VideoClicks != nil && (len(w.VideoClicks.ClickTrackings) > 0 || len(w.VideoClicks.CustomClicks) > 0) {
+		if l.VideoClicks == nil {
+			l.VideoClicks = &VideoClicks{}
+		}
+		l.VideoClicks.ClickTrackings = append(l.VideoClicks.ClickTrackings, w.VideoClicks.ClickTrackings...)
+		l.VideoClicks.CustomClicks = append(l.VideoClicks.CustomClicks, w.VideoClicks.CustomClicks...)
+	}
+	if w.Icons != nil && len(w.Icons.Icon) > 0 {
+		if l.Icons == nil {
+			l.Icons = &Icons{}
+		}
+		l.Icons.Icon = append(l.Icons.Icon, w.Icons.Icon...)
+	}
+}
+
+func mergeNonLinearAdsWrapper(nl *NonLinearAds, w *NonLinearAdsWrapper) {
+	nl.TrackingEvents = append(nl.TrackingEvents, w.TrackingEvents...)
+	for _, wnl := range w.NonLinears {
+		for i := range nl.NonLinears {
+			nl.NonLinears[i].NonLinearClickTrackings = append(nl.NonLinears[i].NonLinearClickTrackings, wnl.NonLinearClickTrackings...)
+		}
+		nl.TrackingEvents = append(nl.TrackingEvents, wnl.TrackingEvents...)
+	}
+}
+
+func mergeCompanionAdsWrapper(ca *CompanionAds, w *CompanionAdsWrapper) {
+	for _, wc := range w.Companions {
+		for i := range ca.Companions {
+			c := &ca.Companions[i]
+			c.TrackingEvents = append(c.TrackingEvents, wc.TrackingEvents...)
+			c.CompanionClickTrackings = append(c.CompanionClickTrackings, wc.CompanionClickTrackings...)
+		}
+	}
+}
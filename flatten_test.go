@@ -0,0 +1,102 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatten(t *testing.T) {
+	w, _, _, err := loadFixture("testdata/vast_wrapper_linear_1.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	in, _, _, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	wrapper := &w.Ads[0]
+	wrapper.Sequence = 2
+	wrapper.Wrapper.Creatives[0].Linear.Icons = &Icons{Icon: []Icon{{Program: "AdChoices"}}}
+	wrapper.Wrapper.Extensions = []Extension{{Type: "wrapper"}}
+	wrapper.Wrapper.AdVerifications = &[]Verification{{Vendor: "wrapper.com-omid"}}
+	wrapper.Wrapper.ViewableImpression = &ViewableImpression{ID: "vi", Viewable: []CDATAURI{{"http://myTrackingURL/wrapper/viewable"}}}
+	inline := &in.Ads[0]
+
+	ad, err := Flatten([]*Ad{wrapper}, inline)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "601364", ad.ID)
+	assert.Equal(t, 2, ad.Sequence)
+	assert.Nil(t, ad.Wrapper)
+	if assert.NotNil(t, ad.InLine) {
+		assert.Len(t, ad.InLine.Impressions, 3)
+		assert.Equal(t, URI("http://myTrackingURL/wrapper/impression"), ad.InLine.Impressions[2].URI)
+		assert.Len(t, ad.InLine.Errors, 3)
+		if assert.NotNil(t, ad.InLine.Extensions) {
+			assert.Equal(t, []Extension{{Type: "wrapper"}}, *ad.InLine.Extensions)
+		}
+		if assert.NotNil(t, ad.InLine.ViewableImpression) {
+			assert.Equal(t, ViewableImpression{ID: "vi", Viewable: []CDATAURI{{"http://myTrackingURL/wrapper/viewable"}}}, *ad.InLine.ViewableImpression)
+		}
+		if assert.NotNil(t, ad.InLine.AdVerifications) {
+			assert.Equal(t, []Verification{{Vendor: "wrapper.com-omid"}}, *ad.InLine.AdVerifications)
+		}
+		linear := ad.InLine.Creatives[0].Linear
+		assert.Len(t, linear.TrackingEvents, 6+11)
+		if assert.NotNil(t, linear.VideoClicks) {
+			assert.Len(t, linear.VideoClicks.ClickThroughs, 1)
+			assert.Equal(t, URI("http://myTrackingURL/wrapper/click"), linear.VideoClicks.ClickTrackings[len(linear.VideoClicks.ClickTrackings)-1].URI)
+		}
+		if assert.NotNil(t, linear.Icons) {
+			assert.Equal(t, "AdChoices", linear.Icons.Icon[0].Program)
+		}
+	}
+
+	// the inputs are left untouched
+	assert.Len(t, inline.InLine.Impressions, 2)
+	assert.Len(t, inline.InLine.Creatives[0].Linear.TrackingEvents, 6)
+	assert.Nil(t, inline.InLine.Extensions)
+	assert.Nil(t, inline.InLine.AdVerifications)
+	assert.Nil(t, inline.InLine.ViewableImpression)
+}
+
+func TestFlattenNonLinear(t *testing.T) {
+	inline := &Ad{InLine: &InLine{Creatives: []Creative{
+		{NonLinearAds: &NonLinearAds{NonLinears: []NonLinear{{ID: "nl"}}}},
+		{CompanionAds: &CompanionAds{Companions: []Companion{{ID: "c"}}}},
+	}}}
+	wrapper := &Ad{Wrapper: &Wrapper{Creatives: []CreativeWrapper{
+		{NonLinearAds: &NonLinearAdsWrapper{
+			TrackingEvents: []Tracking{{Event: "expand", URI: "http://w/expand"}},
+			NonLinears:     []NonLinearWrapper{{NonLinearClickTrackings: []NonLinearClickTracking{{URI: "http://w/nlclick"}}}},
+		}},
+		{CompanionAds: &CompanionAdsWrapper{Companions: []CompanionWrapper{{
+			CompanionClickTrackings: []CompanionClickTracking{{URI: "http://w/cclick"}},
+			TrackingEvents:          []Tracking{{Event: "creativeView", URI: "http://w/cview"}},
+		}}}},
+	}}}
+
+	ad, err := Flatten([]*Ad{wrapper, wrapper}, inline)
+	if !assert.NoError(t, err) {
+		return
+	}
+	nl := ad.InLine.Creatives[0].NonLinearAds
+	assert.Len(t, nl.TrackingEvents, 2)
+	assert.Equal(t, []NonLinearClickTracking{{URI: "http://w/nlclick"}, {URI: "http://w/nlclick"}}, nl.NonLinears[0].NonLinearClickTrackings)
+	c := ad.InLine.Creatives[1].CompanionAds.Companions[0]
+	assert.Len(t, c.TrackingEvents, 2)
+	assert.Len(t, c.CompanionClickTrackings, 2)
+}
+
+func TestFlattenErrors(t *testing.T) {
+	_, err := Flatten(nil, &Ad{Wrapper: &Wrapper{}})
+	assert.EqualError(t, err, "flatten: terminal ad is not an InLine ad")
+	_, err = Flatten([]*Ad{{InLine: &InLine{}}}, &Ad{InLine: &InLine{}})
+	assert.EqualError(t, err, "flatten: ad 0 of the chain is not a Wrapper ad")
+	ad, err := Flatten(nil, &Ad{ID: "1", Sequence: 3, InLine: &InLine{}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, ad.Sequence)
+	}
+}
@@ -0,0 +1,200 @@
+// Package freqcap caps the number of times a user is exposed to a creative,
+// identified by its universal ad id or, failing that, its creative id.
+package freqcap
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// Store records the exposures of users to creatives. Implementations, backed
+// by a shared database in production, must be safe for concurrent use.
+type Store interface {
+	// Count returns the number of exposures of user to the creative after
+	// the given time.
+	Count(ctx context.Context, user, creative string, since time.Time) (int, error)
+	// Record adds an exposure of user to the creative at the given time.
+	Record(ctx context.Context, user, creative string, at time.Time) error
+}
+
+// Cap is the maximum number of exposures to a creative within a rolling
+// window.
+type Cap struct {
+	Max    int
+	Window time.Duration
+}
+
+// Capper removes the ads of the creatives a user has been exposed to too
+// often.
+type Capper struct {
+	Store Store
+	// Cap applied to the creatives without one in Caps. Creatives aren't
+	// capped if its Max is 0.
+	Default Cap
+	// Caps of specific creatives, by Key
+	Caps map[string]Cap
+
+	now func() time.Time
+}
+
+// Key returns the key identifying a creative in the store: the registry and
+// value of its universal ad id, such as "ad-id.org/CNPA0484000H", or
+// "creative/" followed by its id if it has none. It returns an empty string
+// if the creative can't be identified.
+func Key(c *vast.Creative) string {
+	if id := c.UniversalAdID; id != nil && id.ID != "" && id.ID != "unknown" {
+		return id.IDRegistry + "/" + id.ID
+	}
+	if c.ID != "" {
+		return "creative/" + c.ID
+	}
+	return ""
+}
+
+// Keys returns the distinct keys of the creatives of an InLine ad.
+func Keys(ad *vast.Ad) []string {
+	if ad.InLine == nil {
+		return nil
+	}
+	var keys []string
+	seen := map[string]bool{}
+	for i := range ad.InLine.Creatives {
+		if k := Key(&ad.InLine.Creatives[i]); k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Filter removes from doc the ads with a creative the user has reached the
+// cap of, and returns them. The ads kept count as exposures for the following
+// ones, so that a pod doesn't repeat a creative beyond its cap. Ads without
+// identifiable creatives are always kept, and so are all the ads of
+// anonymous users, whose user is empty, rather than capping them as a single
+// user. The removed ads are recorded in the vast.DecisionTrace of ctx.
+func (c *Capper) Filter(ctx context.Context, user string, doc *vast.VAST) ([]vast.Ad, error) {
+	if user == "" {
+		return nil, nil
+	}
+	now := c.clock()
+	trace := vast.DecisionTraceFrom(ctx)
+	counts := map[string]int{}
+	var kept, removed []vast.Ad
+	for _, ad := range doc.Ads {
+		keys := Keys(&ad)
+		capped := false
+		for _, k := range keys {
+			limit := c.cap(k)
+			if limit.Max <= 0 {
+				continue
+			}
+			n, ok := counts[k]
+			if !ok {
+				var err error
+				if n, err = c.Store.Count(ctx, user, k, now.Add(-limit.Window)); err != nil {
+					return nil, err
+				}
+				counts[k] = n
+			}
+			if n >= limit.Max {
+				capped = true
+				trace.Reject("freqcap", ad.ID, "frequency cap of creative "+k+" reached")
+				break
+			}
+		}
+		if capped {
+			removed = append(removed, ad)
+			continue
+		}
+		for _, k := range keys {
+			counts[k]++
+		}
+		kept = append(kept, ad)
+	}
+	doc.Ads = kept
+	return removed, nil
+}
+
+// Record records the exposure of user to the creatives of ad, typically once
+// its impression has been tracked. Anonymous users, whose user is empty, are
+// not tracked.
+func (c *Capper) Record(ctx context.Context, user string, ad *vast.Ad) error {
+	if user == "" {
+		return nil
+	}
+	now := c.clock()
+	for _, k := range Keys(ad) {
+		if err := c.Store.Record(ctx, user, k, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Capper) cap(key string) Cap {
+	if limit, ok := c.Caps[key]; ok {
+		return limit
+	}
+	return c.Default
+}
+
+func (c *Capper) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// MemoryStore is an in-memory Store, for tests and single-instance servers.
+// Exposures older than Retention are dropped as new ones are recorded.
+type MemoryStore struct {
+	// How long exposures are kept, 30 days if 0
+	Retention time.Duration
+
+	mu        sync.Mutex
+	exposures map[string][]time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Count implements the Store interface.
+func (s *MemoryStore) Count(ctx context.Context, user, creative string, since time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, at := range s.exposures[user+"\x00"+creative] {
+		if at.After(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Record implements the Store interface.
+func (s *MemoryStore) Record(ctx context.Context, user, creative string, at time.Time) error {
+	retention := s.Retention
+	if retention == 0 {
+		retention = 30 * 24 * time.Hour
+	}
+	key := user + "\x00" + creative
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.exposures == nil {
+		s.exposures = map[string][]time.Time{}
+	}
+	kept := s.exposures[key][:0]
+	for _, t := range s.exposures[key] {
+		if at.Sub(t) < retention {
+			kept = append(kept, t)
+		}
+	}
+	s.exposures[key] = append(kept, at)
+	return nil
+}
@@ -0,0 +1,117 @@
+package freqcap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func ad(id string, creatives ...vast.Creative) vast.Ad {
+	return vast.Ad{ID: id, InLine: &vast.InLine{Creatives: creatives}}
+}
+
+func TestKeys(t *testing.T) {
+	a := ad("a",
+		vast.Creative{ID: "c1", UniversalAdID: &vast.UniversalAdID{IDRegistry: "ad-id.org", ID: "CNPA0484000H"}},
+		vast.Creative{ID: "c2", UniversalAdID: &vast.UniversalAdID{IDRegistry: "unknown", ID: "unknown"}},
+		vast.Creative{ID: "c2"},
+		vast.Creative{},
+	)
+	assert.Equal(t, []string{"ad-id.org/CNPA0484000H", "creative/c2"}, Keys(&a))
+	assert.Nil(t, Keys(&vast.Ad{Wrapper: &vast.Wrapper{}}))
+}
+
+func TestCapper(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	c := &Capper{
+		Store:   store,
+		Default: Cap{Max: 2, Window: time.Hour},
+		Caps:    map[string]Cap{"creative/once": {Max: 1, Window: 24 * time.Hour}},
+		now:     func() time.Time { return now },
+	}
+	ctx := context.Background()
+	pod := func() *vast.VAST {
+		return &vast.VAST{Ads: []vast.Ad{
+			ad("1", vast.Creative{ID: "twice"}),
+			ad("2", vast.Creative{ID: "once"}),
+			ad("3", vast.Creative{ID: "twice"}),
+			ad("4", vast.Creative{ID: "twice"}),
+			ad("5", vast.Creative{}),
+		}}
+	}
+
+	doc := pod()
+	trace := vast.NewDecisionTrace()
+	removed, err := c.Filter(vast.WithDecisionTrace(ctx, trace), "u1", doc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "5"}, ids(doc.Ads))
+	assert.Equal(t, []string{"4"}, ids(removed))
+	assert.Equal(t, []vast.TraceRejection{{Stage: "freqcap", AdID: "4", Reason: "frequency cap of creative creative/twice reached"}}, trace.Rejections())
+
+	for i := range doc.Ads {
+		assert.NoError(t, c.Record(ctx, "u1", &doc.Ads[i]))
+	}
+	doc = pod()
+	_, _ = c.Filter(ctx, "u1", doc)
+	assert.Equal(t, []string{"5"}, ids(doc.Ads))
+	doc = pod()
+	_, _ = c.Filter(ctx, "u2", doc)
+	assert.Len(t, doc.Ads, 4)
+
+	// anonymous users aren't capped
+	for i := 0; i < 3; i++ {
+		doc = pod()
+		removed, err = c.Filter(ctx, "", doc)
+		assert.NoError(t, err)
+		assert.Empty(t, removed)
+		assert.Len(t, doc.Ads, 5)
+		for i := range doc.Ads {
+			assert.NoError(t, c.Record(ctx, "", &doc.Ads[i]))
+		}
+	}
+
+	// the window of "twice" has passed, not the one of "once"
+	now = now.Add(time.Hour)
+	doc = pod()
+	_, _ = c.Filter(ctx, "u1", doc)
+	assert.Equal(t, []string{"1", "3", "5"}, ids(doc.Ads))
+
+	c.Store = failing{}
+	doc = pod()
+	_, err = c.Filter(ctx, "u1", doc)
+	assert.EqualError(t, err, "down")
+	assert.Len(t, doc.Ads, 5)
+}
+
+func TestMemoryStoreRetention(t *testing.T) {
+	s := &MemoryStore{Retention: time.Hour}
+	ctx := context.Background()
+	now := time.Now()
+	assert.NoError(t, s.Record(ctx, "u", "c", now.Add(-2*time.Hour)))
+	assert.NoError(t, s.Record(ctx, "u", "c", now))
+	n, _ := s.Count(ctx, "u", "c", time.Time{})
+	assert.Equal(t, 1, n)
+}
+
+type failing struct{}
+
+func (failing) Count(context.Context, string, string, time.Time) (int, error) {
+	return 0, errors.New("down")
+}
+
+func (failing) Record(context.Context, string, string, time.Time) error {
+	return errors.New("down")
+}
+
+func ids(ads []vast.Ad) []string {
+	var out []string
+	for _, a := range ads {
+		out = append(out, a.ID)
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package vast
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"sync"
+)
+
+// Frozen is an immutable document memoizing its encodings, for documents
+// served many times as is, such as house ads. It is safe for concurrent use.
+//
+//	house := vast.Freeze(doc)
+//	http.HandleFunc("/house", func(w http.ResponseWriter, r *http.Request) {
+//		body, err := house.XML()
+//		...
+//	})
+type Frozen struct {
+	doc *VAST
+
+	xmlOnce sync.Once
+	xml     []byte
+	xmlErr  error
+
+	gzipOnce sync.Once
+	gzip     []byte
+	gzipErr  error
+}
+
+// Freeze returns a frozen copy of v, unaffected by the later changes of v.
+func Freeze(v *VAST) *Frozen {
+	if v == nil {
+		v = &VAST{}
+	}
+	return &Frozen{doc: v.Clone()}
+}
+
+// VAST returns a copy of the document, which can be modified.
+func (f *Frozen) VAST() *VAST {
+	return f.doc.Clone()
+}
+
+// XML returns the document encoded as XML, preceded by the XML declaration.
+// It is encoded on the first call only, and the bytes returned, shared by
+// every call, must not be modified.
+func (f *Frozen) XML() ([]byte, error) {
+	f.xmlOnce.Do(func() {
+		f.xml, f.xmlErr = AppendFastMarshal([]byte(xml.Header), f.doc)
+	})
+	return f.xml, f.xmlErr
+}
+
+// Gzip returns the bytes of XML compressed with gzip. They are compressed on
+// the first call only, and the bytes returned, shared by every call, must
+// not be modified.
+func (f *Frozen) Gzip() ([]byte, error) {
+	f.gzipOnce.Do(func() {
+		body, err := f.XML()
+		if err != nil {
+			f.gzipErr = err
+			return
+		}
+		var buf bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if _, err := zw.Write(body); err != nil {
+			f.gzipErr = err
+			return
+		}
+		if err := zw.Close(); err != nil {
+			f.gzipErr = err
+			return
+		}
+		f.gzip = buf.Bytes()
+	})
+	return f.gzip, f.gzipErr
+}
@@ -0,0 +1,80 @@
+package vast
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrozen(t *testing.T) {
+	doc, err := NewLinearAd("House", 15*time.Second, []MediaSpec{{URI: "https://cdn.example.com/house.mp4", Type: "video/mp4"}}, []string{"https://t.example.com/imp"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	want, err := xml.Marshal(doc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	want = append([]byte(xml.Header), want...)
+
+	f := Freeze(doc)
+	doc.Ads[0].ID = "changed"
+	copied := f.VAST()
+	copied.Ads[0].InLine.AdTitle = CDATAString{CDATA: "changed"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b, err := f.XML()
+			if assert.NoError(t, err) {
+				assert.Equal(t, string(want), string(b))
+			}
+			z, err := f.Gzip()
+			if assert.NoError(t, err) {
+				zr, err := gzip.NewReader(bytes.NewReader(z))
+				if assert.NoError(t, err) {
+					b, err := ioutil.ReadAll(zr)
+					assert.NoError(t, err)
+					assert.Equal(t, string(want), string(b))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	b1, _ := f.XML()
+	b2, _ := f.XML()
+	assert.True(t, &b1[0] == &b2[0])
+	assert.NotEqual(t, "changed", f.VAST().Ads[0].ID)
+
+	empty, err := Freeze(nil).XML()
+	assert.NoError(t, err)
+	assert.Equal(t, xml.Header+`<VAST version=""></VAST>`, string(empty))
+
+	doc.Ads[0].InLine.Creatives[0].Linear.Duration = Duration(-time.Second)
+	f = Freeze(doc)
+	_, err = f.XML()
+	assert.EqualError(t, err, "invalid duration: -1s")
+	_, err = f.Gzip()
+	assert.EqualError(t, err, "invalid duration: -1s")
+}
+
+func BenchmarkFrozenXML(b *testing.B) {
+	f := Freeze(kitchenSink())
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := f.XML(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,121 @@
+//go:build go1.18
+// +build go1.18
+
+package vast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// The fuzz targets are seeded with testdata/*.xml and the inputs of
+// testdata/fuzz, where go test -fuzz saves the inputs it fails on. Once
+// fixed, a failing input is kept there as a regression test run by go test.
+//
+//	go test -run '^$' -fuzz FuzzUnmarshal -fuzztime 1m
+
+func addFixtures(f *testing.F) {
+	files, err := filepath.Glob("testdata/*.xml")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+}
+
+// FuzzUnmarshal checks that decoding any input doesn't panic, and that the
+// documents decoded survive a round-trip through the encoders.
+func FuzzUnmarshal(f *testing.F) {
+	addFixtures(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v VAST
+		if err := xml.Unmarshal(data, &v); err != nil {
+			return
+		}
+		if l, err := ParseLazy(data); err == nil {
+			if _, err := l.Load(); err != nil {
+				t.Fatalf("lazy load: %v", err)
+			}
+		}
+		v.Validate()
+		v.Clone()
+
+		b, err := xml.Marshal(&v)
+		if err != nil {
+			return
+		}
+		fast, err := FastMarshal(&v)
+		if err != nil {
+			t.Fatalf("fast marshal: %v", err)
+		}
+		if !bytes.Equal(b, fast) {
+			t.Fatalf("fast marshal:\n%s\nwant:\n%s", fast, b)
+		}
+		var again VAST
+		if err := xml.Unmarshal(b, &again); err != nil {
+			t.Fatalf("unmarshal %s: %v", b, err)
+		}
+		b2, err := xml.Marshal(&again)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		if !bytes.Equal(b, b2) {
+			t.Fatalf("round-trip:\n%s\nwant:\n%s", b2, b)
+		}
+	})
+}
+
+// FuzzDuration checks that the durations parsed are encoded back to a
+// duration parsed to the same value.
+func FuzzDuration(f *testing.F) {
+	for _, s := range []string{"00:00:00", "01:02:03.456", "00:00:00.5", "undefined", " 00:01:00 ", "99:59:59.999", "0:0:0"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		var d Duration
+		if err := d.UnmarshalText([]byte(s)); err != nil {
+			return
+		}
+		b, err := d.MarshalText()
+		if err != nil {
+			t.Fatalf("marshal %v: %v", int64(d), err)
+		}
+		var again Duration
+		if err := again.UnmarshalText(b); err != nil || again != d {
+			t.Fatalf("%q: %q parsed to %v, %v, want %v", s, b, int64(again), err, int64(d))
+		}
+	})
+}
+
+// FuzzOffset checks that the offsets parsed are encoded back to an offset
+// parsed to the same value.
+func FuzzOffset(f *testing.F) {
+	for _, s := range []string{"10%", "-5%", "00:00:05", "00:00:05.250", "127%", "undefined"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		var o Offset
+		if err := o.UnmarshalText([]byte(s)); err != nil {
+			return
+		}
+		b, err := o.MarshalText()
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		var again Offset
+		if err := again.UnmarshalText(b); err != nil {
+			t.Fatalf("%q: %q: %v", s, b, err)
+		}
+		if b2, _ := again.MarshalText(); !bytes.Equal(b, b2) {
+			t.Fatalf("%q: %q encoded back to %q", s, b, b2)
+		}
+	})
+}
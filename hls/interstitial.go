@@ -0,0 +1,176 @@
+// Package hls converts VAST ads into HLS interstitials, as defined by the
+// Apple HLS specification, for server-side ad insertion.
+package hls
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// InterstitialClass is the CLASS of the EXT-X-DATERANGE tags describing
+// interstitials.
+const InterstitialClass = "com.apple.hls.interstitial"
+
+// Values of the X-RESTRICT attribute
+const (
+	RestrictSkip = "SKIP"
+	RestrictJump = "JUMP"
+)
+
+// ErrNoAssets is returned when none of the ads has an HLS rendition.
+var ErrNoAssets = errors.New("hls: no ad with an HLS media file and a duration")
+
+// Asset is an ad of an interstitial.
+type Asset struct {
+	// The URI of the multivariant playlist of the ad
+	URI string `json:"URI"`
+	// The duration of the ad in seconds
+	Duration float64 `json:"DURATION"`
+}
+
+// AssetList is the JSON document served at the X-ASSET-LIST URI of an
+// interstitial.
+type AssetList struct {
+	Assets []Asset `json:"ASSETS"`
+}
+
+// Options configures the interstitial built by FromPod.
+type Options struct {
+	// The ID of the date range, unique within the playlist
+	ID string
+	// The time of the content at which the interstitial plays
+	StartDate time.Time
+	// The URI at which the asset list of the interstitial is served, required
+	// for pods of several ads. A single ad is referenced by X-ASSET-URI.
+	AssetListURI string
+	// Where the content resumes after the interstitial, relative to
+	// StartDate. If nil, the attribute is omitted and the player resumes after
+	// the duration of the interstitial, as fit for live content; VOD content
+	// usually resumes at 0.
+	ResumeOffset *time.Duration
+	// Seeking restrictions, RestrictSkip and RestrictJump
+	Restrict []string
+}
+
+// Interstitial is an HLS interstitial, written to a media playlist as an
+// EXT-X-DATERANGE tag.
+type Interstitial struct {
+	ID        string
+	StartDate time.Time
+	// The duration of the ads
+	Duration time.Duration
+	// X-ASSET-URI, for a single ad
+	AssetURI string
+	// X-ASSET-LIST, for a pod, to serve AssetList at
+	AssetListURI string
+	// The ads of the interstitial, to be served at AssetListURI
+	AssetList    AssetList
+	ResumeOffset *time.Duration
+	Restrict     []string
+}
+
+// FromVAST returns the interstitial playing the pod of doc, or its first
+// stand-alone ad if it has no pod.
+func FromVAST(doc *vast.VAST, opts Options) (*Interstitial, error) {
+	pod, standalone := doc.SplitPod()
+	if len(pod) == 0 && len(standalone) > 0 {
+		pod = standalone[:1]
+	}
+	return FromPod(pod, opts)
+}
+
+// FromPod returns the interstitial playing the ads in order. Each ad plays
+// the first HLS media file of its linear creatives; the ads without one or
+// without duration are skipped, and ErrNoAssets is returned if none remains.
+func FromPod(ads []*vast.Ad, opts Options) (*Interstitial, error) {
+	in := &Interstitial{
+		ID:           opts.ID,
+		StartDate:    opts.StartDate,
+		ResumeOffset: opts.ResumeOffset,
+		Restrict:     opts.Restrict,
+	}
+	for _, ad := range ads {
+		uri := hlsMediaFile(ad)
+		d, ok := ad.Duration()
+		if uri == "" || !ok || d <= 0 {
+			continue
+		}
+		in.Duration += d
+		in.AssetList.Assets = append(in.AssetList.Assets, Asset{URI: uri, Duration: d.Seconds()})
+	}
+	switch {
+	case len(in.AssetList.Assets) == 0:
+		return nil, ErrNoAssets
+	case len(in.AssetList.Assets) == 1:
+		in.AssetURI = in.AssetList.Assets[0].URI
+	case opts.AssetListURI == "":
+		return nil, errors.New("hls: an asset list URI is required for a pod")
+	default:
+		in.AssetListURI = opts.AssetListURI
+	}
+	return in, nil
+}
+
+// hlsMediaFile returns the URI of the first HLS media file of the linear
+// creatives of an InLine ad.
+func hlsMediaFile(ad *vast.Ad) string {
+	if ad.InLine == nil {
+		return ""
+	}
+	for _, c := range ad.InLine.Creatives {
+		if c.Linear == nil {
+			continue
+		}
+		files := vast.FilterMediaFiles(c.Linear.MediaFiles, vast.ByMIME(vast.MIMEHLS, vast.MIMEAppleHLS))
+		for _, m := range files {
+			if uri := strings.TrimSpace(string(m.URI)); uri != "" {
+				return uri
+			}
+		}
+	}
+	return ""
+}
+
+// DateRange returns the EXT-X-DATERANGE tag of the interstitial.
+func (in *Interstitial) DateRange() string {
+	var sb strings.Builder
+	sb.WriteString("#EXT-X-DATERANGE:")
+	sb.WriteString("ID=" + quoted(in.ID))
+	sb.WriteString(`,CLASS="` + InterstitialClass + `"`)
+	sb.WriteString(`,START-DATE="` + in.StartDate.Format("2006-01-02T15:04:05.000Z07:00") + `"`)
+	sb.WriteString(",DURATION=" + seconds(in.Duration))
+	if in.AssetURI != "" {
+		sb.WriteString(",X-ASSET-URI=" + quoted(in.AssetURI))
+	}
+	if in.AssetListURI != "" {
+		sb.WriteString(",X-ASSET-LIST=" + quoted(in.AssetListURI))
+	}
+	if in.ResumeOffset != nil {
+		sb.WriteString(",X-RESUME-OFFSET=" + seconds(*in.ResumeOffset))
+	}
+	if len(in.Restrict) > 0 {
+		sb.WriteString(`,X-RESTRICT="` + strings.Join(in.Restrict, ",") + `"`)
+	}
+	return sb.String()
+}
+
+// AssetListJSON returns the asset list of the interstitial to serve at its
+// AssetListURI.
+func (in *Interstitial) AssetListJSON() ([]byte, error) {
+	return json.Marshal(in.AssetList)
+}
+
+// seconds formats d as a decimal number of seconds, as HLS attributes do.
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// quoted returns s as an HLS quoted string, which has no escape sequences.
+func quoted(s string) string {
+	return `"` + s + `"`
+}
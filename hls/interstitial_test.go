@@ -0,0 +1,65 @@
+package hls
+
+import (
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func ad(seq int, d time.Duration, files ...vast.MediaFile) vast.Ad {
+	return vast.Ad{Sequence: seq, InLine: &vast.InLine{Creatives: []vast.Creative{
+		{Linear: &vast.Linear{Duration: vast.Duration(d), MediaFiles: files}},
+	}}}
+}
+
+func TestFromVAST(t *testing.T) {
+	mp4 := vast.MediaFile{Delivery: vast.DeliveryProgressive, Type: vast.MIMEVideoMP4, URI: "https://cdn/a.mp4"}
+	hls := func(uri string) vast.MediaFile {
+		return vast.MediaFile{Delivery: vast.DeliveryStreaming, Type: "application/vnd.apple.mpegURL", URI: vast.URI(uri)}
+	}
+	doc := &vast.VAST{Ads: []vast.Ad{
+		ad(2, 15*time.Second, mp4, hls("https://cdn/b.m3u8")),
+		ad(1, 30*time.Second, hls("https://cdn/a.m3u8")),
+		ad(3, 10*time.Second, mp4),
+	}}
+	start := time.Date(2020, 1, 2, 21, 55, 44, 0, time.UTC)
+	resume := time.Duration(0)
+	in, err := FromVAST(doc, Options{
+		ID:           "break-1",
+		StartDate:    start,
+		AssetListURI: "https://ssai/list.json?break=1",
+		ResumeOffset: &resume,
+		Restrict:     []string{RestrictSkip, RestrictJump},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 45*time.Second, in.Duration)
+	assert.Equal(t, `#EXT-X-DATERANGE:ID="break-1",CLASS="com.apple.hls.interstitial",START-DATE="2020-01-02T21:55:44.000Z",`+
+		`DURATION=45,X-ASSET-LIST="https://ssai/list.json?break=1",X-RESUME-OFFSET=0,X-RESTRICT="SKIP,JUMP"`, in.DateRange())
+	list, err := in.AssetListJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ASSETS":[{"URI":"https://cdn/a.m3u8","DURATION":30},{"URI":"https://cdn/b.m3u8","DURATION":15}]}`, string(list))
+
+	single := &vast.VAST{Ads: []vast.Ad{ad(0, 12500*time.Millisecond, hls("https://cdn/c.m3u8"))}}
+	in, err = FromVAST(single, Options{ID: "b2", StartDate: start})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `#EXT-X-DATERANGE:ID="b2",CLASS="com.apple.hls.interstitial",START-DATE="2020-01-02T21:55:44.000Z",`+
+			`DURATION=12.5,X-ASSET-URI="https://cdn/c.m3u8"`, in.DateRange())
+	}
+}
+
+func TestFromPodErrors(t *testing.T) {
+	mp4 := ad(1, 15*time.Second, vast.MediaFile{Type: vast.MIMEVideoMP4, URI: "https://cdn/a.mp4"})
+	_, err := FromPod([]*vast.Ad{&mp4}, Options{})
+	assert.Equal(t, ErrNoAssets, err)
+	_, err = FromVAST(&vast.VAST{}, Options{})
+	assert.Equal(t, ErrNoAssets, err)
+
+	a := ad(1, 15*time.Second, vast.MediaFile{Type: vast.MIMEHLS, URI: "https://cdn/a.m3u8"})
+	b := ad(2, 15*time.Second, vast.MediaFile{Type: vast.MIMEHLS, URI: "https://cdn/b.m3u8"})
+	_, err = FromPod([]*vast.Ad{&a, &b}, Options{})
+	assert.EqualError(t, err, "hls: an asset list URI is required for a pod")
+}
@@ -0,0 +1,91 @@
+package vast
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IconXPosition is the horizontal position of an icon: a number of pixels
+// from the left of the player, or one of the IconLeft and IconRight keywords.
+type IconXPosition string
+
+// IconYPosition is the vertical position of an icon: a number of pixels from
+// the top of the player, or one of the IconTop and IconBottom keywords.
+type IconYPosition string
+
+// Icon position keywords
+const (
+	IconLeft   IconXPosition = "left"
+	IconRight  IconXPosition = "right"
+	IconTop    IconYPosition = "top"
+	IconBottom IconYPosition = "bottom"
+)
+
+// IconXPixels returns a horizontal position n pixels from the left.
+func IconXPixels(n int) IconXPosition {
+	return IconXPosition(strconv.Itoa(n))
+}
+
+// IconYPixels returns a vertical position n pixels from the top.
+func IconYPixels(n int) IconYPosition {
+	return IconYPosition(strconv.Itoa(n))
+}
+
+// Pixels returns the position in pixels, and false if it is a keyword.
+func (p IconXPosition) Pixels() (int, bool) {
+	return iconPixels(string(p))
+}
+
+// Valid returns true if the position matches ([0-9]*|left|right).
+func (p IconXPosition) Valid() bool {
+	_, ok := p.Pixels()
+	return ok || p == IconLeft || p == IconRight
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. Keywords
+// are matched case-insensitively and a "px" suffix is ignored.
+func (p *IconXPosition) UnmarshalText(data []byte) error {
+	*p = IconXPosition(normalizeIconPosition(string(data)))
+	return nil
+}
+
+// Pixels returns the position in pixels, and false if it is a keyword.
+func (p IconYPosition) Pixels() (int, bool) {
+	return iconPixels(string(p))
+}
+
+// Valid returns true if the position matches ([0-9]*|top|bottom).
+func (p IconYPosition) Valid() bool {
+	_, ok := p.Pixels()
+	return ok || p == IconTop || p == IconBottom
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. Keywords
+// are matched case-insensitively and a "px" suffix is ignored.
+func (p *IconYPosition) UnmarshalText(data []byte) error {
+	*p = IconYPosition(normalizeIconPosition(string(data)))
+	return nil
+}
+
+func iconPixels(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
+
+func normalizeIconPosition(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if n := strings.TrimSuffix(s, "px"); n != s {
+		if _, ok := iconPixels(n); ok {
+			return n
+		}
+	}
+	return s
+}
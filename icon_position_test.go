@@ -0,0 +1,47 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIconPosition(t *testing.T) {
+	assert.Equal(t, IconXPosition("12"), IconXPixels(12))
+	n, ok := IconYPixels(30).Pixels()
+	assert.True(t, ok)
+	assert.Equal(t, 30, n)
+	_, ok = IconRight.Pixels()
+	assert.False(t, ok)
+
+	assert.True(t, IconLeft.Valid())
+	assert.True(t, IconXPixels(0).Valid())
+	assert.False(t, IconXPosition("top").Valid())
+	assert.False(t, IconXPosition("").Valid())
+	assert.False(t, IconXPosition("-5").Valid())
+	assert.True(t, IconBottom.Valid())
+	assert.False(t, IconYPosition("left").Valid())
+}
+
+func TestIconPositionUnmarshal(t *testing.T) {
+	var icon Icon
+	err := xml.Unmarshal([]byte(`<Icon program="AdChoices" width="20" height="20" xPosition=" Right" yPosition="10px"></Icon>`), &icon)
+	if assert.NoError(t, err) {
+		assert.Equal(t, IconRight, icon.XPosition)
+		assert.Equal(t, IconYPixels(10), icon.YPosition)
+	}
+}
+
+func TestValidateIconPosition(t *testing.T) {
+	v := validInLineVAST("3.0")
+	v.Ads[0].InLine.Creatives[0].Linear.Icons = &Icons{Icon: []Icon{{
+		Program:        "AdChoices",
+		Width:          20,
+		Height:         20,
+		XPosition:      "middle",
+		YPosition:      IconTop,
+		StaticResource: &StaticResource{CreativeType: "image/png", URI: "http://cdn.example.com/icon.png"},
+	}}}
+	assert.EqualError(t, v.Validate(), `invalid VAST.Ad[0].InLine.Creative[0].Linear.Icons.Icon[0]: invalid xPosition "middle"`)
+}
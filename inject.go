@@ -0,0 +1,114 @@
+package vast
+
+// AddImpression adds an impression tracking URL to the ad.
+func (a *Ad) AddImpression(url string) {
+	switch {
+	case a.InLine != nil:
+		a.InLine.Impressions = append(a.InLine.Impressions, Impression{URI: URI(url)})
+	case a.Wrapper != nil:
+		a.Wrapper.Impressions = append(a.Wrapper.Impressions, Impression{URI: URI(url)})
+	}
+}
+
+// AddTracking adds a tracking URL for event to every linear and non-linear
+// creative of the ad, and to every companion for creativeView events.
+//
+// A Wrapper ad without any linear or non-linear creative gets a linear
+// creative holding the tracker, as the wrapped ad is unknown at this point.
+func (a *Ad) AddTracking(event EventType, url string) {
+	t := Tracking{Event: string(event), URI: URI(url)}
+	switch {
+	case a.InLine != nil:
+		for i := range a.InLine.Creatives {
+			c := &a.InLine.Creatives[i]
+			if c.Linear != nil {
+				c.Linear.TrackingEvents = append(c.Linear.TrackingEvents, t)
+			}
+			if c.NonLinearAds != nil {
+				c.NonLinearAds.TrackingEvents = append(c.NonLinearAds.TrackingEvents, t)
+			}
+			if c.CompanionAds != nil && event == EventCreativeView {
+				for j := range c.CompanionAds.Companions {
+					comp := &c.CompanionAds.Companions[j]
+					comp.TrackingEvents = append(comp.TrackingEvents, t)
+				}
+			}
+		}
+	case a.Wrapper != nil:
+		found := false
+		for i := range a.Wrapper.Creatives {
+			c := &a.Wrapper.Creatives[i]
+			if c.Linear != nil {
+				c.Linear.TrackingEvents = append(c.Linear.TrackingEvents, t)
+				found = true
+			}
+			if c.NonLinearAds != nil {
+				c.NonLinearAds.TrackingEvents = append(c.NonLinearAds.TrackingEvents, t)
+				found = true
+			}
+			if c.CompanionAds != nil && event == EventCreativeView {
+				for j := range c.CompanionAds.Companions {
+					comp := &c.CompanionAds.Companions[j]
+					comp.TrackingEvents = append(comp.TrackingEvents, t)
+				}
+			}
+		}
+		if !found {
+			a.Wrapper.Creatives = append(a.Wrapper.Creatives, CreativeWrapper{
+				Linear: &LinearWrapper{TrackingEvents: []Tracking{t}},
+			})
+		}
+	}
+}
+
+// AddClickTracking adds a click tracking URL to every linear and non-linear
+// creative of the ad.
+//
+// A Wrapper ad without any linear or non-linear creative gets a linear
+// creative holding the tracker, as the wrapped ad is unknown at this point.
+func (a *Ad) AddClickTracking(url string) {
+	switch {
+	case a.InLine != nil:
+		for i := range a.InLine.Creatives {
+			c := &a.InLine.Creatives[i]
+			if c.Linear != nil {
+				c.Linear.VideoClicks = addVideoClickTracking(c.Linear.VideoClicks, url)
+			}
+			if c.NonLinearAds != nil {
+				for j := range c.NonLinearAds.NonLinears {
+					nl := &c.NonLinearAds.NonLinears[j]
+					nl.NonLinearClickTrackings = append(nl.NonLinearClickTrackings, NonLinearClickTracking{URI: URI(url)})
+				}
+			}
+		}
+	case a.Wrapper != nil:
+		found := false
+		for i := range a.Wrapper.Creatives {
+			c := &a.Wrapper.Creatives[i]
+			if c.Linear != nil {
+				c.Linear.VideoClicks = addVideoClickTracking(c.Linear.VideoClicks, url)
+				found = true
+			}
+			if c.NonLinearAds != nil {
+				for j := range c.NonLinearAds.NonLinears {
+					nl := &c.NonLinearAds.NonLinears[j]
+					nl.NonLinearClickTrackings = append(nl.NonLinearClickTrackings, NonLinearClickTracking{URI: URI(url)})
+					found = true
+				}
+			}
+		}
+		if !found {
+			a.Wrapper.Creatives = append(a.Wrapper.Creatives, CreativeWrapper{
+				Linear: &LinearWrapper{VideoClicks: addVideoClickTracking(nil, url)},
+			})
+		}
+	}
+}
+
+func addVideoClickTracking(vc *VideoClicks, url string) *VideoClicks {
+	if vc == nil {
+		vc = &VideoClicks{}
+	}
+	vc.ClickTrackings = append(vc.ClickTrackings, VideoClick{URI: URI(url)})
+	return vc
+}
@@ -0,0 +1,64 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectInLine(t *testing.T) {
+	ad := &Ad{InLine: &InLine{Creatives: []Creative{
+		{Linear: &Linear{}},
+		{NonLinearAds: &NonLinearAds{NonLinears: []NonLinear{{}}}},
+		{CompanionAds: &CompanionAds{Companions: []Companion{{}}}},
+	}}}
+
+	ad.AddImpression("http://ssai/imp")
+	ad.AddTracking(Event_type_start, "http://ssai/start")
+	ad.AddTracking(Event_type_creativeView, "http://ssai/view")
+	ad.AddClickTracking("http://ssai/click")
+
+	assert.Equal(t, []Impression{{URI: "http://ssai/imp"}}, ad.InLine.Impressions)
+	linear := ad.InLine.Creatives[0].Linear
+	assert.Equal(t, []Tracking{{Event: "start", URI: "http://ssai/start"}, {Event: "creativeView", URI: "http://ssai/view"}}, linear.TrackingEvents)
+	if assert.NotNil(t, linear.VideoClicks) {
+		assert.Equal(t, []VideoClick{{URI: "http://ssai/click"}}, linear.VideoClicks.ClickTrackings)
+	}
+	nl := ad.InLine.Creatives[1].NonLinearAds
+	assert.Len(t, nl.TrackingEvents, 2)
+	assert.Equal(t, []NonLinearClickTracking{{URI: "http://ssai/click"}}, nl.NonLinears[0].NonLinearClickTrackings)
+	comp := ad.InLine.Creatives[2].CompanionAds.Companions[0]
+	assert.Equal(t, []Tracking{{Event: "creativeView", URI: "http://ssai/view"}}, comp.TrackingEvents)
+}
+
+func TestInjectWrapper(t *testing.T) {
+	ad := &Ad{Wrapper: &Wrapper{}}
+
+	ad.AddImpression("http://ssai/imp")
+	ad.AddTracking(Event_type_complete, "http://ssai/complete")
+	ad.AddClickTracking("http://ssai/click")
+
+	assert.Equal(t, []Impression{{URI: "http://ssai/imp"}}, ad.Wrapper.Impressions)
+	if assert.Len(t, ad.Wrapper.Creatives, 1) && assert.NotNil(t, ad.Wrapper.Creatives[0].Linear) {
+		linear := ad.Wrapper.Creatives[0].Linear
+		assert.Equal(t, []Tracking{{Event: "complete", URI: "http://ssai/complete"}}, linear.TrackingEvents)
+		assert.Equal(t, []VideoClick{{URI: "http://ssai/click"}}, linear.VideoClicks.ClickTrackings)
+	}
+
+	nlw := &Ad{Wrapper: &Wrapper{Creatives: []CreativeWrapper{{NonLinearAds: &NonLinearAdsWrapper{NonLinears: []NonLinearWrapper{{}}}}}}}
+	nlw.AddTracking(Event_type_expand, "http://ssai/expand")
+	nlw.AddClickTracking("http://ssai/click")
+	if assert.Len(t, nlw.Wrapper.Creatives, 1) {
+		nl := nlw.Wrapper.Creatives[0].NonLinearAds
+		assert.Equal(t, []Tracking{{Event: "expand", URI: "http://ssai/expand"}}, nl.TrackingEvents)
+		assert.Equal(t, []NonLinearClickTracking{{URI: "http://ssai/click"}}, nl.NonLinears[0].NonLinearClickTrackings)
+	}
+}
+
+func TestInjectEmptyAd(t *testing.T) {
+	ad := &Ad{}
+	ad.AddImpression("http://ssai/imp")
+	ad.AddTracking(Event_type_start, "http://ssai/start")
+	ad.AddClickTracking("http://ssai/click")
+	assert.Equal(t, &Ad{}, ad)
+}
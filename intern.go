@@ -0,0 +1,119 @@
+package vast
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DefaultInternMaxLen is the length of the longest string interned when
+// Interner.MaxLen is not set, long enough for most tracking URIs.
+const DefaultInternMaxLen = 256
+
+// Interner shares the storage of the strings repeated across the ads of
+// documents, such as event names, MIME types, delivery methods, API
+// frameworks and the URIs of the trackers of a domain, which pods of many
+// ads from the same server duplicate heavily. Interning is opt-in: decode
+// documents with Unmarshal, or intern decoded ones with Intern. It slows
+// decoding down, and pays off for the documents kept in memory, such as
+// cached ones.
+//
+// An Interner can be shared by the documents of a process, and is safe for
+// concurrent use.
+type Interner struct {
+	// Length of the longest string interned, DefaultInternMaxLen if 0
+	MaxLen int
+	// Maximum number of distinct strings kept, unlimited if 0. Strings
+	// missing from a full Interner are left as is.
+	MaxEntries int
+
+	mu      sync.Mutex
+	strings map[string]string
+}
+
+// NewInterner returns an empty Interner.
+func NewInterner() *Interner {
+	return &Interner{}
+}
+
+// String returns the interned copy of s, after interning s if it's the first
+// occurrence.
+func (in *Interner) String(s string) string {
+	max := in.MaxLen
+	if max == 0 {
+		max = DefaultInternMaxLen
+	}
+	if s == "" || len(s) > max {
+		return s
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if is, ok := in.strings[s]; ok {
+		return is
+	}
+	if in.MaxEntries > 0 && len(in.strings) >= in.MaxEntries {
+		return s
+	}
+	if in.strings == nil {
+		in.strings = map[string]string{}
+	}
+	in.strings[s] = s
+	return s
+}
+
+// Len returns the number of distinct strings interned.
+func (in *Interner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.strings)
+}
+
+// Unmarshal decodes data into v, as xml.Unmarshal does, and interns its
+// strings.
+func (in *Interner) Unmarshal(data []byte, v *VAST) error {
+	if err := xml.Unmarshal(data, v); err != nil {
+		return err
+	}
+	in.Intern(v)
+	return nil
+}
+
+// Intern replaces the strings of v, attributes and text alike, by their
+// interned copy. The inner XML of extensions is left as is.
+func (in *Interner) Intern(v *VAST) {
+	if v == nil {
+		return
+	}
+	in.intern(reflect.ValueOf(v).Elem())
+}
+
+func (in *Interner) intern(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			in.intern(value.Elem())
+		}
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < value.Len(); i++ {
+			in.intern(value.Index(i))
+		}
+	case reflect.String:
+		if s := value.String(); s != "" {
+			value.SetString(in.String(s))
+		}
+	case reflect.Struct:
+		t := value.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("xml")
+			if f.PkgPath != "" || tag == "-" || strings.Contains(tag, ",innerxml") {
+				continue
+			}
+			in.intern(value.Field(i))
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package vast
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sameStorage tells whether a and b share their bytes.
+func sameStorage(a, b string) bool {
+	return (*reflect.StringHeader)(unsafe.Pointer(&a)).Data == (*reflect.StringHeader)(unsafe.Pointer(&b)).Data
+}
+
+func TestInterner(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var want VAST
+	if !assert.NoError(t, xml.Unmarshal(data, &want)) {
+		return
+	}
+
+	in := NewInterner()
+	var a, b VAST
+	assert.NoError(t, in.Unmarshal(data, &a))
+	assert.NoError(t, in.Unmarshal(data, &b))
+	assert.Equal(t, want, a)
+	assert.Equal(t, want, b)
+
+	la, lb := a.Ads[0].InLine.Creatives[0].Linear, b.Ads[0].InLine.Creatives[0].Linear
+	assert.True(t, sameStorage(la.TrackingEvents[1].Event, lb.TrackingEvents[1].Event))
+	assert.True(t, sameStorage(string(la.TrackingEvents[1].URI), string(lb.TrackingEvents[1].URI)))
+	assert.True(t, sameStorage(la.MediaFiles[0].Type, lb.MediaFiles[0].Type))
+	assert.True(t, sameStorage(string(la.MediaFiles[0].Delivery), string(lb.MediaFiles[0].Delivery)))
+	// across the ads of a document
+	ca := a.Ads[0].InLine.Creatives[1].CompanionAds.Companions[0]
+	assert.True(t, sameStorage(la.TrackingEvents[0].Event, ca.TrackingEvents[0].Event))
+
+	n := in.Len()
+	in.Intern(&b)
+	assert.Equal(t, n, in.Len())
+	in.Intern(nil)
+	assert.Error(t, in.Unmarshal([]byte("<VAST"), &a))
+}
+
+func TestInternerLimits(t *testing.T) {
+	long := strings.Repeat("a", DefaultInternMaxLen+1)
+	in := &Interner{MaxEntries: 1}
+	assert.Equal(t, "start", in.String("start"))
+	assert.Equal(t, "complete", in.String("complete"))
+	assert.Equal(t, long, in.String(long))
+	assert.Equal(t, "", in.String(""))
+	assert.Equal(t, 1, in.Len())
+
+	in = &Interner{MaxLen: 4}
+	in.String("start")
+	in.String("mute")
+	assert.Equal(t, 1, in.Len())
+}
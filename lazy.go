@@ -0,0 +1,414 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// Lazy is a document parsed by ParseLazy. Its large optional sub-trees, the
+// Extensions, CreativeExtensions, AdVerifications and CompanionAds elements,
+// are kept as raw XML and decoded into VAST on first access. It is not safe
+// for concurrent use.
+type Lazy struct {
+	// The document, without the sub-trees not accessed yet
+	VAST  *VAST
+	parts []lazyParts
+}
+
+// lazyParts are the raw sub-trees of an ad, nil once decoded.
+type lazyParts struct {
+	extensions    *rawElement
+	verifications *rawElement
+	creatives     []lazyCreativeParts
+}
+
+type lazyCreativeParts struct {
+	companions *rawElement
+	extensions *rawElement
+}
+
+// rawElement is an element kept as raw XML.
+type rawElement struct {
+	Attrs []xml.Attr `xml:",any,attr"`
+	Inner []byte     `xml:",innerxml"`
+	// The namespace declarations of the ancestors of the element
+	scope []xml.Attr
+}
+
+// decode decodes the element, named name, into v, within the namespace
+// declarations of its ancestors.
+func (r *rawElement) decode(name string, v interface{}) error {
+	var buf bytes.Buffer
+	buf.Grow(2*len(name) + len(r.Inner) + 5)
+	buf.WriteString("<" + name)
+	scope := nsScope(r.scope, r.Attrs)
+	for _, attr := range scope {
+		if attr.Name.Space == "xmlns" {
+			writeAttr(&buf, "xmlns:", attr)
+		} else {
+			writeAttr(&buf, "", attr)
+		}
+	}
+	for _, attr := range r.Attrs {
+		if !isNamespaceDecl(attr) {
+			writeAttr(&buf, nsPrefix(scope, attr.Name.Space), attr)
+		}
+	}
+	buf.WriteByte('>')
+	buf.Write(r.Inner)
+	buf.WriteString("</" + name + ">")
+	return xml.Unmarshal(buf.Bytes(), v)
+}
+
+// writeAttr writes attr, with the given prefix, to buf.
+func writeAttr(buf *bytes.Buffer, prefix string, attr xml.Attr) {
+	buf.WriteByte(' ')
+	buf.WriteString(prefix)
+	buf.WriteString(attr.Name.Local)
+	buf.WriteString(`="`)
+	xml.EscapeText(buf, []byte(attr.Value))
+	buf.WriteByte('"')
+}
+
+// isNamespaceDecl reports whether attr declares a namespace, either
+// xmlns="..." or xmlns:prefix="...".
+func isNamespaceDecl(attr xml.Attr) bool {
+	return attr.Name.Space == "xmlns" || attr.Name.Space == "" && attr.Name.Local == "xmlns"
+}
+
+// nsScope returns the namespace declarations in scope of an element: those
+// of scope, in scope of its parent, overridden by those among attrs, the
+// attributes of the element. scope is not modified.
+func nsScope(scope, attrs []xml.Attr) []xml.Attr {
+	for _, attr := range attrs {
+		if !isNamespaceDecl(attr) {
+			continue
+		}
+		s := make([]xml.Attr, 0, len(scope)+1)
+		for _, decl := range scope {
+			if decl.Name != attr.Name {
+				s = append(s, decl)
+			}
+		}
+		scope = append(s, attr)
+	}
+	return scope
+}
+
+// xmlNamespace is the namespace bound to the xml prefix, which encoding/xml
+// substitutes to it.
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// nsPrefix returns the prefix, with its colon, of an attribute in the
+// namespace space, resolved by encoding/xml, given the declarations in scope.
+// Unbound prefixes, which encoding/xml leaves as is, are returned unchanged.
+func nsPrefix(scope []xml.Attr, space string) string {
+	switch space {
+	case "":
+		return ""
+	case xmlNamespace:
+		return "xml:"
+	}
+	for _, decl := range scope {
+		if decl.Name.Space == "xmlns" && decl.Value == space {
+			return decl.Name.Local + ":"
+		}
+	}
+	return space + ":"
+}
+
+// The types decoded by ParseLazy, shadowing the fields of the lazy
+// sub-trees of the embedded types, which encoding/xml resolves to the
+// shallowest field. Attrs collects the namespace declarations of the
+// ancestors of the sub-trees.
+type (
+	lazyVAST struct {
+		rawVAST
+		Attrs []xml.Attr `xml:",any,attr"`
+		Ads   []lazyAd   `xml:"Ad"`
+	}
+	lazyAd struct {
+		Ad
+		Attrs   []xml.Attr   `xml:",any,attr"`
+		InLine  *lazyInLine  `xml:"InLine"`
+		Wrapper *lazyWrapper `xml:"Wrapper"`
+	}
+	lazyInLine struct {
+		InLine
+		Attrs           []xml.Attr     `xml:",any,attr"`
+		Extensions      *rawElement    `xml:"Extensions"`
+		AdVerifications *rawElement    `xml:"AdVerifications"`
+		Creatives       []lazyCreative `xml:"Creatives>Creative"`
+	}
+	lazyCreative struct {
+		Creative
+		Attrs              []xml.Attr  `xml:",any,attr"`
+		CompanionAds       *rawElement `xml:"CompanionAds"`
+		CreativeExtensions *rawElement `xml:"CreativeExtensions"`
+	}
+	lazyWrapper struct {
+		Wrapper
+		Attrs           []xml.Attr            `xml:",any,attr"`
+		Extensions      *rawElement           `xml:"Extensions"`
+		AdVerifications *rawElement           `xml:"AdVerifications"`
+		Creatives       []lazyCreativeWrapper `xml:"Creatives>Creative"`
+	}
+	lazyCreativeWrapper struct {
+		CreativeWrapper
+		Attrs        []xml.Attr  `xml:",any,attr"`
+		CompanionAds *rawElement `xml:"CompanionAds"`
+	}
+)
+
+// ParseLazy parses a document the same way xml.Unmarshal does, except for
+// its Extensions, CreativeExtensions, AdVerifications and CompanionAds
+// elements which are decoded when accessed through the methods of Lazy.
+// Consumers only reading the media files and trackers of the ads skip
+// their decoding.
+//
+// The inferred version of the document accounts for the sub-trees not
+// decoded yet.
+func ParseLazy(data []byte) (*Lazy, error) {
+	var raw lazyVAST
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	v := VAST(raw.rawVAST)
+	l := &Lazy{VAST: &v, parts: make([]lazyParts, len(raw.Ads))}
+	v.Ads = make([]Ad, len(raw.Ads))
+	var root []xml.Attr
+	if v.XMLNS != "" {
+		root = []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: v.XMLNS}}
+	}
+	root = nsScope(root, raw.Attrs)
+	for i, la := range raw.Ads {
+		ad := la.Ad
+		parts := &l.parts[i]
+		scope := nsScope(root, la.Attrs)
+		if in := la.InLine; in != nil {
+			ad.InLine = &in.InLine
+			scope := nsScope(scope, in.Attrs)
+			parts.extensions = withScope(in.Extensions, scope)
+			parts.verifications = withScope(in.AdVerifications, scope)
+			ad.InLine.Creatives = nil
+			if len(in.Creatives) > 0 {
+				ad.InLine.Creatives = make([]Creative, len(in.Creatives))
+			}
+			parts.creatives = make([]lazyCreativeParts, len(in.Creatives))
+			for j, c := range in.Creatives {
+				ad.InLine.Creatives[j] = c.Creative
+				scope := nsScope(scope, c.Attrs)
+				parts.creatives[j] = lazyCreativeParts{
+					companions: withScope(c.CompanionAds, scope),
+					extensions: withScope(c.CreativeExtensions, scope),
+				}
+			}
+		}
+		if w := la.Wrapper; w != nil {
+			ad.Wrapper = &w.Wrapper
+			scope := nsScope(scope, w.Attrs)
+			parts.extensions = withScope(w.Extensions, scope)
+			parts.verifications = withScope(w.AdVerifications, scope)
+			ad.Wrapper.Creatives = nil
+			if len(w.Creatives) > 0 {
+				ad.Wrapper.Creatives = make([]CreativeWrapper, len(w.Creatives))
+			}
+			parts.creatives = make([]lazyCreativeParts, len(w.Creatives))
+			for j, c := range w.Creatives {
+				ad.Wrapper.Creatives[j] = c.CreativeWrapper
+				parts.creatives[j] = lazyCreativeParts{companions: withScope(c.CompanionAds, nsScope(scope, c.Attrs))}
+			}
+		}
+		v.Ads[i] = ad
+	}
+	v.InferredVersion = l.inferVersion()
+	return l, nil
+}
+
+// withScope sets the namespace declarations of the ancestors of r, if any,
+// and returns r.
+func withScope(r *rawElement, scope []xml.Attr) *rawElement {
+	if r != nil {
+		r.scope = scope
+	}
+	return r
+}
+
+// inferVersion infers the version of the document as if the pending
+// AdVerifications were decoded, since their presence raises it.
+// Verifications are looked up in the raw XML without decoding it.
+func (l *Lazy) inferVersion() SpecVersion {
+	v := *l.VAST
+	v.Ads = make([]Ad, len(l.VAST.Ads))
+	for i, ad := range l.VAST.Ads {
+		if r := l.parts[i].verifications; r != nil && bytes.Contains(r.Inner, []byte("<Verification")) {
+			switch {
+			case ad.InLine != nil:
+				in := *ad.InLine
+				in.AdVerifications = &[]Verification{}
+				ad.InLine = &in
+			case ad.Wrapper != nil:
+				w := *ad.Wrapper
+				w.AdVerifications = &[]Verification{}
+				ad.Wrapper = &w
+			}
+		}
+		v.Ads[i] = ad
+	}
+	return inferVersion(&v)
+}
+
+func (l *Lazy) ad(i int) (*Ad, *lazyParts, error) {
+	if i < 0 || i >= len(l.VAST.Ads) {
+		return nil, nil, fmt.Errorf("vast: ad %d out of range", i)
+	}
+	return &l.VAST.Ads[i], &l.parts[i], nil
+}
+
+// Extensions returns the extensions of the i-th ad, decoding them if
+// needed.
+func (l *Lazy) Extensions(i int) ([]Extension, error) {
+	ad, parts, err := l.ad(i)
+	if err != nil {
+		return nil, err
+	}
+	if parts.extensions != nil {
+		var exts struct {
+			Extensions []Extension `xml:"Extension"`
+		}
+		if err := parts.extensions.decode("Extensions", &exts); err != nil {
+			return nil, err
+		}
+		parts.extensions = nil
+		switch {
+		case ad.InLine != nil && exts.Extensions != nil:
+			ad.InLine.Extensions = &exts.Extensions
+		case ad.Wrapper != nil:
+			ad.Wrapper.Extensions = exts.Extensions
+		}
+	}
+	switch {
+	case ad.InLine != nil && ad.InLine.Extensions != nil:
+		return *ad.InLine.Extensions, nil
+	case ad.Wrapper != nil:
+		return ad.Wrapper.Extensions, nil
+	}
+	return nil, nil
+}
+
+// AdVerifications returns the verifications of the i-th ad, decoding them
+// if needed.
+func (l *Lazy) AdVerifications(i int) ([]Verification, error) {
+	ad, parts, err := l.ad(i)
+	if err != nil {
+		return nil, err
+	}
+	if parts.verifications != nil {
+		var verifications struct {
+			Verifications []Verification `xml:"Verification"`
+		}
+		if err := parts.verifications.decode("AdVerifications", &verifications); err != nil {
+			return nil, err
+		}
+		parts.verifications = nil
+		switch {
+		case verifications.Verifications == nil:
+		case ad.InLine != nil:
+			ad.InLine.AdVerifications = &verifications.Verifications
+		case ad.Wrapper != nil:
+			ad.Wrapper.AdVerifications = &verifications.Verifications
+		}
+	}
+	switch {
+	case ad.InLine != nil && ad.InLine.AdVerifications != nil:
+		return *ad.InLine.AdVerifications, nil
+	case ad.Wrapper != nil && ad.Wrapper.AdVerifications != nil:
+		return *ad.Wrapper.AdVerifications, nil
+	}
+	return nil, nil
+}
+
+// CompanionAds returns the companions of the c-th creative of the i-th ad,
+// an InLine ad, decoding them if needed.
+func (l *Lazy) CompanionAds(i, c int) (*CompanionAds, error) {
+	ad, parts, err := l.ad(i)
+	if err != nil {
+		return nil, err
+	}
+	if ad.InLine == nil || c < 0 || c >= len(ad.InLine.Creatives) {
+		return nil, fmt.Errorf("vast: creative %d of ad %d out of range", c, i)
+	}
+	if err := l.decodeCreative(ad, parts, c); err != nil {
+		return nil, err
+	}
+	return ad.InLine.Creatives[c].CompanionAds, nil
+}
+
+// decodeCreative decodes the pending sub-trees of the c-th creative of ad.
+func (l *Lazy) decodeCreative(ad *Ad, parts *lazyParts, c int) error {
+	cp := &parts.creatives[c]
+	switch {
+	case ad.InLine != nil:
+		creative := &ad.InLine.Creatives[c]
+		if cp.companions != nil {
+			var companions CompanionAds
+			if err := cp.companions.decode("CompanionAds", &companions); err != nil {
+				return err
+			}
+			creative.CompanionAds, cp.companions = &companions, nil
+		}
+		if cp.extensions != nil {
+			var exts struct {
+				Extensions []Extension `xml:"CreativeExtension"`
+			}
+			if err := cp.extensions.decode("CreativeExtensions", &exts); err != nil {
+				return err
+			}
+			if exts.Extensions != nil {
+				creative.CreativeExtensions = &exts.Extensions
+			}
+			cp.extensions = nil
+		}
+	case ad.Wrapper != nil:
+		if cp.companions != nil {
+			var companions CompanionAdsWrapper
+			if err := cp.companions.decode("CompanionAds", &companions); err != nil {
+				return err
+			}
+			ad.Wrapper.Creatives[c].CompanionAds, cp.companions = &companions, nil
+		}
+	}
+	return nil
+}
+
+// Ad returns the i-th ad with all its sub-trees decoded.
+func (l *Lazy) Ad(i int) (*Ad, error) {
+	ad, parts, err := l.ad(i)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := l.Extensions(i); err != nil {
+		return nil, err
+	}
+	if _, err := l.AdVerifications(i); err != nil {
+		return nil, err
+	}
+	for c := range parts.creatives {
+		if err := l.decodeCreative(ad, parts, c); err != nil {
+			return nil, err
+		}
+	}
+	return ad, nil
+}
+
+// Load decodes every pending sub-tree and returns the complete document,
+// the same as xml.Unmarshal would have.
+func (l *Lazy) Load() (*VAST, error) {
+	for i := range l.VAST.Ads {
+		if _, err := l.Ad(i); err != nil {
+			return nil, err
+		}
+	}
+	return l.VAST, nil
+}
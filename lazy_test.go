@@ -0,0 +1,162 @@
+package vast
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLazyLoad(t *testing.T) {
+	sink, err := xml.Marshal(kitchenSink())
+	if !assert.NoError(t, err) {
+		return
+	}
+	docs := map[string][]byte{"kitchen sink": sink, "namespaced": []byte(namespacedLazy)}
+	files, _ := filepath.Glob("testdata/*.xml")
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if assert.NoError(t, err) {
+			docs[file] = b
+		}
+	}
+
+	for name, b := range docs {
+		var want VAST
+		if !assert.NoError(t, xml.Unmarshal(b, &want), name) {
+			continue
+		}
+		l, err := ParseLazy(b)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		assert.Equal(t, want.InferredVersion, l.VAST.InferredVersion, name)
+		got, err := l.Load()
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, &want, got, name)
+		}
+	}
+}
+
+const namespacedLazy = `<VAST version="4.1" xmlns="http://www.iab.com/VAST" xmlns:x="http://x.example.com/ns">
+ <Ad id="1" xmlns:y="http://y.example.com/ns">
+  <InLine>
+   <AdSystem>s</AdSystem>
+   <AdTitle>t</AdTitle>
+   <Impression><![CDATA[https://i.example.com/]]></Impression>
+   <Creatives>
+    <Creative id="c" xmlns:x="http://x2.example.com/ns">
+     <CreativeExtensions><CreativeExtension type="t" x:attr="c"/></CreativeExtensions>
+     <Linear><Duration>00:00:10</Duration></Linear>
+    </Creative>
+   </Creatives>
+   <Extensions>
+    <Extension type="t" x:attr="v" y:attr="w" xml:lang="en"><x:Value>v</x:Value></Extension>
+   </Extensions>
+  </InLine>
+ </Ad>
+</VAST>`
+
+func TestParseLazyNamespaces(t *testing.T) {
+	l, err := ParseLazy([]byte(namespacedLazy))
+	if !assert.NoError(t, err) {
+		return
+	}
+	exts, err := l.Extensions(0)
+	if assert.NoError(t, err) && assert.Len(t, exts, 1) {
+		assert.Equal(t, []xml.Attr{
+			{Name: xml.Name{Space: "http://x.example.com/ns", Local: "attr"}, Value: "v"},
+			{Name: xml.Name{Space: "http://y.example.com/ns", Local: "attr"}, Value: "w"},
+			{Name: xml.Name{Space: "http://www.w3.org/XML/1998/namespace", Local: "lang"}, Value: "en"},
+		}, exts[0].Attrs)
+	}
+	ad, err := l.Ad(0)
+	if assert.NoError(t, err) {
+		cexts := ad.InLine.Creatives[0].CreativeExtensions
+		if assert.NotNil(t, cexts) && assert.Len(t, *cexts, 1) {
+			// redeclared by the Creative
+			assert.Equal(t, []xml.Attr{{Name: xml.Name{Space: "http://x2.example.com/ns", Local: "attr"}, Value: "c"}}, (*cexts)[0].Attrs)
+		}
+	}
+}
+
+func TestParseLazy(t *testing.T) {
+	b, err := xml.Marshal(kitchenSink())
+	if !assert.NoError(t, err) {
+		return
+	}
+	l, err := ParseLazy(b)
+	if !assert.NoError(t, err) {
+		return
+	}
+	in := l.VAST.Ads[0].InLine
+	assert.Nil(t, in.Extensions)
+	assert.Nil(t, in.AdVerifications)
+	assert.Nil(t, in.Creatives[0].CreativeExtensions)
+	assert.Nil(t, in.Creatives[1].CompanionAds)
+	// the rest of the document is decoded
+	assert.Len(t, in.Creatives[0].Linear.MediaFiles, 2)
+	assert.Equal(t, URI("https://t.example.com/start?a=1&b=2"), in.Creatives[0].Linear.TrackingEvents[0].URI)
+
+	exts, err := l.Extensions(0)
+	if assert.NoError(t, err) {
+		assert.Len(t, exts, 3)
+		assert.Equal(t, "waterfall", exts[0].Type)
+		assert.Equal(t, []xml.Attr{{Name: xml.Name{Local: "source"}, Value: "x&y"}}, exts[2].Attrs)
+		assert.Equal(t, &exts, in.Extensions)
+	}
+	verifications, err := l.AdVerifications(0)
+	if assert.NoError(t, err) {
+		assert.Len(t, verifications, 1)
+		assert.Equal(t, "vendor.com-omid", verifications[0].Vendor)
+	}
+	companions, err := l.CompanionAds(0, 1)
+	if assert.NoError(t, err) && assert.NotNil(t, companions) {
+		assert.Equal(t, "any", companions.Required)
+		assert.Len(t, companions.Companions, 2)
+		assert.Equal(t, companions, in.Creatives[1].CompanionAds)
+	}
+	// decoded once
+	again, err := l.CompanionAds(0, 1)
+	assert.NoError(t, err)
+	assert.True(t, companions == again)
+
+	_, err = l.Extensions(len(l.VAST.Ads))
+	assert.EqualError(t, err, "vast: ad 4 out of range")
+	_, err = l.CompanionAds(0, 5)
+	assert.EqualError(t, err, "vast: creative 5 of ad 0 out of range")
+	_, err = l.CompanionAds(1, 0)
+	assert.EqualError(t, err, "vast: creative 0 of ad 1 out of range")
+
+	_, err = ParseLazy([]byte("<VAST"))
+	assert.Error(t, err)
+}
+
+func BenchmarkParseLazy(b *testing.B) {
+	data, err := ioutil.ReadFile("testdata/vast4_verification.xml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseLazy(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkXMLUnmarshal(b *testing.B) {
+	data, err := ioutil.ReadFile("testdata/vast4_verification.xml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var v VAST
+		if err := xml.Unmarshal(data, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package vast
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// DefaultAdSystem is the name of the ad server used by the convenience
+// constructors when they need to fill the AdSystem element.
+var DefaultAdSystem = "vast"
+
+// MediaSpec describes a media file rendition of a simple video ad.
+type MediaSpec struct {
+	// URI of the media file
+	URI string
+	// MIME type of the media file, such as "video/mp4"
+	Type string
+	// Delivery method, "progressive" if empty
+	Delivery Delivery
+	// Codec used to produce the media file, optional
+	Codec string
+	// Bitrate of the media file in Kbps, optional
+	Bitrate int
+	// Pixel dimensions of the video
+	Width  int
+	Height int
+}
+
+// NewLinearAd returns a minimal valid VAST 4.2 document made of a single
+// InLine ad with a single linear creative playing the given media files. The
+// returned error is a ValidationErrors if the ad would be incomplete.
+func NewLinearAd(title string, duration time.Duration, mediaURLs []MediaSpec, impressions []string) (*VAST, error) {
+	lb := NewLinear(duration)
+	for _, m := range mediaURLs {
+		mb := NewMediaFile(m.URI, m.Type).Size(m.Width, m.Height).Bitrate(m.Bitrate).Codec(m.Codec)
+		if m.Delivery != "" {
+			mb.Delivery(m.Delivery)
+		}
+		lb.MediaFile(mb)
+	}
+	linear, err := lb.Build()
+	if err != nil {
+		return nil, err
+	}
+	imps := make([]Impression, len(impressions))
+	for i, uri := range impressions {
+		imps[i] = Impression{URI: URI(uri)}
+	}
+	v := &VAST{
+		Version: string(Version4_2),
+		XMLNS:   "http://www.iab.com/VAST",
+		Ads: []Ad{
+			{
+				InLine: &InLine{
+					AdSystem:    &AdSystem{Name: DefaultAdSystem},
+					Impressions: imps,
+					AdServingId: NewAdServingID(DefaultAdSystem),
+					AdTitle:     CDATAString{CDATA: title},
+					Creatives: []Creative{
+						{
+							UniversalAdID: &UniversalAdID{IDRegistry: "unknown", ID: "unknown"},
+							Linear:        linear,
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
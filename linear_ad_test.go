@@ -0,0 +1,55 @@
+package vast
+
+import (
+	"encoding/xml"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLinearAd(t *testing.T) {
+	v, err := NewLinearAd("My Ad", 30*time.Second, []MediaSpec{
+		{URI: "http://cdn.example.com/ad.mp4", Type: "video/mp4", Width: 1280, Height: 720, Bitrate: 2000},
+	}, []string{"http://track.example.com/imp"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "4.2", v.Version)
+	if assert.Len(t, v.Ads, 1) && assert.NotNil(t, v.Ads[0].InLine) {
+		inline := v.Ads[0].InLine
+		assert.Equal(t, "vast", inline.AdSystem.Name)
+		assert.Equal(t, "My Ad", inline.AdTitle.CDATA)
+		assert.Regexp(t, regexp.MustCompile(`^vast-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), inline.AdServingId)
+		assert.Equal(t, []Impression{{URI: "http://track.example.com/imp"}}, inline.Impressions)
+		if assert.Len(t, inline.Creatives, 1) {
+			c := inline.Creatives[0]
+			assert.NotNil(t, c.UniversalAdID)
+			if assert.NotNil(t, c.Linear) && assert.Len(t, c.Linear.MediaFiles, 1) {
+				assert.Equal(t, Duration(30*time.Second), c.Linear.Duration)
+				assert.Equal(t, DeliveryProgressive, c.Linear.MediaFiles[0].Delivery)
+			}
+		}
+	}
+
+	b, err := xml.Marshal(v)
+	if assert.NoError(t, err) {
+		var parsed VAST
+		if assert.NoError(t, xml.Unmarshal(b, &parsed)) {
+			assert.Equal(t, Version4_2, parsed.InferredVersion)
+		}
+	}
+}
+
+func TestNewLinearAdErrors(t *testing.T) {
+	media := []MediaSpec{{URI: "http://cdn.example.com/ad.mp4", Type: "video/mp4"}}
+	imps := []string{"http://track.example.com/imp"}
+
+	_, err := NewLinearAd("", time.Second, media, imps)
+	assert.EqualError(t, err, "invalid VAST.Ad[0].InLine.AdTitle: missing")
+	_, err = NewLinearAd("title", time.Second, media, nil)
+	assert.EqualError(t, err, "invalid VAST.Ad[0].InLine.Impression: missing")
+	_, err = NewLinearAd("title", time.Second, nil, imps)
+	assert.EqualError(t, err, "invalid Linear.MediaFiles: missing")
+}
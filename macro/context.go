@@ -0,0 +1,271 @@
+package macro
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// BreakPosition is the position of an ad break, reported through the
+// [BREAKPOSITION] macro.
+type BreakPosition int
+
+// Break positions defined by VAST 4.1
+const (
+	BreakPositionUnknown BreakPosition = iota
+	BreakPositionPreroll
+	BreakPositionMidroll
+	BreakPositionPostroll
+	BreakPositionStandalone
+)
+
+// PlacementType is the kind of ad placement, reported through the
+// [PLACEMENTTYPE] macro.
+type PlacementType int
+
+// Placement types defined by VAST 4.1
+const (
+	PlacementTypeUnknown PlacementType = iota
+	PlacementTypeLinear
+	PlacementTypeCompanion
+	PlacementTypeNonLinear
+	PlacementTypeInFeed
+	PlacementTypeStandalone
+)
+
+// Context holds the values known by the player or the ad server when firing
+// a tracking URI. Every field feeds one or more macros, named after the
+// field comment. Unset fields are reported as Unknown, unless noted
+// otherwise, and string fields may be set to NotShared.
+type Context struct {
+	// TIMESTAMP, the time of the event, now if zero
+	Timestamp time.Time
+	// CACHEBUSTING, a random 8 digit number if empty
+	CacheBusting string
+
+	// BREAKPOSITION
+	BreakPosition BreakPosition
+	// BREAKMAXDURATION, BREAKMINDURATION, in seconds
+	BreakMaxDuration, BreakMinDuration time.Duration
+	// BREAKMAXADLENGTH, BREAKMINADLENGTH, in seconds
+	BreakMaxAdLength, BreakMinAdLength time.Duration
+	// BREAKMAXADS
+	BreakMaxAds int
+	// ADCOUNT, the number of ads played in the break, starting at 1
+	AdCount int
+	// PODSEQUENCE, the sequence of the ad in its pod
+	PodSequence int
+	// PLACEMENTTYPE
+	PlacementType PlacementType
+	// TRANSACTIONID
+	TransactionID string
+	// ADCATEGORIES, BLOCKEDADCATEGORIES
+	AdCategories, BlockedAdCategories []string
+
+	// ADPLAYHEAD, CONTENTPLAYHEAD and MEDIAPLAYHEAD, as HH:MM:SS.mmm, if
+	// known
+	AdPlayhead, ContentPlayhead *time.Duration
+	// ADSERVINGID
+	AdServingID string
+	// UNIVERSALADID, as "registry id"
+	UniversalAdID string
+	// ASSETURI, the media file played
+	AssetURI string
+	// CONTENTID, CONTENTURI
+	ContentID, ContentURI string
+	// PLAYERSIZE, as "width,height"
+	PlayerWidth, PlayerHeight int
+	// PLAYERSTATE, e.g. "muted" or "fullscreen"
+	PlayerState []string
+	// INVENTORYSTATE, e.g. "skippable" or "autoplayed"
+	InventoryState []string
+	// CLICKTYPE: 1 for a click-through, 2 for a click-to-call, etc.
+	ClickType int
+	// CLICKPOS, as "x,y"
+	ClickX, ClickY *int
+
+	// APIFRAMEWORKS, the API frameworks supported by the player
+	APIFrameworks []string
+	// EXTENSIONS, the extension types supported by the player
+	Extensions []string
+	// VERIFICATIONVENDORS, the verification vendors supported by the player
+	VerificationVendors []string
+	// OMIDPARTNER, as "name/version"
+	OMIDPartner string
+	// MEDIAMIME, the MIME types supported by the player
+	MediaMIME []string
+	// PLAYERCAPABILITIES, e.g. "skip" or "mute"
+	PlayerCapabilities []string
+	// VASTVERSIONS, the VAST versions supported by the player
+	VASTVersions []string
+
+	// DOMAIN, PAGEURL
+	Domain, PageURL string
+	// APPBUNDLE, STOREID, STOREURL
+	AppBundle, StoreID, StoreURL string
+
+	// IFA, IFATYPE, the advertising identifier of the device
+	IFA, IFAType string
+	// CLIENTUA, SERVERUA, DEVICEUA
+	ClientUA, ServerUA, DeviceUA string
+	// SERVERSIDE: true if the event is reported by a server
+	ServerSide *vast.Bool
+	// DEVICEIP
+	DeviceIP string
+	// LATLONG, as "latitude,longitude"
+	LatLong string
+
+	// GDPRCONSENT, the TCF consent string
+	GDPRConsent string
+	// LIMITADTRACKING. When true, IFA, DEVICEIP and LATLONG are reported as
+	// NotShared.
+	LimitAdTracking *vast.Bool
+	// REGULATIONS, e.g. "gdpr" or "coppa"
+	Regulations []string
+	// US_PRIVACY, the CCPA privacy string
+	USPrivacy string
+	// GPP_STRING, GPP_SID, the IAB Global Privacy Platform values
+	GPPString     string
+	GPPSectionIDs []int
+}
+
+// Values returns the value of every macro fed by the context, keyed by macro
+// name without brackets, applying the defaulting rules of VAST 4.1.
+func (c *Context) Values() map[string]string {
+	v := map[string]string{}
+	set := func(name, value string) {
+		if value == "" {
+			value = Unknown
+		}
+		v[name] = value
+	}
+
+	ts := c.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	set("TIMESTAMP", ts.Format("2006-01-02T15:04:05.000Z07:00"))
+	cb := c.CacheBusting
+	if cb == "" {
+		cb = strconv.Itoa(10000000 + rand.Intn(90000000))
+	}
+	set("CACHEBUSTING", cb)
+
+	set("BREAKPOSITION", positive(int(c.BreakPosition)))
+	set("BREAKMAXDURATION", seconds(c.BreakMaxDuration))
+	set("BREAKMINDURATION", seconds(c.BreakMinDuration))
+	set("BREAKMAXADLENGTH", seconds(c.BreakMaxAdLength))
+	set("BREAKMINADLENGTH", seconds(c.BreakMinAdLength))
+	set("BREAKMAXADS", positive(c.BreakMaxAds))
+	set("ADCOUNT", positive(c.AdCount))
+	set("PODSEQUENCE", positive(c.PodSequence))
+	set("PLACEMENTTYPE", positive(int(c.PlacementType)))
+	set("TRANSACTIONID", c.TransactionID)
+	set("ADCATEGORIES", strings.Join(c.AdCategories, ","))
+	set("BLOCKEDADCATEGORIES", strings.Join(c.BlockedAdCategories, ","))
+
+	set("ADPLAYHEAD", playhead(c.AdPlayhead))
+	set("CONTENTPLAYHEAD", playhead(c.ContentPlayhead))
+	set("MEDIAPLAYHEAD", playhead(c.ContentPlayhead))
+	set("ADSERVINGID", c.AdServingID)
+	set("UNIVERSALADID", c.UniversalAdID)
+	set("ASSETURI", c.AssetURI)
+	set("CONTENTID", c.ContentID)
+	set("CONTENTURI", c.ContentURI)
+	if c.PlayerWidth > 0 && c.PlayerHeight > 0 {
+		set("PLAYERSIZE", strconv.Itoa(c.PlayerWidth)+","+strconv.Itoa(c.PlayerHeight))
+	} else {
+		set("PLAYERSIZE", "")
+	}
+	set("PLAYERSTATE", strings.Join(c.PlayerState, ","))
+	set("INVENTORYSTATE", strings.Join(c.InventoryState, ","))
+	set("CLICKTYPE", positive(c.ClickType))
+	if c.ClickX != nil && c.ClickY != nil {
+		set("CLICKPOS", strconv.Itoa(*c.ClickX)+","+strconv.Itoa(*c.ClickY))
+	} else {
+		set("CLICKPOS", "")
+	}
+
+	set("APIFRAMEWORKS", strings.Join(c.APIFrameworks, ","))
+	set("EXTENSIONS", strings.Join(c.Extensions, ","))
+	set("VERIFICATIONVENDORS", strings.Join(c.VerificationVendors, ","))
+	set("OMIDPARTNER", c.OMIDPartner)
+	set("MEDIAMIME", strings.Join(c.MediaMIME, ","))
+	set("PLAYERCAPABILITIES", strings.Join(c.PlayerCapabilities, ","))
+	set("VASTVERSIONS", strings.Join(c.VASTVersions, ","))
+
+	set("DOMAIN", c.Domain)
+	set("PAGEURL", c.PageURL)
+	set("APPBUNDLE", c.AppBundle)
+	set("STOREID", c.StoreID)
+	set("STOREURL", c.StoreURL)
+
+	set("IFA", c.IFA)
+	set("IFATYPE", c.IFAType)
+	set("CLIENTUA", c.ClientUA)
+	set("SERVERUA", c.ServerUA)
+	set("DEVICEUA", c.DeviceUA)
+	set("SERVERSIDE", flag(c.ServerSide))
+	set("DEVICEIP", c.DeviceIP)
+	set("LATLONG", c.LatLong)
+
+	set("GDPRCONSENT", c.GDPRConsent)
+	set("LIMITADTRACKING", flag(c.LimitAdTracking))
+	if c.LimitAdTracking != nil && bool(*c.LimitAdTracking) {
+		for _, name := range []string{"IFA", "DEVICEIP", "LATLONG"} {
+			v[name] = NotShared
+		}
+	}
+	set("REGULATIONS", strings.Join(c.Regulations, ","))
+	set("US_PRIVACY", c.USPrivacy)
+	set("GPP_STRING", c.GPPString)
+	sids := make([]string, len(c.GPPSectionIDs))
+	for i, id := range c.GPPSectionIDs {
+		sids[i] = strconv.Itoa(id)
+	}
+	set("GPP_SID", strings.Join(sids, ","))
+	return v
+}
+
+// Expand returns uri with the macros fed by the context expanded, as done by
+// Expand.
+func (c *Context) Expand(ctx context.Context, uri string) string {
+	return Expand(ctx, uri, c.Values())
+}
+
+func positive(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func seconds(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return strconv.Itoa(int(d / time.Second))
+}
+
+func playhead(d *time.Duration) string {
+	if d == nil || *d < 0 {
+		return ""
+	}
+	ms := *d / time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+func flag(b *vast.Bool) string {
+	switch {
+	case b == nil:
+		return ""
+	case bool(*b):
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,68 @@
+package macro
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextValues(t *testing.T) {
+	playhead := 90*time.Second + 250*time.Millisecond
+	c := &Context{
+		Timestamp:       time.Date(2020, 1, 2, 3, 4, 5, 6e6, time.UTC),
+		CacheBusting:    "12345678",
+		BreakPosition:   BreakPositionMidroll,
+		ContentPlayhead: &playhead,
+		PlayerWidth:     640,
+		PlayerHeight:    360,
+		APIFrameworks:   []string{"omid", "SIMID"},
+		GDPRConsent:     "CO-consent",
+		Regulations:     []string{"gdpr"},
+		DeviceUA:        NotShared,
+	}
+	v := c.Values()
+	assert.Equal(t, "2020-01-02T03:04:05.006Z", v["TIMESTAMP"])
+	assert.Equal(t, "12345678", v["CACHEBUSTING"])
+	assert.Equal(t, "2", v["BREAKPOSITION"])
+	assert.Equal(t, "00:01:30.250", v["CONTENTPLAYHEAD"])
+	assert.Equal(t, "00:01:30.250", v["MEDIAPLAYHEAD"])
+	assert.Equal(t, "640,360", v["PLAYERSIZE"])
+	assert.Equal(t, "omid,SIMID", v["APIFRAMEWORKS"])
+	assert.Equal(t, "CO-consent", v["GDPRCONSENT"])
+	assert.Equal(t, "gdpr", v["REGULATIONS"])
+	assert.Equal(t, NotShared, v["DEVICEUA"])
+
+	// unknown values
+	for _, name := range []string{"ADPLAYHEAD", "LIMITADTRACKING", "IFA", "US_PRIVACY", "CLICKPOS", "BREAKMAXADS"} {
+		assert.Equal(t, Unknown, v[name], name)
+	}
+}
+
+func TestContextDefaults(t *testing.T) {
+	v := (&Context{}).Values()
+	assert.Len(t, v["CACHEBUSTING"], 8)
+	_, err := time.Parse("2006-01-02T15:04:05.000Z07:00", v["TIMESTAMP"])
+	assert.NoError(t, err)
+
+	// limited ad tracking hides the device identifiers
+	c := &Context{IFA: "abc", DeviceIP: "1.2.3.4", LimitAdTracking: vast.NewBool(true)}
+	v = c.Values()
+	assert.Equal(t, "1", v["LIMITADTRACKING"])
+	assert.Equal(t, NotShared, v["IFA"])
+	assert.Equal(t, NotShared, v["DEVICEIP"])
+	assert.Equal(t, NotShared, v["LATLONG"])
+
+	c.LimitAdTracking = vast.NewBool(false)
+	v = c.Values()
+	assert.Equal(t, "0", v["LIMITADTRACKING"])
+	assert.Equal(t, "abc", v["IFA"])
+}
+
+func TestContextExpand(t *testing.T) {
+	c := &Context{CacheBusting: "42", PageURL: "https://example.com/a b?c=d&e"}
+	got := c.Expand(context.Background(), "https://t.example.com/p?cb=[CACHEBUSTING]&url=%5BPAGEURL%5D&ifa=[ifa]&x=[UNSUPPORTED]")
+	assert.Equal(t, "https://t.example.com/p?cb=42&url=https%3A%2F%2Fexample.com%2Fa%20b%3Fc%3Dd%26e&ifa=-1&x=[UNSUPPORTED]", got)
+}
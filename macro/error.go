@@ -0,0 +1,32 @@
+package macro
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/haxqer/vast"
+)
+
+// ErrorURLs returns the error tracking URIs ready to be fired: the
+// [ERRORCODE] macro is replaced by code, in any of its spellings, as done by
+// vast.ErrorCode.ErrorURL, and the other macros by the values of c. Only
+// [ERRORCODE] is replaced if c is nil.
+//
+// uris are typically the result of (*vast.Ad).ErrorURIs or
+// (*vast.VAST).ErrorURIs.
+func ErrorURLs(ctx context.Context, uris []string, code vast.ErrorCode, c *Context) []string {
+	var values map[string]string
+	if c != nil {
+		values = c.Values()
+	}
+	trace := vast.DecisionTraceFrom(ctx)
+	urls := make([]string, 0, len(uris))
+	for _, u := range uris {
+		url := code.ErrorURL(u)
+		if url != u {
+			trace.RecordMacro("macro", "ERRORCODE", strconv.Itoa(int(code)))
+		}
+		urls = append(urls, Expand(ctx, url, values))
+	}
+	return urls
+}
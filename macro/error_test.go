@@ -0,0 +1,36 @@
+package macro
+
+import (
+	"context"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorURLs(t *testing.T) {
+	ad := &vast.Ad{Wrapper: &vast.Wrapper{Errors: []vast.CDATAURI{
+		{URI: " https://t.example.com/e?c=[ERRORCODE]&cb=[CACHEBUSTING] "},
+		{URI: "https://t.example.com/e?c=%5berrorcode%5D"},
+		{URI: ""},
+	}}}
+	uris := ad.ErrorURIs()
+	assert.Equal(t, []string{
+		"https://t.example.com/e?c=303&cb=[CACHEBUSTING]",
+		"https://t.example.com/e?c=303",
+	}, ErrorURLs(context.Background(), uris, vast.ErrorWrapperNoAd, nil))
+	assert.Equal(t, []string{
+		"https://t.example.com/e?c=301&cb=1234",
+		"https://t.example.com/e?c=301",
+	}, ErrorURLs(context.Background(), uris, vast.ErrorWrapperTimeout, &Context{CacheBusting: "1234"}))
+	assert.Empty(t, ErrorURLs(context.Background(), nil, vast.ErrorUndefined, nil))
+	// the input is left untouched
+	assert.Equal(t, []string{"https://t.example.com/e?c=[ERRORCODE]&cb=[CACHEBUSTING]", "https://t.example.com/e?c=%5berrorcode%5D"}, uris)
+
+	trace := vast.NewDecisionTrace()
+	ErrorURLs(vast.WithDecisionTrace(context.Background(), trace), uris[:1], vast.ErrorWrapperTimeout, &Context{CacheBusting: "1234"})
+	assert.Equal(t, []vast.TraceMacro{
+		{Stage: "macro", Macro: "ERRORCODE", Value: "301"},
+		{Stage: "macro", Macro: "CACHEBUSTING", Value: "1234"},
+	}, trace.Macros())
+}
@@ -0,0 +1,140 @@
+// Package macro expands the VAST macros, such as [CACHEBUSTING] or
+// [GDPRCONSENT], found in the tracking URIs of VAST documents.
+package macro
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/haxqer/vast"
+)
+
+// Values substituted to macros whose value is not available, as defined by
+// VAST 4.1.
+const (
+	// The value of the macro is unknown
+	Unknown = "-1"
+	// The value of the macro is known but not shared, e.g. for privacy
+	NotShared = "-2"
+)
+
+// names are the macros defined by VAST 4.2 and the IAB macro list.
+var names = []string{
+	"TIMESTAMP", "CACHEBUSTING",
+	"BREAKPOSITION", "BREAKMAXDURATION", "BREAKMINDURATION", "BREAKMAXADLENGTH",
+	"BREAKMINADLENGTH", "BREAKMAXADS", "ADCOUNT", "PODSEQUENCE", "PLACEMENTTYPE",
+	"TRANSACTIONID", "ADCATEGORIES", "BLOCKEDADCATEGORIES", "ADTYPE",
+	"ADPLAYHEAD", "CONTENTPLAYHEAD", "MEDIAPLAYHEAD", "ADSERVINGID",
+	"UNIVERSALADID", "ASSETURI", "CONTENTID", "CONTENTURI", "PLAYERSIZE",
+	"PLAYERSTATE", "INVENTORYSTATE", "CLICKTYPE", "CLICKPOS",
+	"APIFRAMEWORKS", "EXTENSIONS", "VERIFICATIONVENDORS", "OMIDPARTNER",
+	"MEDIAMIME", "PLAYERCAPABILITIES", "VASTVERSIONS",
+	"DOMAIN", "PAGEURL", "APPBUNDLE", "STOREID", "STOREURL",
+	"IFA", "IFATYPE", "CLIENTUA", "SERVERUA", "DEVICEUA", "SERVERSIDE",
+	"DEVICEIP", "LATLONG",
+	"GDPRCONSENT", "LIMITADTRACKING", "REGULATIONS", "US_PRIVACY",
+	"GPP_STRING", "GPP_SID",
+	"ERRORCODE", "REASON",
+}
+
+var known = func() map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}()
+
+// Known returns true if name, without brackets, is a macro defined by the
+// spec. Macros are upper case.
+func Known(name string) bool {
+	return known[name]
+}
+
+// Expand returns uri with the macros found in values replaced by their
+// percent-encoded value. values is keyed by macro name, without brackets,
+// e.g. "CACHEBUSTING". Macros are matched regardless of case, and so are
+// their percent-encoded spellings such as "%5BCACHEBUSTING%5D". Macros not
+// found in values are kept as is.
+//
+// Every expansion is recorded to the vast.DecisionTrace of ctx, if any.
+func Expand(ctx context.Context, uri string, values map[string]string) string {
+	if !strings.Contains(uri, "[") && !strings.Contains(uri, "%5") {
+		return uri
+	}
+	trace := vast.DecisionTraceFrom(ctx)
+	var b strings.Builder
+	for rest := uri; ; {
+		start, end, name := nextMacro(rest)
+		if start < 0 {
+			b.WriteString(rest)
+			break
+		}
+		value, ok := values[strings.ToUpper(name)]
+		b.WriteString(rest[:start])
+		if ok {
+			b.WriteString(escape(value))
+			trace.RecordMacro("macro", strings.ToUpper(name), value)
+		} else {
+			b.WriteString(rest[start:end])
+		}
+		rest = rest[end:]
+	}
+	return b.String()
+}
+
+// nextMacro returns the position and the name of the first macro of s, with
+// start < 0 if there is none. Both the bracketed and percent-encoded
+// spellings are recognized.
+func nextMacro(s string) (start, end int, name string) {
+	for i := 0; i < len(s); i++ {
+		open, close := "", ""
+		switch {
+		case s[i] == '[':
+			open, close = "[", "]"
+		case len(s)-i >= 3 && strings.EqualFold(s[i:i+3], "%5B"):
+			open, close = s[i:i+3], "%5D"
+		default:
+			continue
+		}
+		body := s[i+len(open):]
+		j := indexFold(body, close)
+		if j <= 0 || !isName(body[:j]) {
+			continue
+		}
+		return i, i + len(open) + j + len(close), body[:j]
+	}
+	return -1, -1, ""
+}
+
+// indexFold returns the index in s of the first instance of sub, an ASCII
+// string, matched regardless of case, or -1. The bytes of s are compared as
+// is, as case mapping may change the length of non-ASCII characters.
+func indexFold(s, sub string) int {
+	if sub == "]" {
+		return strings.IndexByte(s, ']')
+	}
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isName(s string) bool {
+	for _, c := range s {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// escape percent-encodes a macro value, spaces included.
+func escape(v string) string {
+	return strings.Replace(url.QueryEscape(v), "+", "%20", -1)
+}
@@ -0,0 +1,34 @@
+package macro
+
+import (
+	"context"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand(t *testing.T) {
+	values := map[string]string{"ERRORCODE": "303", "REASON": "a b"}
+	for in, want := range map[string]string{
+		"":                              "",
+		"http://t/?e=[ERRORCODE]":       "http://t/?e=303",
+		"http://t/?e=%5bERRORCODE%5d":   "http://t/?e=303",
+		"http://t/?e=[ERRORCODE":        "http://t/?e=[ERRORCODE",
+		"http://t/?a=[]&r=[REASON]":     "http://t/?a=[]&r=a%20b",
+		"http://t/?a=[x y]&r=[REASON]":  "http://t/?a=[x y]&r=a%20b",
+		"http://t/?[OTHER]&[ERRORCODE]": "http://t/?[OTHER]&303",
+		// upper-casing changes the length of ɐ
+		"http://x/?a=%5Bɐɐɐɐ%5D":        "http://x/?a=%5Bɐɐɐɐ%5D",
+		"http://x/?a=ɐɐ%5BERRORCODE%5D": "http://x/?a=ɐɐ303",
+	} {
+		assert.Equal(t, want, Expand(context.Background(), in, values), in)
+	}
+}
+
+func TestExpandTrace(t *testing.T) {
+	trace := vast.NewDecisionTrace()
+	ctx := vast.WithDecisionTrace(context.Background(), trace)
+	Expand(ctx, "http://t/?e=[ERRORCODE]&o=[OTHER]", map[string]string{"ERRORCODE": "303"})
+	assert.Equal(t, []vast.TraceMacro{{Stage: "macro", Macro: "ERRORCODE", Value: "303"}}, trace.Macros())
+}
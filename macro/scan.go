@@ -0,0 +1,147 @@
+package macro
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/haxqer/vast"
+)
+
+// Finding is a macro found in a VAST document.
+type Finding struct {
+	// The field holding the macro, e.g. "VAST.Ad[0].InLine.Impression[0]"
+	Path string
+	// The macro name as written, without brackets
+	Name string
+	// Whether the macro is not defined by the spec
+	Unknown bool
+	// The macro defined by the spec the name is likely a misspelling of, for
+	// unknown macros
+	Suggestion string `json:",omitempty"`
+	// Whether the field is not a URI, so that the macro won't be substituted
+	Misplaced bool
+}
+
+// String implements the fmt.Stringer interface.
+func (f Finding) String() string {
+	s := fmt.Sprintf("%s: [%s]", f.Path, f.Name)
+	switch {
+	case f.Suggestion != "":
+		s += fmt.Sprintf(" unknown macro, did you mean [%s]?", f.Suggestion)
+	case f.Unknown:
+		s += " unknown macro"
+	}
+	if f.Misplaced {
+		s += " not substituted in this field"
+	}
+	return s
+}
+
+// Scan returns every macro found in the fields of v, with the findings of a
+// field in order of appearance.
+func Scan(v *vast.VAST) []Finding {
+	var s scanner
+	s.walk("VAST", reflect.ValueOf(v))
+	return s.findings
+}
+
+var uriType = reflect.TypeOf(vast.URI(""))
+
+type scanner struct {
+	findings []Finding
+}
+
+// walk scans value, the field at path.
+func (s *scanner) walk(path string, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			s.walk(path, value.Elem())
+		}
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < value.Len(); i++ {
+			s.walk(fmt.Sprintf("%s[%d]", path, i), value.Index(i))
+		}
+	case reflect.String:
+		s.scan(path, value.String(), value.Type() == uriType)
+	case reflect.Struct:
+		t := value.Type()
+		for i := 0; i < t.NumField(); i++ {
+			tag, ok := vast.ParseXMLField(t.Field(i))
+			switch {
+			case !ok, tag.InnerXML, tag.Any, tag.Comment:
+				continue
+			case tag.CharData:
+				s.walk(path, value.Field(i))
+				continue
+			}
+			s.walk(path+"."+strings.Join(tag.Names, "."), value.Field(i))
+		}
+	}
+}
+
+func (s *scanner) scan(path, text string, uri bool) {
+	for rest := text; ; {
+		_, end, name := nextMacro(rest)
+		if end < 0 {
+			return
+		}
+		f := Finding{Path: path, Name: name, Unknown: !Known(name), Misplaced: !uri}
+		if f.Unknown {
+			f.Suggestion = suggest(name)
+		}
+		s.findings = append(s.findings, f)
+		rest = rest[end:]
+	}
+}
+
+// suggest returns the known macro closest to name, if close enough to be a
+// misspelling.
+func suggest(name string) string {
+	upper := strings.ToUpper(name)
+	best, bestDist := "", 3
+	if len(upper) < 6 {
+		bestDist = 2
+	}
+	for _, known := range names {
+		if d := distance(upper, known); d < bestDist {
+			best, bestDist = known, d
+		}
+	}
+	return best
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min(n ...int) int {
+	m := n[0]
+	for _, v := range n[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
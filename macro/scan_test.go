@@ -0,0 +1,40 @@
+package macro
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+const scanDoc = `<VAST version="4.1"><Error><![CDATA[https://t.example.com/err?e=[ERRORCODE]]]></Error><Ad id="a"><InLine><AdSystem>DSP</AdSystem><AdTitle><![CDATA[Ad [CACHEBUSTING]]]></AdTitle><Impression><![CDATA[https://t.example.com/imp?cb=[CACHBUSTING]&x=[FOO]&g=%5Bgdprconsent%5D]]></Impression><Creatives><Creative><Linear><Duration>00:00:15</Duration><TrackingEvents><Tracking event="start"><![CDATA[https://t.example.com/start?p=[CONTENTPLAYHEAD]]]></Tracking></TrackingEvents><MediaFiles><MediaFile delivery="progressive" type="video/mp4" width="640" height="360"><![CDATA[https://cdn.example.com/ad.mp4]]></MediaFile></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`
+
+func TestScan(t *testing.T) {
+	var v vast.VAST
+	if !assert.NoError(t, xml.Unmarshal([]byte(scanDoc), &v)) {
+		return
+	}
+	findings := Scan(&v)
+	assert.Equal(t, []Finding{
+		{Path: "VAST.Ad[0].InLine.Impression[0]", Name: "CACHBUSTING", Unknown: true, Suggestion: "CACHEBUSTING"},
+		{Path: "VAST.Ad[0].InLine.Impression[0]", Name: "FOO", Unknown: true},
+		{Path: "VAST.Ad[0].InLine.Impression[0]", Name: "gdprconsent", Unknown: true, Suggestion: "GDPRCONSENT"},
+		{Path: "VAST.Ad[0].InLine.AdTitle", Name: "CACHEBUSTING", Misplaced: true},
+		{Path: "VAST.Ad[0].InLine.Creatives.Creative[0].Linear.TrackingEvents.Tracking[0]", Name: "CONTENTPLAYHEAD"},
+		{Path: "VAST.Error[0]", Name: "ERRORCODE"},
+	}, findings)
+
+	assert.Equal(t, "VAST.Ad[0].InLine.Impression[0]: [CACHBUSTING] unknown macro, did you mean [CACHEBUSTING]?", findings[0].String())
+	assert.Equal(t, "VAST.Ad[0].InLine.AdTitle: [CACHEBUSTING] not substituted in this field", findings[3].String())
+	assert.Empty(t, Scan(&vast.VAST{}))
+}
+
+func TestKnown(t *testing.T) {
+	assert.True(t, Known("CACHEBUSTING"))
+	assert.False(t, Known("cachebusting"))
+	// every macro fed by Context is known
+	for name := range (&Context{}).Values() {
+		assert.True(t, Known(name), name)
+	}
+}
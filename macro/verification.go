@@ -0,0 +1,35 @@
+package macro
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/haxqer/vast"
+)
+
+// VerificationNotExecutedURLs returns the verificationNotExecuted tracking
+// URIs of the verification ready to be fired: the [REASON] macro is replaced
+// by reason, and the other macros by the values of c. [VERIFICATIONVENDORS]
+// defaults to the vendor of the verification when c doesn't set it. They are
+// fired by track.Tracker.FireVerificationNotExecuted.
+func VerificationNotExecutedURLs(ctx context.Context, v *vast.Verification, reason vast.VerificationReason, c *Context) []string {
+	values := map[string]string{}
+	if c != nil {
+		values = c.Values()
+	}
+	values["REASON"] = strconv.Itoa(int(reason))
+	if (c == nil || len(c.VerificationVendors) == 0) && v.Vendor != "" {
+		values["VERIFICATIONVENDORS"] = v.Vendor
+	}
+	var urls []string
+	for _, t := range v.TrackingEvents {
+		if vast.EventType(t.Event) != vast.EventVerificationNotExecuted {
+			continue
+		}
+		if u := strings.TrimSpace(string(t.URI)); u != "" {
+			urls = append(urls, Expand(ctx, u, values))
+		}
+	}
+	return urls
+}
@@ -0,0 +1,27 @@
+package macro
+
+import (
+	"context"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerificationNotExecutedURLs(t *testing.T) {
+	v := &vast.Verification{
+		Vendor: "company.com-omid",
+		TrackingEvents: []vast.Tracking{
+			{Event: "verificationNotExecuted", URI: "https://t.example.com/vne?r=[REASON]&v=[VERIFICATIONVENDORS]&cb=[CACHEBUSTING]"},
+			{Event: "start", URI: "https://t.example.com/start"},
+		},
+	}
+	c := &Context{CacheBusting: "7"}
+	assert.Equal(t, []string{"https://t.example.com/vne?r=2&v=company.com-omid&cb=7"},
+		VerificationNotExecutedURLs(context.Background(), v, vast.VerificationNotSupported, c))
+	c.VerificationVendors = []string{"a.com-omid", "b.com-omid"}
+	assert.Equal(t, []string{"https://t.example.com/vne?r=3&v=a.com-omid%2Cb.com-omid&cb=7"},
+		VerificationNotExecutedURLs(context.Background(), v, vast.VerificationLoadError, c))
+	assert.Equal(t, []string{"https://t.example.com/vne?r=1&v=company.com-omid&cb=[CACHEBUSTING]"},
+		VerificationNotExecutedURLs(context.Background(), v, vast.VerificationResourceRejected, nil))
+}
@@ -0,0 +1,74 @@
+package vast
+
+import "math"
+
+// MediaFileFilter reports whether a media file is kept by FilterMediaFiles.
+type MediaFileFilter func(m *MediaFile) bool
+
+// FilterMediaFiles returns the media files kept by every filter, in order.
+// The returned slice doesn't share its storage with files.
+func FilterMediaFiles(files []MediaFile, filters ...MediaFileFilter) []MediaFile {
+	var kept []MediaFile
+	for i := range files {
+		ok := true
+		for _, f := range filters {
+			if ok = f(&files[i]); !ok {
+				break
+			}
+		}
+		if ok {
+			kept = append(kept, files[i])
+		}
+	}
+	return kept
+}
+
+// ByMIME keeps the media files of the given MIME types, compared
+// case-insensitively and ignoring parameters.
+func ByMIME(types ...string) MediaFileFilter {
+	return func(m *MediaFile) bool {
+		return containsFold(types, baseMIMEType(m.Type), baseMIMEType)
+	}
+}
+
+// ByCodec keeps the media files whose codecs are all among codecs, as
+// matched by MediaFileCapabilities. Media files without codec are kept.
+func ByCodec(codecs ...string) MediaFileFilter {
+	caps := &MediaFileCapabilities{Codecs: codecs}
+	return func(m *MediaFile) bool {
+		return m.Codec == "" || caps.supportsCodecs(m.Codec)
+	}
+}
+
+// ByMaxBitrate keeps the media files whose bitrate, or minimum bitrate for
+// adaptive streams, doesn't exceed kbps. Media files without bitrate are
+// kept.
+func ByMaxBitrate(kbps int) MediaFileFilter {
+	return func(m *MediaFile) bool {
+		lo, _ := m.bitrateRange()
+		return lo <= kbps
+	}
+}
+
+// ByAspectRatio keeps the media files whose width to height ratio is within
+// tolerance of ratio, e.g. ByAspectRatio(16.0/9, 0.05). Media files without
+// dimensions are kept.
+func ByAspectRatio(ratio, tolerance float64) MediaFileFilter {
+	return func(m *MediaFile) bool {
+		if m.Width <= 0 || m.Height <= 0 {
+			return true
+		}
+		return math.Abs(float64(m.Width)/float64(m.Height)-ratio) <= tolerance
+	}
+}
+
+// ProgressiveOnly keeps the progressively downloaded media files.
+func ProgressiveOnly(m *MediaFile) bool {
+	return m.Delivery == DeliveryProgressive
+}
+
+// ExcludeInteractive drops the media files requiring an API framework, such
+// as VPAID units.
+func ExcludeInteractive(m *MediaFile) bool {
+	return !m.IsInteractive()
+}
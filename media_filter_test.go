@@ -0,0 +1,46 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mediaFileIDs(files []MediaFile) []string {
+	var ids []string
+	for _, m := range files {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func TestFilterMediaFiles(t *testing.T) {
+	files := []MediaFile{
+		{ID: "mp4-hd", Delivery: DeliveryProgressive, Type: "video/mp4", Codec: "avc1.640028", Width: 1920, Height: 1080, Bitrate: 6000},
+		{ID: "mp4-sd", Delivery: DeliveryProgressive, Type: "Video/MP4", Width: 640, Height: 480, Bitrate: 800},
+		{ID: "webm", Delivery: DeliveryProgressive, Type: "video/webm", Codec: "vp9", Width: 1280, Height: 720, Bitrate: 2000},
+		{ID: "hls", Delivery: DeliveryStreaming, Type: MIMEHLS, MinBitrate: 500, MaxBitrate: 8000},
+		{ID: "vpaid", Delivery: DeliveryProgressive, Type: MIMEJavaScript, APIFramework: "VPAID", Width: 640, Height: 360},
+	}
+	for name, tc := range map[string]struct {
+		filters []MediaFileFilter
+		want    []string
+	}{
+		"none":            {nil, []string{"mp4-hd", "mp4-sd", "webm", "hls", "vpaid"}},
+		"mime":            {[]MediaFileFilter{ByMIME(MIMEVideoMP4, MIMEJavaScript)}, []string{"mp4-hd", "mp4-sd", "vpaid"}},
+		"codec":           {[]MediaFileFilter{ByCodec("avc1")}, []string{"mp4-hd", "mp4-sd", "hls", "vpaid"}},
+		"bitrate":         {[]MediaFileFilter{ByMaxBitrate(1000)}, []string{"mp4-sd", "hls", "vpaid"}},
+		"aspect ratio":    {[]MediaFileFilter{ByAspectRatio(16.0/9, 0.01)}, []string{"mp4-hd", "webm", "hls", "vpaid"}},
+		"progressive":     {[]MediaFileFilter{ProgressiveOnly}, []string{"mp4-hd", "mp4-sd", "webm", "vpaid"}},
+		"not interactive": {[]MediaFileFilter{ExcludeInteractive}, []string{"mp4-hd", "mp4-sd", "webm", "hls"}},
+		"composed": {[]MediaFileFilter{ProgressiveOnly, ExcludeInteractive, ByAspectRatio(16.0/9, 0.01), ByMaxBitrate(3000)},
+			[]string{"webm"}},
+	} {
+		assert.Equal(t, tc.want, mediaFileIDs(FilterMediaFiles(files, tc.filters...)), name)
+	}
+
+	kept := FilterMediaFiles(files, ProgressiveOnly)
+	kept[0].ID = "changed"
+	assert.Equal(t, "mp4-hd", files[0].ID)
+	assert.Empty(t, FilterMediaFiles(files, ByMIME()))
+}
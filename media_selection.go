@@ -0,0 +1,116 @@
+package vast
+
+import "strings"
+
+// SelectionCriteria describes the media file a player is looking for. The
+// embedded capabilities are hard constraints: media files the player doesn't
+// support are never selected. The other fields are preferences, zero values
+// meaning no preference.
+type SelectionCriteria struct {
+	MediaFileCapabilities
+	// MIME types in decreasing order of preference. Media files of other
+	// types come after the listed ones.
+	PreferredMIMETypes []string
+	// Preferred delivery method
+	PreferredDelivery Delivery
+	// Dimensions of the player. The media file closest to them is preferred,
+	// the largest one if they are zero.
+	TargetWidth  int
+	TargetHeight int
+	// Bitrate in Kbps the player targets, e.g. its estimated bandwidth. The
+	// highest bitrate not above it is preferred, the lowest one above it
+	// otherwise; the highest bitrate if zero.
+	TargetBitrate int
+}
+
+// SelectMediaFile returns the media file of the linear creative best
+// matching the criteria, or nil if the player supports none of them.
+//
+// The supported media files are ranked by the following criteria, each one
+// only breaking the ties of the previous ones:
+//  1. the rank of their MIME type among PreferredMIMETypes;
+//  2. whether their delivery is PreferredDelivery;
+//  3. the distance of their pixel count to the target one, smaller first,
+//     or their pixel count, larger first, without target dimensions;
+//  4. their bitrate relative to TargetBitrate as described there. Media
+//     files without bitrate, such as adaptive streams, match any target;
+//  5. non-interactive media files first, as they need no API framework;
+//  6. their order in the document.
+func (l *Linear) SelectMediaFile(criteria SelectionCriteria) *MediaFile {
+	var best *MediaFile
+	var bestScore mediaScore
+	for i := range l.MediaFiles {
+		m := &l.MediaFiles[i]
+		if !criteria.Supports(m) {
+			continue
+		}
+		if s := criteria.score(m); best == nil || s.less(bestScore) {
+			best, bestScore = m, s
+		}
+	}
+	return best
+}
+
+// mediaScore ranks a media file, lower values being better.
+type mediaScore [5]int64
+
+func (s mediaScore) less(o mediaScore) bool {
+	for i := range s {
+		if s[i] != o[i] {
+			return s[i] < o[i]
+		}
+	}
+	return false
+}
+
+func (c *SelectionCriteria) score(m *MediaFile) mediaScore {
+	var s mediaScore
+	s[0] = int64(len(c.PreferredMIMETypes))
+	for i, t := range c.PreferredMIMETypes {
+		if strings.EqualFold(baseMIMEType(t), baseMIMEType(m.Type)) {
+			s[0] = int64(i)
+			break
+		}
+	}
+	if c.PreferredDelivery != "" && m.Delivery != c.PreferredDelivery {
+		s[1] = 1
+	}
+	pixels := int64(m.Width) * int64(m.Height)
+	if target := int64(c.TargetWidth) * int64(c.TargetHeight); target > 0 {
+		s[2] = abs64(pixels - target)
+	} else {
+		s[2] = -pixels
+	}
+	s[3] = c.bitrateScore(m)
+	if m.IsInteractive() {
+		s[4] = 1
+	}
+	return s
+}
+
+// bitrateScore ranks the bitrate of m against the target bitrate: bitrates
+// not above the target come first, highest first, then the ones above it,
+// lowest first.
+func (c *SelectionCriteria) bitrateScore(m *MediaFile) int64 {
+	bitrate := int64(m.Bitrate)
+	if bitrate == 0 {
+		bitrate = int64(m.MaxBitrate)
+	}
+	target := int64(c.TargetBitrate)
+	switch {
+	case bitrate == 0:
+		return 0
+	case target <= 0:
+		return -bitrate
+	case bitrate <= target:
+		return target - bitrate
+	}
+	return target + bitrate
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
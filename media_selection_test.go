@@ -0,0 +1,49 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectMediaFile(t *testing.T) {
+	l := &Linear{MediaFiles: []MediaFile{
+		{ID: "vpaid", Delivery: DeliveryProgressive, Type: MIMEJavaScript, APIFramework: APIFrameworkVPAID, Width: 640, Height: 360},
+		{ID: "1080", Delivery: DeliveryProgressive, Type: MIMEVideoMP4, Width: 1920, Height: 1080, Bitrate: 6000},
+		{ID: "720", Delivery: DeliveryProgressive, Type: MIMEVideoMP4, Width: 1280, Height: 720, Bitrate: 2500},
+		{ID: "720-low", Delivery: DeliveryProgressive, Type: MIMEVideoMP4, Width: 1280, Height: 720, Bitrate: 1200},
+		{ID: "360", Delivery: DeliveryProgressive, Type: MIMEVideoMP4, Width: 640, Height: 360, Bitrate: 800},
+		{ID: "webm", Delivery: DeliveryProgressive, Type: MIMEVideoWebM, Width: 1280, Height: 720, Bitrate: 2000},
+		{ID: "hls", Delivery: DeliveryStreaming, Type: MIMEHLS, Width: 1280, Height: 720},
+	}}
+	for name, tc := range map[string]struct {
+		criteria SelectionCriteria
+		want     string
+	}{
+		"largest by default":   {SelectionCriteria{}, "1080"},
+		"closest to the size":  {SelectionCriteria{TargetWidth: 1280, TargetHeight: 720}, "720"},
+		"bitrate below target": {SelectionCriteria{TargetWidth: 1280, TargetHeight: 720, TargetBitrate: 2000}, "webm"},
+		"preferred delivery": {SelectionCriteria{TargetWidth: 1280, TargetHeight: 720, TargetBitrate: 2000,
+			PreferredDelivery: DeliveryStreaming}, "hls"},
+		"preferred MIME type": {SelectionCriteria{TargetWidth: 1280, TargetHeight: 720, TargetBitrate: 2000,
+			PreferredMIMETypes: []string{MIMEVideoMP4}}, "720-low"},
+		"lowest above target": {SelectionCriteria{TargetWidth: 1280, TargetHeight: 720, TargetBitrate: 1000,
+			PreferredMIMETypes: []string{MIMEVideoMP4}}, "720-low"},
+		"capabilities": {SelectionCriteria{MediaFileCapabilities: MediaFileCapabilities{MaxBitrate: 1000, MIMETypes: []string{MIMEVideoMP4}}}, "360"},
+		"interactive last": {SelectionCriteria{TargetWidth: 640, TargetHeight: 360,
+			MediaFileCapabilities: MediaFileCapabilities{APIFrameworks: []string{APIFrameworkVPAID}},
+			PreferredMIMETypes:    []string{MIMEJavaScript, MIMEVideoMP4}}, "vpaid"},
+	} {
+		if m := l.SelectMediaFile(tc.criteria); assert.NotNil(t, m, name) {
+			assert.Equal(t, tc.want, m.ID, name)
+		}
+	}
+
+	// ties are broken by document order
+	l.MediaFiles[3].Bitrate = 2500
+	assert.Equal(t, "720", l.SelectMediaFile(SelectionCriteria{TargetWidth: 1280, TargetHeight: 720, PreferredDelivery: DeliveryProgressive}).ID)
+
+	none := SelectionCriteria{MediaFileCapabilities: MediaFileCapabilities{MIMETypes: []string{MIMEAudioMPEG}}}
+	assert.Nil(t, l.SelectMediaFile(none))
+	assert.Nil(t, (&Linear{}).SelectMediaFile(SelectionCriteria{}))
+}
@@ -0,0 +1,117 @@
+package vast
+
+import "sort"
+
+// MergePolicy controls how Merge combines the ads of two documents.
+type MergePolicy struct {
+	// If true, the ads part of a pod (sequence > 0) are renumbered from 1 so
+	// that the pod ads of src play after the pod ads of dst.
+	RenumberSequences bool
+	// If true, the ads of src whose creatives share a UniversalAdId with an
+	// ad already in dst are dropped.
+	DedupUniversalAdID bool
+	// If true, the impressions of a dropped duplicate ad are appended to the
+	// ad it duplicates, so that every demand source is still notified.
+	ConcatImpressions bool
+	// If true, the root Error URIs of src are appended to the ones of dst.
+	ConcatErrors bool
+}
+
+// Merge appends the ads of src to dst according to policy. The ads are deep
+// copied so that dst and src don't share memory. If src declares a newer
+// version than dst, dst is upgraded to it.
+func Merge(dst, src *VAST, policy MergePolicy) {
+	if src == nil {
+		return
+	}
+	if sv, dv := src.EffectiveVersion(), dst.EffectiveVersion(); sv.Valid() && !dv.AtLeast(sv) {
+		dst.Version = string(sv)
+		if dst.InferredVersion != "" {
+			dst.InferredVersion = sv
+		}
+	}
+
+	seen := map[UniversalAdID]int{}
+	if policy.DedupUniversalAdID {
+		for i := range dst.Ads {
+			for _, id := range dst.Ads[i].UniversalAdIDs() {
+				if _, ok := seen[id]; !ok {
+					seen[id] = i
+				}
+			}
+		}
+	}
+
+	dstLen := len(dst.Ads)
+	for i := range src.Ads {
+		ad := src.Ads[i].Clone()
+		if policy.DedupUniversalAdID {
+			if j, dup := duplicateOf(ad, seen); dup {
+				if policy.ConcatImpressions {
+					appendImpressions(&dst.Ads[j], adImpressions(ad))
+				}
+				continue
+			}
+			for _, id := range ad.UniversalAdIDs() {
+				seen[id] = len(dst.Ads)
+			}
+		}
+		dst.Ads = append(dst.Ads, *ad)
+	}
+
+	if policy.ConcatErrors {
+		dst.Errors = append(dst.Errors, src.Errors...)
+	}
+
+	if policy.RenumberSequences {
+		renumberPod(dst.Ads, dstLen)
+	}
+}
+
+// renumberPod renumbers the pod ads from 1, keeping the ones before split
+// ahead of the ones after it, and ordering each group by its sequence.
+func renumberPod(ads []Ad, split int) {
+	var pod []int
+	for i := range ads {
+		if ads[i].Sequence > 0 {
+			pod = append(pod, i)
+		}
+	}
+	sort.SliceStable(pod, func(a, b int) bool {
+		ia, ib := pod[a], pod[b]
+		if (ia < split) != (ib < split) {
+			return ia < split
+		}
+		return ads[ia].Sequence < ads[ib].Sequence
+	})
+	for n, i := range pod {
+		ads[i].Sequence = n + 1
+	}
+}
+
+func duplicateOf(ad *Ad, seen map[UniversalAdID]int) (int, bool) {
+	for _, id := range ad.UniversalAdIDs() {
+		if j, ok := seen[id]; ok {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+func adImpressions(ad *Ad) []Impression {
+	if ad.InLine != nil {
+		return ad.InLine.Impressions
+	}
+	if ad.Wrapper != nil {
+		return ad.Wrapper.Impressions
+	}
+	return nil
+}
+
+func appendImpressions(ad *Ad, imps []Impression) {
+	if ad.InLine != nil {
+		ad.InLine.Impressions = append(ad.InLine.Impressions, imps...)
+	} else if ad.Wrapper != nil {
+		ad.Wrapper.Impressions = append(ad.Wrapper.Impressions, imps...)
+	}
+}
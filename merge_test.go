@@ -0,0 +1,74 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mergeAd(id string, seq int, uaid string, imp URI) Ad {
+	return Ad{
+		ID:       id,
+		Sequence: seq,
+		InLine: &InLine{
+			Impressions: []Impression{{URI: imp}},
+			Creatives:   []Creative{{UniversalAdID: &UniversalAdID{IDRegistry: "Ad-ID", ID: uaid}}},
+		},
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dst := &VAST{
+		Version: "3.0",
+		Ads:     []Ad{mergeAd("d1", 1, "A", "http://dsp1/a"), mergeAd("d2", 2, "B", "http://dsp1/b")},
+		Errors:  []CDATAURI{{URI: "http://dsp1/error"}},
+	}
+	src := &VAST{
+		Version: "4.0",
+		Ads: []Ad{
+			mergeAd("s1", 2, "C", "http://dsp2/c"),
+			mergeAd("s2", 1, "A", "http://dsp2/a"),
+			mergeAd("s3", 0, "D", "http://dsp2/d"),
+		},
+		Errors: []CDATAURI{{URI: "http://dsp2/error"}},
+	}
+
+	Merge(dst, src, MergePolicy{RenumberSequences: true, DedupUniversalAdID: true, ConcatImpressions: true, ConcatErrors: true})
+
+	assert.Equal(t, "4.0", dst.Version)
+	if assert.Len(t, dst.Ads, 4) {
+		var ids []string
+		var seqs []int
+		for _, ad := range dst.Ads {
+			ids = append(ids, ad.ID)
+			seqs = append(seqs, ad.Sequence)
+		}
+		assert.Equal(t, []string{"d1", "d2", "s1", "s3"}, ids)
+		assert.Equal(t, []int{1, 2, 3, 0}, seqs)
+		assert.Equal(t, []Impression{{URI: "http://dsp1/a"}, {URI: "http://dsp2/a"}}, dst.Ads[0].InLine.Impressions)
+	}
+	assert.Equal(t, []CDATAURI{{URI: "http://dsp1/error"}, {URI: "http://dsp2/error"}}, dst.Errors)
+
+	// src is left untouched and shares nothing with dst
+	dst.Ads[2].InLine.Impressions[0].URI = "changed"
+	assert.Equal(t, URI("http://dsp2/c"), src.Ads[0].InLine.Impressions[0].URI)
+	assert.Equal(t, 2, src.Ads[0].Sequence)
+}
+
+func TestMergeDefaultPolicy(t *testing.T) {
+	dst := &VAST{Version: "4.1", Ads: []Ad{mergeAd("d1", 1, "A", "http://dsp1/a")}, Errors: []CDATAURI{{URI: "http://dsp1/error"}}}
+	src := &VAST{Version: "3.0", Ads: []Ad{mergeAd("s1", 1, "A", "http://dsp2/a")}, Errors: []CDATAURI{{URI: "http://dsp2/error"}}}
+
+	Merge(dst, src, MergePolicy{})
+
+	assert.Equal(t, "4.1", dst.Version)
+	if assert.Len(t, dst.Ads, 2) {
+		assert.Equal(t, 1, dst.Ads[0].Sequence)
+		assert.Equal(t, 1, dst.Ads[1].Sequence)
+		assert.Len(t, dst.Ads[0].InLine.Impressions, 1)
+	}
+	assert.Len(t, dst.Errors, 1)
+
+	Merge(dst, nil, MergePolicy{})
+	assert.Len(t, dst.Ads, 2)
+}
@@ -0,0 +1,10 @@
+package vast
+
+// Mezzanine returns the first mezzanine file of the linear creative, or nil
+// if it has none.
+func (l *Linear) Mezzanine() *Mezzanine {
+	if len(l.Mezzanines) == 0 {
+		return nil
+	}
+	return &l.Mezzanines[0]
+}
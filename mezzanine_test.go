@@ -0,0 +1,56 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const mezzanineLinear = `<Linear><Duration>00:00:15</Duration><MediaFiles>` +
+	`<MediaFile delivery="progressive" type="video/mp4" width="640" height="360"><![CDATA[http://cdn.example.com/ad.mp4]]></MediaFile>` +
+	`<Mezzanine id="mz" delivery="progressive" type="video/mp4" width="1920" height="1080" codec="prores" fileSize="123456" mediaType="2D"><![CDATA[http://cdn.example.com/mezz.mov]]></Mezzanine>` +
+	`</MediaFiles></Linear>`
+
+func TestMezzanine(t *testing.T) {
+	var l Linear
+	if !assert.NoError(t, xml.Unmarshal([]byte(mezzanineLinear), &l)) {
+		return
+	}
+	assert.Len(t, l.MediaFiles, 1)
+	m := l.Mezzanine()
+	if assert.NotNil(t, m) {
+		assert.Equal(t, Mezzanine{
+			ID:        "mz",
+			Delivery:  DeliveryProgressive,
+			Type:      "video/mp4",
+			Width:     1920,
+			Height:    1080,
+			Codec:     "prores",
+			FileSize:  123456,
+			MediaType: "2D",
+			URI:       "http://cdn.example.com/mezz.mov",
+		}, *m)
+	}
+
+	// both media files and mezzanine share the MediaFiles container
+	b, err := xml.Marshal(l)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(b), mezzanineLinear[len("<Linear><Duration>00:00:15</Duration>"):])
+	}
+
+	assert.Nil(t, (&Linear{}).Mezzanine())
+}
+
+func TestMezzanineValidation(t *testing.T) {
+	v := validInLineVAST("4.0")
+	linear := v.Ads[0].InLine.Creatives[0].Linear
+	linear.Mezzanines = []Mezzanine{{Delivery: DeliveryProgressive, Type: "video/mp4", Width: 1920, Height: 1080, URI: "http://cdn.example.com/mezz.mov"}}
+	assert.NoError(t, v.Validate())
+
+	linear.Mezzanines[0].Width = 0
+	assert.EqualError(t, v.Validate(), "invalid VAST.Ad[0].InLine.Creative[0].Linear.Mezzanine[0]: width and height must be positive")
+
+	v.Ads[0].InLine.AdServingId = ""
+	assert.Equal(t, Version4_0, minimumVersion(v))
+}
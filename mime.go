@@ -0,0 +1,150 @@
+package vast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Common media MIME types
+const (
+	MIMEVideoMP4       = "video/mp4"
+	MIMEVideoWebM      = "video/webm"
+	MIMEVideo3GPP      = "video/3gpp"
+	MIMEVideoOgg       = "video/ogg"
+	MIMEHLS            = "application/x-mpegURL"
+	MIMEAppleHLS       = "application/vnd.apple.mpegurl"
+	MIMEDASH           = "application/dash+xml"
+	MIMEAudioMPEG      = "audio/mpeg"
+	MIMEAudioMP4       = "audio/mp4"
+	MIMEAudioAAC       = "audio/aac"
+	MIMEAudioOgg       = "audio/ogg"
+	MIMEJavaScript     = "application/javascript"
+	MIMEShockwaveFlash = "application/x-shockwave-flash"
+)
+
+// adaptiveMIMETypes are the manifest MIME types of adaptive streaming formats.
+// They describe a set of renditions, so a single fixed bitrate makes no sense
+// for them and they can't be progressively downloaded.
+var adaptiveMIMETypes = map[string]bool{
+	"application/x-mpegurl":         true,
+	"application/vnd.apple.mpegurl": true,
+	"application/dash+xml":          true,
+}
+
+// baseMIMEType returns the lower-cased MIME type without its parameters.
+func baseMIMEType(t string) string {
+	if i := strings.IndexByte(t, ';'); i >= 0 {
+		t = t[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(t))
+}
+
+// IsAdaptiveMIMEType returns true if t is the MIME type of an adaptive
+// streaming manifest (HLS or DASH).
+func IsAdaptiveMIMEType(t string) bool {
+	return adaptiveMIMETypes[baseMIMEType(t)]
+}
+
+// MediaFileCapabilities describes the media files a player supports. Zero
+// values mean no restriction.
+type MediaFileCapabilities struct {
+	// MIME types the player can play, compared case-insensitively and
+	// ignoring parameters
+	MIMETypes []string
+	// Codecs the player can decode. A codec matches the codecs of a media
+	// file having it as prefix, e.g. "avc1" matches "avc1.4d401f". Media files
+	// without codec are assumed to be playable.
+	Codecs []string
+	// Delivery methods the player supports
+	Delivery []Delivery
+	// Maximum pixel dimensions of the player
+	MaxWidth  int
+	MaxHeight int
+	// Bitrate range in Kbps the player can sustain
+	MinBitrate int
+	MaxBitrate int
+	// Maximum size in bytes of media files
+	MaxFileSize int
+	// API frameworks the player implements. Media files requiring any other
+	// framework are rejected.
+	APIFrameworks []string
+}
+
+// Supports returns true if the player can play the media file.
+func (c *MediaFileCapabilities) Supports(m *MediaFile) bool {
+	return c.Check(m) == nil
+}
+
+// Check returns an error describing why the player can't play the media
+// file, or nil if it can.
+func (c *MediaFileCapabilities) Check(m *MediaFile) error {
+	if len(c.MIMETypes) > 0 && !containsFold(c.MIMETypes, baseMIMEType(m.Type), baseMIMEType) {
+		return fmt.Errorf("unsupported MIME type %q", m.Type)
+	}
+	if len(c.Delivery) > 0 {
+		ok := false
+		for _, d := range c.Delivery {
+			ok = ok || d == m.Delivery
+		}
+		if !ok {
+			return fmt.Errorf("unsupported delivery %q", m.Delivery)
+		}
+	}
+	if len(c.Codecs) > 0 && m.Codec != "" && !c.supportsCodecs(m.Codec) {
+		return fmt.Errorf("unsupported codec %q", m.Codec)
+	}
+	if (c.MaxWidth > 0 && m.Width > c.MaxWidth) || (c.MaxHeight > 0 && m.Height > c.MaxHeight) {
+		return fmt.Errorf("size %dx%d exceeds %dx%d", m.Width, m.Height, c.MaxWidth, c.MaxHeight)
+	}
+	lo, hi := m.bitrateRange()
+	if hi > 0 && c.MinBitrate > 0 && hi < c.MinBitrate {
+		return fmt.Errorf("bitrate %d below %d", hi, c.MinBitrate)
+	}
+	if lo > 0 && c.MaxBitrate > 0 && lo > c.MaxBitrate {
+		return fmt.Errorf("bitrate %d above %d", lo, c.MaxBitrate)
+	}
+	if c.MaxFileSize > 0 && m.FileSize > c.MaxFileSize {
+		return fmt.Errorf("file size %d above %d", m.FileSize, c.MaxFileSize)
+	}
+	if m.APIFramework != "" && !containsFold(c.APIFrameworks, m.APIFramework, strings.TrimSpace) {
+		return fmt.Errorf("unsupported API framework %q", m.APIFramework)
+	}
+	return nil
+}
+
+// bitrateRange returns the range of bitrates of the media file in Kbps, 0
+// when unknown.
+func (m *MediaFile) bitrateRange() (lo, hi int) {
+	if m.MinBitrate > 0 || m.MaxBitrate > 0 {
+		return m.MinBitrate, m.MaxBitrate
+	}
+	return m.Bitrate, m.Bitrate
+}
+
+// supportsCodecs returns true if every codec listed in codecs, separated by
+// commas, is supported.
+func (c *MediaFileCapabilities) supportsCodecs(codecs string) bool {
+	for _, codec := range strings.Split(codecs, ",") {
+		codec = strings.ToLower(strings.TrimSpace(codec))
+		if codec == "" {
+			continue
+		}
+		ok := false
+		for _, s := range c.Codecs {
+			ok = ok || strings.HasPrefix(codec, strings.ToLower(s))
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(list []string, s string, norm func(string) string) bool {
+	for _, e := range list {
+		if strings.EqualFold(norm(e), s) {
+			return true
+		}
+	}
+	return false
+}
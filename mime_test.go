@@ -0,0 +1,55 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAdaptiveMIMEType(t *testing.T) {
+	assert.True(t, IsAdaptiveMIMEType(MIMEHLS))
+	assert.True(t, IsAdaptiveMIMEType("Application/Dash+XML; profiles=x"))
+	assert.False(t, IsAdaptiveMIMEType(MIMEVideoMP4))
+}
+
+func TestMediaFileCapabilities(t *testing.T) {
+	caps := MediaFileCapabilities{
+		MIMETypes:     []string{MIMEVideoMP4, MIMEHLS},
+		Codecs:        []string{"avc1", "mp4a"},
+		Delivery:      []Delivery{DeliveryProgressive, DeliveryStreaming},
+		MaxWidth:      1920,
+		MaxHeight:     1080,
+		MaxBitrate:    5000,
+		APIFrameworks: []string{APIFrameworkSIMID},
+	}
+	mp4 := MediaFile{Delivery: DeliveryProgressive, Type: "video/MP4", Codec: "avc1.4d401f, mp4a.40.2", Width: 1280, Height: 720, Bitrate: 2000}
+	assert.True(t, caps.Supports(&mp4))
+
+	hls := MediaFile{Delivery: DeliveryStreaming, Type: "application/x-mpegurl", MinBitrate: 500, MaxBitrate: 8000}
+	assert.True(t, caps.Supports(&hls))
+
+	for _, tc := range []struct {
+		edit func(*MediaFile)
+		err  string
+	}{
+		{func(m *MediaFile) { m.Type = MIMEVideoWebM }, `unsupported MIME type "video/webm"`},
+		{func(m *MediaFile) { m.Codec = "hev1.1.6.L93.B0" }, `unsupported codec "hev1.1.6.L93.B0"`},
+		{func(m *MediaFile) { m.Delivery = "download" }, `unsupported delivery "download"`},
+		{func(m *MediaFile) { m.Width = 3840 }, "size 3840x720 exceeds 1920x1080"},
+		{func(m *MediaFile) { m.Bitrate = 6000 }, "bitrate 6000 above 5000"},
+		{func(m *MediaFile) { m.APIFramework = "VPAID" }, `unsupported API framework "VPAID"`},
+	} {
+		m := mp4
+		tc.edit(&m)
+		assert.EqualError(t, caps.Check(&m), tc.err)
+	}
+
+	m := mp4
+	m.APIFramework = "simid"
+	assert.NoError(t, caps.Check(&m))
+
+	// zero capabilities accept anything but interactive media files
+	var any MediaFileCapabilities
+	assert.True(t, any.Supports(&hls))
+	assert.False(t, any.Supports(&m))
+}
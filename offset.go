@@ -1,9 +1,11 @@
 package vast
 
 import (
+	"encoding/xml"
 	"fmt"
+	"math"
 	"strconv"
-	"strings"
+	"time"
 )
 
 // Offset represents either a vast.Duration or a percentage of the video duration.
@@ -14,25 +16,131 @@ type Offset struct {
 	Percent float32
 }
 
+// OffsetDuration returns a duration based offset.
+func OffsetDuration(d time.Duration) *Offset {
+	dur := FromDuration(d)
+	return &Offset{Duration: &dur}
+}
+
+// OffsetSeconds returns a duration based offset of the given number of seconds.
+func OffsetSeconds(seconds float64) *Offset {
+	return OffsetDuration(time.Duration(seconds * float64(time.Second)))
+}
+
+// OffsetPercent returns a percent based offset, percent being a fraction of
+// the creative duration between 0 and 1.
+func OffsetPercent(percent float32) *Offset {
+	return &Offset{Percent: percent}
+}
+
+// IsPercent returns true if the offset is percent based.
+func (o *Offset) IsPercent() bool {
+	return o.Duration == nil
+}
+
+// AsDuration returns the duration of a duration based offset, and false if
+// the offset is percent based.
+func (o *Offset) AsDuration() (time.Duration, bool) {
+	if o.Duration == nil {
+		return 0, false
+	}
+	return time.Duration(*o.Duration), true
+}
+
+// ResolveAgainst returns the absolute time of the offset within a creative
+// of the given duration. Percent based offsets are rounded to the
+// millisecond; a nil offset resolves to 0.
+func (o *Offset) ResolveAgainst(d Duration) time.Duration {
+	switch {
+	case o == nil:
+		return 0
+	case o.Duration != nil:
+		return time.Duration(*o.Duration)
+	}
+	ms := math.Round(float64(o.Percent) * float64(time.Duration(d)/time.Millisecond))
+	return time.Duration(ms) * time.Millisecond
+}
+
 // MarshalText implements the encoding.TextMarshaler interface.
 func (o Offset) MarshalText() ([]byte, error) {
 	if o.Duration != nil {
 		return o.Duration.MarshalText()
 	}
-	return []byte(fmt.Sprintf("%d%%", int(o.Percent*100))), nil
+	b := strconv.AppendInt(make([]byte, 0, 5), int64(o.Percent*100), 10)
+	return append(b, '%'), nil
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
 func (o *Offset) UnmarshalText(data []byte) error {
-	if strings.HasSuffix(string(data), "%") {
-		p, err := strconv.ParseInt(string(data[:len(data)-1]), 10, 8)
-		if err != nil {
-			return fmt.Errorf("invalid offset: %s", data)
-		}
+	d, p, percent, ok := parseOffset(string(data))
+	switch {
+	case !ok && percent:
+		return fmt.Errorf("invalid offset: %s", data)
+	case !ok:
+		return fmt.Errorf("invalid duration: %s", data)
+	}
+	o.set(d, p, percent)
+	return nil
+}
+
+// UnmarshalXMLAttr implements the xml.UnmarshalerAttr interface, parsing the
+// attribute value in place.
+func (o *Offset) UnmarshalXMLAttr(attr xml.Attr) error {
+	d, p, percent, ok := parseOffset(attr.Value)
+	switch {
+	case !ok && percent:
+		return fmt.Errorf("invalid offset: %s", attr.Value)
+	case !ok:
+		return fmt.Errorf("invalid duration: %s", attr.Value)
+	}
+	o.set(d, p, percent)
+	return nil
+}
+
+func (o *Offset) set(d Duration, p int, percent bool) {
+	if percent {
 		o.Percent = float32(p) / 100
-		return nil
+		return
+	}
+	dur := d
+	o.Duration = &dur
+}
+
+// parseOffset parses a percent ("25%") or duration based offset, reporting
+// which one it is even if it is invalid. Like parseDuration, it doesn't
+// retain s.
+func parseOffset(s string) (d Duration, p int, percent, ok bool) {
+	if n := len(s); n > 0 && s[n-1] == '%' {
+		p, ok = parsePercent(s[:n-1])
+		return 0, p, true, ok
+	}
+	d, ok = parseDuration(s)
+	return d, 0, false, ok
+}
+
+// parsePercent parses the integer percentage of an offset, between -128 and
+// 127 as historically accepted.
+func parsePercent(s string) (int, bool) {
+	neg := false
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		neg, s = s[0] == '-', s[1:]
+	}
+	if len(s) == 0 {
+		return 0, false
+	}
+	p := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' || p > 128 {
+			return 0, false
+		}
+		p = p*10 + int(c-'0')
+	}
+	if neg {
+		p = -p
+	}
+	if p < -128 || p > 127 {
+		return 0, false
 	}
-	var d Duration
-	o.Duration = &d
-	return o.Duration.UnmarshalText(data)
+	return p, true
 }
@@ -1,7 +1,9 @@
 package vast
 
 import (
+	"encoding/xml"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -42,4 +44,65 @@ func TestOffsetUnmarshaler(t *testing.T) {
 	}
 	o = Offset{}
 	assert.EqualError(t, o.UnmarshalText([]byte("abc%")), "invalid offset: abc%")
+	assert.EqualError(t, o.UnmarshalText([]byte("200%")), "invalid offset: 200%")
+	assert.EqualError(t, o.UnmarshalText([]byte("5s")), "invalid duration: 5s")
+
+	var tracking Tracking
+	if assert.NoError(t, xml.Unmarshal([]byte(`<Tracking event="progress" offset="00:00:05.500"></Tracking>`), &tracking)) {
+		d, ok := tracking.Offset.AsDuration()
+		assert.True(t, ok)
+		assert.Equal(t, 5500*time.Millisecond, d)
+	}
+	assert.EqualError(t, xml.Unmarshal([]byte(`<Tracking offset="x%"></Tracking>`), &tracking), "invalid offset: x%")
+}
+
+func TestOffsetAllocs(t *testing.T) {
+	var o Offset
+	text := []byte("25%")
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() { _ = o.UnmarshalText(text) }))
+	attr := xml.Attr{Value: "00:00:05"}
+	// only the Duration pointed to by the offset is allocated
+	assert.Equal(t, float64(1), testing.AllocsPerRun(100, func() { _ = o.UnmarshalXMLAttr(attr) }))
+}
+
+func BenchmarkOffsetUnmarshalText(b *testing.B) {
+	percent, duration := []byte("25%"), []byte("00:00:05.500")
+	var o Offset
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := o.UnmarshalText(percent); err != nil {
+			b.Fatal(err)
+		}
+		if err := o.UnmarshalText(duration); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestOffsetConstructors(t *testing.T) {
+	o := OffsetSeconds(5.5)
+	assert.False(t, o.IsPercent())
+	d, ok := o.AsDuration()
+	assert.True(t, ok)
+	assert.Equal(t, 5500*time.Millisecond, d)
+
+	o = OffsetPercent(.25)
+	assert.True(t, o.IsPercent())
+	_, ok = o.AsDuration()
+	assert.False(t, ok)
+	b, err := o.MarshalText()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "25%", string(b))
+	}
+}
+
+func TestOffsetResolveAgainst(t *testing.T) {
+	total := Duration(30 * time.Second)
+	assert.Equal(t, 7500*time.Millisecond, OffsetPercent(.25).ResolveAgainst(total))
+	assert.Equal(t, 10*time.Second, OffsetPercent(1/3.0).ResolveAgainst(total))
+	assert.Equal(t, 5*time.Second, OffsetDuration(5*time.Second).ResolveAgainst(total))
+	assert.Equal(t, time.Duration(0), OffsetPercent(.5).ResolveAgainst(0))
+
+	var o *Offset
+	assert.Equal(t, time.Duration(0), o.ResolveAgainst(total))
 }
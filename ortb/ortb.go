@@ -0,0 +1,210 @@
+// Package ortb bridges VAST documents and OpenRTB bids: it extracts the VAST
+// document of the adm field of a bid, and fills the fields of a bid from a
+// VAST document.
+package ortb
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// AuctionPriceMacro is substituted by exchanges with the clearing price of
+// the auction.
+const AuctionPriceMacro = "${AUCTION_PRICE}"
+
+// Media types of a bid, as found in its mtype field (OpenRTB 2.6)
+const (
+	MTypeBanner = 1
+	MTypeVideo  = 2
+	MTypeAudio  = 3
+	MTypeNative = 4
+)
+
+// ErrNoVAST is returned when the adm field of a bid doesn't hold a VAST
+// document.
+var ErrNoVAST = errors.New("ortb: adm does not hold a VAST document")
+
+// maxUnwrap bounds the number of encoding layers removed from an adm field.
+const maxUnwrap = 4
+
+// ExtractVAST returns the VAST document carried by the adm field of a bid.
+// Besides plain XML, it accepts documents which are URL-encoded, XML-escaped,
+// JSON-quoted or wrapped in a CDATA section, possibly several times. An adm
+// holding a URL is the ad tag of a VAST wrapper returned as such.
+func ExtractVAST(adm string) (*vast.VAST, error) {
+	s := adm
+	for i := 0; ; i++ {
+		s = strings.TrimSpace(strings.TrimPrefix(s, "\ufeff"))
+		if strings.HasPrefix(s, "<") && !strings.HasPrefix(s, "<![CDATA[") {
+			break
+		}
+		if i == maxUnwrap {
+			return nil, ErrNoVAST
+		}
+		lower := strings.ToLower(s)
+		switch {
+		case strings.HasPrefix(lower, "http://"), strings.HasPrefix(lower, "https://"), strings.HasPrefix(lower, "//"):
+			return wrapperOf(s), nil
+		case strings.HasPrefix(s, `"`):
+			var unquoted string
+			if err := json.Unmarshal([]byte(s), &unquoted); err != nil {
+				return nil, ErrNoVAST
+			}
+			s = unquoted
+		case strings.HasPrefix(lower, "%3c"), strings.HasPrefix(lower, "%253c"):
+			unescaped, err := url.QueryUnescape(s)
+			if err != nil {
+				return nil, ErrNoVAST
+			}
+			s = unescaped
+		case strings.HasPrefix(lower, "&lt;"):
+			s = html.UnescapeString(s)
+		case strings.HasPrefix(s, "<![CDATA[") && strings.HasSuffix(s, "]]>"):
+			s = s[len("<![CDATA[") : len(s)-len("]]>")]
+		default:
+			return nil, ErrNoVAST
+		}
+	}
+	if name, err := rootElement(s); err != nil || name != "VAST" {
+		return nil, ErrNoVAST
+	}
+	var v vast.VAST
+	if err := xml.Unmarshal([]byte(s), &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// rootElement returns the name of the root element of an XML document.
+func rootElement(s string) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader(s))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// wrapperOf returns a document made of a single wrapper ad pointing to the
+// ad tag.
+func wrapperOf(tag string) *vast.VAST {
+	return &vast.VAST{Version: string(vast.Version3_0), Ads: []vast.Ad{{Wrapper: &vast.Wrapper{
+		AdSystem:     &vast.AdSystem{Name: vast.DefaultAdSystem},
+		VASTAdTagURI: vast.CDATAURI{URI: vast.URI(tag)},
+	}}}}
+}
+
+// Bid holds the fields of an OpenRTB bid describing a VAST ad. The ID and
+// ImpID fields are left for the caller to fill.
+type Bid struct {
+	ID    string  `json:"id"`
+	ImpID string  `json:"impid"`
+	Price float64 `json:"price"`
+	// The VAST document
+	AdM string `json:"adm"`
+	// The ID of the first ad of the document
+	AdID string `json:"adid,omitempty"`
+	// The ID of the first creative of the document
+	CrID string `json:"crid,omitempty"`
+	// The protocol of the document, as defined by OpenRTB
+	Protocol int `json:"protocol,omitempty"`
+	// The media type, MTypeVideo or MTypeAudio
+	MType int `json:"mtype,omitempty"`
+	// The size of the first media file, if any
+	W int `json:"w,omitempty"`
+	H int `json:"h,omitempty"`
+	// The duration of the ads in seconds, rounded up
+	Dur int `json:"dur,omitempty"`
+}
+
+// NewBid returns a bid of the given price carrying doc. If currency is not
+// empty, the InLine ads without Pricing element get one holding the
+// AuctionPriceMacro in that currency, so that the clearing price is passed to
+// the player; doc itself is left untouched.
+func NewBid(doc *vast.VAST, price float64, currency string) (*Bid, error) {
+	if doc == nil || len(doc.Ads) == 0 {
+		return nil, ErrNoVAST
+	}
+	if currency != "" {
+		doc = doc.Clone()
+		for i := range doc.Ads {
+			if in := doc.Ads[i].InLine; in != nil && in.Pricing == nil {
+				in.Pricing = &vast.Pricing{Model: vast.PricingCPM, Currency: currency, Value: AuctionPriceMacro}
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := xml.NewEncoder(&buf).Encode(doc); err != nil {
+		return nil, err
+	}
+	ad := &doc.Ads[0]
+	bid := &Bid{
+		Price:    price,
+		AdM:      buf.String(),
+		AdID:     ad.ID,
+		Protocol: Protocol(doc.EffectiveVersion(), ad.Wrapper != nil),
+		MType:    MTypeVideo,
+	}
+	if ad.IsAudioOnly() {
+		bid.MType = MTypeAudio
+	}
+	if ad.InLine != nil {
+		for _, c := range ad.InLine.Creatives {
+			if bid.CrID == "" {
+				bid.CrID = c.ID
+			}
+			if c.Linear != nil && len(c.Linear.MediaFiles) > 0 && bid.W == 0 {
+				bid.W, bid.H = c.Linear.MediaFiles[0].Width, c.Linear.MediaFiles[0].Height
+			}
+		}
+	}
+	pod, _ := doc.SplitPod()
+	if len(pod) == 0 {
+		pod = []*vast.Ad{ad}
+	}
+	if d := vast.PodDuration(pod); d > 0 {
+		bid.Dur = int((d + time.Second - 1) / time.Second)
+	}
+	return bid, nil
+}
+
+// Protocol returns the OpenRTB protocol identifier of a VAST version, or 0
+// for an unknown version.
+func Protocol(version vast.SpecVersion, wrapper bool) int {
+	var p int
+	switch version {
+	case vast.Version2_0:
+		p = 2
+	case vast.Version3_0:
+		p = 3
+	case vast.Version4_0:
+		p = 7
+	case vast.Version4_1:
+		p = 11
+	case vast.Version4_2:
+		p = 13
+	default:
+		return 0
+	}
+	if wrapper {
+		switch version {
+		case vast.Version2_0, vast.Version3_0:
+			p += 3
+		default:
+			p++
+		}
+	}
+	return p
+}
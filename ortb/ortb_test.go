@@ -0,0 +1,102 @@
+package ortb
+
+import (
+	"encoding/json"
+	"html"
+	"net/url"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+const doc = `<VAST version="3.0"><Ad id="a1"><InLine><AdSystem>DSP</AdSystem><AdTitle>t</AdTitle>` +
+	`<Impression><![CDATA[https://t.example.com/imp?a=1&b=2]]></Impression><Creatives><Creative id="c1"><Linear>` +
+	`<Duration>00:00:15.500</Duration><MediaFiles><MediaFile delivery="progressive" type="video/mp4" width="640" height="360">` +
+	`<![CDATA[https://cdn.example.com/a.mp4]]></MediaFile></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`
+
+func TestExtractVAST(t *testing.T) {
+	quoted, _ := json.Marshal(doc)
+	for name, adm := range map[string]string{
+		"plain":          doc,
+		"prolog":         "\ufeff  <?xml version=\"1.0\"?>\n" + doc,
+		"url encoded":    url.QueryEscape(doc),
+		"xml escaped":    html.EscapeString(doc),
+		"json quoted":    string(quoted),
+		"cdata":          "<![CDATA[" + doc + "]]>",
+		"double encoded": url.QueryEscape(url.QueryEscape(doc)),
+	} {
+		v, err := ExtractVAST(adm)
+		if assert.NoError(t, err, name) && assert.Len(t, v.Ads, 1, name) {
+			assert.Equal(t, "a1", v.Ads[0].ID, name)
+			assert.Equal(t, []string{"https://t.example.com/imp?a=1&b=2"}, v.Ads[0].ImpressionURLs(), name)
+		}
+	}
+
+	v, err := ExtractVAST(" https://ads.example.com/vast?id=1 ")
+	if assert.NoError(t, err) && assert.NotNil(t, v.Ads[0].Wrapper) {
+		assert.Equal(t, "https://ads.example.com/vast?id=1", string(v.Ads[0].Wrapper.VASTAdTagURI.URI))
+	}
+
+	for _, adm := range []string{"", "hello", `<div>banner</div>`, `{"native":{}}`, `"unterminated`, "%3Cbad%zz"} {
+		_, err := ExtractVAST(adm)
+		assert.Equal(t, ErrNoVAST, err, adm)
+	}
+	_, err = ExtractVAST(`<VAST version="3.0"><Ad>`)
+	assert.Error(t, err)
+}
+
+func TestNewBid(t *testing.T) {
+	v, err := ExtractVAST(doc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	bid, err := NewBid(v, 2.5, "USD")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2.5, bid.Price)
+	assert.Equal(t, "a1", bid.AdID)
+	assert.Equal(t, "c1", bid.CrID)
+	assert.Equal(t, 3, bid.Protocol)
+	assert.Equal(t, MTypeVideo, bid.MType)
+	assert.Equal(t, 640, bid.W)
+	assert.Equal(t, 360, bid.H)
+	assert.Equal(t, 16, bid.Dur)
+	assert.Contains(t, bid.AdM, `<Pricing model="cpm" currency="USD"><![CDATA[${AUCTION_PRICE}]]></Pricing>`)
+	assert.Nil(t, v.Ads[0].InLine.Pricing)
+
+	back, err := ExtractVAST(bid.AdM)
+	if assert.NoError(t, err) {
+		assert.Equal(t, AuctionPriceMacro, back.Ads[0].InLine.Pricing.Value)
+	}
+
+	b, err := json.Marshal(bid)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(b), `"price":2.5,"adm":"\u003cVAST`)
+		var decoded Bid
+		assert.NoError(t, json.Unmarshal(b, &decoded))
+		assert.Equal(t, *bid, decoded)
+	}
+
+	bid, err = NewBid(v, 1, "")
+	if assert.NoError(t, err) {
+		assert.NotContains(t, bid.AdM, "Pricing")
+	}
+	_, err = NewBid(&vast.VAST{}, 1, "")
+	assert.Equal(t, ErrNoVAST, err)
+}
+
+func TestProtocol(t *testing.T) {
+	for version, want := range map[vast.SpecVersion][2]int{
+		vast.Version2_0: {2, 5},
+		vast.Version3_0: {3, 6},
+		vast.Version4_0: {7, 8},
+		vast.Version4_1: {11, 12},
+		vast.Version4_2: {13, 14},
+		"5.0":           {0, 0},
+	} {
+		assert.Equal(t, want[0], Protocol(version, false), version)
+		assert.Equal(t, want[1], Protocol(version, true), version)
+	}
+}
@@ -0,0 +1,78 @@
+// Package pipeline composes the steps of serving an ad request, such as
+// resolving wrappers, filtering ads and injecting trackers, into a single
+// flow reporting the time spent in every step.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// Stage transforms a document. It may modify doc in place or return a new
+// document, and returns nil to end the pipeline without ad.
+type Stage func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error)
+
+// Step is a named stage of a pipeline.
+type Step struct {
+	Name  string
+	Stage Stage
+}
+
+// Timing reports the run of a step.
+type Timing struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// Pipeline runs steps in order, each one receiving the document returned by
+// the previous one.
+type Pipeline struct {
+	Steps []Step
+	// Called after every step, if set
+	OnStep func(ctx context.Context, t Timing)
+}
+
+// New returns a pipeline running the given steps.
+func New(steps ...Step) *Pipeline {
+	return &Pipeline{Steps: steps}
+}
+
+// Then appends a step to the pipeline and returns it.
+func (p *Pipeline) Then(name string, stage Stage) *Pipeline {
+	p.Steps = append(p.Steps, Step{Name: name, Stage: stage})
+	return p
+}
+
+// Run runs the steps on a copy of doc, so that doc itself, such as a cached
+// template, is left untouched. It returns the document returned by the last
+// step, nil if a step ended the pipeline without ad, and the timings of the
+// steps run. The pipeline stops at the first step failing or once ctx is
+// done.
+func (p *Pipeline) Run(ctx context.Context, doc *vast.VAST) (*vast.VAST, []Timing, error) {
+	doc = doc.Clone()
+	timings := make([]Timing, 0, len(p.Steps))
+	for _, step := range p.Steps {
+		if err := ctx.Err(); err != nil {
+			return nil, timings, err
+		}
+		start := time.Now()
+		out, err := step.Stage(ctx, doc)
+		t := Timing{Name: step.Name, Duration: time.Since(start), Err: err}
+		timings = append(timings, t)
+		if p.OnStep != nil {
+			p.OnStep(ctx, t)
+		}
+		if err != nil {
+			return nil, timings, fmt.Errorf("pipeline: %s: %w", step.Name, err)
+		}
+		if out == nil {
+			return nil, timings, nil
+		}
+		doc = out
+	}
+	return doc, timings, nil
+}
@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/freqcap"
+	"github.com/haxqer/vast/macro"
+	"github.com/haxqer/vast/resolve"
+	"github.com/stretchr/testify/assert"
+)
+
+func skeleton(t *testing.T, id string) vast.Ad {
+	doc, err := vast.Skeleton(vast.Version4_1, vast.AdKindInLineLinear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ad := doc.Ads[0]
+	ad.ID = id
+	ad.InLine.Creatives[0].UniversalAdID = &vast.UniversalAdID{IDRegistry: "ad-id.org", ID: id}
+	return ad
+}
+
+func TestPipeline(t *testing.T) {
+	dup := skeleton(t, "a")
+	dup.ID = "a-dup"
+	doc := &vast.VAST{Version: "4.1", Ads: []vast.Ad{skeleton(t, "a"), skeleton(t, "b"), dup, skeleton(t, "drop")}}
+
+	var steps []string
+	p := New(
+		Resolve(&resolve.Resolver{}),
+		Filter(func(ad *vast.Ad) bool { return ad.ID != "drop" }),
+		Dedupe(),
+		InjectTrackers(Trackers{
+			Impressions: []string{"https://pub.example.com/imp?cb=[CACHEBUSTING]&ph=[ADPLAYHEAD]"},
+			Events:      map[vast.EventType][]string{vast.EventStart: {"https://pub.example.com/start"}},
+		}),
+		Macros(&macro.Context{CacheBusting: "42"}),
+		Validate(),
+	)
+	p.OnStep = func(ctx context.Context, timing Timing) { steps = append(steps, timing.Name) }
+	trace := vast.NewDecisionTrace()
+	out, timings, err := p.Run(vast.WithDecisionTrace(context.Background(), trace), doc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"resolve", "filter", "dedupe", "inject-trackers", "macros", "validate"}, steps)
+	assert.Len(t, timings, 6)
+	if assert.Len(t, out.Ads, 2) {
+		assert.Equal(t, "a", out.Ads[0].ID)
+		assert.Equal(t, "b", out.Ads[1].ID)
+		assert.Equal(t, []string{"https://example.com/impression", "https://pub.example.com/imp?cb=42&ph=[ADPLAYHEAD]"}, out.Ads[0].ImpressionURLs())
+		assert.Equal(t, []string{"https://pub.example.com/start"}, out.Ads[1].TrackingURLs(vast.EventStart))
+	}
+	assert.Equal(t, []vast.TraceRejection{
+		{Stage: "filter", AdID: "drop", Reason: "filtered out"},
+		{Stage: "dedupe", AdID: "a-dup", Reason: "duplicate creative"},
+	}, trace.Rejections())
+	assert.Equal(t, []vast.TraceTracker{
+		{Stage: "inject-trackers", AdID: "a", Kind: "impression", URL: "https://pub.example.com/imp?cb=[CACHEBUSTING]&ph=[ADPLAYHEAD]"},
+		{Stage: "inject-trackers", AdID: "a", Kind: "start", URL: "https://pub.example.com/start"},
+		{Stage: "inject-trackers", AdID: "b", Kind: "impression", URL: "https://pub.example.com/imp?cb=[CACHEBUSTING]&ph=[ADPLAYHEAD]"},
+		{Stage: "inject-trackers", AdID: "b", Kind: "start", URL: "https://pub.example.com/start"},
+	}, trace.Trackers())
+	// the input document is left untouched
+	assert.Len(t, doc.Ads, 4)
+	assert.Len(t, doc.Ads[0].InLine.Impressions, 1)
+}
+
+func TestPipelineStops(t *testing.T) {
+	doc := &vast.VAST{Version: "4.1", Ads: []vast.Ad{skeleton(t, "a")}}
+	ran := false
+	p := New(Validate()).
+		Then("fail", func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error) { return nil, errors.New("boom") }).
+		Then("never", func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error) { ran = true; return doc, nil })
+	out, timings, err := p.Run(context.Background(), doc)
+	assert.Nil(t, out)
+	assert.EqualError(t, err, "pipeline: fail: boom")
+	assert.Len(t, timings, 2)
+	assert.False(t, ran)
+
+	p = New(Step{Name: "no-ad", Stage: func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error) { return nil, nil }}, Validate())
+	out, timings, err = p.Run(context.Background(), doc)
+	assert.NoError(t, err)
+	assert.Nil(t, out)
+	assert.Len(t, timings, 1)
+
+	doc.Ads[0].InLine.AdSystem = nil
+	_, _, err = New(Validate()).Run(context.Background(), doc)
+	var verrs vast.ValidationErrors
+	assert.True(t, errors.As(err, &verrs))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, timings, err = New(Validate()).Run(ctx, doc)
+	assert.Equal(t, context.Canceled, err)
+	assert.Empty(t, timings)
+}
+
+func TestFilterSSAI(t *testing.T) {
+	conditional := skeleton(t, "c")
+	yes := vast.Bool(true)
+	conditional.ConditionalAd = &yes
+	var rejected []vast.RejectedAd
+	p := New(FilterSSAI(func(ctx context.Context, ads []vast.RejectedAd) { rejected = ads }))
+	trace := vast.NewDecisionTrace()
+	out, _, err := p.Run(vast.WithDecisionTrace(context.Background(), trace), &vast.VAST{Version: "4.1", Ads: []vast.Ad{skeleton(t, "a"), conditional}})
+	assert.NoError(t, err)
+	if assert.Len(t, out.Ads, 1) {
+		assert.Equal(t, "a", out.Ads[0].ID)
+	}
+	if assert.Len(t, rejected, 1) {
+		assert.Equal(t, vast.SSAIConditional, rejected[0].Reason)
+	}
+	assert.Equal(t, []vast.TraceRejection{{Stage: "filter-ssai", AdID: "c", Reason: "conditional ad"}}, trace.Rejections())
+}
+
+func TestFrequencyCap(t *testing.T) {
+	type userKey struct{}
+	c := &freqcap.Capper{Store: freqcap.NewMemoryStore(), Default: freqcap.Cap{Max: 1, Window: time.Hour}}
+	seen := skeleton(t, "a")
+	assert.NoError(t, c.Record(context.Background(), "u1", &seen))
+
+	var capped []vast.Ad
+	p := New(FrequencyCap(c, func(ctx context.Context) string { return ctx.Value(userKey{}).(string) }, func(ctx context.Context, ads []vast.Ad) {
+		capped = ads
+	}))
+	ctx := context.WithValue(context.Background(), userKey{}, "u1")
+	out, _, err := p.Run(ctx, &vast.VAST{Version: "4.1", Ads: []vast.Ad{skeleton(t, "a"), skeleton(t, "b")}})
+	assert.NoError(t, err)
+	if assert.Len(t, out.Ads, 1) {
+		assert.Equal(t, "b", out.Ads[0].ID)
+	}
+	if assert.Len(t, capped, 1) {
+		assert.Equal(t, "a", capped[0].ID)
+	}
+}
@@ -0,0 +1,169 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/freqcap"
+	"github.com/haxqer/vast/macro"
+	"github.com/haxqer/vast/resolve"
+)
+
+// Resolve returns a step resolving the wrappers of the document with r, and
+// replacing it with the merged InLine ads, as returned by
+// resolve.Resolved.Merged. It fails if no InLine ad could be reached because
+// of failures.
+func Resolve(r *resolve.Resolver) Step {
+	return Step{Name: "resolve", Stage: func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error) {
+		res, err := r.Resolve(ctx, doc)
+		if err != nil {
+			return nil, err
+		}
+		merged, err := res.Merged()
+		if err != nil {
+			return nil, err
+		}
+		merged.Errors = doc.Errors
+		return merged, nil
+	}}
+}
+
+// Validate returns a step failing if the document doesn't pass
+// vast.VAST.Validate.
+func Validate() Step {
+	return Step{Name: "validate", Stage: func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error) {
+		if err := doc.Validate(); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}}
+}
+
+// Filter returns a step keeping the ads for which keep returns true. The
+// other ones are recorded as rejected in the vast.DecisionTrace of the
+// context of the run.
+func Filter(keep func(ad *vast.Ad) bool) Step {
+	return Step{Name: "filter", Stage: func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error) {
+		trace := vast.DecisionTraceFrom(ctx)
+		ads := doc.Ads[:0]
+		for i := range doc.Ads {
+			if keep(&doc.Ads[i]) {
+				ads = append(ads, doc.Ads[i])
+			} else {
+				trace.Reject("filter", doc.Ads[i].ID, "filtered out")
+			}
+		}
+		doc.Ads = ads
+		return doc, nil
+	}}
+}
+
+// FilterSSAI returns a step removing the ads server-side ad insertion can't
+// stitch, as done by vast.FilterForSSAI. The removed ads are recorded in the
+// vast.DecisionTrace of the context of the run, and passed to rejected, if
+// set.
+func FilterSSAI(rejected func(ctx context.Context, ads []vast.RejectedAd)) Step {
+	return Step{Name: "filter-ssai", Stage: func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error) {
+		ads := vast.FilterForSSAI(doc)
+		trace := vast.DecisionTraceFrom(ctx)
+		for _, ad := range ads {
+			trace.Reject("filter-ssai", ad.Ad.ID, ad.Reason.String())
+		}
+		if len(ads) > 0 && rejected != nil {
+			rejected(ctx, ads)
+		}
+		return doc, nil
+	}}
+}
+
+// Trackers are the tracking URIs added to every ad by InjectTrackers.
+type Trackers struct {
+	Impressions    []string
+	Events         map[vast.EventType][]string
+	ClickTrackings []string
+}
+
+// InjectTrackers returns a step adding the trackers to every ad, with
+// vast.Ad.AddImpression, AddTracking and AddClickTracking, and recording them
+// in the vast.DecisionTrace of the context of the run.
+func InjectTrackers(t Trackers) Step {
+	return Step{Name: "inject-trackers", Stage: func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error) {
+		trace := vast.DecisionTraceFrom(ctx)
+		for i := range doc.Ads {
+			ad := &doc.Ads[i]
+			for _, u := range t.Impressions {
+				ad.AddImpression(u)
+				trace.RecordTracker("inject-trackers", ad.ID, "impression", u)
+			}
+			for event, urls := range t.Events {
+				for _, u := range urls {
+					ad.AddTracking(event, u)
+					trace.RecordTracker("inject-trackers", ad.ID, string(event), u)
+				}
+			}
+			for _, u := range t.ClickTrackings {
+				ad.AddClickTracking(u)
+				trace.RecordTracker("inject-trackers", ad.ID, "click", u)
+			}
+		}
+		return doc, nil
+	}}
+}
+
+// Macros returns a step expanding the macros of the URIs of the document
+// known server-side, that is the ones c sets to a value other than
+// macro.Unknown. The other macros, such as [ERRORCODE] or [ADPLAYHEAD], are
+// left for the player to expand.
+func Macros(c *macro.Context) Step {
+	return Step{Name: "macros", Stage: func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error) {
+		values := c.Values()
+		for name, v := range values {
+			if v == macro.Unknown {
+				delete(values, name)
+			}
+		}
+		vast.RewriteURLs(doc, func(f vast.URLField, u string) string {
+			return macro.Expand(ctx, u, values)
+		})
+		return doc, nil
+	}}
+}
+
+// Dedupe returns a step removing the ads sharing a creative with a previous
+// one, as done by vast.DedupeByCreative. The removed ads are recorded in the
+// vast.DecisionTrace of the context of the run.
+func Dedupe() Step {
+	return Step{Name: "dedupe", Stage: func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error) {
+		ads := make([]*vast.Ad, len(doc.Ads))
+		for i := range doc.Ads {
+			ads[i] = &doc.Ads[i]
+		}
+		kept, dropped := vast.DedupeByCreative(ads)
+		trace := vast.DecisionTraceFrom(ctx)
+		for _, ad := range dropped {
+			trace.Reject("dedupe", ad.ID, "duplicate creative")
+		}
+		out := make([]vast.Ad, len(kept))
+		for i, ad := range kept {
+			out[i] = *ad
+		}
+		doc.Ads = out
+		return doc, nil
+	}}
+}
+
+// FrequencyCap returns a step removing the ads of the creatives the user,
+// as returned by user for the context of the run, has been exposed to too
+// often. The removed ads are passed to capped, if set.
+func FrequencyCap(c *freqcap.Capper, user func(ctx context.Context) string, capped func(ctx context.Context, ads []vast.Ad)) Step {
+	return Step{Name: "frequency-cap", Stage: func(ctx context.Context, doc *vast.VAST) (*vast.VAST, error) {
+		ads, err := c.Filter(ctx, user(ctx), doc)
+		if err != nil {
+			return nil, err
+		}
+		if len(ads) > 0 && capped != nil {
+			capped(ctx, ads)
+		}
+		return doc, nil
+	}}
+}
@@ -0,0 +1,81 @@
+package vast
+
+import (
+	"sort"
+	"time"
+)
+
+// IsPodAd returns true if the ad belongs to the ad pod of its document, i.e.
+// has a sequence.
+func (a *Ad) IsPodAd() bool {
+	return a.Sequence > 0
+}
+
+// SplitPod returns the ads of the pod of the document, sorted by sequence,
+// and its stand-alone ads, in document order. The returned ads point into
+// v.Ads.
+func (v *VAST) SplitPod() (pod, standalone []*Ad) {
+	for i := range v.Ads {
+		if v.Ads[i].IsPodAd() {
+			pod = append(pod, &v.Ads[i])
+		} else {
+			standalone = append(standalone, &v.Ads[i])
+		}
+	}
+	SortBySequence(pod)
+	return pod, standalone
+}
+
+// SortBySequence sorts ads by sequence, keeping the order of the ads having
+// the same sequence. Stand-alone ads come last.
+func SortBySequence(ads []*Ad) {
+	sort.SliceStable(ads, func(i, j int) bool {
+		si, sj := ads[i].Sequence, ads[j].Sequence
+		if si <= 0 || sj <= 0 {
+			return si > 0 && sj <= 0
+		}
+		return si < sj
+	})
+}
+
+// Duration returns the duration of the first linear creative of an InLine
+// ad, and false if the ad has none.
+func (a *Ad) Duration() (time.Duration, bool) {
+	if a.InLine == nil {
+		return 0, false
+	}
+	for _, c := range a.InLine.Creatives {
+		if c.Linear != nil {
+			return time.Duration(c.Linear.Duration), true
+		}
+	}
+	return 0, false
+}
+
+// PodDuration returns the sum of the durations of ads. Ads without linear
+// creative, such as wrappers, count for 0.
+func PodDuration(ads []*Ad) time.Duration {
+	var total time.Duration
+	for _, a := range ads {
+		d, _ := a.Duration()
+		total += d
+	}
+	return total
+}
+
+// FitPod selects the ads fitting in an ad break of the given duration, in
+// order: every ad is kept if it fits in what remains of the budget, and
+// dropped otherwise. Ads whose duration is unknown, such as wrappers, are
+// dropped as they can't be proven to fit.
+func FitPod(ads []*Ad, budget time.Duration) (kept, dropped []*Ad) {
+	for _, a := range ads {
+		d, ok := a.Duration()
+		if ok && d <= budget {
+			kept = append(kept, a)
+			budget -= d
+		} else {
+			dropped = append(dropped, a)
+		}
+	}
+	return kept, dropped
+}
@@ -0,0 +1,64 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func podAd(id string, sequence int, seconds int) Ad {
+	ad := Ad{ID: id, Sequence: sequence}
+	if seconds < 0 {
+		ad.Wrapper = &Wrapper{}
+		return ad
+	}
+	ad.InLine = &InLine{Creatives: []Creative{{Linear: &Linear{Duration: Duration(time.Duration(seconds) * time.Second)}}}}
+	return ad
+}
+
+func adIDs(ads []*Ad) []string {
+	var ids []string
+	for _, a := range ads {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func TestSplitPod(t *testing.T) {
+	v := VAST{Ads: []Ad{podAd("c", 3, 30), podAd("s1", 0, 15), podAd("a", 1, 15), podAd("b", 2, 20), podAd("s2", 0, 10)}}
+	pod, standalone := v.SplitPod()
+	assert.Equal(t, []string{"a", "b", "c"}, adIDs(pod))
+	assert.Equal(t, []string{"s1", "s2"}, adIDs(standalone))
+	assert.True(t, pod[0] == &v.Ads[2])
+	assert.Equal(t, 65*time.Second, PodDuration(pod))
+
+	all := []*Ad{&v.Ads[0], &v.Ads[1], &v.Ads[2], &v.Ads[3], &v.Ads[4]}
+	SortBySequence(all)
+	assert.Equal(t, []string{"a", "b", "c", "s1", "s2"}, adIDs(all))
+}
+
+func TestAdDuration(t *testing.T) {
+	ad := podAd("a", 1, 15)
+	d, ok := ad.Duration()
+	assert.True(t, ok)
+	assert.Equal(t, 15*time.Second, d)
+
+	wrapper := podAd("w", 1, -1)
+	_, ok = wrapper.Duration()
+	assert.False(t, ok)
+	_, ok = (&Ad{InLine: &InLine{Creatives: []Creative{{NonLinearAds: &NonLinearAds{}}}}}).Duration()
+	assert.False(t, ok)
+}
+
+func TestFitPod(t *testing.T) {
+	v := VAST{Ads: []Ad{podAd("a", 1, 15), podAd("b", 2, 30), podAd("w", 3, -1), podAd("c", 4, 10), podAd("d", 5, 5)}}
+	pod, _ := v.SplitPod()
+	kept, dropped := FitPod(pod, 30*time.Second)
+	assert.Equal(t, []string{"a", "c", "d"}, adIDs(kept))
+	assert.Equal(t, []string{"b", "w"}, adIDs(dropped))
+
+	kept, dropped = FitPod(pod, 0)
+	assert.Empty(t, kept)
+	assert.Len(t, dropped, 5)
+}
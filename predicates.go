@@ -0,0 +1,74 @@
+package vast
+
+// The predicates below inspect the creatives of InLine ads. Wrapper ads
+// don't carry creatives of their own, only trackers, so the predicates are
+// false for them: use them on the InLine ad, or on the ad returned by
+// Flatten.
+
+// HasLinear returns true if the ad has a linear creative.
+func (a *Ad) HasLinear() bool {
+	return a.anyCreative(func(c *Creative) bool { return c.Linear != nil })
+}
+
+// HasNonLinear returns true if the ad has a non-linear creative with at
+// least one NonLinear element.
+func (a *Ad) HasNonLinear() bool {
+	return a.anyCreative(func(c *Creative) bool {
+		return c.NonLinearAds != nil && len(c.NonLinearAds.NonLinears) > 0
+	})
+}
+
+// HasCompanions returns true if the ad has at least one companion.
+func (a *Ad) HasCompanions() bool {
+	return a.anyCreative(func(c *Creative) bool {
+		return c.CompanionAds != nil && len(c.CompanionAds.Companions) > 0
+	})
+}
+
+// IsAudioOnly returns true if every media file of the ad is an audio file
+// and the ad has no non-linear creative, regardless of its adType. Unlike
+// IsAudio, it doesn't trust the adType attribute.
+func (a *Ad) IsAudioOnly() bool {
+	if a.HasNonLinear() {
+		return false
+	}
+	found := false
+	audio := !a.anyCreative(func(c *Creative) bool {
+		if c.Linear == nil {
+			return false
+		}
+		for i := range c.Linear.MediaFiles {
+			if !c.Linear.MediaFiles[i].IsAudio() {
+				return true
+			}
+			found = true
+		}
+		return false
+	})
+	return audio && found
+}
+
+// RequiresVPAID returns true if any creative of the ad requires VPAID, as
+// reported by Creative.RequiresVPAID.
+func (a *Ad) RequiresVPAID() bool {
+	return a.anyCreative((*Creative).RequiresVPAID)
+}
+
+// HasSIMID returns true if any creative of the ad declares the SIMID API
+// framework, as reported by Creative.RequiresSIMID.
+func (a *Ad) HasSIMID() bool {
+	return a.anyCreative((*Creative).RequiresSIMID)
+}
+
+// anyCreative returns true if f is true for any creative of an InLine ad.
+func (a *Ad) anyCreative(f func(*Creative) bool) bool {
+	if a.InLine == nil {
+		return false
+	}
+	for i := range a.InLine.Creatives {
+		if f(&a.InLine.Creatives[i]) {
+			return true
+		}
+	}
+	return false
+}
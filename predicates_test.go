@@ -0,0 +1,41 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdPredicates(t *testing.T) {
+	video := Ad{InLine: &InLine{Creatives: []Creative{
+		{Linear: &Linear{MediaFiles: []MediaFile{{Type: MIMEVideoMP4}, {Type: MIMEJavaScript, APIFramework: "VPAID"}}}},
+		{CompanionAds: &CompanionAds{Companions: []Companion{{}}}},
+	}}}
+	assert.True(t, video.HasLinear())
+	assert.True(t, video.HasCompanions())
+	assert.False(t, video.HasNonLinear())
+	assert.False(t, video.IsAudioOnly())
+	assert.True(t, video.RequiresVPAID())
+	assert.False(t, video.HasSIMID())
+
+	overlay := Ad{InLine: &InLine{Creatives: []Creative{
+		{NonLinearAds: &NonLinearAds{NonLinears: []NonLinear{{APIFramework: "SIMID"}}}},
+		{CompanionAds: &CompanionAds{}},
+	}}}
+	assert.False(t, overlay.HasLinear())
+	assert.False(t, overlay.HasCompanions())
+	assert.True(t, overlay.HasNonLinear())
+	assert.True(t, overlay.HasSIMID())
+	assert.False(t, overlay.RequiresVPAID())
+
+	audio := Ad{AdType: AdTypeVideo, InLine: &InLine{Creatives: []Creative{
+		{Linear: &Linear{MediaFiles: []MediaFile{{Type: MIMEAudioMPEG}, {Type: MIMEAudioAAC}}}},
+	}}}
+	assert.True(t, audio.IsAudioOnly())
+	assert.False(t, audio.IsAudio())
+	assert.False(t, (&Ad{InLine: &InLine{Creatives: []Creative{{Linear: &Linear{}}}}}).IsAudioOnly())
+
+	wrapper := Ad{Wrapper: &Wrapper{Creatives: []CreativeWrapper{{Linear: &LinearWrapper{}}}}}
+	assert.False(t, wrapper.HasLinear())
+	assert.False(t, wrapper.IsAudioOnly())
+}
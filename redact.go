@@ -0,0 +1,102 @@
+package vast
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// PIIParams are query parameters commonly carrying personal data: device
+// advertising ids, user ids, IP addresses and locations.
+var PIIParams = []string{
+	"ifa", "idfa", "aaid", "gaid", "adid", "device_id", "deviceid",
+	"uid", "user_id", "userid", "uuid", "ip", "ipaddress",
+	"lat", "lon", "long", "latlong", "geo",
+}
+
+// RedactPolicy configures how Redact scrubs the URLs of a document. Query
+// parameter names are matched case-insensitively.
+type RedactPolicy struct {
+	// Query parameters removed from the URLs. If both Strip and Hash are
+	// empty, the parameters of PIIParams are removed.
+	Strip []string
+	// Query parameters whose value is replaced by a hash, so that requests of
+	// the same user can still be correlated
+	Hash []string
+	// Salt prepended to the values before hashing them, to prevent
+	// recovering them with a dictionary
+	Salt string
+}
+
+// Redact returns a copy of doc whose URLs are scrubbed according to policy,
+// safe to log. Values which are unexpanded macros, such as "[IFA]", are kept
+// as they carry no personal data.
+func Redact(doc *VAST, policy RedactPolicy) *VAST {
+	if doc == nil {
+		return nil
+	}
+	redacted := doc.Clone()
+	Walk(redacted, Visitor{URI: func(_ string, uri *string) error {
+		*uri = policy.RedactURL(*uri)
+		return nil
+	}})
+	return redacted
+}
+
+// RedactURL returns u scrubbed according to the policy. The order of the
+// remaining query parameters is preserved.
+func (p RedactPolicy) RedactURL(u string) string {
+	strip, hash := p.Strip, p.Hash
+	if len(strip) == 0 && len(hash) == 0 {
+		strip = PIIParams
+	}
+	head, fragment := u, ""
+	if i := strings.IndexByte(head, '#'); i >= 0 {
+		head, fragment = head[:i], head[i:]
+	}
+	i := strings.IndexByte(head, '?')
+	if i < 0 {
+		return u
+	}
+	base, query := head[:i], head[i+1:]
+	var params []string
+	for _, param := range strings.Split(query, "&") {
+		name, value := param, ""
+		if j := strings.IndexByte(param, '='); j >= 0 {
+			name, value = param[:j], param[j+1:]
+		}
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		switch {
+		case matchParam(strip, name):
+			continue
+		case matchParam(hash, name) && value != "" && !isMacro(value):
+			sum := sha256.Sum256([]byte(p.Salt + value))
+			param = param[:len(param)-len(value)] + hex.EncodeToString(sum[:8])
+		}
+		params = append(params, param)
+	}
+	if len(params) == 0 {
+		return base + fragment
+	}
+	return base + "?" + strings.Join(params, "&") + fragment
+}
+
+func matchParam(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMacro reports whether the query parameter value is a single unexpanded
+// macro, bracketed or URL encoded.
+func isMacro(value string) bool {
+	upper := strings.ToUpper(value)
+	return (strings.HasPrefix(upper, "[") && strings.HasSuffix(upper, "]")) ||
+		(strings.HasPrefix(upper, "%5B") && strings.HasSuffix(upper, "%5D"))
+}
@@ -0,0 +1,34 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactURL(t *testing.T) {
+	var p RedactPolicy
+	assert.Equal(t, "http://t/imp?a=1&c=3#frag", p.RedactURL("http://t/imp?a=1&IFA=abc&c=3&lat=1.5#frag"))
+	assert.Equal(t, "http://t/imp", p.RedactURL("http://t/imp?ifa=abc&uid="))
+	assert.Equal(t, "http://t/imp", p.RedactURL("http://t/imp"))
+
+	p = RedactPolicy{Strip: []string{"ip"}, Hash: []string{"user_id"}, Salt: "s"}
+	got := p.RedactURL("http://t/imp?user_id=42&ip=1.2.3.4&ifa=abc&u%73er_id=42")
+	assert.Equal(t, "http://t/imp?user_id=e903fcd0a7b9e8f1&ifa=abc&u%73er_id=e903fcd0a7b9e8f1", got)
+	assert.NotEqual(t, got, RedactPolicy{Hash: []string{"user_id"}}.RedactURL("http://t/imp?user_id=42"))
+	assert.Equal(t, "http://t/imp?user_id=[USERID]&x=%5BIFA%5D", RedactPolicy{Hash: []string{"user_id", "x"}}.RedactURL("http://t/imp?user_id=[USERID]&x=%5BIFA%5D"))
+}
+
+func TestRedact(t *testing.T) {
+	v := &VAST{Ads: []Ad{{InLine: &InLine{
+		Impressions: []Impression{{URI: "http://t/imp?ifa=abc&id=1"}},
+		Creatives: []Creative{{Linear: &Linear{
+			TrackingEvents: []Tracking{{Event: "start", URI: "http://t/start?lat=1&lon=2"}},
+		}}},
+	}}}}
+	redacted := Redact(v, RedactPolicy{})
+	assert.Equal(t, URI("http://t/imp?id=1"), redacted.Ads[0].InLine.Impressions[0].URI)
+	assert.Equal(t, URI("http://t/start"), redacted.Ads[0].InLine.Creatives[0].Linear.TrackingEvents[0].URI)
+	assert.Equal(t, URI("http://t/imp?ifa=abc&id=1"), v.Ads[0].InLine.Impressions[0].URI)
+	assert.Nil(t, Redact(nil, RedactPolicy{}))
+}
@@ -0,0 +1,139 @@
+package resolve
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// Cache stores the raw documents fetched by a Resolver. Implementations must
+// be safe for concurrent use.
+type Cache interface {
+	// Get returns the document stored for key, if it hasn't expired.
+	Get(key string) ([]byte, bool)
+	// Set stores the document for key for the given duration.
+	Set(key string, body []byte, ttl time.Duration)
+}
+
+// store caches the document fetched for hop, if it is cacheable.
+func (r *Resolver) store(hop *Hop, body []byte, doc *vast.VAST) {
+	ttl, ok := cacheTTL(hop.header, doc, time.Now())
+	if !ok {
+		ttl = r.CacheTTL
+	}
+	if ttl > 0 {
+		r.Cache.Set(hop.key, body, ttl)
+	}
+}
+
+// cacheTTL returns the time to live of a document received at now, given
+// the HTTP cache headers of the response and the Expires elements of its
+// InLine ads, the shortest one winning. It returns false if neither provide
+// the information.
+func cacheTTL(h http.Header, doc *vast.VAST, now time.Time) (time.Duration, bool) {
+	ttl, ok := httpTTL(h, now)
+	for _, ad := range doc.Ads {
+		if ad.InLine == nil || ad.InLine.Expires == nil {
+			continue
+		}
+		if d := ad.InLine.Expires.Duration(); !ok || d < ttl {
+			ttl, ok = d, true
+		}
+	}
+	return ttl, ok
+}
+
+// httpTTL returns the time to live of a response given its Cache-Control or
+// Expires headers.
+func httpTTL(h http.Header, now time.Time) (time.Duration, bool) {
+	if cc := h.Get("Cache-Control"); cc != "" {
+		maxAge, sMaxAge := -1, -1
+		for _, directive := range strings.Split(cc, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+			switch {
+			case directive == "no-store" || directive == "no-cache":
+				return 0, true
+			case strings.HasPrefix(directive, "s-maxage="):
+				sMaxAge, _ = strconv.Atoi(directive[len("s-maxage="):])
+			case strings.HasPrefix(directive, "max-age="):
+				maxAge, _ = strconv.Atoi(directive[len("max-age="):])
+			}
+		}
+		if sMaxAge >= 0 {
+			return time.Duration(sMaxAge) * time.Second, true
+		}
+		if maxAge >= 0 {
+			return time.Duration(maxAge) * time.Second, true
+		}
+	}
+	if exp := h.Get("Expires"); exp != "" {
+		t, err := http.ParseTime(exp)
+		if err != nil || !t.After(now) {
+			return 0, true
+		}
+		return t.Sub(now), true
+	}
+	return 0, false
+}
+
+// MemoryCache is an in-memory Cache. Expired entries are dropped when
+// accessed or when the cache is purged.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]memoryEntry{}}
+}
+
+// Get implements the Cache interface.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.body, true
+}
+
+// Set implements the Cache interface.
+func (c *MemoryCache) Set(key string, body []byte, ttl time.Duration) {
+	c.mu.Lock()
+	c.entries[key] = memoryEntry{body: body, expires: time.Now().Add(ttl)}
+	c.mu.Unlock()
+}
+
+// Purge drops the expired entries.
+func (c *MemoryCache) Purge() {
+	now := time.Now()
+	c.mu.Lock()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.mu.Unlock()
+}
+
+// Len returns the number of entries, expired ones included.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
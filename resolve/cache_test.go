@@ -0,0 +1,154 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/macro"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPTTL(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		header http.Header
+		ttl    time.Duration
+		ok     bool
+	}{
+		{http.Header{}, 0, false},
+		{http.Header{"Cache-Control": {"public, max-age=60"}}, time.Minute, true},
+		{http.Header{"Cache-Control": {"max-age=60, s-maxage=30"}}, 30 * time.Second, true},
+		{http.Header{"Cache-Control": {"no-store"}}, 0, true},
+		{http.Header{"Cache-Control": {"max-age=60, No-Cache"}}, 0, true},
+		{http.Header{"Expires": {"Wed, 01 Jan 2020 00:02:00 GMT"}}, 2 * time.Minute, true},
+		{http.Header{"Expires": {"0"}}, 0, true},
+		{http.Header{"Cache-Control": {"max-age=10"}, "Expires": {"Wed, 01 Jan 2020 00:02:00 GMT"}}, 10 * time.Second, true},
+	} {
+		ttl, ok := httpTTL(tc.header, now)
+		assert.Equal(t, tc.ttl, ttl, "%v", tc.header)
+		assert.Equal(t, tc.ok, ok, "%v", tc.header)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	doc := &vast.VAST{Ads: []vast.Ad{
+		{InLine: &vast.InLine{Expires: vast.NewExpires(time.Hour)}},
+		{InLine: &vast.InLine{Expires: vast.NewExpires(10 * time.Minute)}},
+		{InLine: &vast.InLine{}},
+	}}
+	ttl, ok := cacheTTL(http.Header{}, doc, time.Now())
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Minute, ttl)
+
+	ttl, _ = cacheTTL(http.Header{"Cache-Control": {"max-age=60"}}, doc, time.Now())
+	assert.Equal(t, time.Minute, ttl)
+	ttl, _ = cacheTTL(http.Header{"Cache-Control": {"max-age=3600"}}, doc, time.Now())
+	assert.Equal(t, 10*time.Minute, ttl)
+
+	_, ok = cacheTTL(http.Header{}, &vast.VAST{Ads: []vast.Ad{{Wrapper: &vast.Wrapper{}}}}, time.Now())
+	assert.False(t, ok)
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache()
+	c.Set("a", []byte("A"), time.Hour)
+	c.Set("b", []byte("B"), -time.Second)
+	b, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "A", string(b))
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("c")
+	assert.False(t, ok)
+
+	c.Set("b", []byte("B"), -time.Second)
+	assert.Equal(t, 2, c.Len())
+	c.Purge()
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestResolveCache(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		switch r.URL.Path {
+		case "/inline":
+			fmt.Fprint(w, strings.Replace(inlineDoc, "</AdTitle>", "</AdTitle><Expires>60</Expires>", 1))
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+			fmt.Fprint(w, inlineDoc)
+		default:
+			fmt.Fprint(w, inlineDoc)
+		}
+	}))
+	defer srv.Close()
+
+	cache := NewMemoryCache()
+	r := &Resolver{Cache: cache}
+	for i := 0; i < 2; i++ {
+		res, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+		if assert.NoError(t, err) {
+			assert.Equal(t, i == 1, res.Ads[0].Hops[0].Cached)
+		}
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// neither headers nor Expires: cached according to CacheTTL only
+	for _, path := range []string{"/plain", "/plain", "/nostore", "/nostore"} {
+		_, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+path)))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+
+	r.CacheTTL = time.Minute
+	for _, path := range []string{"/plain", "/plain", "/nostore", "/nostore"} {
+		_, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+path)))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(8), atomic.LoadInt32(&calls))
+}
+
+func TestResolveCacheMacros(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+		fmt.Fprint(w, strings.Replace(inlineDoc, "</AdTitle>", "</AdTitle><Expires>60</Expires>", 1))
+	}))
+	defer srv.Close()
+
+	// the tag is fetched expanded, and cached regardless of its
+	// cache-busting macros
+	mc := &macro.Context{DeviceIP: "192.0.2.1"}
+	r := &Resolver{Cache: NewMemoryCache(), Macros: mc}
+	tag := srv.URL + "/inline?cb=[CACHEBUSTING]&ts=[TIMESTAMP]&ip=[DEVICEIP]"
+	for i := 0; i < 2; i++ {
+		res, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", tag)))
+		if assert.NoError(t, err) {
+			assert.Equal(t, i == 1, res.Ads[0].Hops[0].Cached)
+			assert.NotContains(t, res.Ads[0].Hops[0].URL, "[")
+		}
+	}
+	if assert.Len(t, queries, 1) {
+		assert.NotContains(t, queries[0], "[")
+		assert.Contains(t, queries[0], "ip=192.0.2.1")
+	}
+
+	// per request values are part of the key
+	mc.DeviceIP = "192.0.2.2"
+	_, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", tag)))
+	assert.NoError(t, err)
+	if assert.Len(t, queries, 2) {
+		assert.Contains(t, queries[1], "ip=192.0.2.2")
+	}
+}
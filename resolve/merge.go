@@ -0,0 +1,40 @@
+package resolve
+
+import "github.com/haxqer/vast"
+
+// Flatten returns the InLine ad with the trackers of its wrapper chain merged
+// in: impressions, error URIs, tracking events, click trackings, viewable
+// impressions, icons and verifications. See vast.Flatten for the rules.
+func (ra *ResolvedAd) Flatten() (*vast.Ad, error) {
+	return vast.Flatten(ra.Wrappers, ra.InLine)
+}
+
+// Merged returns a new document made of the flattened InLine ads, ready to be
+// played without any further fetch. Its version is the highest one of the
+// documents involved, so that no element is invalid in the result.
+func (r *Resolved) Merged() (*vast.VAST, error) {
+	var version vast.SpecVersion
+	raise := func(v *vast.VAST) {
+		if v == nil {
+			return
+		}
+		if sv := v.EffectiveVersion(); sv != "" && (version == "" || !version.AtLeast(sv)) {
+			version = sv
+		}
+	}
+	raise(r.Document)
+	merged := &vast.VAST{}
+	for i := range r.Ads {
+		ra := &r.Ads[i]
+		for _, h := range ra.Hops {
+			raise(h.Document)
+		}
+		ad, err := ra.Flatten()
+		if err != nil {
+			return nil, err
+		}
+		merged.Ads = append(merged.Ads, *ad)
+	}
+	merged.Version = string(version)
+	return merged, nil
+}
@@ -0,0 +1,46 @@
+package resolve
+
+import (
+	"context"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// Metrics receives the measurements of a Resolver, to monitor the health of
+// wrapper chains. Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveHop is called after every document fetch.
+	ObserveHop(hop HopTrace)
+	// ObserveCache is called after every cache lookup.
+	ObserveCache(hit bool)
+	// ObserveError is called for every chain which couldn't be resolved.
+	ObserveError(code vast.ErrorCode)
+	// ObserveResolution is called at the end of every resolution with the
+	// number of documents fetched and the total latency.
+	ObserveResolution(hops int, latency time.Duration)
+}
+
+// Tracer creates spans around resolutions and document fetches. It is meant
+// to be backed by a tracing library such as OpenTelemetry.
+// Implementations must be safe for concurrent use.
+type Tracer interface {
+	// Start starts a span named name, child of the span carried by ctx if
+	// any, and returns a context carrying it along with a function ending it
+	// with the error of the operation, if any.
+	Start(ctx context.Context, name string, attrs map[string]string) (context.Context, func(err error))
+}
+
+// Span names used with the Tracer
+const (
+	SpanResolve = "vast.resolve"
+	SpanHop     = "vast.resolve.hop"
+)
+
+// startSpan starts a span with the tracer of the resolver, if any.
+func (r *Resolver) startSpan(ctx context.Context, name string, attrs map[string]string) (context.Context, func(error)) {
+	if r.Tracer == nil {
+		return ctx, func(error) {}
+	}
+	return r.Tracer.Start(ctx, name, attrs)
+}
@@ -0,0 +1,95 @@
+package resolve
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	mu          sync.Mutex
+	hops        []string
+	cache       []bool
+	codes       []vast.ErrorCode
+	resolutions []int
+	spans       []string
+}
+
+func (r *recorder) ObserveHop(hop HopTrace) {
+	r.mu.Lock()
+	r.hops = append(r.hops, hop.URL)
+	r.mu.Unlock()
+}
+
+func (r *recorder) ObserveCache(hit bool) {
+	r.mu.Lock()
+	r.cache = append(r.cache, hit)
+	r.mu.Unlock()
+}
+
+func (r *recorder) ObserveError(code vast.ErrorCode) {
+	r.mu.Lock()
+	r.codes = append(r.codes, code)
+	r.mu.Unlock()
+}
+
+func (r *recorder) ObserveResolution(hops int, latency time.Duration) {
+	r.mu.Lock()
+	r.resolutions = append(r.resolutions, hops)
+	r.mu.Unlock()
+}
+
+type spanKey struct{}
+
+func (r *recorder) Start(ctx context.Context, name string, attrs map[string]string) (context.Context, func(error)) {
+	parent, _ := ctx.Value(spanKey{}).(string)
+	span := name
+	if url, ok := attrs["url"]; ok {
+		span += " " + url + " depth=" + attrs["depth"]
+	}
+	return context.WithValue(ctx, spanKey{}, span), func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		s := span + " parent=" + parent
+		if err != nil {
+			s += " failed"
+		}
+		r.spans = append(r.spans, s)
+	}
+}
+
+func TestResolveInstrumentation(t *testing.T) {
+	srv := newServer(map[string]string{
+		"/w2":     wrapperDoc("w2", "{{server}}/inline"),
+		"/inline": inlineDoc,
+		"/empty":  `<VAST version="3.0"></VAST>`,
+	})
+	defer srv.Close()
+
+	rec := &recorder{}
+	r := &Resolver{Metrics: rec, Tracer: rec, Cache: NewMemoryCache(), CacheTTL: time.Minute}
+	doc := `<VAST version="3.0">` +
+		`<Ad id="a"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>` + srv.URL + `/w2</VASTAdTagURI></Wrapper></Ad>` +
+		`<Ad id="b"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>` + srv.URL + `/empty</VASTAdTagURI></Wrapper></Ad>` +
+		`<Ad id="c"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>` + srv.URL + `/w2</VASTAdTagURI></Wrapper></Ad>` +
+		`</VAST>`
+	_, err := r.Resolve(context.Background(), parse(t, doc))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{srv.URL + "/w2", srv.URL + "/inline", srv.URL + "/empty", srv.URL + "/w2", srv.URL + "/inline"}, rec.hops)
+	assert.Equal(t, []bool{false, false, false, true, true}, rec.cache)
+	assert.Equal(t, []vast.ErrorCode{vast.ErrorWrapperNoAd}, rec.codes)
+	assert.Equal(t, []int{5}, rec.resolutions)
+	assert.Equal(t, []string{
+		SpanHop + " " + srv.URL + "/w2 depth=0 parent=" + SpanResolve,
+		SpanHop + " " + srv.URL + "/inline depth=1 parent=" + SpanResolve,
+		SpanHop + " " + srv.URL + "/empty depth=0 parent=" + SpanResolve + " failed",
+		SpanHop + " " + srv.URL + "/w2 depth=0 parent=" + SpanResolve,
+		SpanHop + " " + srv.URL + "/inline depth=1 parent=" + SpanResolve,
+		SpanResolve + " parent=",
+	}, rec.spans)
+}
@@ -0,0 +1,86 @@
+package resolve
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/macro"
+)
+
+// errorPixelTimeout bounds the error pixel requests when HopTimeout is not
+// set.
+const errorPixelTimeout = 5 * time.Second
+
+// ErrorPixel is an error tracking URI fired by the resolver.
+type ErrorPixel struct {
+	// The URI fired, with the [ERRORCODE] macro replaced
+	URL string
+	// The error code reported
+	Code vast.ErrorCode
+	// The status code of the response, 0 if the request failed
+	StatusCode int
+	// The error of the request, if any
+	Err error
+}
+
+// fireErrors fires the error tracking URIs of the wrappers of the failed
+// chains, and returns the pixels fired. Every URI is fired once, and the
+// macros expanded are recorded to the decision trace of ctx.
+func (r *Resolver) fireErrors(ctx context.Context, errs []*Error) []ErrorPixel {
+	var pixels []ErrorPixel
+	seen := map[string]bool{}
+	for _, e := range errs {
+		for _, w := range e.Wrappers {
+			for _, u := range macro.ErrorURLs(ctx, w.ErrorURIs(), e.Code, r.Macros) {
+				if !seen[u] {
+					seen[u] = true
+					pixels = append(pixels, ErrorPixel{URL: u, Code: e.Code})
+				}
+			}
+		}
+	}
+	var wg sync.WaitGroup
+	for i := range pixels {
+		wg.Add(1)
+		go func(p *ErrorPixel) {
+			defer wg.Done()
+			r.firePixel(p)
+		}(&pixels[i])
+	}
+	wg.Wait()
+	if r.OnErrorPixel != nil {
+		for _, p := range pixels {
+			r.OnErrorPixel(p)
+		}
+	}
+	return pixels
+}
+
+// firePixel requests p.URL, independently of the resolution context which
+// may be done already.
+func (r *Resolver) firePixel(p *ErrorPixel) {
+	timeout := r.HopTimeout
+	if timeout <= 0 {
+		timeout = errorPixelTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, p.URL, nil)
+	if err == nil && r.restricted() {
+		err = r.checkURL(ctx, req.URL)
+	}
+	if err != nil {
+		p.Err = err
+		return
+	}
+	resp, err := r.client().Do(req.WithContext(ctx))
+	if err != nil {
+		p.Err = err
+		return
+	}
+	resp.Body.Close()
+	p.StatusCode = resp.StatusCode
+}
@@ -0,0 +1,59 @@
+package resolve
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/macro"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveFireErrors(t *testing.T) {
+	var mu sync.Mutex
+	var fired []string
+	pixels := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fired = append(fired, r.URL.RequestURI())
+		mu.Unlock()
+	}))
+	defer pixels.Close()
+
+	srv := newServer(map[string]string{
+		"/w2": `<VAST version="3.0"><Ad id="w2"><Wrapper><AdSystem>SSP</AdSystem>` +
+			`<Error><![CDATA[` + pixels.URL + `/w2?code=[ERRORCODE]]]></Error>` +
+			`<Error><![CDATA[` + pixels.URL + `/w2-encoded?code=%5BERRORCODE%5D]]></Error>` +
+			`<VASTAdTagURI><![CDATA[{{server}}/empty]]></VASTAdTagURI></Wrapper></Ad></VAST>`,
+		"/empty": `<VAST version="3.0"></VAST>`,
+	})
+	defer srv.Close()
+
+	root := `<VAST version="3.0"><Ad id="w1"><Wrapper><AdSystem>SSP</AdSystem>` +
+		`<Error><![CDATA[` + pixels.URL + `/w1?code=[ERRORCODE]&cb=[CACHEBUSTING]]]></Error>` +
+		`<VASTAdTagURI><![CDATA[` + srv.URL + `/w2]]></VASTAdTagURI></Wrapper></Ad></VAST>`
+
+	var observed []ErrorPixel
+	r := &Resolver{FireErrors: true, Macros: &macro.Context{CacheBusting: "42"}, OnErrorPixel: func(p ErrorPixel) { observed = append(observed, p) }}
+	res, err := r.Resolve(context.Background(), parse(t, root))
+	assert.Error(t, err)
+
+	sort.Strings(fired)
+	assert.Equal(t, []string{"/w1?code=303&cb=42", "/w2-encoded?code=303", "/w2?code=303"}, fired)
+	if assert.Len(t, observed, 3) {
+		assert.Equal(t, res.ErrorPixels, observed)
+		assert.Equal(t, pixels.URL+"/w1?code=303&cb=42", observed[0].URL)
+		assert.Equal(t, vast.ErrorWrapperNoAd, observed[0].Code)
+		assert.Equal(t, http.StatusOK, observed[0].StatusCode)
+		assert.NoError(t, observed[0].Err)
+	}
+
+	// nothing is fired by default
+	fired = nil
+	res, _ = (&Resolver{}).Resolve(context.Background(), parse(t, root))
+	assert.Empty(t, fired)
+	assert.Empty(t, res.ErrorPixels)
+}
@@ -0,0 +1,199 @@
+package resolve
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultBreakerCooldown is the time a circuit stays open when
+// Resolver.BreakerCooldown is not set.
+const DefaultBreakerCooldown = 10 * time.Second
+
+// DefaultMaxBreakers is the number of hosts whose failures are tracked when
+// Resolver.MaxBreakers is not set.
+const DefaultMaxBreakers = 10000
+
+// ErrCircuitOpen is the underlying error of an Error when the ad tag URI
+// wasn't fetched because its host failed too many times recently.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// hostBreaker is the circuit breaker of a host. The circuit opens after a
+// number of consecutive failures, and lets a single trial request through
+// once the cooldown elapsed: its success closes the circuit, its failure
+// opens it again.
+type hostBreaker struct {
+	host      string
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func (b *hostBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(b.openUntil) || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// release frees the trial slot taken by allow when the outcome of the
+// request says nothing about the host, such as when the caller gave up.
+func (b *hostBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+func (b *hostBreaker) record(ok bool, now time.Time, threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if ok {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		return
+	}
+	b.failures++
+	if b.failures >= threshold {
+		b.openUntil = now.Add(cooldown)
+	}
+}
+
+// breaker returns the circuit breaker of host, or nil if circuit breaking is
+// disabled. The breakers of the hosts used the least recently are dropped
+// beyond MaxBreakers.
+func (r *Resolver) breaker(host string) *hostBreaker {
+	if r.BreakerFailures <= 0 {
+		return nil
+	}
+	r.breakersMu.Lock()
+	defer r.breakersMu.Unlock()
+	if el, ok := r.breakers[host]; ok {
+		r.breakerLRU.MoveToFront(el)
+		return el.Value.(*hostBreaker)
+	}
+	if r.breakers == nil {
+		r.breakers, r.breakerLRU = map[string]*list.Element{}, list.New()
+	}
+	b := &hostBreaker{host: host}
+	r.breakers[host] = r.breakerLRU.PushFront(b)
+	max := r.MaxBreakers
+	if max <= 0 {
+		max = DefaultMaxBreakers
+	}
+	for r.breakerLRU.Len() > max {
+		el := r.breakerLRU.Back()
+		r.breakerLRU.Remove(el)
+		delete(r.breakers, el.Value.(*hostBreaker).host)
+	}
+	return b
+}
+
+// forget drops b, closed by a success, as the breaker of a healthy host has
+// nothing to remember.
+func (r *Resolver) forget(b *hostBreaker) {
+	r.breakersMu.Lock()
+	defer r.breakersMu.Unlock()
+	if el, ok := r.breakers[b.host]; ok && el.Value == b {
+		r.breakerLRU.Remove(el)
+		delete(r.breakers, b.host)
+	}
+}
+
+func (r *Resolver) breakerCooldown() time.Duration {
+	if r.BreakerCooldown > 0 {
+		return r.BreakerCooldown
+	}
+	return DefaultBreakerCooldown
+}
+
+// cancelBody cancels the context of its request once closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+type hedgeResult struct {
+	i    int
+	resp *http.Response
+	err  error
+}
+
+// do sends req. When hedging is enabled, an identical request is sent if no
+// response was received after HedgeDelay, the first response winning and
+// the other request being canceled.
+func (r *Resolver) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	client := r.client()
+	if r.HedgeDelay <= 0 {
+		return client.Do(req.WithContext(ctx))
+	}
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	launch := func() {
+		rctx, cancel := context.WithCancel(ctx)
+		i := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := client.Do(req.WithContext(rctx))
+			results <- hedgeResult{i, resp, err}
+		}()
+	}
+	launch()
+	timer := time.NewTimer(r.HedgeDelay)
+	defer timer.Stop()
+	pending := 1
+	var firstErr error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if len(cancels) == 1 {
+				launch()
+				pending++
+			}
+		case res := <-results:
+			pending--
+			if res.err != nil {
+				cancels[res.i]()
+				if firstErr == nil {
+					firstErr = res.err
+				}
+				if len(cancels) == 1 {
+					return nil, firstErr
+				}
+				continue
+			}
+			for i, cancel := range cancels {
+				if i != res.i {
+					cancel()
+				}
+			}
+			go func(n int) {
+				for ; n > 0; n-- {
+					if res := <-results; res.resp != nil {
+						res.resp.Body.Close()
+					}
+				}
+			}(pending)
+			res.resp.Body = cancelBody{res.resp.Body, cancels[res.i]}
+			return res.resp, nil
+		}
+	}
+	return nil, firstErr
+}
@@ -0,0 +1,169 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveCircuitBreaker(t *testing.T) {
+	var calls, healthy int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, inlineDoc)
+	}))
+	defer srv.Close()
+
+	r := &Resolver{BreakerFailures: 2, BreakerCooldown: 50 * time.Millisecond}
+	for i := 0; i < 2; i++ {
+		_, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// the circuit is open, the host isn't requested anymore
+	res, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, vast.ErrorWrapperTimeout, res.Errors[0].Code)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// a successful trial request closes the circuit once the cooldown elapsed
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(60 * time.Millisecond)
+	_, err = r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	assert.NoError(t, err)
+	_, err = r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestResolveCircuitBreakerCanceledTrial(t *testing.T) {
+	var slow int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.LoadInt32(&slow) {
+		case 1:
+			w.WriteHeader(http.StatusInternalServerError)
+			atomic.StoreInt32(&slow, 2)
+			return
+		case 2:
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+			return
+		}
+		fmt.Fprint(w, inlineDoc)
+	}))
+	defer srv.Close()
+
+	r := &Resolver{BreakerFailures: 1, BreakerCooldown: 20 * time.Millisecond}
+	_, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	assert.Error(t, err)
+
+	// the trial request is abandoned by its caller
+	time.Sleep(30 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	_, err = r.Resolve(ctx, parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	cancel()
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+
+	// which doesn't keep the circuit open forever
+	atomic.StoreInt32(&slow, 0)
+	_, err = r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	assert.NoError(t, err)
+}
+
+func TestHostBreaker(t *testing.T) {
+	now := time.Now()
+	b := &hostBreaker{}
+	assert.True(t, b.allow(now))
+	b.record(false, now, 1, time.Second)
+	assert.False(t, b.allow(now))
+
+	// a single trial request once the cooldown elapsed
+	later := now.Add(2 * time.Second)
+	assert.True(t, b.allow(later))
+	assert.False(t, b.allow(later))
+	b.record(false, later, 1, time.Second)
+	assert.False(t, b.allow(later))
+	assert.True(t, b.allow(later.Add(time.Second)))
+	b.record(true, later, 1, time.Second)
+	assert.True(t, b.allow(later))
+	assert.True(t, b.allow(later))
+
+	// a released trial lets another one through
+	b.record(false, now, 1, time.Second)
+	assert.True(t, b.allow(later))
+	assert.False(t, b.allow(later))
+	b.release()
+	assert.True(t, b.allow(later))
+}
+
+func TestResolverBreakers(t *testing.T) {
+	r := &Resolver{BreakerFailures: 1, MaxBreakers: 2}
+	a := r.breaker("a")
+	assert.True(t, r.breaker("a") == a)
+	r.breaker("b")
+	r.breaker("a")
+	// b is the least recently used
+	r.breaker("c")
+	assert.Len(t, r.breakers, 2)
+	assert.Contains(t, r.breakers, "a")
+	assert.Contains(t, r.breakers, "c")
+
+	// closed breakers are forgotten
+	r.forget(a)
+	assert.Len(t, r.breakers, 1)
+	assert.True(t, r.breaker("a") != a)
+	// unless replaced
+	r.forget(a)
+	assert.Len(t, r.breakers, 2)
+
+	assert.Nil(t, (&Resolver{}).breaker("a"))
+}
+
+func TestResolveHedging(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-time.After(2 * time.Second):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		fmt.Fprint(w, inlineDoc)
+	}))
+	defer srv.Close()
+
+	r := &Resolver{HedgeDelay: 20 * time.Millisecond}
+	start := time.Now()
+	res, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	if assert.NoError(t, err) {
+		assert.Len(t, res.Ads, 1)
+	}
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// a fast response doesn't get hedged
+	atomic.StoreInt32(&calls, 1)
+	_, err = r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	assert.NoError(t, err)
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,457 @@
+// Package resolve follows the Wrapper ads of VAST documents over HTTP down to
+// the InLine ads they eventually point to.
+package resolve
+
+import (
+	"container/list"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/macro"
+)
+
+// DefaultMaxDepth is the number of wrappers followed in a chain when
+// Resolver.MaxDepth is not set, the minimum VAST players should support.
+const DefaultMaxDepth = 5
+
+// DefaultMaxBodySize is the maximum size of the fetched documents when
+// Resolver.MaxBodySize is not set.
+const DefaultMaxBodySize = 4 << 20
+
+// Resolver follows wrapper chains. The zero value is ready to use, and a
+// Resolver is safe for concurrent use. Its fields must not be modified once
+// it has been used.
+//
+// Ad tag URIs come from untrusted documents: servers resolving them should
+// enable the fetch restrictions, such as HTTPSOnly and BlockPrivateNetworks.
+type Resolver struct {
+	// Client used to fetch the ad tags. If nil, a client using Transport is
+	// used, or http.DefaultClient if Transport is nil too.
+	Client *http.Client
+	// Transport used to fetch the ad tags when Client is nil
+	Transport http.RoundTripper
+	// Only fetch https ad tag URIs
+	HTTPSOnly bool
+	// Refuse to fetch ad tag URIs resolving to loopback, private, link-local
+	// or otherwise non public addresses. The check is made at connection
+	// time unless Client or Transport is set, in which case it is only made
+	// on the resolved host names before each request and redirect.
+	BlockPrivateNetworks bool
+	// Hosts allowed to be fetched, with their subdomains. Any host is allowed
+	// if empty.
+	AllowedDomains []string
+	// Hosts never fetched, with their subdomains
+	DeniedDomains []string
+	// Maximum number of redirects followed by a request, the client's policy
+	// applying if zero. Redirects are not followed if negative.
+	MaxRedirects int
+	// Maximum time of each request, not limited if zero
+	HopTimeout time.Duration
+	// Maximum time of a whole resolution, not limited if zero
+	Timeout time.Duration
+	// Maximum number of ads of a pod resolved concurrently. The ads are
+	// resolved one at a time if zero.
+	Concurrency int
+	// Maximum time to resolve each ad of a pod, not limited if zero
+	AdTimeout time.Duration
+	// Number of consecutive transient failures of a host after which its ad
+	// tags are not fetched anymore for BreakerCooldown. Circuit breaking is
+	// disabled if zero.
+	BreakerFailures int
+	// Time the circuit of a failing host stays open before a trial request
+	// is let through, DefaultBreakerCooldown if zero
+	BreakerCooldown time.Duration
+	// Maximum number of failing hosts tracked by circuit breaking, the ones
+	// failing the least recently being forgotten, DefaultMaxBreakers if zero
+	MaxBreakers int
+	// Delay after which a second identical request is sent if the first one
+	// didn't get any response, the first response winning. Requests are not
+	// hedged if zero.
+	HedgeDelay time.Duration
+	// Policy for retrying the requests failing transiently, no retry by
+	// default
+	Retry RetryPolicy
+	// Cache of the fetched documents, keyed by ad tag URI once expanded,
+	// the cache-busting macros [CACHEBUSTING] and [TIMESTAMP] aside.
+	// Documents are not cached if nil.
+	Cache Cache
+	// Time to live of the cached documents lacking both HTTP cache headers
+	// and Expires elements, which are not cached if zero
+	CacheTTL time.Duration
+	// Maximum number of wrappers followed in a chain, DefaultMaxDepth if
+	// zero. Longer chains fail with ErrWrapperLimit.
+	MaxDepth int
+	// Maximum size in bytes of the fetched documents, DefaultMaxBodySize if
+	// zero. Larger documents fail with ErrBodyTooLarge.
+	MaxBodySize int64
+	// Fire the error tracking URIs of the wrappers of the chains which
+	// couldn't be resolved, with the [ERRORCODE] macro replaced
+	FireErrors bool
+	// Called with every error pixel fired, once all of them completed
+	OnErrorPixel func(ErrorPixel)
+	// Values of the macros of the ad tag URIs, and of the error tracking
+	// URIs other than [ERRORCODE], which are kept as is if nil
+	Macros *macro.Context
+	// Maximum number of bytes of the response bodies kept in the trace. The
+	// bodies are not kept if zero, and kept whole if negative.
+	TraceBodies int
+	// Receives the measurements of the resolutions, if set
+	Metrics Metrics
+	// Creates spans around the resolutions and the document fetches, if set
+	Tracer Tracer
+
+	clientOnce sync.Once
+	httpClient *http.Client
+	breakersMu sync.Mutex
+	breakers   map[string]*list.Element
+	breakerLRU *list.List
+}
+
+// Hop is a document fetched while following a wrapper chain.
+type Hop struct {
+	// The ad tag URI fetched
+	URL string
+	// Number of wrappers followed before this hop, starting at 0
+	Depth int
+	// The time spent fetching and parsing the document
+	Duration time.Duration
+	// Number of requests made, retries included
+	Attempts int
+	// Whether the document was found in the cache
+	Cached bool
+	// The parsed document
+	Document *vast.VAST
+
+	// The key of the document in the cache
+	key        string
+	header     http.Header
+	statusCode int
+	body       []byte
+}
+
+// ResolvedAd is an InLine ad along with the wrapper chain leading to it.
+type ResolvedAd struct {
+	// The wrappers followed, from the outermost to the innermost one. It is
+	// empty for the InLine ads of the resolved document itself.
+	Wrappers []*vast.Ad
+	// The terminal InLine ad
+	InLine *vast.Ad
+	// The documents fetched to reach the InLine ad, in order
+	Hops []Hop
+}
+
+// Resolved is the outcome of a resolution. Its ads share memory with the
+// resolved document and the fetched ones.
+type Resolved struct {
+	// The resolved document
+	Document *vast.VAST
+	// The InLine ads reached, in document order
+	Ads []ResolvedAd
+	// The wrapper chains which couldn't be resolved
+	Errors []*Error
+	// The error tracking URIs fired when Resolver.FireErrors is set
+	ErrorPixels []ErrorPixel
+	// Every document fetched, successfully or not. The hops of the ads of a
+	// pod are grouped by ad, in sequence order.
+	Trace []HopTrace
+}
+
+// Error is a wrapper chain which couldn't be resolved.
+type Error struct {
+	// The ad tag URI which failed
+	URL string
+	// The wrappers followed, from the outermost to the innermost one which
+	// failed
+	Wrappers []*vast.Ad
+	// The VAST error code matching the failure
+	Code vast.ErrorCode
+	// The underlying error
+	Err error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("resolve %s: %v (VAST error %d)", e.URL, e.Err, int(e.Code))
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Underlying errors of an Error for the failures detected by the resolver
+var (
+	// A fetched document has no ad (VAST error 303)
+	ErrNoAd = errors.New("no ad in response")
+	// The chain has more wrappers than Resolver.MaxDepth (VAST error 302)
+	ErrWrapperLimit = errors.New("wrapper limit reached")
+	// The chain points back to an ad tag it already fetched (VAST error 302)
+	ErrWrapperLoop = errors.New("wrapper loop detected")
+	// A wrapper of the chain has followAdditionalWrappers="false" (VAST
+	// error 302)
+	ErrWrapperNotAllowed = errors.New("additional wrappers not allowed")
+	// A fetched document is larger than Resolver.MaxBodySize
+	ErrBodyTooLarge = errors.New("response body too large")
+)
+
+// Resolve follows every Wrapper ad of v and returns the InLine ads reached.
+// The followAdditionalWrappers, allowMultipleAds and fallbackOnNoAd
+// attributes of the wrappers are honored: further wrappers are not followed,
+// responses are stripped down to their first stand-alone ad, and the
+// stand-alone ads of a response replace its pod ads which couldn't be
+// resolved, respectively.
+// Chains which can't be resolved are reported in Resolved.Errors; the
+// returned error is only set when ctx is done or when no InLine ad could be
+// reached because of failures, in which case it is the first of them.
+//
+// Every hop is recorded in the vast.DecisionTrace carried by ctx, if any.
+func (r *Resolver) Resolve(ctx context.Context, v *vast.VAST) (*Resolved, error) {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	ctx, end := r.startSpan(ctx, SpanResolve, nil)
+	res := &Resolved{Document: v}
+	r.resolve(ctx, v, nil, nil, res)
+	if r.FireErrors && len(res.Errors) > 0 {
+		res.ErrorPixels = r.fireErrors(ctx, res.Errors)
+	}
+	if r.Metrics != nil {
+		for _, e := range res.Errors {
+			r.Metrics.ObserveError(e.Code)
+		}
+		r.Metrics.ObserveResolution(len(res.Trace), time.Since(start))
+	}
+	err := ctx.Err()
+	if err == nil && len(res.Ads) == 0 && len(res.Errors) > 0 {
+		err = res.Errors[0]
+	}
+	end(err)
+	return res, err
+}
+
+// resolve resolves the ads of v, a document reached through wrappers.
+//
+// The ads of a pod are resolved concurrently and kept in sequence order.
+// When v holds an ad pod, its stand-alone ads are only used to replace the
+// pod ads which couldn't be resolved, unless their wrapper disables it with
+// fallbackOnNoAd="false". Otherwise every ad is resolved.
+func (r *Resolver) resolve(ctx context.Context, v *vast.VAST, wrappers []*vast.Ad, hops []Hop, res *Resolved) {
+	pod, standalone := v.SplitPod()
+	if len(pod) == 0 {
+		for _, ad := range standalone {
+			r.resolveAd(ctx, ad, wrappers, hops, res)
+		}
+		return
+	}
+	parts := make([]Resolved, len(pod))
+	found := make([]bool, len(pod))
+	r.parallel(len(pod), func(i int) {
+		ctx := ctx
+		if r.AdTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, r.AdTimeout)
+			defer cancel()
+		}
+		found[i] = r.resolveAd(ctx, pod[i], wrappers, hops, &parts[i])
+	})
+	next := 0
+	for i, ad := range pod {
+		res.Ads = append(res.Ads, parts[i].Ads...)
+		res.Errors = append(res.Errors, parts[i].Errors...)
+		res.Trace = append(res.Trace, parts[i].Trace...)
+		if found[i] || ad.Wrapper == nil || !isTrue(ad.Wrapper.FallbackOnNoAd, true) {
+			continue
+		}
+		for next < len(standalone) {
+			next++
+			if r.resolveAd(ctx, standalone[next-1], wrappers, hops, res) {
+				break
+			}
+		}
+	}
+}
+
+// parallel calls f for every index from 0 to n-1, running up to Concurrency
+// calls at a time.
+func (r *Resolver) parallel(n int, f func(i int)) {
+	if r.Concurrency <= 1 || n <= 1 {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+	sem := make(chan struct{}, r.Concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			f(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// resolveAd resolves ad, reached through wrappers, and returns true if at
+// least one InLine ad was found.
+func (r *Resolver) resolveAd(ctx context.Context, ad *vast.Ad, wrappers []*vast.Ad, hops []Hop, res *Resolved) bool {
+	switch {
+	case ad.InLine != nil:
+		res.Ads = append(res.Ads, ResolvedAd{Wrappers: wrappers, InLine: ad, Hops: hops})
+		return true
+	case ad.Wrapper != nil:
+		chain := append(wrappers[:len(wrappers):len(wrappers)], ad)
+		url, key := r.tagURL(ctx, strings.TrimSpace(string(ad.Wrapper.VASTAdTagURI.URI)))
+		if err := r.checkChain(url, key, chain, hops); err != nil {
+			res.Errors = append(res.Errors, err)
+			return false
+		}
+		hop, err := r.fetch(ctx, url, key, len(wrappers), res)
+		if err == nil && !isTrue(ad.Wrapper.AllowMultipleAds, false) {
+			err = singleAd(hop.Document, url)
+		}
+		if err != nil {
+			err.Wrappers = chain
+			res.Errors = append(res.Errors, err)
+			return false
+		}
+		n := len(res.Ads)
+		r.resolve(ctx, hop.Document, chain, append(hops[:len(hops):len(hops)], hop), res)
+		return len(res.Ads) > n
+	}
+	return false
+}
+
+// singleAd strips doc down to its first stand-alone ad, as required by
+// wrappers with allowMultipleAds="false".
+func singleAd(doc *vast.VAST, url string) *Error {
+	for _, ad := range doc.Ads {
+		if !ad.IsPodAd() {
+			doc.Ads = []vast.Ad{ad}
+			return nil
+		}
+	}
+	return &Error{URL: url, Code: vast.ErrorWrapperNoAd, Err: ErrNoAd}
+}
+
+// isTrue returns the value of b, or def if b is not set.
+func isTrue(b *vast.Bool, def bool) bool {
+	if b == nil {
+		return def
+	}
+	return bool(*b)
+}
+
+// cacheBustingMacros are the macros left out of the cache keys of the ad
+// tags, as their value changes with every request.
+var cacheBustingMacros = []string{"CACHEBUSTING", "TIMESTAMP"}
+
+// tagURL returns the URL of an ad tag, with the macros of r.Macros expanded,
+// and the key of its document in the cache, where the cache-busting macros
+// are left unexpanded.
+func (r *Resolver) tagURL(ctx context.Context, tag string) (url, key string) {
+	if r.Macros == nil {
+		return tag, tag
+	}
+	values := r.Macros.Values()
+	url = macro.Expand(ctx, tag, values)
+	for _, m := range cacheBustingMacros {
+		delete(values, m)
+	}
+	return url, macro.Expand(context.Background(), tag, values)
+}
+
+// checkChain returns an error if following the last wrapper of chain to url,
+// whose cache key is key, would exceed the depth limit or loop.
+func (r *Resolver) checkChain(url, key string, chain []*vast.Ad, hops []Hop) *Error {
+	max := r.MaxDepth
+	if max <= 0 {
+		max = DefaultMaxDepth
+	}
+	if len(chain) > max {
+		return &Error{URL: url, Wrappers: chain, Code: vast.ErrorWrapperLimit, Err: ErrWrapperLimit}
+	}
+	for _, h := range hops {
+		if h.key == key {
+			return &Error{URL: url, Wrappers: chain, Code: vast.ErrorWrapperLimit, Err: ErrWrapperLoop}
+		}
+	}
+	for _, w := range chain[:len(chain)-1] {
+		if !isTrue(w.Wrapper.FollowAdditionalWrappers, true) {
+			return &Error{URL: url, Wrappers: chain, Code: vast.ErrorWrapperLimit, Err: ErrWrapperNotAllowed}
+		}
+	}
+	return nil
+}
+
+// fetch retrieves and parses the document at url, cached under key,
+// recording the hop in the trace of res.
+func (r *Resolver) fetch(ctx context.Context, url, key string, depth int, res *Resolved) (Hop, *Error) {
+	hop := Hop{URL: url, Depth: depth, key: key}
+	ctx, end := r.startSpan(ctx, SpanHop, map[string]string{"url": url, "depth": strconv.Itoa(depth)})
+	start := time.Now()
+	doc, err := r.get(ctx, &hop)
+	hop.Duration = time.Since(start)
+	hop.Document = doc
+	trace := r.hopTrace(&hop, err)
+	res.Trace = append(res.Trace, trace)
+	if r.Metrics != nil {
+		r.Metrics.ObserveHop(trace)
+	}
+	end(trace.Err)
+
+	th := vast.TraceHop{Stage: "resolver", Depth: depth, URL: url, Duration: hop.Duration}
+	if err != nil {
+		th.Error = err.Error()
+	}
+	vast.DecisionTraceFrom(ctx).RecordHop(th)
+	return hop, err
+}
+
+// get retrieves and parses the document at hop.URL, counting the attempts made
+// in hop.
+func (r *Resolver) get(ctx context.Context, hop *Hop) (*vast.VAST, *Error) {
+	if hop.URL == "" {
+		return nil, &Error{Code: vast.ErrorWrapper, Err: errors.New("missing VASTAdTagURI")}
+	}
+	var body []byte
+	if r.Cache != nil {
+		body, hop.Cached = r.Cache.Get(hop.key)
+		if r.Metrics != nil {
+			r.Metrics.ObserveCache(hop.Cached)
+		}
+	}
+	if !hop.Cached {
+		var err *Error
+		if body, err = r.download(ctx, hop); err != nil {
+			return nil, err
+		}
+	}
+	hop.body = body
+	var doc vast.VAST
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, &Error{URL: hop.URL, Code: vast.ErrorXMLParsing, Err: err}
+	}
+	if len(doc.Ads) == 0 {
+		return nil, &Error{URL: hop.URL, Code: vast.ErrorWrapperNoAd, Err: ErrNoAd}
+	}
+	if r.Cache != nil && !hop.Cached {
+		r.store(hop, body, &doc)
+	}
+	return &doc, nil
+}
@@ -0,0 +1,344 @@
+package resolve
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+const inlineDoc = `<VAST version="3.0"><Ad id="inline"><InLine><AdSystem>DSP</AdSystem><AdTitle>t</AdTitle>` +
+	`<Impression><![CDATA[http://example.com/inline-imp]]></Impression>` +
+	`<Creatives><Creative><Linear><Duration>00:00:15</Duration><MediaFiles>` +
+	`<MediaFile delivery="progressive" type="video/mp4" width="640" height="360"><![CDATA[http://example.com/ad.mp4]]></MediaFile>` +
+	`</MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`
+
+func wrapperDoc(id, tag string) string {
+	return `<VAST version="3.0"><Ad id="` + id + `"><Wrapper><AdSystem>SSP</AdSystem>` +
+		`<Error><![CDATA[http://example.com/error?code=[ERRORCODE]]]></Error>` +
+		`<Impression><![CDATA[http://example.com/` + id + `-imp]]></Impression>` +
+		`<VASTAdTagURI><![CDATA[` + tag + `]]></VASTAdTagURI></Wrapper></Ad></VAST>`
+}
+
+// newServer serves docs by path, the "{{server}}" placeholder being replaced
+// by the URL of the server.
+func newServer(docs map[string]string) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, ok := docs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, strings.Replace(doc, "{{server}}", srv.URL, -1))
+	}))
+	return srv
+}
+
+func parse(t *testing.T, doc string) *vast.VAST {
+	var v vast.VAST
+	if err := xml.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatal(err)
+	}
+	return &v
+}
+
+func TestResolve(t *testing.T) {
+	srv := newServer(map[string]string{
+		"/w2":     wrapperDoc("w2", "{{server}}/inline"),
+		"/inline": inlineDoc,
+	})
+	defer srv.Close()
+
+	trace := vast.NewDecisionTrace()
+	ctx := vast.WithDecisionTrace(context.Background(), trace)
+	res, err := (&Resolver{}).Resolve(ctx, parse(t, wrapperDoc("w1", srv.URL+"/w2")))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, res.Errors)
+	if assert.Len(t, res.Ads, 1) {
+		ra := res.Ads[0]
+		assert.Equal(t, "inline", ra.InLine.ID)
+		if assert.Len(t, ra.Wrappers, 2) {
+			assert.Equal(t, "w1", ra.Wrappers[0].ID)
+			assert.Equal(t, "w2", ra.Wrappers[1].ID)
+		}
+		if assert.Len(t, ra.Hops, 2) {
+			assert.Equal(t, srv.URL+"/w2", ra.Hops[0].URL)
+			assert.Equal(t, 0, ra.Hops[0].Depth)
+			assert.Equal(t, srv.URL+"/inline", ra.Hops[1].URL)
+			assert.Equal(t, 1, ra.Hops[1].Depth)
+			assert.Equal(t, "inline", ra.Hops[1].Document.Ads[0].ID)
+		}
+	}
+	if hops := trace.Hops(); assert.Len(t, hops, 2) {
+		assert.Equal(t, "resolver", hops[0].Stage)
+		assert.Equal(t, srv.URL+"/inline", hops[1].URL)
+	}
+}
+
+func TestResolveInLine(t *testing.T) {
+	res, err := (&Resolver{}).Resolve(context.Background(), parse(t, inlineDoc))
+	if assert.NoError(t, err) && assert.Len(t, res.Ads, 1) {
+		assert.Empty(t, res.Ads[0].Wrappers)
+		assert.Empty(t, res.Ads[0].Hops)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	srv := newServer(map[string]string{
+		"/empty":   `<VAST version="3.0"></VAST>`,
+		"/garbage": `<VAST version="3.0"><Ad>`,
+		"/inline":  inlineDoc,
+	})
+	defer srv.Close()
+
+	doc := `<VAST version="3.0">` +
+		`<Ad id="a"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>` + srv.URL + `/empty</VASTAdTagURI></Wrapper></Ad>` +
+		`<Ad id="b"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>` + srv.URL + `/garbage</VASTAdTagURI></Wrapper></Ad>` +
+		`<Ad id="c"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>` + srv.URL + `/missing</VASTAdTagURI></Wrapper></Ad>` +
+		`<Ad id="d"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>` + srv.URL + `/inline</VASTAdTagURI></Wrapper></Ad>` +
+		`</VAST>`
+	res, err := (&Resolver{}).Resolve(context.Background(), parse(t, doc))
+	assert.NoError(t, err)
+	assert.Len(t, res.Ads, 1)
+	if assert.Len(t, res.Errors, 3) {
+		assert.Equal(t, vast.ErrorWrapperNoAd, res.Errors[0].Code)
+		assert.True(t, errors.Is(res.Errors[0], ErrNoAd))
+		assert.Equal(t, "a", res.Errors[0].Wrappers[0].ID)
+		assert.Equal(t, vast.ErrorXMLParsing, res.Errors[1].Code)
+		assert.Equal(t, vast.ErrorWrapperTimeout, res.Errors[2].Code)
+		assert.Equal(t, "resolve "+srv.URL+"/missing: unexpected status 404 Not Found (VAST error 301)", res.Errors[2].Error())
+	}
+
+	// without any InLine ad, the first failure is returned
+	res, err = (&Resolver{}).Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/empty")))
+	if assert.Error(t, err) {
+		assert.Equal(t, res.Errors[0], err)
+	}
+}
+
+func TestResolveCanceled(t *testing.T) {
+	srv := newServer(map[string]string{"/inline": inlineDoc})
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := (&Resolver{}).Resolve(ctx, parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	assert.Equal(t, context.Canceled, err)
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, vast.ErrorWrapperTimeout, res.Errors[0].Code)
+	}
+}
+
+func TestResolveDepthLimit(t *testing.T) {
+	docs := map[string]string{"/inline": inlineDoc}
+	for i := 1; i < 6; i++ {
+		docs[fmt.Sprintf("/w%d", i)] = wrapperDoc(fmt.Sprintf("w%d", i), fmt.Sprintf("{{server}}/w%d", i+1))
+	}
+	docs["/w6"] = wrapperDoc("w6", "{{server}}/inline")
+	srv := newServer(docs)
+	defer srv.Close()
+
+	// w0 to w6 makes a chain of 7 wrappers
+	doc := parse(t, wrapperDoc("w0", srv.URL+"/w1"))
+	_, err := (&Resolver{}).Resolve(context.Background(), doc)
+	if assert.Error(t, err) {
+		var rerr *Error
+		if assert.True(t, errors.As(err, &rerr)) {
+			assert.Equal(t, vast.ErrorWrapperLimit, rerr.Code)
+			assert.Len(t, rerr.Wrappers, DefaultMaxDepth+1)
+		}
+		assert.True(t, errors.Is(err, ErrWrapperLimit))
+	}
+
+	res, err := (&Resolver{MaxDepth: 8}).Resolve(context.Background(), doc)
+	if assert.NoError(t, err) && assert.Len(t, res.Ads, 1) {
+		assert.Len(t, res.Ads[0].Wrappers, 7)
+	}
+}
+
+func TestResolveLoop(t *testing.T) {
+	srv := newServer(map[string]string{
+		"/a": wrapperDoc("a", "{{server}}/b"),
+		"/b": wrapperDoc("b", "{{server}}/a"),
+	})
+	defer srv.Close()
+
+	res, err := (&Resolver{MaxDepth: 10}).Resolve(context.Background(), parse(t, wrapperDoc("root", srv.URL+"/a")))
+	assert.True(t, errors.Is(err, ErrWrapperLoop))
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, vast.ErrorWrapperLimit, res.Errors[0].Code)
+		assert.Equal(t, srv.URL+"/a", res.Errors[0].URL)
+		assert.Len(t, res.Errors[0].Wrappers, 3)
+	}
+}
+
+func TestResolveMerged(t *testing.T) {
+	inline41 := strings.Replace(inlineDoc, `version="3.0"`, `version="4.1"`, 1)
+	inline41 = strings.Replace(inline41, `<Linear>`, `<Linear><TrackingEvents><Tracking event="start"><![CDATA[http://example.com/inline-start]]></Tracking></TrackingEvents>`, 1)
+	srv := newServer(map[string]string{
+		"/w2": `<VAST version="3.0"><Ad id="w2"><Wrapper><AdSystem>SSP</AdSystem>` +
+			`<Error><![CDATA[http://example.com/w2-error]]></Error>` +
+			`<Impression><![CDATA[http://example.com/w2-imp]]></Impression>` +
+			`<VASTAdTagURI><![CDATA[{{server}}/inline]]></VASTAdTagURI>` +
+			`<Creatives><Creative><Linear>` +
+			`<TrackingEvents><Tracking event="start"><![CDATA[http://example.com/w2-start]]></Tracking></TrackingEvents>` +
+			`<VideoClicks><ClickTracking><![CDATA[http://example.com/w2-click]]></ClickTracking></VideoClicks>` +
+			`</Linear></Creative></Creatives></Wrapper></Ad></VAST>`,
+		"/inline": inline41,
+	})
+	defer srv.Close()
+
+	root := parse(t, wrapperDoc("w1", srv.URL+"/w2"))
+	res, err := (&Resolver{}).Resolve(context.Background(), root)
+	if !assert.NoError(t, err) {
+		return
+	}
+	merged, err := res.Merged()
+	if !assert.NoError(t, err) || !assert.Len(t, merged.Ads, 1) {
+		return
+	}
+	assert.Equal(t, "4.1", merged.Version)
+	in := merged.Ads[0].InLine
+	assert.Equal(t, "inline", merged.Ads[0].ID)
+	assert.Equal(t, []vast.Impression{
+		{URI: "http://example.com/inline-imp"},
+		{URI: "http://example.com/w1-imp"},
+		{URI: "http://example.com/w2-imp"},
+	}, in.Impressions)
+	assert.Len(t, in.Errors, 2)
+	linear := in.Creatives[0].Linear
+	assert.Equal(t, []vast.Tracking{
+		{Event: "start", URI: "http://example.com/inline-start"},
+		{Event: "start", URI: "http://example.com/w2-start"},
+	}, linear.TrackingEvents)
+	assert.Equal(t, vast.URI("http://example.com/w2-click"), linear.VideoClicks.ClickTrackings[0].URI)
+
+	// the resolved documents are left untouched
+	assert.Len(t, res.Ads[0].InLine.InLine.Impressions, 1)
+	assert.Equal(t, root, res.Document)
+}
+
+func TestResolveWrapperAttributes(t *testing.T) {
+	pod := `<VAST version="3.0">` +
+		`<Ad id="p1" sequence="1"><Wrapper fallbackOnNoAd="false"><AdSystem>SSP</AdSystem><VASTAdTagURI>{{server}}/empty</VASTAdTagURI></Wrapper></Ad>` +
+		`<Ad id="p2" sequence="2"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>{{server}}/empty</VASTAdTagURI></Wrapper></Ad>` +
+		`<Ad id="p3" sequence="3"><InLine><AdSystem>DSP</AdSystem><AdTitle>p3</AdTitle></InLine></Ad>` +
+		`<Ad id="s1"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>{{server}}/missing</VASTAdTagURI></Wrapper></Ad>` +
+		`<Ad id="s2"><InLine><AdSystem>DSP</AdSystem><AdTitle>s2</AdTitle></InLine></Ad>` +
+		`<Ad id="s3"><InLine><AdSystem>DSP</AdSystem><AdTitle>s3</AdTitle></InLine></Ad>` +
+		`</VAST>`
+	srv := newServer(map[string]string{
+		"/empty":  `<VAST version="3.0"></VAST>`,
+		"/pod":    pod,
+		"/inline": inlineDoc,
+		"/nested": wrapperDoc("nested", "{{server}}/inline"),
+	})
+	defer srv.Close()
+
+	ids := func(res *Resolved) []string {
+		var ids []string
+		for _, ra := range res.Ads {
+			ids = append(ids, ra.InLine.ID)
+		}
+		return ids
+	}
+
+	t.Run("fallbackOnNoAd", func(t *testing.T) {
+		// p1 fails without fallback, p2 fails and is replaced by s2 once s1
+		// failed too, s3 is left unused
+		res, err := (&Resolver{}).Resolve(context.Background(), parse(t, strings.Replace(pod, "{{server}}", srv.URL, -1)))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"s2", "p3"}, ids(res))
+		assert.Len(t, res.Errors, 3)
+	})
+
+	t.Run("allowMultipleAds", func(t *testing.T) {
+		doc := `<VAST version="3.0"><Ad id="w"><Wrapper allowMultipleAds="%s"><AdSystem>SSP</AdSystem><VASTAdTagURI>` + srv.URL + `/pod</VASTAdTagURI></Wrapper></Ad></VAST>`
+		res, err := (&Resolver{}).Resolve(context.Background(), parse(t, fmt.Sprintf(doc, "true")))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"s2", "p3"}, ids(res))
+
+		// only the first stand-alone ad is kept, even if it fails
+		res, err = (&Resolver{}).Resolve(context.Background(), parse(t, fmt.Sprintf(doc, "false")))
+		assert.Error(t, err)
+		assert.Empty(t, res.Ads)
+		if assert.Len(t, res.Errors, 1) {
+			assert.Equal(t, srv.URL+"/missing", res.Errors[0].URL)
+			assert.Len(t, res.Errors[0].Wrappers, 2)
+		}
+	})
+
+	t.Run("followAdditionalWrappers", func(t *testing.T) {
+		doc := `<VAST version="3.0"><Ad id="w"><Wrapper followAdditionalWrappers="%s"><AdSystem>SSP</AdSystem><VASTAdTagURI>` + srv.URL + `/nested</VASTAdTagURI></Wrapper></Ad></VAST>`
+		res, err := (&Resolver{}).Resolve(context.Background(), parse(t, fmt.Sprintf(doc, "true")))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"inline"}, ids(res))
+
+		res, err = (&Resolver{}).Resolve(context.Background(), parse(t, fmt.Sprintf(doc, "false")))
+		assert.True(t, errors.Is(err, ErrWrapperNotAllowed))
+		if assert.Len(t, res.Errors, 1) {
+			assert.Equal(t, vast.ErrorWrapperLimit, res.Errors[0].Code)
+			assert.Equal(t, srv.URL+"/inline", res.Errors[0].URL)
+		}
+	})
+}
+
+func TestResolvePodConcurrently(t *testing.T) {
+	var inflight, peak int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		if r.URL.Path == "/slow" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+		atomic.AddInt32(&inflight, -1)
+		fmt.Fprint(w, strings.Replace(inlineDoc, `id="inline"`, `id="`+r.URL.Path[1:]+`"`, 1))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	pod := `<VAST version="3.0">`
+	// the slow ad is requested first so that it is in flight with the others
+	for i, path := range []string{"c", "b", "a", "slow"} {
+		pod += fmt.Sprintf(`<Ad id="w%d" sequence="%d"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>%s/%s</VASTAdTagURI></Wrapper></Ad>`, i, 4-i, srv.URL, path)
+	}
+	pod += `</VAST>`
+
+	// the slow ad times out while the others are resolved concurrently
+	r := &Resolver{Concurrency: 2, AdTimeout: 50 * time.Millisecond}
+	res, err := r.Resolve(context.Background(), parse(t, pod))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var ids []string
+	for _, ra := range res.Ads {
+		ids = append(ids, ra.InLine.ID)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, ids)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, srv.URL+"/slow", res.Errors[0].URL)
+		assert.Equal(t, vast.ErrorWrapperTimeout, res.Errors[0].Code)
+	}
+}
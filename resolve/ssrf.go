@@ -0,0 +1,148 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrBlocked is the underlying error of an Error when an ad tag URI, or one
+// of its redirects, is rejected by the fetch restrictions of the Resolver.
+var ErrBlocked = errors.New("ad tag URI blocked")
+
+// privateNetworks are the IP ranges which are not reachable from the
+// internet, in addition to the loopback, link-local, multicast and
+// unspecified addresses.
+var privateNetworks = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",
+		"10.0.0.0/8",
+		"100.64.0.0/10",
+		"172.16.0.0/12",
+		"192.0.0.0/24",
+		"192.168.0.0/16",
+		"198.18.0.0/15",
+		"fc00::/7",
+	} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// isPrivateIP returns true if ip is not a public unicast address.
+func isPrivateIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDomain returns true if host is domain or one of its subdomains.
+func matchDomain(host, domain string) bool {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// restricted returns true if any fetch restriction is enabled.
+func (r *Resolver) restricted() bool {
+	return r.HTTPSOnly || r.BlockPrivateNetworks || len(r.AllowedDomains) > 0 || len(r.DeniedDomains) > 0
+}
+
+// checkURL returns an error wrapping ErrBlocked if u may not be fetched.
+func (r *Resolver) checkURL(ctx context.Context, u *url.URL) error {
+	if r.HTTPSOnly && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q not allowed", ErrBlocked, u.Scheme)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, d := range r.DeniedDomains {
+		if matchDomain(host, d) {
+			return fmt.Errorf("%w: host %q denied", ErrBlocked, host)
+		}
+	}
+	if len(r.AllowedDomains) > 0 {
+		allowed := false
+		for _, d := range r.AllowedDomains {
+			allowed = allowed || matchDomain(host, d)
+		}
+		if !allowed {
+			return fmt.Errorf("%w: host %q not allowed", ErrBlocked, host)
+		}
+	}
+	if !r.BlockPrivateNetworks {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if isPrivateIP(ip) {
+			return fmt.Errorf("%w: private address %s", ErrBlocked, ip)
+		}
+		return nil
+	}
+	// a failed lookup is reported by the request itself
+	addrs, _ := net.DefaultResolver.LookupIPAddr(ctx, host)
+	for _, addr := range addrs {
+		if isPrivateIP(addr.IP) {
+			return fmt.Errorf("%w: host %q resolves to private address %s", ErrBlocked, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// guardedTransport returns a transport refusing to connect to private
+// addresses, which protects against DNS rebinding between checkURL and the
+// connection.
+func guardedTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+				return fmt.Errorf("%w: private address %s", ErrBlocked, ip)
+			}
+			return nil
+		},
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = dialer.DialContext
+	return t
+}
+
+// checkRedirect enforces MaxRedirects and the fetch restrictions on
+// redirects, before calling next if set.
+func (r *Resolver) checkRedirect(next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		switch {
+		case r.MaxRedirects < 0:
+			return fmt.Errorf("%w: redirects not allowed", ErrBlocked)
+		case r.MaxRedirects > 0 && len(via) > r.MaxRedirects:
+			return fmt.Errorf("%w: stopped after %d redirects", ErrBlocked, r.MaxRedirects)
+		}
+		if err := r.checkURL(req.Context(), req.URL); err != nil {
+			return err
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPrivateIP(t *testing.T) {
+	for ip, private := range map[string]bool{
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"172.20.0.1":      true,
+		"192.168.1.1":     true,
+		"169.254.169.254": true,
+		"100.64.0.1":      true,
+		"0.0.0.0":         true,
+		"::1":             true,
+		"fe80::1":         true,
+		"fd00::1":         true,
+		"224.0.0.1":       true,
+		"8.8.8.8":         false,
+		"172.32.0.1":      false,
+		"2001:4860::8888": false,
+	} {
+		assert.Equal(t, private, isPrivateIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestMatchDomain(t *testing.T) {
+	assert.True(t, matchDomain("example.com", "example.com"))
+	assert.True(t, matchDomain("ads.example.com", "Example.com"))
+	assert.True(t, matchDomain("ads.example.com", ".example.com"))
+	assert.False(t, matchDomain("badexample.com", "example.com"))
+	assert.False(t, matchDomain("example.com", "ads.example.com"))
+}
+
+func TestResolveFetchRestrictions(t *testing.T) {
+	srv := newServer(map[string]string{"/inline": inlineDoc})
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	hostname, port, _ := net.SplitHostPort(host)
+
+	for name, r := range map[string]*Resolver{
+		"https only":      {HTTPSOnly: true},
+		"private network": {BlockPrivateNetworks: true},
+		"denied domain":   {DeniedDomains: []string{hostname}},
+		"allowed domains": {AllowedDomains: []string{"example.com"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+			assert.True(t, errors.Is(err, ErrBlocked), "%v", err)
+			if assert.Len(t, res.Errors, 1) {
+				assert.Equal(t, vast.ErrorWrapper, res.Errors[0].Code)
+			}
+		})
+	}
+
+	// a redirect to a denied host is refused
+	redirect := httptest.NewServer(http.RedirectHandler("http://localhost:"+port+"/inline", http.StatusFound))
+	defer redirect.Close()
+	r := &Resolver{DeniedDomains: []string{"localhost"}}
+	_, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", redirect.URL)))
+	assert.True(t, errors.Is(err, ErrBlocked), "%v", err)
+
+	// allowed otherwise
+	res, err := (&Resolver{AllowedDomains: []string{"localhost", hostname}}).Resolve(context.Background(), parse(t, wrapperDoc("w", redirect.URL)))
+	if assert.NoError(t, err) {
+		assert.Len(t, res.Ads, 1)
+	}
+}
+
+func TestResolveMaxRedirects(t *testing.T) {
+	srv := newServer(map[string]string{"/inline": inlineDoc})
+	defer srv.Close()
+	mux := http.NewServeMux()
+	mux.Handle("/r1", http.RedirectHandler(srv.URL+"/inline", http.StatusFound))
+	mux.Handle("/r2", http.RedirectHandler("/r1", http.StatusFound))
+	redirect := httptest.NewServer(mux)
+	defer redirect.Close()
+
+	_, err := (&Resolver{MaxRedirects: 2}).Resolve(context.Background(), parse(t, wrapperDoc("w", redirect.URL+"/r2")))
+	assert.NoError(t, err)
+	_, err = (&Resolver{MaxRedirects: 1}).Resolve(context.Background(), parse(t, wrapperDoc("w", redirect.URL+"/r2")))
+	assert.True(t, errors.Is(err, ErrBlocked), "%v", err)
+	_, err = (&Resolver{MaxRedirects: -1}).Resolve(context.Background(), parse(t, wrapperDoc("w", redirect.URL+"/r1")))
+	assert.True(t, errors.Is(err, ErrBlocked), "%v", err)
+
+	// the redirect policy of a supplied client is kept
+	called := false
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		called = true
+		return nil
+	}}
+	_, err = (&Resolver{Client: client, MaxRedirects: 2}).Resolve(context.Background(), parse(t, wrapperDoc("w", redirect.URL+"/r1")))
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
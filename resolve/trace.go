@@ -0,0 +1,60 @@
+package resolve
+
+import (
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// HopTrace describes a document fetch, for debugging misbehaving wrapper
+// chains.
+type HopTrace struct {
+	// The ad tag URI fetched
+	URL string
+	// Number of wrappers followed before this hop, starting at 0
+	Depth int
+	// Status code of the last response, 0 if no response was received or
+	// the document came from the cache
+	StatusCode int
+	// Time spent fetching and parsing the document, retries included
+	Latency time.Duration
+	// Number of requests made
+	Attempts int
+	// Whether the document came from the cache
+	Cached bool
+	// Size of the response body in bytes
+	Size int
+	// Version of the parsed document, empty if it couldn't be parsed
+	Version vast.SpecVersion
+	// The response body, truncated to Resolver.TraceBodies bytes
+	Body []byte
+	// The error which ended the chain at this hop, if any
+	Err error
+}
+
+func (r *Resolver) hopTrace(hop *Hop, err *Error) HopTrace {
+	t := HopTrace{
+		URL:        hop.URL,
+		Depth:      hop.Depth,
+		StatusCode: hop.statusCode,
+		Latency:    hop.Duration,
+		Attempts:   hop.Attempts,
+		Cached:     hop.Cached,
+		Size:       len(hop.body),
+	}
+	if hop.Document != nil {
+		t.Version = hop.Document.EffectiveVersion()
+	}
+	switch {
+	case r.TraceBodies < 0:
+		t.Body = hop.body
+	case r.TraceBodies > 0 && len(hop.body) > r.TraceBodies:
+		t.Body = hop.body[:r.TraceBodies]
+	case r.TraceBodies > 0:
+		t.Body = hop.body
+	}
+	if err != nil {
+		t.Err = err
+	}
+	return t
+}
@@ -0,0 +1,56 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTrace(t *testing.T) {
+	srv := newServer(map[string]string{
+		"/w2":     wrapperDoc("w2", "{{server}}/inline"),
+		"/inline": inlineDoc,
+	})
+	defer srv.Close()
+
+	doc := `<VAST version="3.0">` +
+		`<Ad id="a"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>` + srv.URL + `/w2</VASTAdTagURI></Wrapper></Ad>` +
+		`<Ad id="b"><Wrapper><AdSystem>SSP</AdSystem><VASTAdTagURI>` + srv.URL + `/missing</VASTAdTagURI></Wrapper></Ad>` +
+		`</VAST>`
+	res, err := (&Resolver{TraceBodies: 10}).Resolve(context.Background(), parse(t, doc))
+	if !assert.NoError(t, err) || !assert.Len(t, res.Trace, 3) {
+		return
+	}
+
+	w2 := res.Trace[0]
+	assert.Equal(t, srv.URL+"/w2", w2.URL)
+	assert.Equal(t, 0, w2.Depth)
+	assert.Equal(t, http.StatusOK, w2.StatusCode)
+	assert.Equal(t, len(wrapperDoc("w2", srv.URL+"/inline")), w2.Size)
+	assert.Equal(t, vast.Version3_0, w2.Version)
+	assert.Equal(t, `<VAST vers`, string(w2.Body))
+	assert.Equal(t, 1, w2.Attempts)
+	assert.True(t, w2.Latency > 0)
+	assert.NoError(t, w2.Err)
+
+	assert.Equal(t, 1, res.Trace[1].Depth)
+	assert.Equal(t, srv.URL+"/inline", res.Trace[1].URL)
+
+	missing := res.Trace[2]
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode)
+	assert.Equal(t, vast.SpecVersion(""), missing.Version)
+	assert.Equal(t, "404 page n", string(missing.Body))
+	assert.Equal(t, len("404 page not found\n"), missing.Size)
+	var rerr *Error
+	assert.True(t, errors.As(missing.Err, &rerr))
+
+	// bodies are kept whole or dropped
+	res, _ = (&Resolver{TraceBodies: -1}).Resolve(context.Background(), parse(t, doc))
+	assert.Equal(t, inlineDoc, string(res.Trace[1].Body))
+	res, _ = (&Resolver{}).Resolve(context.Background(), parse(t, doc))
+	assert.Nil(t, res.Trace[1].Body)
+}
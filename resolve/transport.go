@@ -0,0 +1,161 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// RetryPolicy defines how requests failing transiently are retried: network
+// errors, request timeouts, 429 and 5xx statuses. The zero value disables
+// retries.
+type RetryPolicy struct {
+	// Maximum number of retries of a request
+	MaxRetries int
+	// Delay before the first retry, doubled at every following one
+	Backoff time.Duration
+	// Maximum delay between two attempts, not limited if zero
+	MaxBackoff time.Duration
+}
+
+// delay returns the delay before the given retry, starting at 0.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.Backoff
+	for i := 0; i < retry && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// client returns the client to use, configured to enforce the fetch
+// restrictions.
+func (r *Resolver) client() *http.Client {
+	r.clientOnce.Do(func() {
+		switch {
+		case r.Client != nil:
+			c := *r.Client
+			r.httpClient = &c
+		case r.Transport != nil:
+			r.httpClient = &http.Client{Transport: r.Transport}
+		case r.BlockPrivateNetworks:
+			r.httpClient = &http.Client{Transport: guardedTransport()}
+		default:
+			r.httpClient = &http.Client{}
+		}
+		if r.restricted() || r.MaxRedirects != 0 {
+			r.httpClient.CheckRedirect = r.checkRedirect(r.httpClient.CheckRedirect)
+		}
+	})
+	return r.httpClient
+}
+
+// download returns the body of hop.URL, retrying according to the retry
+// policy.
+func (r *Resolver) download(ctx context.Context, hop *Hop) ([]byte, *Error) {
+	for retry := 0; ; retry++ {
+		hop.Attempts++
+		body, transient, err := r.attempt(ctx, hop)
+		if err == nil {
+			return body, nil
+		}
+		if errors.Is(err, ErrBlocked) {
+			return nil, &Error{URL: hop.URL, Code: vast.ErrorWrapper, Err: err}
+		}
+		if !transient || retry >= r.Retry.MaxRetries || ctx.Err() != nil {
+			return nil, &Error{URL: hop.URL, Code: vast.ErrorWrapperTimeout, Err: err}
+		}
+		t := time.NewTimer(r.Retry.delay(retry))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, &Error{URL: hop.URL, Code: vast.ErrorWrapperTimeout, Err: ctx.Err()}
+		case <-t.C:
+		}
+	}
+}
+
+// attempt makes a single request to hop.URL, and reports whether its failure
+// is transient.
+func (r *Resolver) attempt(ctx context.Context, hop *Hop) (body []byte, transient bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	parent := ctx
+	if r.HopTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.HopTimeout)
+		defer cancel()
+	}
+	req, err := http.NewRequest(http.MethodGet, hop.URL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if r.restricted() {
+		if err := r.checkURL(ctx, req.URL); err != nil {
+			return nil, false, err
+		}
+	}
+	b := r.breaker(req.URL.Host)
+	if b != nil && !b.allow(time.Now()) {
+		return nil, false, fmt.Errorf("%w: %s", ErrCircuitOpen, req.URL.Host)
+	}
+	body, transient, err = r.roundTrip(ctx, req, hop)
+	switch {
+	case b == nil:
+	case parent.Err() != nil:
+		// the caller gave up: the host is neither healthy nor failing
+		b.release()
+	case err == nil || !transient:
+		b.record(true, time.Now(), r.BreakerFailures, r.breakerCooldown())
+		r.forget(b)
+	default:
+		b.record(false, time.Now(), r.BreakerFailures, r.breakerCooldown())
+	}
+	return body, transient, err
+}
+
+// roundTrip sends req, hedged if enabled, and reads the response.
+func (r *Resolver) roundTrip(ctx context.Context, req *http.Request, hop *Hop) (body []byte, transient bool, err error) {
+	resp, err := r.do(ctx, req)
+	if err != nil {
+		return nil, !errors.Is(err, ErrBlocked), err
+	}
+	defer resp.Body.Close()
+	hop.header = resp.Header
+	hop.statusCode = resp.StatusCode
+	max := r.maxBodySize()
+	// one more byte to tell a body of max bytes from a larger one
+	body, err = ioutil.ReadAll(io.LimitReader(resp.Body, max+1))
+	tooLarge := int64(len(body)) > max
+	if tooLarge {
+		body = body[:max]
+	}
+	hop.body = body
+	if resp.StatusCode != http.StatusOK {
+		transient = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return nil, transient, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	if tooLarge {
+		return nil, false, fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, max)
+	}
+	return body, false, nil
+}
+
+func (r *Resolver) maxBodySize() int64 {
+	if r.MaxBodySize > 0 {
+		return r.MaxBodySize
+	}
+	return DefaultMaxBodySize
+}
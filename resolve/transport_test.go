@@ -0,0 +1,129 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestResolveTransport(t *testing.T) {
+	srv := newServer(map[string]string{"/inline": inlineDoc})
+	defer srv.Close()
+
+	var calls int32
+	r := &Resolver{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	res, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	if assert.NoError(t, err) {
+		assert.Len(t, res.Ads, 1)
+		assert.Equal(t, 1, res.Ads[0].Hops[0].Attempts)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestResolveRetry(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch n := atomic.AddInt32(&calls, 1); {
+		case r.URL.Path == "/notfound":
+			http.NotFound(w, r)
+		case n <= 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			fmt.Fprint(w, inlineDoc)
+		}
+	}))
+	defer srv.Close()
+
+	r := &Resolver{Retry: RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}}
+	res, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, res.Ads[0].Hops[0].Attempts)
+	}
+
+	// not found isn't transient
+	atomic.StoreInt32(&calls, 10)
+	res, err = r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/notfound")))
+	assert.Error(t, err)
+	assert.Equal(t, int32(11), atomic.LoadInt32(&calls))
+
+	// retries are exhausted
+	atomic.StoreInt32(&calls, 0)
+	r.Retry.MaxRetries = 1
+	_, err = r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "503 Service Unavailable")
+	}
+}
+
+func TestResolveMaxBodySize(t *testing.T) {
+	srv := newServer(map[string]string{"/inline": inlineDoc})
+	defer srv.Close()
+
+	r := &Resolver{MaxBodySize: int64(len(inlineDoc))}
+	_, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	assert.NoError(t, err)
+
+	r = &Resolver{MaxBodySize: int64(len(inlineDoc)) - 1}
+	_, err = r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	assert.True(t, errors.Is(err, ErrBodyTooLarge), "%v", err)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	assert.Equal(t, 10*time.Millisecond, p.delay(0))
+	assert.Equal(t, 20*time.Millisecond, p.delay(1))
+	assert.Equal(t, 40*time.Millisecond, p.delay(2))
+	assert.Equal(t, 50*time.Millisecond, p.delay(3))
+	assert.Equal(t, 50*time.Millisecond, p.delay(30))
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.delay(2))
+}
+
+func TestResolveTimeouts(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		fmt.Fprint(w, inlineDoc)
+	}))
+	defer srv.Close()
+
+	// the first attempt times out and is retried
+	r := &Resolver{HopTimeout: 20 * time.Millisecond, Retry: RetryPolicy{MaxRetries: 1}}
+	res, err := r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, res.Ads[0].Hops[0].Attempts)
+	}
+
+	// the total deadline stops the resolution
+	atomic.StoreInt32(&calls, 0)
+	r = &Resolver{Timeout: 20 * time.Millisecond, Retry: RetryPolicy{MaxRetries: 5}}
+	start := time.Now()
+	res, err = r.Resolve(context.Background(), parse(t, wrapperDoc("w", srv.URL+"/inline")))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, vast.ErrorWrapperTimeout, res.Errors[0].Code)
+	}
+}
@@ -0,0 +1,115 @@
+package vast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// URLKind is the purpose of a URI of a VAST document.
+type URLKind int
+
+// URL kinds reported by RewriteURLs
+const (
+	// A URI not fitting any other kind, such as a Survey
+	URLOther URLKind = iota
+	// An Impression
+	URLImpression
+	// A Tracking event, IconViewTracking or viewability URI
+	URLTracking
+	// A URI opened when the user clicks on a creative
+	URLClickThrough
+	// A URI pinged when the user clicks on a creative
+	URLClickTracking
+	// A media file, mezzanine, interactive creative file or closed caption file
+	URLMedia
+	// A static or iframe resource of a companion, non-linear or icon
+	URLResource
+	// An Error URI
+	URLError
+	// A verification script
+	URLVerification
+	// The VASTAdTagURI of a wrapper
+	URLAdTag
+)
+
+// String implements the fmt.Stringer interface.
+func (k URLKind) String() string {
+	switch k {
+	case URLOther:
+		return "other"
+	case URLImpression:
+		return "impression"
+	case URLTracking:
+		return "tracking"
+	case URLClickThrough:
+		return "click-through"
+	case URLClickTracking:
+		return "click-tracking"
+	case URLMedia:
+		return "media"
+	case URLResource:
+		return "resource"
+	case URLError:
+		return "error"
+	case URLVerification:
+		return "verification"
+	case URLAdTag:
+		return "ad-tag"
+	}
+	return fmt.Sprintf("URLKind(%d)", int(k))
+}
+
+// urlKinds maps the elements holding a URI to their kind.
+var urlKinds = map[string]URLKind{
+	"Impression":              URLImpression,
+	"Tracking":                URLTracking,
+	"IconViewTracking":        URLTracking,
+	"Viewable":                URLTracking,
+	"NotViewable":             URLTracking,
+	"ViewUndetermined":        URLTracking,
+	"ClickThrough":            URLClickThrough,
+	"CompanionClickThrough":   URLClickThrough,
+	"NonLinearClickThrough":   URLClickThrough,
+	"IconClickThrough":        URLClickThrough,
+	"ClickTracking":           URLClickTracking,
+	"CustomClick":             URLClickTracking,
+	"CompanionClickTracking":  URLClickTracking,
+	"NonLinearClickTracking":  URLClickTracking,
+	"IconClickTracking":       URLClickTracking,
+	"MediaFile":               URLMedia,
+	"Mezzanine":               URLMedia,
+	"InteractiveCreativeFile": URLMedia,
+	"ClosedCaptionFile":       URLMedia,
+	"StaticResource":          URLResource,
+	"IFrameResource":          URLResource,
+	"Error":                   URLError,
+	"JavaScriptResource":      URLVerification,
+	"ExecutableResource":      URLVerification,
+	"VASTAdTagURI":            URLAdTag,
+}
+
+// URLField identifies a URI of a VAST document.
+type URLField struct {
+	// Path of the element holding the URI, as reported by Walk, such as
+	// "VAST.Ad[0].InLine.Impression[0]"
+	Path string
+	// Name of the element holding the URI, such as "Impression"
+	Element string
+	// Purpose of the URI
+	Kind URLKind
+}
+
+// RewriteURLs replaces every non-empty URI of doc by the one returned by fn,
+// given the URI without surrounding whitespace. It allows routing every
+// beacon and media file through a proxy or CDN in a single pass.
+func RewriteURLs(doc *VAST, fn func(field URLField, url string) string) {
+	Walk(doc, Visitor{URI: func(path string, uri *string) error {
+		u := strings.TrimSpace(*uri)
+		if u == "" {
+			return nil
+		}
+		element := elementName(path)
+		*uri = fn(URLField{Path: path, Element: element, Kind: urlKinds[element]}, u)
+		return nil
+	}})
+}
@@ -0,0 +1,56 @@
+package vast
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteURLs(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast4_verification.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	kinds := map[URLKind][]string{}
+	RewriteURLs(v, func(f URLField, u string) string {
+		kinds[f.Kind] = append(kinds[f.Kind], f.Element)
+		if f.Kind == URLMedia {
+			return "https://cdn.example.com/?src=" + url.QueryEscape(u)
+		}
+		return "https://proxy.example.com/?u=" + url.QueryEscape(u)
+	})
+	assert.Equal(t, []string{"Impression"}, kinds[URLImpression])
+	assert.Equal(t, []string{"MediaFile"}, kinds[URLMedia])
+	assert.Equal(t, []string{"JavaScriptResource", "ExecutableResource"}, kinds[URLVerification])
+	assert.Contains(t, kinds[URLTracking], "Viewable")
+	assert.Contains(t, kinds[URLTracking], "Tracking")
+
+	in := v.Ads[0].InLine
+	assert.Equal(t, URI("https://proxy.example.com/?u=http%3A%2F%2Fexample.com%2Ftrack%2Fimpression"), in.Impressions[0].URI)
+	assert.Equal(t, URI("https://cdn.example.com/?src=https%3A%2F%2Fiabtechlab.com%2Fwp-content%2Fuploads%2F2016%2F07%2FVAST-4.0-Short-Intro.mp4"),
+		in.Creatives[0].Linear.MediaFiles[0].URI)
+	assert.Equal(t, "https://proxy.example.com/?u=http%3A%2F%2Fexample.com%2Fviewable", string(in.ViewableImpression.Viewable[0].URI))
+}
+
+func TestRewriteURLsFields(t *testing.T) {
+	v := &VAST{
+		Errors: []CDATAURI{{URI: "http://t/root-error"}},
+		Ads: []Ad{{Wrapper: &Wrapper{
+			VASTAdTagURI: CDATAURI{URI: " http://t/tag "},
+			Impressions:  []Impression{{URI: ""}},
+		}}},
+	}
+	var fields []URLField
+	RewriteURLs(v, func(f URLField, u string) string {
+		fields = append(fields, f)
+		return u + "#"
+	})
+	assert.Equal(t, []URLField{
+		{Path: "VAST.Ad[0].Wrapper.VASTAdTagURI", Element: "VASTAdTagURI", Kind: URLAdTag},
+		{Path: "VAST.Error[0]", Element: "Error", Kind: URLError},
+	}, fields)
+	assert.Equal(t, "http://t/tag#", string(v.Ads[0].Wrapper.VASTAdTagURI.URI))
+	assert.Equal(t, URI(""), v.Ads[0].Wrapper.Impressions[0].URI)
+	assert.Equal(t, "ad-tag", URLAdTag.String())
+}
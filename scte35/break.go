@@ -0,0 +1,137 @@
+// Package scte35 bridges SCTE-35 splice signaling and VAST: it plans the ad
+// requests filling a signaled ad break and trims the returned pods to fit it.
+//
+// The package works on the fields of a decoded splice_insert command, and
+// doesn't decode SCTE-35 sections itself.
+package scte35
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/macro"
+)
+
+// TicksPerSecond is the rate of the 90kHz clock of SCTE-35 times and
+// durations.
+const TicksPerSecond = 90000
+
+// Errors returned by Plan
+var (
+	ErrNotOutOfNetwork = errors.New("scte35: splice insert doesn't start a break")
+	ErrNoBreakDuration = errors.New("scte35: splice insert has no break duration")
+)
+
+// SpliceInsert holds the fields of a splice_insert command describing an
+// ad break.
+type SpliceInsert struct {
+	// splice_event_id
+	EventID uint32
+	// out_of_network_indicator, true when the break starts
+	OutOfNetwork bool
+	// break_duration, 0 if not signaled
+	BreakDuration time.Duration
+	// auto_return
+	AutoReturn bool
+	// unique_program_id
+	UniqueProgramID uint16
+	// avail_num and avails_expected
+	AvailNum, AvailsExpected uint8
+}
+
+// Duration converts a 90kHz tick count, such as the break_duration of a
+// splice insert, to a duration.
+func Duration(ticks uint64) time.Duration {
+	return time.Duration(ticks/TicksPerSecond)*time.Second +
+		time.Duration(ticks%TicksPerSecond)*time.Second/TicksPerSecond
+}
+
+// Ticks converts a duration to a 90kHz tick count, rounded to the nearest
+// tick.
+func Ticks(d time.Duration) uint64 {
+	if d <= 0 {
+		return 0
+	}
+	return uint64(d/time.Second)*TicksPerSecond + (uint64(d%time.Second)*TicksPerSecond+uint64(time.Second)/2)/uint64(time.Second)
+}
+
+// BreakID returns the identifier of the break, the splice event ID in
+// decimal.
+func (s SpliceInsert) BreakID() string {
+	return strconv.FormatUint(uint64(s.EventID), 10)
+}
+
+// PlanOptions configures the request plan of a break.
+type PlanOptions struct {
+	// The macro values known besides the break, copied in the plan
+	Context *macro.Context
+	// The allowed lengths of the ads, unbounded if 0
+	MinAdLength, MaxAdLength time.Duration
+	// The maximum number of ads in the break. If 0 and MinAdLength is set,
+	// as many ads of MinAdLength as fit in the break.
+	MaxAds int
+	// How much shorter than the break the pod may be
+	Tolerance time.Duration
+	// The position of the break in the content
+	Position macro.BreakPosition
+}
+
+// RequestPlan describes the ad request filling a break.
+type RequestPlan struct {
+	BreakID  string
+	Duration time.Duration
+	// The macro values of the request, with the break constraints set
+	Context *macro.Context
+}
+
+// Plan returns the request plan of the break started by s.
+func Plan(s SpliceInsert, opts PlanOptions) (*RequestPlan, error) {
+	if !s.OutOfNetwork {
+		return nil, ErrNotOutOfNetwork
+	}
+	if s.BreakDuration <= 0 {
+		return nil, ErrNoBreakDuration
+	}
+	c := &macro.Context{}
+	if opts.Context != nil {
+		*c = *opts.Context
+	}
+	c.BreakPosition = opts.Position
+	c.BreakMaxDuration = s.BreakDuration
+	if opts.Tolerance > 0 && opts.Tolerance < s.BreakDuration {
+		c.BreakMinDuration = s.BreakDuration - opts.Tolerance
+	}
+	c.BreakMinAdLength = opts.MinAdLength
+	c.BreakMaxAdLength = opts.MaxAdLength
+	c.BreakMaxAds = opts.MaxAds
+	if c.BreakMaxAds == 0 && opts.MinAdLength > 0 {
+		c.BreakMaxAds = int(s.BreakDuration / opts.MinAdLength)
+	}
+	return &RequestPlan{BreakID: s.BreakID(), Duration: s.BreakDuration, Context: c}, nil
+}
+
+// URL returns the ad tag URL with the macros of the plan expanded.
+func (p *RequestPlan) URL(ctx context.Context, tag string) string {
+	return p.Context.Expand(ctx, tag)
+}
+
+// Trim returns a copy of doc keeping the ads fitting in the break, as done
+// by vast.FitPod on the pod followed by the stand-alone ads, and how much of
+// the break remains to fill, such as with a slate. The kept ads form a pod
+// sequenced from 1 in the order they were selected.
+func Trim(doc *vast.VAST, s SpliceInsert) (*vast.VAST, time.Duration) {
+	out := doc.Clone()
+	pod, standalone := out.SplitPod()
+	kept, _ := vast.FitPod(append(pod, standalone...), s.BreakDuration)
+	ads := make([]vast.Ad, 0, len(kept))
+	for i, a := range kept {
+		ad := *a
+		ad.Sequence = i + 1
+		ads = append(ads, ad)
+	}
+	out.Ads = ads
+	return out, s.BreakDuration - vast.PodDuration(kept)
+}
@@ -0,0 +1,66 @@
+package scte35
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/macro"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTicks(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Duration(2700000))
+	assert.Equal(t, 1001*time.Millisecond/30, Duration(3003))
+	assert.Equal(t, uint64(2700000), Ticks(30*time.Second))
+	assert.Equal(t, uint64(3003), Ticks(Duration(3003)))
+	assert.Equal(t, uint64(0), Ticks(-time.Second))
+}
+
+func TestPlan(t *testing.T) {
+	s := SpliceInsert{EventID: 1207959695, OutOfNetwork: true, BreakDuration: Duration(5400000)}
+	plan, err := Plan(s, PlanOptions{
+		Context:     &macro.Context{CacheBusting: "42"},
+		MinAdLength: 15 * time.Second,
+		MaxAdLength: 30 * time.Second,
+		Tolerance:   5 * time.Second,
+		Position:    macro.BreakPositionMidroll,
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1207959695", plan.BreakID)
+		assert.Equal(t, time.Minute, plan.Duration)
+		assert.Equal(t, "https://ads/vast?max=60&min=55&ads=4&adlen=15-30&pos=2&cb=42",
+			plan.URL(context.Background(), "https://ads/vast?max=[BREAKMAXDURATION]&min=[BREAKMINDURATION]&ads=[BREAKMAXADS]"+
+				"&adlen=[BREAKMINADLENGTH]-[BREAKMAXADLENGTH]&pos=[BREAKPOSITION]&cb=[CACHEBUSTING]"))
+	}
+
+	_, err = Plan(SpliceInsert{BreakDuration: time.Minute}, PlanOptions{})
+	assert.Equal(t, ErrNotOutOfNetwork, err)
+	_, err = Plan(SpliceInsert{OutOfNetwork: true}, PlanOptions{})
+	assert.Equal(t, ErrNoBreakDuration, err)
+}
+
+func TestTrim(t *testing.T) {
+	ad := func(id string, seq int, d time.Duration) vast.Ad {
+		return vast.Ad{ID: id, Sequence: seq, InLine: &vast.InLine{Creatives: []vast.Creative{
+			{Linear: &vast.Linear{Duration: vast.Duration(d)}},
+		}}}
+	}
+	doc := &vast.VAST{Version: "4.1", Ads: []vast.Ad{
+		ad("standalone", 0, 10*time.Second),
+		ad("second", 2, 30*time.Second),
+		ad("first", 1, 15*time.Second),
+		ad("third", 3, 30*time.Second),
+	}}
+	out, remaining := Trim(doc, SpliceInsert{OutOfNetwork: true, BreakDuration: time.Minute})
+	assert.Equal(t, 5*time.Second, remaining)
+	if assert.Len(t, out.Ads, 3) {
+		assert.Equal(t, "first", out.Ads[0].ID)
+		assert.Equal(t, "second", out.Ads[1].ID)
+		assert.Equal(t, "standalone", out.Ads[2].ID)
+		assert.Equal(t, 3, out.Ads[2].Sequence)
+	}
+	assert.Equal(t, 0, doc.Ads[0].Sequence)
+	assert.Len(t, doc.Ads, 4)
+}
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"container/list"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// Cache caches the documents of a source keyed by the normalized parameters
+// of the requests, so that popular placements are generated or resolved once
+// per caching period. Concurrent requests for a key missing from the cache
+// share a single call to the source.
+//
+// Its Get method is a Source:
+//
+//	cache := server.NewCache(source, time.Minute)
+//	cache.Params = []string{"placement", "w", "h"}
+//	handler := server.New(cache.Get)
+//
+// Params should be set in production: keyed by every query parameter, the
+// cache holds a document per distinct query clients send, up to MaxEntries.
+type Cache struct {
+	Source Source
+	// Longest time a document is cached. InLine ads expiring sooner shorten
+	// it. Documents are only cached for their Expires if 0.
+	MaxAge time.Duration
+	// Maximum number of documents cached, DefaultCacheMaxEntries if 0. The
+	// least recently used one is evicted to make room for a new one.
+	MaxEntries int
+	// Query parameters making up the key, every parameter if empty
+	Params []string
+	// Query parameters left out of the key, such as cache busters
+	Ignore []string
+	// Returns the key of the request, replacing the normalized parameters
+	Key func(r *http.Request) string
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// The entries, most recently used first
+	lru   *list.List
+	swept time.Time
+	calls map[string]*cacheCall
+	now   func() time.Time
+}
+
+// DefaultCacheMaxEntries is the number of documents a Cache holds at most
+// when MaxEntries is 0.
+const DefaultCacheMaxEntries = 10000
+
+// cacheSweepInterval is how often Get drops the expired documents.
+const cacheSweepInterval = time.Minute
+
+type cacheEntry struct {
+	key     string
+	doc     *vast.VAST
+	expires time.Time
+}
+
+type cacheCall struct {
+	done chan struct{}
+	doc  *vast.VAST
+	err  error
+}
+
+// NewCache returns a cache of the documents of source, cached for at most
+// maxAge.
+func NewCache(source Source, maxAge time.Duration) *Cache {
+	return &Cache{Source: source, MaxAge: maxAge}
+}
+
+// Get returns a copy of the document cached for the request, calling the
+// source if there is none. Errors are not cached, while the absence of
+// document is cached for MaxAge. The expired documents are dropped every
+// minute.
+//
+// The requests sharing a call to the source get its result, including the
+// errors caused by the cancellation of the request that made the call.
+func (c *Cache) Get(r *http.Request) (*vast.VAST, error) {
+	key := c.key(r)
+	c.mu.Lock()
+	now := c.clock()
+	if now.Sub(c.swept) >= cacheSweepInterval {
+		c.purge(now)
+	}
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*cacheEntry)
+		if now.Before(e.expires) {
+			c.lru.MoveToFront(el)
+			c.mu.Unlock()
+			return clone(e.doc), nil
+		}
+		c.remove(el)
+	}
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return clone(call.doc), call.err
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	if c.calls == nil {
+		c.calls = map[string]*cacheCall{}
+	}
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	call.doc, call.err = c.Source(r)
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	if call.err == nil {
+		if ttl := c.ttl(call.doc); ttl > 0 {
+			c.add(&cacheEntry{key: key, doc: call.doc, expires: c.clock().Add(ttl)})
+		}
+	}
+	c.mu.Unlock()
+	close(call.done)
+	return clone(call.doc), call.err
+}
+
+// add caches e, replacing the entry of its key if any, and evicts the least
+// recently used entries beyond MaxEntries.
+func (c *Cache) add(e *cacheEntry) {
+	if c.entries == nil {
+		c.entries, c.lru = map[string]*list.Element{}, list.New()
+	}
+	if el, ok := c.entries[e.key]; ok {
+		c.remove(el)
+	}
+	c.entries[e.key] = c.lru.PushFront(e)
+	max := c.MaxEntries
+	if max <= 0 {
+		max = DefaultCacheMaxEntries
+	}
+	for c.lru.Len() > max {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *Cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).key)
+}
+
+// Purge drops the expired documents.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	c.purge(c.clock())
+	c.mu.Unlock()
+}
+
+func (c *Cache) purge(now time.Time) {
+	c.swept = now
+	for _, el := range c.entries {
+		if !now.Before(el.Value.(*cacheEntry).expires) {
+			c.remove(el)
+		}
+	}
+}
+
+// Len returns the number of cached documents, expired ones included.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// key returns the cache key of the request: its path followed by its query
+// parameters sorted by name.
+func (c *Cache) key(r *http.Request) string {
+	if c.Key != nil {
+		return c.Key(r)
+	}
+	query := r.URL.Query()
+	params := url.Values{}
+	if len(c.Params) > 0 {
+		for _, p := range c.Params {
+			if v, ok := query[p]; ok {
+				params[p] = v
+			}
+		}
+	} else {
+		for p, v := range query {
+			params[p] = v
+		}
+	}
+	for _, p := range c.Ignore {
+		delete(params, p)
+	}
+	return r.URL.Path + "?" + params.Encode()
+}
+
+// ttl returns how long doc is cached: MaxAge, shortened by the Expires
+// elements of its InLine ads.
+func (c *Cache) ttl(doc *vast.VAST) time.Duration {
+	ttl := c.MaxAge
+	if doc == nil {
+		return ttl
+	}
+	for _, ad := range doc.Ads {
+		if ad.InLine == nil || ad.InLine.Expires == nil {
+			continue
+		}
+		if d := ad.InLine.Expires.Duration(); ttl == 0 || d < ttl {
+			ttl = d
+		}
+	}
+	return ttl
+}
+
+func (c *Cache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func clone(doc *vast.VAST) *vast.VAST {
+	if doc == nil {
+		return nil
+	}
+	return doc.Clone()
+}
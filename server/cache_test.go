@@ -0,0 +1,141 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	doc, err := vast.Skeleton(vast.Version4_1, vast.AdKindInLineLinear)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var calls int32
+	c := NewCache(func(r *http.Request) (*vast.VAST, error) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Query().Get("fail") != "" {
+			return nil, errors.New("boom")
+		}
+		if r.URL.Query().Get("nofill") != "" {
+			return nil, nil
+		}
+		return doc, nil
+	}, time.Minute)
+	c.Ignore = []string{"cb"}
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	get := func(target string) (*vast.VAST, error) {
+		return c.Get(httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	got, err := get("/vast?w=640&h=360&cb=1")
+	assert.NoError(t, err)
+	assert.Equal(t, doc, got)
+	got.Ads = nil
+	got, _ = get("/vast?cb=2&h=360&w=640")
+	assert.Len(t, got.Ads, 1)
+	assert.Equal(t, int32(1), calls)
+	_, _ = get("/vast?w=1280&h=720")
+	assert.Equal(t, int32(2), calls)
+
+	// errors are not cached, missing documents are
+	_, err = get("/vast?fail=1")
+	assert.Error(t, err)
+	_, err = get("/vast?fail=1")
+	assert.Error(t, err)
+	assert.Equal(t, int32(4), calls)
+	got, err = get("/vast?nofill=1")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+	_, _ = get("/vast?nofill=1")
+	assert.Equal(t, int32(5), calls)
+	assert.Equal(t, 3, c.Len())
+
+	now = now.Add(time.Minute)
+	_, _ = get("/vast?w=640&h=360")
+	assert.Equal(t, int32(6), calls)
+	c.Purge()
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestCacheExpires(t *testing.T) {
+	doc, _ := vast.Skeleton(vast.Version4_1, vast.AdKindInLineLinear)
+	doc.Ads[0].InLine.Expires = vast.NewExpires(10 * time.Second)
+	c := NewCache(Static(doc), time.Minute)
+	c.Params = []string{"placement"}
+	assert.Equal(t, "/vast?placement=a", c.key(httptest.NewRequest(http.MethodGet, "/vast?w=640&placement=a", nil)))
+	assert.Equal(t, 10*time.Second, c.ttl(doc))
+	c.MaxAge = time.Second
+	assert.Equal(t, time.Second, c.ttl(doc))
+	c.MaxAge = 0
+	assert.Equal(t, 10*time.Second, c.ttl(doc))
+	assert.Equal(t, time.Duration(0), c.ttl(&vast.VAST{}))
+}
+
+func TestCacheSingleflight(t *testing.T) {
+	doc, _ := vast.Skeleton(vast.Version4_1, vast.AdKindInLineLinear)
+	var calls int32
+	release := make(chan struct{})
+	c := NewCache(func(r *http.Request) (*vast.VAST, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return doc, nil
+	}, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := c.Get(httptest.NewRequest(http.MethodGet, "/vast", nil))
+			assert.NoError(t, err)
+			assert.Len(t, got.Ads, 1)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestCacheMaxEntries(t *testing.T) {
+	doc, _ := vast.Skeleton(vast.Version4_1, vast.AdKindInLineLinear)
+	var calls int32
+	c := NewCache(func(r *http.Request) (*vast.VAST, error) {
+		atomic.AddInt32(&calls, 1)
+		return doc, nil
+	}, time.Hour)
+	c.MaxEntries = 2
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	get := func(target string) {
+		_, err := c.Get(httptest.NewRequest(http.MethodGet, target, nil))
+		assert.NoError(t, err)
+	}
+
+	get("/vast?x=1")
+	get("/vast?x=2")
+	get("/vast?x=1")
+	get("/vast?x=3") // evicts x=2, the least recently used
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, int32(3), calls)
+	get("/vast?x=1")
+	assert.Equal(t, int32(3), calls)
+	get("/vast?x=2")
+	assert.Equal(t, int32(4), calls)
+	assert.Equal(t, 2, c.Len())
+
+	// expired documents are swept by Get
+	c.MaxAge = time.Second
+	now = now.Add(2 * time.Hour)
+	get("/vast?x=4")
+	assert.Equal(t, 1, c.Len())
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// Errors returned by TrackingProxy
+var (
+	ErrInvalidToken = errors.New("server: invalid tracking token")
+	ErrExpiredToken = errors.New("server: expired tracking token")
+	// The proxy has no key: anyone could sign tokens, turning the endpoint
+	// into an open redirect
+	ErrNoKey = errors.New("server: tracking proxy without key")
+)
+
+// tokenParam is the query parameter of the proxied URLs holding the token,
+// and macroPrefix the prefix of the parameters holding the macros of the
+// original URL.
+const (
+	tokenParam  = "t"
+	macroPrefix = "m."
+)
+
+// macroPattern matches the macros of a URL, escaped or not.
+var macroPattern = regexp.MustCompile(`\[([A-Za-z0-9_]+)\]|%5[Bb]([A-Za-z0-9_]+)%5[Dd]`)
+
+// Beacon is a tracker or a click routed through a TrackingProxy.
+type Beacon struct {
+	// The original URL, with the macros expanded by the player
+	URL string
+	// The kind of the URL
+	Kind vast.URLKind
+	// Path of the element holding the URL in the document, such as
+	// "VAST.Ad[0].InLine.Impression[0]"
+	Path string
+	// When the URL was wrapped
+	Issued time.Time
+}
+
+// TrackingProxy routes the trackers and clicks of documents through a
+// publisher-owned endpoint, serving first-party beacon collection. Wrap
+// rewrites the URLs of a document to point to the endpoint, with the
+// original URL and its metadata in a signed token, and the proxy, as an
+// http.Handler serving the endpoint, decodes the token, reports the beacon
+// and redirects the player to the original URL.
+//
+// The macros of the original URLs are copied as query parameters of the
+// proxied URLs so the player still expands them.
+type TrackingProxy struct {
+	// The URL of the endpoint serving the proxy
+	Endpoint string
+	// The key signing the tokens, required
+	Key []byte
+	// How long the proxied URLs are valid, forever if 0
+	TTL time.Duration
+	// The kinds of URLs routed through the proxy, impressions, trackers,
+	// clicks and error URLs if empty
+	Kinds []vast.URLKind
+	// Called with every decoded beacon
+	OnBeacon func(r *http.Request, b *Beacon)
+	// If true, trackers are answered with 204 No Content rather than
+	// redirected to their original URL, leaving it to OnBeacon to forward
+	// them. Clicks are always redirected.
+	NoRedirect bool
+}
+
+// token is the signed content of a proxied URL.
+type token struct {
+	URL    string       `json:"u"`
+	Kind   vast.URLKind `json:"k"`
+	Path   string       `json:"p,omitempty"`
+	Issued int64        `json:"i"`
+}
+
+func (p *TrackingProxy) proxied(kind vast.URLKind) bool {
+	if len(p.Kinds) == 0 {
+		switch kind {
+		case vast.URLImpression, vast.URLTracking, vast.URLClickThrough, vast.URLClickTracking, vast.URLError:
+			return true
+		}
+		return false
+	}
+	for _, k := range p.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Wrap returns a copy of doc with its URLs routed through the proxy. It
+// returns ErrNoKey if the proxy has no key.
+func (p *TrackingProxy) Wrap(doc *vast.VAST) (*vast.VAST, error) {
+	if len(p.Key) == 0 {
+		return nil, ErrNoKey
+	}
+	out := doc.Clone()
+	now := time.Now()
+	vast.RewriteURLs(out, func(f vast.URLField, u string) string {
+		if !p.proxied(f.Kind) {
+			return u
+		}
+		return p.url(token{URL: u, Kind: f.Kind, Path: f.Path, Issued: now.Unix()})
+	})
+	return out, nil
+}
+
+// URL returns the proxied URL of u, of the given kind. It returns ErrNoKey if
+// the proxy has no key.
+func (p *TrackingProxy) URL(kind vast.URLKind, u string) (string, error) {
+	if len(p.Key) == 0 {
+		return "", ErrNoKey
+	}
+	return p.url(token{URL: u, Kind: kind, Issued: time.Now().Unix()}), nil
+}
+
+func (p *TrackingProxy) url(t token) string {
+	payload, _ := json.Marshal(t)
+	var sb strings.Builder
+	sb.WriteString(p.Endpoint)
+	if strings.Contains(p.Endpoint, "?") {
+		sb.WriteByte('&')
+	} else {
+		sb.WriteByte('?')
+	}
+	sb.WriteString(tokenParam + "=")
+	sb.WriteString(base64.RawURLEncoding.EncodeToString(payload))
+	sb.WriteByte('.')
+	sb.WriteString(base64.RawURLEncoding.EncodeToString(p.sign(payload)))
+	seen := map[string]bool{}
+	for _, m := range macroPattern.FindAllStringSubmatch(t.URL, -1) {
+		name := strings.ToUpper(m[1] + m[2])
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		sb.WriteString("&" + macroPrefix + name + "=[" + name + "]")
+	}
+	return sb.String()
+}
+
+func (p *TrackingProxy) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.Key)
+	mac.Write(payload)
+	return mac.Sum(nil)[:16]
+}
+
+// Decode returns the beacon of a request to the endpoint, with the macros of
+// the original URL replaced by the values expanded by the player. It returns
+// ErrNoKey if the proxy has no key.
+func (p *TrackingProxy) Decode(r *http.Request) (*Beacon, error) {
+	if len(p.Key) == 0 {
+		return nil, ErrNoKey
+	}
+	query := r.URL.Query()
+	parts := strings.SplitN(query.Get(tokenParam), ".", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(sig, p.sign(payload)) {
+		return nil, ErrInvalidToken
+	}
+	var t token
+	if err := json.Unmarshal(payload, &t); err != nil {
+		return nil, ErrInvalidToken
+	}
+	issued := time.Unix(t.Issued, 0)
+	if p.TTL > 0 && time.Since(issued) > p.TTL {
+		return nil, ErrExpiredToken
+	}
+	u := macroPattern.ReplaceAllStringFunc(t.URL, func(m string) string {
+		sub := macroPattern.FindStringSubmatch(m)
+		values, ok := query[macroPrefix+strings.ToUpper(sub[1]+sub[2])]
+		if !ok || len(values) == 0 || macroPattern.MatchString(values[0]) {
+			// not expanded by the player
+			return m
+		}
+		return url.QueryEscape(values[0])
+	})
+	return &Beacon{URL: u, Kind: t.Kind, Path: t.Path, Issued: issued}, nil
+}
+
+// ServeHTTP implements the http.Handler interface, serving the endpoint of
+// the proxy.
+func (p *TrackingProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, err := p.Decode(r)
+	if err == ErrNoKey {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if p.OnBeacon != nil {
+		p.OnBeacon(r, b)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	if p.NoRedirect && b.Kind != vast.URLClickThrough {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	http.Redirect(w, r, b.URL, http.StatusFound)
+}
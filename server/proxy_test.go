@@ -0,0 +1,105 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackingProxy(t *testing.T) {
+	doc, err := vast.Skeleton(vast.Version4_1, vast.AdKindInLineLinear)
+	if !assert.NoError(t, err) {
+		return
+	}
+	in := doc.Ads[0].InLine
+	in.Impressions[0].URI = "https://track.example.com/imp?cb=[CACHEBUSTING]&ts=%5BTIMESTAMP%5D"
+	in.Creatives[0].Linear.VideoClicks = &vast.VideoClicks{ClickThroughs: []vast.VideoClick{{URI: "https://advertiser.example.com/"}}}
+
+	var beacons []*Beacon
+	p := &TrackingProxy{
+		Endpoint: "https://pub.example.com/b",
+		Key:      []byte("secret"),
+		OnBeacon: func(r *http.Request, b *Beacon) { beacons = append(beacons, b) },
+	}
+	wrapped, err := p.Wrap(doc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, vast.URI("https://track.example.com/imp?cb=[CACHEBUSTING]&ts=%5BTIMESTAMP%5D"), in.Impressions[0].URI)
+	imp := string(wrapped.Ads[0].InLine.Impressions[0].URI)
+	assert.True(t, strings.HasPrefix(imp, "https://pub.example.com/b?t="))
+	assert.True(t, strings.HasSuffix(imp, "&m.CACHEBUSTING=[CACHEBUSTING]&m.TIMESTAMP=[TIMESTAMP]"))
+	assert.Equal(t, vast.URI("https://example.com/ad.mp4"), wrapped.Ads[0].InLine.Creatives[0].Linear.MediaFiles[0].URI)
+
+	// the player expands the macros of the proxied URL
+	expanded := strings.NewReplacer("[CACHEBUSTING]", "1234", "[TIMESTAMP]", url.QueryEscape("2020-01-01T00:00:00.000Z")).Replace(imp)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, expanded, nil))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://track.example.com/imp?cb=1234&ts=2020-01-01T00%3A00%3A00.000Z", w.Header().Get("Location"))
+	if assert.Len(t, beacons, 1) {
+		assert.Equal(t, vast.URLImpression, beacons[0].Kind)
+		assert.Equal(t, "VAST.Ad[0].InLine.Impression[0]", beacons[0].Path)
+	}
+
+	p.NoRedirect = true
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, imp, nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://track.example.com/imp?cb=[CACHEBUSTING]&ts=%5BTIMESTAMP%5D", beacons[1].URL)
+
+	click := string(wrapped.Ads[0].InLine.Creatives[0].Linear.VideoClicks.ClickThroughs[0].URI)
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, click, nil))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://advertiser.example.com/", w.Header().Get("Location"))
+
+	// tampered or expired tokens are rejected
+	_, err = p.Decode(httptest.NewRequest(http.MethodGet, strings.Replace(imp, "t=e", "t=f", 1), nil))
+	assert.Equal(t, ErrInvalidToken, err)
+	_, err = (&TrackingProxy{Key: []byte("other")}).Decode(httptest.NewRequest(http.MethodGet, imp, nil))
+	assert.Equal(t, ErrInvalidToken, err)
+	old := p.url(token{URL: "https://track.example.com/", Kind: vast.URLTracking, Issued: time.Now().Add(-time.Hour).Unix()})
+	p.TTL = time.Minute
+	_, err = p.Decode(httptest.NewRequest(http.MethodGet, old, nil))
+	assert.Equal(t, ErrExpiredToken, err)
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/b", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTrackingProxyWithoutKey(t *testing.T) {
+	doc, err := vast.Skeleton(vast.Version4_1, vast.AdKindInLineLinear)
+	if !assert.NoError(t, err) {
+		return
+	}
+	p := &TrackingProxy{Endpoint: "https://pub.example.com/b"}
+	_, err = p.Wrap(doc)
+	assert.Equal(t, ErrNoKey, err)
+	_, err = p.URL(vast.URLClickThrough, "https://advertiser.example.com/")
+	assert.Equal(t, ErrNoKey, err)
+
+	// a token signed with an empty key is refused
+	forged := (&TrackingProxy{Endpoint: p.Endpoint}).url(token{URL: "https://evil.example.com/", Kind: vast.URLClickThrough})
+	_, err = p.Decode(httptest.NewRequest(http.MethodGet, forged, nil))
+	assert.Equal(t, ErrNoKey, err)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, forged, nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Location"))
+
+	p.Key = []byte("secret")
+	u, err := p.URL(vast.URLClickThrough, "https://advertiser.example.com/")
+	if assert.NoError(t, err) {
+		b, err := p.Decode(httptest.NewRequest(http.MethodGet, u, nil))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "https://advertiser.example.com/", b.URL)
+		}
+	}
+}
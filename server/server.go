@@ -0,0 +1,186 @@
+// Package server serves VAST documents over HTTP to video players, including
+// browser-based players requesting ad tags cross-origin.
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// ContentType is the content type of the served documents.
+const ContentType = "application/xml; charset=utf-8"
+
+// gzipMinSize is the size of the smallest document compressed by the handler.
+const gzipMinSize = 512
+
+// Source returns the document served for a request. A nil document is
+// served as a vast.EmptyResponse.
+type Source func(r *http.Request) (*vast.VAST, error)
+
+// Static returns a source always serving doc.
+func Static(doc *vast.VAST) Source {
+	return func(*http.Request) (*vast.VAST, error) {
+		return doc, nil
+	}
+}
+
+// Template returns a source rendering t with the data returned by params for
+// the request.
+func Template(t *vast.TemplateAd, params func(r *http.Request) (interface{}, error)) Source {
+	return func(r *http.Request) (*vast.VAST, error) {
+		data, err := params(r)
+		if err != nil {
+			return nil, err
+		}
+		return t.Render(data)
+	}
+}
+
+// Handler is an http.Handler serving the documents of a source.
+type Handler struct {
+	Source Source
+	// How long the responses may be cached by the player. Responses are not
+	// cacheable if 0.
+	MaxAge time.Duration
+	// Origins allowed to read the responses cross-origin, with credentials,
+	// such as "https://player.example.com". Every origin is allowed if
+	// empty, without credentials.
+	AllowedOrigins []string
+	// Version of the document served when the source returns none, 4.2 if
+	// empty
+	EmptyVersion vast.SpecVersion
+	// Error tracking URIs of the document served when the source returns
+	// none
+	ErrorURLs []string
+	// Called with the errors of the source, which are served as a 500
+	// response
+	OnError func(r *http.Request, err error)
+}
+
+// New returns a handler serving the documents of source.
+func New(source Source) *Handler {
+	return &Handler{Source: source}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	h.cors(header, r)
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodOptions:
+		header.Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+		if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+			header.Set("Access-Control-Allow-Headers", headers)
+		}
+		header.Set("Access-Control-Max-Age", "86400")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		header.Set("Allow", "GET, HEAD, OPTIONS")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	doc, err := h.Source(r)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	if doc == nil {
+		version := h.EmptyVersion
+		if version == "" {
+			version = vast.Version4_2
+		}
+		doc = vast.EmptyResponse(version, h.ErrorURLs...)
+	}
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	body = append([]byte(xml.Header), body...)
+
+	header.Set("Content-Type", ContentType)
+	if h.MaxAge > 0 {
+		header.Set("Cache-Control", "private, max-age="+strconv.Itoa(int(h.MaxAge/time.Second)))
+	} else {
+		header.Set("Cache-Control", "no-store")
+	}
+	header.Add("Vary", "Accept-Encoding")
+	if len(body) >= gzipMinSize && acceptsGzip(r) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err == nil && zw.Close() == nil {
+			header.Set("Content-Encoding", "gzip")
+			body = buf.Bytes()
+		}
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if h.OnError != nil {
+		h.OnError(r, err)
+	}
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// cors sets the CORS headers of the response. The origins listed in
+// AllowedOrigins are echoed, rather than "*", with credentials allowed, as
+// players send the ad requests with credentials to carry the cookies of the
+// ad server. Without AllowedOrigins, any origin may read the responses, but
+// not with credentials, which would let any site read them on behalf of the
+// users.
+func (h *Handler) cors(header http.Header, r *http.Request) {
+	if len(h.AllowedOrigins) == 0 {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	header.Add("Vary", "Origin")
+	origin := r.Header.Get("Origin")
+	if origin == "" || !h.allowed(origin) {
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	header.Set("Access-Control-Allow-Credentials", "true")
+}
+
+func (h *Handler) allowed(origin string) bool {
+	for _, o := range h.AllowedOrigins {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip returns true if the request accepts gzip encoded responses.
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		params := strings.Split(enc, ";")
+		if strings.TrimSpace(params[0]) != "gzip" {
+			continue
+		}
+		for _, p := range params[1:] {
+			if q := strings.TrimSpace(p); strings.HasPrefix(q, "q=") {
+				if v, err := strconv.ParseFloat(q[2:], 64); err == nil && v == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
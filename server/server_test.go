@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(h http.Handler, method, target string, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	doc, err := vast.Skeleton(vast.Version4_1, vast.AdKindInLineLinear)
+	if !assert.NoError(t, err) {
+		return
+	}
+	h := New(Static(doc))
+	h.MaxAge = time.Minute
+
+	w := serve(h, http.MethodGet, "/vast", map[string]string{"Origin": "https://player.example.com"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+	// any origin, without credentials
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, []string{"Accept-Encoding"}, w.Header()["Vary"])
+	assert.True(t, strings.HasPrefix(w.Body.String(), xml.Header+`<VAST version="4.1">`))
+	var got vast.VAST
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got.Ads, 1)
+
+	w = serve(h, http.MethodGet, "/vast", map[string]string{"Accept-Encoding": "br, gzip"})
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	zr, err := gzip.NewReader(w.Body)
+	if assert.NoError(t, err) {
+		body, err := ioutil.ReadAll(zr)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Contains(body, []byte("https://example.com/ad.mp4")))
+	}
+	w = serve(h, http.MethodGet, "/vast", map[string]string{"Accept-Encoding": "gzip;q=0"})
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+
+	w = serve(h, http.MethodHead, "/vast", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Content-Length"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestHandlerCORS(t *testing.T) {
+	h := &Handler{Source: Static(nil), AllowedOrigins: []string{"https://player.example.com"}, ErrorURLs: []string{"https://pub.example.com/noad"}}
+
+	w := serve(h, http.MethodOptions, "/vast", map[string]string{
+		"Origin":                         "https://player.example.com",
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "X-Player-Version",
+	})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://player.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, []string{"Origin"}, w.Header()["Vary"])
+	assert.Equal(t, "GET, HEAD, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "X-Player-Version", w.Header().Get("Access-Control-Allow-Headers"))
+
+	w = serve(h, http.MethodGet, "/vast", map[string]string{"Origin": "https://evil.example.com"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, xml.Header+`<VAST version="4.2"><Error><![CDATA[https://pub.example.com/noad]]></Error></VAST>`, w.Body.String())
+
+	w = serve(h, http.MethodPost, "/vast", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS", w.Header().Get("Allow"))
+}
+
+func TestHandlerTemplate(t *testing.T) {
+	tmpl, err := vast.NewTemplateAd("ad", `<VAST version="3.0"><Ad id="{{.}}"><InLine><AdTitle>t</AdTitle></InLine></Ad></VAST>`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var logged error
+	h := New(Template(tmpl, func(r *http.Request) (interface{}, error) {
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			return nil, errors.New("missing id")
+		}
+		return id, nil
+	}))
+	h.OnError = func(r *http.Request, err error) { logged = err }
+
+	w := serve(h, http.MethodGet, "/vast?id=42", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<Ad id="42">`)
+
+	w = serve(h, http.MethodGet, "/vast", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.EqualError(t, logged, "missing id")
+}
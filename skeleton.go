@@ -0,0 +1,96 @@
+package vast
+
+import (
+	"fmt"
+	"time"
+)
+
+// AdKind is the shape of the ad generated by Skeleton.
+type AdKind int
+
+// Ad kinds supported by Skeleton
+const (
+	// An InLine ad with a linear video creative
+	AdKindInLineLinear AdKind = iota
+	// A Wrapper ad pointing to another ad server
+	AdKindWrapper
+	// An InLine ad with a non-linear overlay creative
+	AdKindNonLinear
+	// An InLine ad with a linear audio creative
+	AdKindAudio
+)
+
+// String implements the fmt.Stringer interface.
+func (k AdKind) String() string {
+	switch k {
+	case AdKindInLineLinear:
+		return "inline-linear"
+	case AdKindWrapper:
+		return "wrapper"
+	case AdKindNonLinear:
+		return "nonlinear"
+	case AdKindAudio:
+		return "audio"
+	}
+	return fmt.Sprintf("AdKind(%d)", int(k))
+}
+
+// Skeleton returns the smallest document of the given kind passing validation
+// for the given version. URLs are placeholders under https://example.com.
+func Skeleton(version SpecVersion, kind AdKind) (*VAST, error) {
+	if !version.Valid() {
+		return nil, fmt.Errorf("skeleton: unknown version %q", version)
+	}
+	ad := Ad{}
+	imps := []Impression{{URI: "https://example.com/impression"}}
+	if kind == AdKindWrapper {
+		ad.Wrapper = &Wrapper{
+			AdSystem:     &AdSystem{Name: DefaultAdSystem},
+			Impressions:  imps,
+			VASTAdTagURI: CDATAURI{URI: "https://example.com/vast.xml"},
+		}
+		return &VAST{Version: string(version), Ads: []Ad{ad}}, nil
+	}
+
+	creative := Creative{}
+	switch kind {
+	case AdKindInLineLinear:
+		creative.Linear = &Linear{
+			Duration: Duration(15 * time.Second),
+			MediaFiles: []MediaFile{
+				{Delivery: DeliveryProgressive, Type: "video/mp4", Width: 640, Height: 360, URI: "https://example.com/ad.mp4"},
+			},
+		}
+	case AdKindNonLinear:
+		creative.NonLinearAds = &NonLinearAds{
+			NonLinears: []NonLinear{
+				{Width: 300, Height: 50, StaticResource: &StaticResource{CreativeType: "image/png", URI: "https://example.com/overlay.png"}},
+			},
+		}
+	case AdKindAudio:
+		creative.Linear = &Linear{
+			Duration: Duration(15 * time.Second),
+			MediaFiles: []MediaFile{
+				{Delivery: DeliveryProgressive, Type: "audio/mpeg", URI: "https://example.com/ad.mp3"},
+			},
+		}
+		if version.AtLeast(Version4_1) {
+			ad.AdType = AdTypeAudio
+		}
+	default:
+		return nil, fmt.Errorf("skeleton: unknown ad kind %v", kind)
+	}
+	if version.AtLeast(Version4_0) {
+		creative.UniversalAdID = &UniversalAdID{IDRegistry: "unknown", ID: "unknown"}
+	}
+	ad.InLine = &InLine{
+		AdSystem:    &AdSystem{Name: DefaultAdSystem},
+		AdTitle:     CDATAString{CDATA: "Skeleton"},
+		Impressions: imps,
+		Creatives:   []Creative{creative},
+	}
+	if version.AtLeast(Version4_1) {
+		ad.InLine.AdServingId = NewAdServingID(DefaultAdSystem)
+	}
+	return &VAST{Version: string(version), Ads: []Ad{ad}}, nil
+}
@@ -0,0 +1,52 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkeleton(t *testing.T) {
+	for _, version := range specVersions {
+		for _, kind := range []AdKind{AdKindInLineLinear, AdKindWrapper, AdKindNonLinear, AdKindAudio} {
+			t.Run(string(version)+"/"+kind.String(), func(t *testing.T) {
+				v, err := Skeleton(version, kind)
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.NoError(t, v.Validate())
+
+				// the skeleton survives a round trip and keeps its version
+				b, err := xml.Marshal(v)
+				if !assert.NoError(t, err) {
+					return
+				}
+				var parsed VAST
+				if assert.NoError(t, xml.Unmarshal(b, &parsed)) {
+					assert.Equal(t, version, parsed.InferredVersion)
+					assert.NoError(t, parsed.Validate())
+				}
+			})
+		}
+	}
+}
+
+func TestSkeletonAudio(t *testing.T) {
+	v, err := Skeleton(Version4_2, AdKindAudio)
+	if assert.NoError(t, err) {
+		assert.Equal(t, AdTypeAudio, v.Ads[0].AdType)
+		assert.Equal(t, "audio/mpeg", v.Ads[0].InLine.Creatives[0].Linear.MediaFiles[0].Type)
+	}
+	v, err = Skeleton(Version3_0, AdKindAudio)
+	if assert.NoError(t, err) {
+		assert.Equal(t, AdType(""), v.Ads[0].AdType)
+	}
+}
+
+func TestSkeletonErrors(t *testing.T) {
+	_, err := Skeleton("5.0", AdKindWrapper)
+	assert.EqualError(t, err, `skeleton: unknown version "5.0"`)
+	_, err = Skeleton(Version4_0, AdKind(42))
+	assert.EqualError(t, err, "skeleton: unknown ad kind AdKind(42)")
+}
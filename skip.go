@@ -0,0 +1,45 @@
+package vast
+
+import "time"
+
+// SkipInfo describes the skip behavior of a linear creative.
+type SkipInfo struct {
+	// Whether a skip control should be offered
+	Skippable bool
+	// Time after the start of the creative when the skip control should be
+	// offered, valid when Skippable is true
+	Offset time.Duration
+	// What is wrong with the skipoffset attribute, if anything. A creative
+	// with issues is not skippable.
+	Issues []string
+}
+
+// SkipInfo returns the skip behavior of the linear creative, resolving its
+// skipoffset attribute against its duration. A creative whose skip offset
+// isn't before the end of the creative is not skippable, as the control
+// would never be offered.
+func (l *Linear) SkipInfo() SkipInfo {
+	o := l.SkipOffset
+	if o == nil {
+		return SkipInfo{}
+	}
+	var info SkipInfo
+	switch {
+	case o.Duration == nil && (o.Percent < 0 || o.Percent > 1):
+		info.Issues = append(info.Issues, "skipoffset percentage must be between 0% and 100%")
+	case o.Duration == nil && l.Duration <= 0:
+		info.Issues = append(info.Issues, "percent based skipoffset without a duration")
+	case o.Duration != nil && *o.Duration < 0:
+		info.Issues = append(info.Issues, "skipoffset must be positive")
+	}
+	if len(info.Issues) > 0 {
+		return info
+	}
+	info.Offset = o.ResolveAgainst(l.Duration)
+	if l.Duration > 0 && info.Offset >= time.Duration(l.Duration) {
+		info.Issues = append(info.Issues, "skipoffset is not before the end of the creative")
+		return info
+	}
+	info.Skippable = true
+	return info
+}
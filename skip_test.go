@@ -0,0 +1,31 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipInfo(t *testing.T) {
+	dur := Duration(30 * time.Second)
+	for name, test := range map[string]struct {
+		linear Linear
+		want   SkipInfo
+	}{
+		"not skippable": {Linear{Duration: dur}, SkipInfo{}},
+		"duration":      {Linear{Duration: dur, SkipOffset: OffsetDuration(5 * time.Second)}, SkipInfo{Skippable: true, Offset: 5 * time.Second}},
+		"percent":       {Linear{Duration: dur, SkipOffset: OffsetPercent(0.25)}, SkipInfo{Skippable: true, Offset: 7500 * time.Millisecond}},
+		"no duration":   {Linear{SkipOffset: OffsetDuration(5 * time.Second)}, SkipInfo{Skippable: true, Offset: 5 * time.Second}},
+		"percent without duration": {Linear{SkipOffset: OffsetPercent(0.25)},
+			SkipInfo{Issues: []string{"percent based skipoffset without a duration"}}},
+		"percent out of range": {Linear{Duration: dur, SkipOffset: OffsetPercent(1.5)},
+			SkipInfo{Issues: []string{"skipoffset percentage must be between 0% and 100%"}}},
+		"negative": {Linear{Duration: dur, SkipOffset: OffsetDuration(-time.Second)},
+			SkipInfo{Issues: []string{"skipoffset must be positive"}}},
+		"after end": {Linear{Duration: dur, SkipOffset: OffsetDuration(30 * time.Second)},
+			SkipInfo{Offset: 30 * time.Second, Issues: []string{"skipoffset is not before the end of the creative"}}},
+	} {
+		assert.Equal(t, test.want, test.linear.SkipInfo(), name)
+	}
+}
@@ -0,0 +1,108 @@
+package vast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SSAIRejection is why FilterForSSAI removed an ad.
+type SSAIRejection int
+
+// Reasons of the removal of an ad by FilterForSSAI
+const (
+	// The ad is conditional, see Ad.IsConditional
+	SSAIConditional SSAIRejection = iota + 1
+	// The media files of the ad are all VPAID units
+	SSAIVPAIDOnly
+	// The ad has neither a mezzanine nor a progressive MP4 file
+	SSAINoStitchableMedia
+)
+
+// String implements the fmt.Stringer interface.
+func (r SSAIRejection) String() string {
+	switch r {
+	case SSAIConditional:
+		return "conditional ad"
+	case SSAIVPAIDOnly:
+		return "VPAID only"
+	case SSAINoStitchableMedia:
+		return "no stitchable media"
+	}
+	return fmt.Sprintf("SSAIRejection(%d)", int(r))
+}
+
+// ErrorCode returns the error code to report the rejection with. VPAID only
+// ads are reported as lacking a supported media file, since server-side ad
+// insertion can't stitch VPAID units.
+func (r SSAIRejection) ErrorCode() ErrorCode {
+	if r == SSAIConditional {
+		return ErrorConditionalAdRejected
+	}
+	return ErrorMediaFileNotSupported
+}
+
+// RejectedAd is an ad removed by FilterForSSAI.
+type RejectedAd struct {
+	Ad     Ad
+	Reason SSAIRejection
+}
+
+// FilterForSSAI removes from the document the ads server-side ad insertion
+// can't stitch, and returns them with the reason of their removal:
+// conditional ads, ads whose media files are all VPAID units, and ads having
+// neither a mezzanine nor a progressive MP4 file, such as unresolved
+// wrappers.
+func FilterForSSAI(doc *VAST) []RejectedAd {
+	var kept []Ad
+	var rejected []RejectedAd
+	for i := range doc.Ads {
+		ad := &doc.Ads[i]
+		if reason := ssaiRejection(ad); reason != 0 {
+			rejected = append(rejected, RejectedAd{Ad: *ad, Reason: reason})
+		} else {
+			kept = append(kept, *ad)
+		}
+	}
+	if len(rejected) > 0 {
+		doc.Ads = kept
+	}
+	return rejected
+}
+
+func ssaiRejection(ad *Ad) SSAIRejection {
+	if ad.IsConditional() {
+		return SSAIConditional
+	}
+	// whether the ad has VPAID media files, and other ones
+	vpaid, other := false, false
+	stitchable := ad.anyCreative(func(c *Creative) bool {
+		if c.Linear == nil {
+			return false
+		}
+		if m := c.Linear.Mezzanine(); m != nil && strings.TrimSpace(string(m.URI)) != "" {
+			return true
+		}
+		for i := range c.Linear.MediaFiles {
+			m := &c.Linear.MediaFiles[i]
+			if m.IsVPAID() {
+				vpaid = true
+				continue
+			}
+			other = true
+			if m.IsInteractive() || m.Delivery != DeliveryProgressive || baseMIMEType(m.Type) != MIMEVideoMP4 {
+				continue
+			}
+			if strings.TrimSpace(string(m.URI)) != "" {
+				return true
+			}
+		}
+		return false
+	})
+	switch {
+	case stitchable:
+		return 0
+	case vpaid && !other:
+		return SSAIVPAIDOnly
+	}
+	return SSAINoStitchableMedia
+}
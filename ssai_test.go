@@ -0,0 +1,52 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterForSSAI(t *testing.T) {
+	linear := func(id string, l *Linear) Ad {
+		return Ad{ID: id, InLine: &InLine{Creatives: []Creative{{Linear: l}}}}
+	}
+	yes := Bool(true)
+	mp4 := MediaFile{Delivery: DeliveryProgressive, Type: "video/mp4; codecs=avc1", URI: "https://cdn/a.mp4"}
+	vpaid := MediaFile{Delivery: DeliveryProgressive, Type: MIMEJavaScript, APIFramework: "VPAID", URI: "https://cdn/vpaid.js"}
+	hls := MediaFile{Delivery: DeliveryStreaming, Type: MIMEHLS, URI: "https://cdn/a.m3u8"}
+
+	conditional := linear("conditional", &Linear{MediaFiles: []MediaFile{mp4}})
+	conditional.ConditionalAd = &yes
+	doc := &VAST{Ads: []Ad{
+		linear("mp4", &Linear{MediaFiles: []MediaFile{vpaid, mp4}}),
+		conditional,
+		linear("vpaid", &Linear{MediaFiles: []MediaFile{vpaid}}),
+		linear("hls", &Linear{MediaFiles: []MediaFile{hls}}),
+		linear("vpaid and hls", &Linear{MediaFiles: []MediaFile{vpaid, hls}}),
+		linear("mezzanine", &Linear{MediaFiles: []MediaFile{hls}, Mezzanines: []Mezzanine{{URI: "https://cdn/a.mov"}}}),
+		{ID: "wrapper", Wrapper: &Wrapper{VASTAdTagURI: CDATAURI{URI: "https://ads/vast"}}},
+	}}
+	rejected := FilterForSSAI(doc)
+	if assert.Len(t, doc.Ads, 2) {
+		assert.Equal(t, "mp4", doc.Ads[0].ID)
+		assert.Equal(t, "mezzanine", doc.Ads[1].ID)
+	}
+	reasons := map[string]SSAIRejection{}
+	for _, r := range rejected {
+		reasons[r.Ad.ID] = r.Reason
+	}
+	assert.Equal(t, map[string]SSAIRejection{
+		"conditional":   SSAIConditional,
+		"vpaid":         SSAIVPAIDOnly,
+		"hls":           SSAINoStitchableMedia,
+		"vpaid and hls": SSAINoStitchableMedia,
+		"wrapper":       SSAINoStitchableMedia,
+	}, reasons)
+	assert.Equal(t, ErrorConditionalAdRejected, SSAIConditional.ErrorCode())
+	assert.Equal(t, ErrorMediaFileNotSupported, SSAIVPAIDOnly.ErrorCode())
+	assert.Equal(t, ErrorMediaFileNotSupported, SSAINoStitchableMedia.ErrorCode())
+	assert.Equal(t, "VPAID only", SSAIVPAIDOnly.String())
+
+	assert.Empty(t, FilterForSSAI(doc))
+	assert.Len(t, doc.Ads, 2)
+}
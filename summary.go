@@ -0,0 +1,102 @@
+package vast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Summary returns a compact, human readable description of the document for
+// log lines and debugging: its version, pod layout, and for each ad its
+// kind, creatives, duration, media files and number of trackers.
+func (v *VAST) Summary() string {
+	var sb strings.Builder
+	pod, standalone := v.SplitPod()
+	fmt.Fprintf(&sb, "VAST %s: %d ad(s)", v.EffectiveVersion(), len(v.Ads))
+	if len(pod) > 0 {
+		fmt.Fprintf(&sb, ", pod of %d (%s)", len(pod), Duration(PodDuration(pod)))
+	}
+	if len(pod) > 0 && len(standalone) > 0 {
+		fmt.Fprintf(&sb, " + %d stand-alone", len(standalone))
+	}
+	if len(v.Ads) == 0 && len(v.Errors) > 0 {
+		fmt.Fprintf(&sb, ", %d error URI(s)", len(v.ErrorURIs()))
+	}
+	sb.WriteByte('\n')
+	for i := range v.Ads {
+		summarizeAd(&sb, i, &v.Ads[i])
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func summarizeAd(sb *strings.Builder, i int, ad *Ad) {
+	fmt.Fprintf(sb, "  Ad[%d]", i)
+	if ad.ID != "" {
+		fmt.Fprintf(sb, " id=%s", ad.ID)
+	}
+	if ad.IsPodAd() {
+		fmt.Fprintf(sb, " seq=%d", ad.Sequence)
+	}
+	switch {
+	case ad.InLine != nil:
+		sb.WriteString(" InLine")
+		if title := strings.TrimSpace(ad.InLine.AdTitle.CDATA); title != "" {
+			fmt.Fprintf(sb, " %q", title)
+		}
+		var kinds []string
+		if ad.HasLinear() {
+			kinds = append(kinds, "linear")
+		}
+		if ad.HasNonLinear() {
+			kinds = append(kinds, "nonlinear")
+		}
+		if ad.HasCompanions() {
+			kinds = append(kinds, "companions")
+		}
+		if len(kinds) > 0 {
+			fmt.Fprintf(sb, " [%s]", strings.Join(kinds, "+"))
+		}
+		if d, ok := ad.Duration(); ok {
+			fmt.Fprintf(sb, " %s", Duration(d))
+		}
+	case ad.Wrapper != nil:
+		fmt.Fprintf(sb, " Wrapper -> %s", strings.TrimSpace(string(ad.Wrapper.VASTAdTagURI.URI)))
+	default:
+		sb.WriteString(" empty")
+	}
+	sb.WriteByte('\n')
+
+	if ad.InLine != nil {
+		for j := range ad.InLine.Creatives {
+			l := ad.InLine.Creatives[j].Linear
+			if l == nil {
+				continue
+			}
+			for _, m := range l.MediaFiles {
+				fmt.Fprintf(sb, "    %-11s %-24s %9s %8s\n", m.Delivery, m.Type, formatSize(m.Width, m.Height), formatBitrate(m.Bitrate))
+			}
+		}
+	}
+
+	events := 0
+	Walk(&VAST{Ads: []Ad{*ad}}, Visitor{Tracking: func(string, *Tracking) error {
+		events++
+		return nil
+	}})
+	fmt.Fprintf(sb, "    trackers: %d impression(s), %d event(s), %d click(s), %d error(s)\n",
+		len(ad.ImpressionURLs()), events, len(ad.ClickTrackingURLs()), len(ad.ErrorURIs()))
+}
+
+func formatSize(w, h int) string {
+	if w <= 0 || h <= 0 {
+		return "-"
+	}
+	return strconv.Itoa(w) + "x" + strconv.Itoa(h)
+}
+
+func formatBitrate(kbps int) string {
+	if kbps <= 0 {
+		return "-"
+	}
+	return strconv.Itoa(kbps) + "kbps"
+}
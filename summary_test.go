@@ -0,0 +1,39 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `VAST 2.0: 1 ad(s)
+  Ad[0] id=601364 InLine "VAST 2.0 Instream Test 1" [linear+companions] 00:00:30
+    progressive video/x-flv                400x300  500kbps
+    trackers: 2 impression(s), 7 event(s), 1 click(s), 2 error(s)`, v.Summary())
+}
+
+func TestSummaryPod(t *testing.T) {
+	ad, err := NewLinearAd("Pod", 15*time.Second, []MediaSpec{{URI: "http://t/a.mp3", Type: "audio/mpeg"}}, []string{"http://t/imp"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	v := &VAST{Version: "4.2", Ads: []Ad{ad.Ads[0], ad.Ads[0], {ID: "w", Wrapper: &Wrapper{VASTAdTagURI: CDATAURI{URI: "http://t/vast"}}}}}
+	v.Ads[0].Sequence, v.Ads[1].Sequence = 1, 2
+	assert.Equal(t, `VAST 4.2: 3 ad(s), pod of 2 (00:00:30) + 1 stand-alone
+  Ad[0] seq=1 InLine "Pod" [linear] 00:00:15
+    progressive audio/mpeg                       -        -
+    trackers: 1 impression(s), 0 event(s), 0 click(s), 0 error(s)
+  Ad[1] seq=2 InLine "Pod" [linear] 00:00:15
+    progressive audio/mpeg                       -        -
+    trackers: 1 impression(s), 0 event(s), 0 click(s), 0 error(s)
+  Ad[2] id=w Wrapper -> http://t/vast
+    trackers: 0 impression(s), 0 event(s), 0 click(s), 0 error(s)`, v.Summary())
+
+	assert.Equal(t, "VAST 3.0: 0 ad(s), 1 error URI(s)", (&VAST{Version: "3.0", Errors: []CDATAURI{{URI: "http://t/err"}}}).Summary())
+}
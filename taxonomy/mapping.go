@@ -0,0 +1,90 @@
+package taxonomy
+
+// crosswalk maps 1.0 categories to their 2.x equivalent, in order of
+// preference for the reverse mapping. Categories which are missing map as
+// their parent, except for Uncategorized (IAB24), Non-Standard Content
+// (IAB25) and Illegal Content (IAB26) which flag content rather than
+// describe it, and have no 2.x equivalent.
+var crosswalk = []struct{ v1, v2 string }{
+	{"IAB1-1", "42"},
+	{"IAB1-3", "201"},
+	{"IAB1-5", "324"},
+	{"IAB1-6", "338"},
+	{"IAB1-7", "640"},
+	{"IAB2", "1"},
+	{"IAB3", "52"},
+	{"IAB4", "123"},
+	{"IAB5", "132"},
+	{"IAB6", "186"},
+	{"IAB7", "223"},
+	{"IAB8", "210"},
+	{"IAB9", "239"},
+	{"IAB9-30", "680"},
+	{"IAB10", "274"},
+	{"IAB12", "379"},
+	{"IAB11", "379"},
+	{"IAB13", "391"},
+	{"IAB14", "186"},
+	{"IAB15", "464"},
+	{"IAB16", "422"},
+	{"IAB17", "483"},
+	{"IAB18", "552"},
+	{"IAB19", "596"},
+	{"IAB20", "653"},
+	{"IAB21", "441"},
+	{"IAB22", "473"},
+	{"IAB23", "453"},
+}
+
+// Map returns the category of version to closest to the category code of
+// version from, and false if there is none. 2.x and 3.0 share their codes,
+// while 1.0 categories are mapped through their closest 2.x equivalent,
+// which may be less specific.
+func Map(code string, from, to Version) (string, bool) {
+	src, dst := Get(from), Get(to)
+	if src == nil || dst == nil || !src.Valid(code) {
+		return "", false
+	}
+	switch {
+	case from == to:
+		return code, true
+	case from == Version1:
+		v2, ok := toV2(code)
+		if !ok || !dst.Valid(v2) {
+			return "", false
+		}
+		return v2, true
+	case to == Version1:
+		return toV1(src, code)
+	}
+	return code, dst.Valid(code)
+}
+
+func toV2(code string) (string, bool) {
+	v1 := Get(Version1)
+	for code != "" {
+		for _, c := range crosswalk {
+			if c.v1 == code {
+				return c.v2, true
+			}
+		}
+		e, ok := v1.Lookup(code)
+		if !ok {
+			break
+		}
+		code = e.Parent
+	}
+	return "", false
+}
+
+func toV1(src *Taxonomy, code string) (string, bool) {
+	for _, c := range crosswalk {
+		if c.v2 == code {
+			return c.v1, true
+		}
+	}
+	if tier1, ok := src.tier1(code); ok && tier1 != code {
+		return toV1(src, tier1)
+	}
+	return "", false
+}
@@ -0,0 +1,147 @@
+// Package taxonomy validates the content categories of VAST ads against the
+// IAB Content Taxonomy, and maps categories between its versions.
+//
+// The package bundles the complete 1.0 code list, and the tier-1 categories
+// of 2.x and 3.0. The complete 2.x and 3.0 lists can be loaded from the TSV
+// files published by the IAB Tech Lab with Load; until then, other codes of
+// these versions are neither valid nor mapped, and Validate reports them as
+// unverified.
+package taxonomy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Version is a version of the IAB Content Taxonomy.
+type Version string
+
+// Supported versions
+const (
+	Version1 Version = "1.0"
+	Version2 Version = "2.x"
+	Version3 Version = "3.0"
+)
+
+// Entry is a category of a taxonomy.
+type Entry struct {
+	Code string
+	// The code of the parent category, empty for a tier-1 category
+	Parent string
+	Name   string
+}
+
+// Taxonomy is the code list of a version of the IAB Content Taxonomy.
+type Taxonomy struct {
+	Version Version
+	// Complete is false when the code list is partial, in which case codes
+	// missing from the list may still be categories of the taxonomy
+	Complete bool
+	syntax   *regexp.Regexp
+	entries  map[string]Entry
+}
+
+var (
+	mu         sync.RWMutex
+	taxonomies = map[Version]*Taxonomy{
+		Version1: newTaxonomy(Version1, `^IAB[0-9]+(-[0-9]+)?$`, listV1, true),
+		Version2: newTaxonomy(Version2, `^[0-9]+$`, listV2, false),
+		Version3: newTaxonomy(Version3, `^([0-9]+|[0-9A-Z]{6})$`, listV3, false),
+	}
+)
+
+func newTaxonomy(v Version, syntax, list string, complete bool) *Taxonomy {
+	t := &Taxonomy{Version: v, Complete: complete, syntax: regexp.MustCompile(syntax)}
+	if err := t.read(strings.NewReader(list)); err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// read fills the code list from TSV lines of unique ID, parent ID and name.
+// Lines whose first column isn't a well formed code, such as headers, are
+// skipped.
+func (t *Taxonomy) read(r io.Reader) error {
+	t.entries = map[string]Entry{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		cols := strings.Split(scanner.Text(), "\t")
+		if len(cols) < 3 {
+			continue
+		}
+		e := Entry{Code: strings.TrimSpace(cols[0]), Parent: strings.TrimSpace(cols[1]), Name: strings.TrimSpace(cols[2])}
+		if !t.syntax.MatchString(e.Code) {
+			continue
+		}
+		t.entries[e.Code] = e
+	}
+	return scanner.Err()
+}
+
+// Get returns the taxonomy of the given version, or nil if the version is
+// unknown.
+func Get(v Version) *Taxonomy {
+	mu.RLock()
+	defer mu.RUnlock()
+	return taxonomies[v]
+}
+
+// Load replaces the bundled code list of a version by the complete list read
+// from r, in the TSV format published by the IAB Tech Lab: unique ID, parent
+// ID and name, followed by columns which are ignored.
+func Load(v Version, r io.Reader) error {
+	prev := Get(v)
+	if prev == nil {
+		return fmt.Errorf("taxonomy: unknown version %q", v)
+	}
+	t := &Taxonomy{Version: v, Complete: true, syntax: prev.syntax}
+	if err := t.read(r); err != nil {
+		return fmt.Errorf("taxonomy: %w", err)
+	}
+	if len(t.entries) == 0 {
+		return fmt.Errorf("taxonomy: no %s category found", v)
+	}
+	mu.Lock()
+	taxonomies[v] = t
+	mu.Unlock()
+	return nil
+}
+
+// Lookup returns the category of the given code, and false if it's not in
+// the code list.
+func (t *Taxonomy) Lookup(code string) (Entry, bool) {
+	e, ok := t.entries[code]
+	return e, ok
+}
+
+// Valid returns true if the code is a category of the code list.
+func (t *Taxonomy) Valid(code string) bool {
+	_, ok := t.entries[code]
+	return ok
+}
+
+// unverified returns true if the code is missing from a partial code list
+// while being well formed, so that it may be a category of the taxonomy.
+func (t *Taxonomy) unverified(code string) bool {
+	return !t.Complete && !t.Valid(code) && t.syntax.MatchString(code)
+}
+
+// tier1 returns the tier-1 ancestor of code, and false if an ancestor is
+// unknown.
+func (t *Taxonomy) tier1(code string) (string, bool) {
+	for i := 0; i < 8; i++ {
+		e, ok := t.entries[code]
+		if !ok {
+			return "", false
+		}
+		if e.Parent == "" {
+			return code, true
+		}
+		code = e.Parent
+	}
+	return "", false
+}
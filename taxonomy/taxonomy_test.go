@@ -0,0 +1,94 @@
+package taxonomy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	v1 := Get(Version1)
+	e, ok := v1.Lookup("IAB9-30")
+	assert.True(t, ok)
+	assert.Equal(t, Entry{Code: "IAB9-30", Parent: "IAB9", Name: "Video & Computer Games"}, e)
+	assert.True(t, v1.Valid("IAB24"))
+	assert.False(t, v1.Valid("IAB24-1"))
+	assert.False(t, v1.Valid("IAB27"))
+
+	// partial lists only hold the listed codes
+	v2 := Get(Version2)
+	assert.True(t, v2.Valid("483"))
+	assert.False(t, v2.Valid("1234"))
+	assert.True(t, v2.unverified("1234"))
+	assert.False(t, v2.Valid("IAB1"))
+	assert.False(t, v2.unverified("IAB1"))
+	assert.Nil(t, Get("4.0"))
+}
+
+func TestLoad(t *testing.T) {
+	defer func() { taxonomies[Version2] = newTaxonomy(Version2, `^[0-9]+$`, listV2, false) }()
+	tsv := "Relational ID System\t\t\t\n" +
+		"Unique ID\tParent\tName\tTier 1\tTier 2\n" +
+		"1\t\tAutomotive\tAutomotive\t\n" +
+		"2\t1\tAuto Body Styles\tAutomotive\tAuto Body Styles\n"
+	assert.NoError(t, Load(Version2, strings.NewReader(tsv)))
+	v2 := Get(Version2)
+	assert.True(t, v2.Complete)
+	assert.True(t, v2.Valid("2"))
+	assert.False(t, v2.Valid("483"))
+
+	assert.EqualError(t, Load(Version2, strings.NewReader("Unique ID\tParent\tName\n")), "taxonomy: no 2.x category found")
+	assert.EqualError(t, Load("4.0", strings.NewReader(tsv)), `taxonomy: unknown version "4.0"`)
+}
+
+func TestMap(t *testing.T) {
+	for _, tc := range []struct {
+		code     string
+		from, to Version
+		want     string
+		ok       bool
+	}{
+		{"IAB17", Version1, Version2, "483", true},
+		{"IAB17-26", Version1, Version2, "483", true},
+		{"IAB9-30", Version1, Version3, "680", true},
+		{"IAB14-1", Version1, Version2, "186", true},
+		{"IAB22-2", Version1, Version3, "473", true},
+		{"IAB25-3", Version1, Version2, "", false},
+		{"1234", Version2, Version3, "", false},
+		{"IAB99", Version1, Version2, "", false},
+		{"379", Version2, Version1, "IAB12", true},
+		{"596", Version3, Version1, "IAB19", true},
+		{"150", Version2, Version1, "", false},
+		{"483", Version2, Version3, "483", true},
+		{"IAB1-6", Version1, Version1, "IAB1-6", true},
+	} {
+		got, ok := Map(tc.code, tc.from, tc.to)
+		assert.Equal(t, tc.want, got, tc.code)
+		assert.Equal(t, tc.ok, ok, tc.code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	doc := &vast.VAST{Version: "4.1", Ads: []vast.Ad{
+		{InLine: &vast.InLine{Categories: []vast.Category{
+			{Authority: "https://www.iabtechlab.com/categoryauthority", Code: "IAB1-6"},
+			{Authority: "https://www.iabtechlab.com/categoryauthority", Code: "IAB1-99"},
+			{Authority: "https://iabtechlab.com/content-taxonomy/2.2", Code: "483"},
+			{Authority: "https://iabtechlab.com/content-taxonomy/2.2", Code: "IAB17"},
+			{Authority: "iabtechlab.com", Code: " 596 "},
+			{Authority: "https://ads.example.com/categories", Code: "sports"},
+			{Authority: "https://iabtechlab.com/content-taxonomy/3.0", Code: "1234"},
+		}}},
+		{Wrapper: &vast.Wrapper{}},
+	}}
+	assert.EqualError(t, Validate(doc), `invalid VAST.Ad[0].InLine.Category[1]: unknown IAB Content Taxonomy 1.0 code "IAB1-99"; `+
+		`invalid VAST.Ad[0].InLine.Category[3]: unknown IAB Content Taxonomy 2.x code "IAB17"; `+
+		`invalid VAST.Ad[0].InLine.Category[6]: unverified IAB Content Taxonomy 3.0 code "1234", load the complete code list`)
+
+	doc.Ads[0].InLine.Categories = doc.Ads[0].InLine.Categories[:1]
+	assert.NoError(t, Validate(doc))
+	assert.Len(t, ForAuthority("https://www.iabtechlab.com/categoryauthority"), 3)
+	assert.Empty(t, ForAuthority("https://iabtechlab.com.example.org/"))
+}
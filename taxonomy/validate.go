@@ -0,0 +1,108 @@
+package taxonomy
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/haxqer/vast"
+)
+
+// versionInPath matches a taxonomy version in the path of an authority URL,
+// such as "/content-taxonomy/2.2".
+var versionInPath = regexp.MustCompile(`(?:^|[^0-9.])([123])(?:\.[0-9]+)?(?:$|[^0-9.])`)
+
+// ForAuthority returns the taxonomies a category authority may refer to: none
+// for an authority other than the IAB, the version found in the authority
+// URL if any, and every version otherwise, such as for the
+// "https://www.iabtechlab.com/categoryauthority" authority of the VAST 4.1
+// examples.
+func ForAuthority(authority string) []*Taxonomy {
+	authority = strings.TrimSpace(authority)
+	if !strings.Contains(authority, "://") {
+		authority = "https://" + authority
+	}
+	u, err := url.Parse(authority)
+	if err != nil {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	if !isIABHost(host) {
+		return nil
+	}
+	if m := versionInPath.FindStringSubmatch(u.Path); m != nil {
+		switch m[1] {
+		case "1":
+			return []*Taxonomy{Get(Version1)}
+		case "2":
+			return []*Taxonomy{Get(Version2)}
+		case "3":
+			return []*Taxonomy{Get(Version3)}
+		}
+	}
+	return []*Taxonomy{Get(Version1), Get(Version2), Get(Version3)}
+}
+
+func isIABHost(host string) bool {
+	for _, domain := range []string{"iabtechlab.com", "iab.com"} {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the categories of the InLine ads of doc declared by an IAB
+// authority against the taxonomies the authority refers to. Categories of
+// other authorities, or without authority, are not checked. It returns nil
+// or vast.ValidationErrors.
+func Validate(doc *vast.VAST) error {
+	var errs vast.ValidationErrors
+	for i := range doc.Ads {
+		in := doc.Ads[i].InLine
+		if in == nil {
+			continue
+		}
+		for j, c := range in.Categories {
+			if reason := check(c); reason != "" {
+				errs = append(errs, &vast.ValidationError{
+					Path:    "VAST.Ad[" + strconv.Itoa(i) + "].InLine.Category[" + strconv.Itoa(j) + "]",
+					Element: "Category",
+					Reason:  reason,
+				})
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// check returns why the category is invalid, or "" if it's valid.
+func check(c vast.Category) string {
+	taxonomies := ForAuthority(c.Authority)
+	if len(taxonomies) == 0 {
+		return ""
+	}
+	code := strings.TrimSpace(c.Code)
+	if v1 := Get(Version1); len(taxonomies) > 1 && v1.syntax.MatchString(code) {
+		// only 1.0 codes look like "IAB1-6"
+		taxonomies = []*Taxonomy{v1}
+	}
+	var versions, partial []string
+	for _, t := range taxonomies {
+		if t.Valid(code) {
+			return ""
+		}
+		versions = append(versions, string(t.Version))
+		if t.unverified(code) {
+			partial = append(partial, string(t.Version))
+		}
+	}
+	if len(partial) > 0 {
+		return "unverified IAB Content Taxonomy " + strings.Join(partial, "/") + " code " + strconv.Quote(code) + ", load the complete code list"
+	}
+	return "unknown IAB Content Taxonomy " + strings.Join(versions, "/") + " code " + strconv.Quote(code)
+}
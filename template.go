@@ -0,0 +1,104 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateFuncs returns the functions available to VAST templates:
+//
+//   - vastDuration formats a time.Duration, a Duration, or a number of seconds
+//     in the VAST HH:MM:SS(.mmm) format;
+//   - vastCDATA wraps a string in a CDATA section, splitting any "]]>" it
+//     contains so the section can't be closed early;
+//   - vastEscape escapes a string for use in XML text or attribute values;
+//   - vastMacro formats a macro name as a VAST macro, e.g. "[ERRORCODE]".
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"vastDuration": templateDuration,
+		"vastCDATA":    templateCDATA,
+		"vastEscape":   templateEscape,
+		"vastMacro":    templateMacro,
+	}
+}
+
+func templateDuration(v interface{}) (string, error) {
+	var d Duration
+	switch v := v.(type) {
+	case Duration:
+		d = v
+	case time.Duration:
+		d = Duration(v)
+	case int:
+		d = Duration(time.Duration(v) * time.Second)
+	case int64:
+		d = Duration(time.Duration(v) * time.Second)
+	case float64:
+		d = Duration(v * float64(time.Second))
+	case string:
+		td, err := time.ParseDuration(v)
+		if err != nil {
+			return "", err
+		}
+		d = Duration(td)
+	default:
+		return "", fmt.Errorf("vastDuration: unsupported type %T", v)
+	}
+	b, err := d.MarshalText()
+	return string(b), err
+}
+
+func templateCDATA(s string) string {
+	return "<![CDATA[" + strings.Replace(s, "]]>", "]]]]><![CDATA[>", -1) + "]]>"
+}
+
+func templateEscape(s string) (string, error) {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func templateMacro(name string) string {
+	return "[" + strings.ToUpper(strings.Trim(name, "[]")) + "]"
+}
+
+// TemplateAd renders VAST documents from a text/template having access to
+// the TemplateFuncs helpers.
+type TemplateAd struct {
+	tmpl *template.Template
+}
+
+// NewTemplateAd parses text as a VAST template named name.
+func NewTemplateAd(name, text string) (*TemplateAd, error) {
+	tmpl, err := template.New(name).Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateAd{tmpl: tmpl}, nil
+}
+
+// Execute renders the template with data into w.
+func (t *TemplateAd) Execute(w io.Writer, data interface{}) error {
+	return t.tmpl.Execute(w, data)
+}
+
+// Render renders the template with data and parses the result, ensuring the
+// template produced a well-formed VAST document.
+func (t *TemplateAd) Render(data interface{}) (*VAST, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	var v VAST
+	if err := xml.Unmarshal(buf.Bytes(), &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,85 @@
+package vast
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	funcs := TemplateFuncs()
+
+	duration := funcs["vastDuration"].(func(interface{}) (string, error))
+	for in, want := range map[interface{}]string{
+		15 * time.Second:                       "00:00:15",
+		Duration(90 * time.Second):             "00:01:30",
+		30:                                     "00:00:30",
+		1.5:                                    "00:00:01.500",
+		"1h2m3s":                               "01:02:03",
+		time.Duration(2500) * time.Millisecond: "00:00:02.500",
+	} {
+		got, err := duration(in)
+		if assert.NoError(t, err) {
+			assert.Equal(t, want, got)
+		}
+	}
+	_, err := duration(struct{}{})
+	assert.EqualError(t, err, "vastDuration: unsupported type struct {}")
+
+	cdata := funcs["vastCDATA"].(func(string) string)
+	assert.Equal(t, "<![CDATA[http://a?b=1&c=2]]>", cdata("http://a?b=1&c=2"))
+	assert.Equal(t, "<![CDATA[a]]]]><![CDATA[>b]]>", cdata("a]]>b"))
+
+	escape := funcs["vastEscape"].(func(string) (string, error))
+	got, err := escape(`a<b & "c"`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "a&lt;b &amp; &#34;c&#34;", got)
+	}
+
+	macro := funcs["vastMacro"].(func(string) string)
+	assert.Equal(t, "[ERRORCODE]", macro("errorcode"))
+	assert.Equal(t, "[CACHEBUSTING]", macro("[CACHEBUSTING]"))
+}
+
+const testTemplate = `<VAST version="3.0"><Ad id="{{vastEscape .ID}}"><InLine><AdSystem>DSP</AdSystem><AdTitle>{{vastCDATA .Title}}</AdTitle><Error>{{vastCDATA .Error}}</Error><Impression>{{vastCDATA .Impression}}</Impression><Creatives><Creative><Linear><Duration>{{vastDuration .Duration}}</Duration><MediaFiles><MediaFile delivery="progressive" type="video/mp4" width="640" height="360">{{vastCDATA .Media}}</MediaFile></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`
+
+func TestTemplateAd(t *testing.T) {
+	ta, err := NewTemplateAd("ad", testTemplate)
+	if !assert.NoError(t, err) {
+		return
+	}
+	data := map[string]interface{}{
+		"ID":         `a"1`,
+		"Title":      "Tom & Jerry ]]> special",
+		"Error":      "http://track.example.com/error?code=" + templateMacro("ERRORCODE"),
+		"Impression": "http://track.example.com/imp?a=1&b=2",
+		"Duration":   15 * time.Second,
+		"Media":      "http://cdn.example.com/ad.mp4",
+	}
+	v, err := ta.Render(data)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, v.Ads, 1) {
+		ad := v.Ads[0]
+		assert.Equal(t, `a"1`, ad.ID)
+		assert.Equal(t, "Tom & Jerry ]]> special", ad.InLine.AdTitle.CDATA)
+		assert.Equal(t, "http://track.example.com/error?code=[ERRORCODE]", string(ad.InLine.Errors[0].URI))
+		assert.Equal(t, URI("http://track.example.com/imp?a=1&b=2"), ad.InLine.Impressions[0].URI)
+		assert.Equal(t, Duration(15*time.Second), ad.InLine.Creatives[0].Linear.Duration)
+	}
+	assert.NoError(t, v.Validate())
+
+	var buf bytes.Buffer
+	if assert.NoError(t, ta.Execute(&buf, data)) {
+		assert.Contains(t, buf.String(), "<Duration>00:00:15</Duration>")
+	}
+
+	_, err = NewTemplateAd("bad", "{{vastUnknown}}")
+	assert.Error(t, err)
+	broken, _ := NewTemplateAd("broken", "<VAST>")
+	_, err = broken.Render(nil)
+	assert.Error(t, err)
+}
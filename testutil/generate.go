@@ -0,0 +1,293 @@
+// Package testutil generates VAST documents for tests, load tests and fuzz
+// seeds, and checks corpora of documents against golden files.
+package testutil
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// DefaultBaseURL is the URL the URIs of the generated documents are under
+// when GeneratorSpec.BaseURL is not set.
+const DefaultBaseURL = "https://ads.example.com"
+
+// CreativeMix is the relative weight of each kind of creative in the
+// generated InLine ads: with {Linear: 3, NonLinear: 1}, three creatives out
+// of four are linear on average. The zero value only generates linear
+// creatives.
+type CreativeMix struct {
+	Linear    int
+	NonLinear int
+	Companion int
+}
+
+// GeneratorSpec is the shape of the documents returned by Generate.
+type GeneratorSpec struct {
+	// Seed of the pseudo-random generator: the same spec always generates
+	// the same documents
+	Seed int64
+	// Version of the documents, vast.Version4_2 if empty. The documents only
+	// use the elements this version supports, and pass its validation.
+	Version vast.SpecVersion
+	// Number of ads of the root document, 1 if 0
+	Ads int
+	// Number of wrappers leading to each InLine ad: the ads of the root
+	// document are InLine ones if 0
+	WrapperDepth int
+	// Number of creatives of each InLine ad, 1 if 0
+	Creatives int
+	// Kinds of the creatives of the InLine ads
+	Mix CreativeMix
+	// URL the URIs of the documents are under, DefaultBaseURL if empty
+	BaseURL string
+}
+
+// Generated is the outcome of Generate.
+type Generated struct {
+	// The root document, whose ads are wrappers if WrapperDepth is set
+	Root *vast.VAST
+	// The documents the wrappers point to, keyed by ad tag URI
+	Docs map[string]*vast.VAST
+}
+
+// Generate returns pseudo-random yet valid documents shaped by spec. The
+// wrappers of the root document point to the other documents, served by
+// Generated.Transport.
+func Generate(spec GeneratorSpec) (*Generated, error) {
+	g := &generator{spec: spec, rand: rand.New(rand.NewSource(spec.Seed))}
+	if g.spec.Version == "" {
+		g.spec.Version = vast.Version4_2
+	}
+	if !g.spec.Version.Valid() {
+		return nil, fmt.Errorf("generate: unknown version %q", g.spec.Version)
+	}
+	if g.spec.Ads == 0 {
+		g.spec.Ads = 1
+	}
+	if g.spec.Creatives == 0 {
+		g.spec.Creatives = 1
+	}
+	if g.spec.BaseURL == "" {
+		g.spec.BaseURL = DefaultBaseURL
+	}
+	switch {
+	case g.spec.Ads < 0:
+		return nil, fmt.Errorf("generate: negative ad count %d", g.spec.Ads)
+	case g.spec.WrapperDepth < 0:
+		return nil, fmt.Errorf("generate: negative wrapper depth %d", g.spec.WrapperDepth)
+	case g.spec.Creatives < 0:
+		return nil, fmt.Errorf("generate: negative creative count %d", g.spec.Creatives)
+	case g.spec.Mix.Linear < 0 || g.spec.Mix.NonLinear < 0 || g.spec.Mix.Companion < 0:
+		return nil, fmt.Errorf("generate: negative creative weight in %+v", g.spec.Mix)
+	}
+
+	res := &Generated{Root: g.doc(), Docs: map[string]*vast.VAST{}}
+	for i := 0; i < g.spec.Ads; i++ {
+		if g.spec.WrapperDepth == 0 {
+			res.Root.Ads = append(res.Root.Ads, g.inline(i))
+			continue
+		}
+		url := g.url(i, 1)
+		res.Root.Ads = append(res.Root.Ads, g.wrapper(i, url))
+		for depth := 1; depth <= g.spec.WrapperDepth; depth++ {
+			doc := g.doc()
+			if depth < g.spec.WrapperDepth {
+				next := g.url(i, depth+1)
+				doc.Ads = []vast.Ad{g.wrapper(i, next)}
+				res.Docs[url], url = doc, next
+			} else {
+				doc.Ads = []vast.Ad{g.inline(i)}
+				res.Docs[url] = doc
+			}
+		}
+	}
+	if g.spec.Ads > 1 {
+		for i := range res.Root.Ads {
+			if g.spec.Version.AtLeast(vast.Version3_0) {
+				res.Root.Ads[i].Sequence = i + 1
+			}
+		}
+	}
+	return res, nil
+}
+
+// Transport returns an http.RoundTripper serving the documents the wrappers
+// point to, such as for a resolve.Resolver, and 404 for any other URL.
+func (g *Generated) Transport() http.RoundTripper {
+	return transport(g.Docs)
+}
+
+type transport map[string]*vast.VAST
+
+func (t transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	status, body := http.StatusNotFound, []byte(nil)
+	if doc, ok := t[r.URL.String()]; ok {
+		b, err := xml.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		status, body = http.StatusOK, append([]byte(xml.Header), b...)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/xml"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		Request:    r,
+	}, nil
+}
+
+type generator struct {
+	spec GeneratorSpec
+	rand *rand.Rand
+}
+
+func (g *generator) doc() *vast.VAST {
+	v := &vast.VAST{Version: string(g.spec.Version)}
+	if g.spec.Version.AtLeast(vast.Version4_0) {
+		v.XMLNS = "http://www.iab.com/VAST"
+	}
+	return v
+}
+
+func (g *generator) url(ad, depth int) string {
+	return fmt.Sprintf("%s/wrapper/%d/%d.xml", g.spec.BaseURL, ad, depth)
+}
+
+func (g *generator) uri(format string, args ...interface{}) vast.URI {
+	return vast.URI(g.spec.BaseURL + fmt.Sprintf(format, args...))
+}
+
+func (g *generator) adSystem() *vast.AdSystem {
+	return &vast.AdSystem{Name: "testutil", Version: "1.0"}
+}
+
+func (g *generator) impressions(ad int) []vast.Impression {
+	return []vast.Impression{{ID: fmt.Sprintf("imp-%d", ad), URI: g.uri("/impression?ad=%d&r=%d", ad, g.rand.Int31())}}
+}
+
+func (g *generator) wrapper(ad int, url string) vast.Ad {
+	return vast.Ad{
+		ID: fmt.Sprintf("wrapper-%d", ad),
+		Wrapper: &vast.Wrapper{
+			AdSystem:     g.adSystem(),
+			VASTAdTagURI: vast.CDATAURI{URI: vast.URI(url)},
+			Impressions:  g.impressions(ad),
+		},
+	}
+}
+
+func (g *generator) inline(ad int) vast.Ad {
+	in := &vast.InLine{
+		AdSystem:    g.adSystem(),
+		AdTitle:     vast.CDATAString{CDATA: fmt.Sprintf("Generated ad %d", ad)},
+		Impressions: g.impressions(ad),
+	}
+	if g.spec.Version.AtLeast(vast.Version4_1) {
+		in.AdServingId = g.adServingID()
+		if g.rand.Intn(2) == 0 {
+			in.AdVerifications = &[]vast.Verification{{
+				Vendor:              "example.com-omid",
+				JavaScriptResources: []vast.JavaScriptResource{{APIFramework: "omid", BrowserOptional: true, URI: g.uri("/omid.js")}},
+			}}
+		}
+	}
+	for i := 0; i < g.spec.Creatives; i++ {
+		in.Creatives = append(in.Creatives, g.creative(ad, i))
+	}
+	return vast.Ad{ID: fmt.Sprintf("ad-%d", ad), InLine: in}
+}
+
+// adServingID returns an AdServingId made of pseudo-random bytes, unlike
+// vast.NewAdServingID, so that generated documents are reproducible.
+func (g *generator) adServingID() string {
+	var b [16]byte
+	g.rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("testutil-%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func (g *generator) creative(ad, i int) vast.Creative {
+	c := vast.Creative{ID: fmt.Sprintf("creative-%d-%d", ad, i)}
+	if g.spec.Version.AtLeast(vast.Version4_0) {
+		c.UniversalAdID = &vast.UniversalAdID{IDRegistry: "ad-id.org", ID: fmt.Sprintf("TEST%08d", g.rand.Intn(100000000))}
+	}
+	mix := g.spec.Mix
+	if mix.Linear+mix.NonLinear+mix.Companion == 0 {
+		mix.Linear = 1
+	}
+	switch n := g.rand.Intn(mix.Linear + mix.NonLinear + mix.Companion); {
+	case n < mix.Linear:
+		c.Linear = g.linear(ad, i)
+	case n < mix.Linear+mix.NonLinear:
+		c.NonLinearAds = g.nonLinearAds(ad, i)
+	default:
+		c.CompanionAds = g.companionAds(ad, i)
+	}
+	return c
+}
+
+// renditions are the sizes and bitrates the media files of linear
+// creatives are picked from.
+var renditions = []struct{ width, height, bitrate int }{
+	{640, 360, 800},
+	{854, 480, 1200},
+	{1280, 720, 2500},
+	{1920, 1080, 4500},
+}
+
+var quartiles = []vast.EventType{vast.EventStart, vast.EventFirstQuartile, vast.EventMidpoint, vast.EventThirdQuartile, vast.EventComplete}
+
+func (g *generator) linear(ad, i int) *vast.Linear {
+	duration := time.Duration(5*(1+g.rand.Intn(6))) * time.Second
+	l := &vast.Linear{Duration: vast.Duration(duration)}
+	if g.spec.Version.AtLeast(vast.Version3_0) && g.rand.Intn(2) == 0 {
+		skip := vast.Duration(5 * time.Second)
+		l.SkipOffset = &vast.Offset{Duration: &skip}
+	}
+	first := g.rand.Intn(len(renditions))
+	for _, r := range renditions[first:] {
+		l.MediaFiles = append(l.MediaFiles, vast.MediaFile{
+			Delivery: vast.DeliveryProgressive,
+			Type:     "video/mp4",
+			Width:    r.width,
+			Height:   r.height,
+			Bitrate:  r.bitrate,
+			URI:      g.uri("/media/%d-%d-%dp.mp4", ad, i, r.height),
+		})
+	}
+	for _, event := range quartiles {
+		l.TrackingEvents = append(l.TrackingEvents, vast.Tracking{Event: string(event), URI: g.uri("/track?ad=%d&event=%s", ad, event)})
+	}
+	l.VideoClicks = &vast.VideoClicks{ClickThroughs: []vast.VideoClick{{URI: g.uri("/landing?ad=%d", ad)}}}
+	return l
+}
+
+func (g *generator) nonLinearAds(ad, i int) *vast.NonLinearAds {
+	return &vast.NonLinearAds{NonLinears: []vast.NonLinear{{
+		ID:             fmt.Sprintf("overlay-%d-%d", ad, i),
+		Width:          480,
+		Height:         70,
+		StaticResource: &vast.StaticResource{CreativeType: "image/png", URI: g.uri("/overlay/%d-%d.png", ad, i)},
+	}}}
+}
+
+func (g *generator) companionAds(ad, i int) *vast.CompanionAds {
+	companions := &vast.CompanionAds{}
+	for j := 0; j <= g.rand.Intn(2); j++ {
+		companions.Companions = append(companions.Companions, vast.Companion{
+			ID:             fmt.Sprintf("companion-%d-%d-%d", ad, i, j),
+			Width:          300,
+			Height:         250,
+			StaticResource: &vast.StaticResource{CreativeType: "image/jpeg", URI: g.uri("/companion/%d-%d-%d.jpg", ad, i, j)},
+		})
+	}
+	return companions
+}
@@ -0,0 +1,113 @@
+package testutil
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/resolve"
+	"github.com/stretchr/testify/assert"
+)
+
+var versions = []vast.SpecVersion{vast.Version2_0, vast.Version3_0, vast.Version4_0, vast.Version4_1, vast.Version4_2}
+
+func TestGenerateValid(t *testing.T) {
+	for _, version := range versions {
+		for seed := int64(0); seed < 20; seed++ {
+			g, err := Generate(GeneratorSpec{
+				Seed:         seed,
+				Version:      version,
+				Ads:          3,
+				WrapperDepth: int(seed % 3),
+				Creatives:    2,
+				Mix:          CreativeMix{Linear: 2, NonLinear: 1, Companion: 1},
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+			docs := []*vast.VAST{g.Root}
+			for _, doc := range g.Docs {
+				docs = append(docs, doc)
+			}
+			for _, doc := range docs {
+				assert.NoError(t, doc.Validate(), "%s seed %d", version, seed)
+				assert.True(t, version.AtLeast(vast.RequiredVersion(doc)), "%s seed %d requires %s", version, seed, vast.RequiredVersion(doc))
+
+				// the documents survive encoding
+				b, err := xml.Marshal(doc)
+				if assert.NoError(t, err) {
+					var decoded vast.VAST
+					assert.NoError(t, xml.Unmarshal(b, &decoded))
+					assert.NoError(t, decoded.Validate())
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateShape(t *testing.T) {
+	g, err := Generate(GeneratorSpec{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "4.2", g.Root.Version)
+		assert.Len(t, g.Root.Ads, 1)
+		assert.Empty(t, g.Docs)
+		in := g.Root.Ads[0].InLine
+		if assert.NotNil(t, in) && assert.Len(t, in.Creatives, 1) {
+			assert.NotNil(t, in.Creatives[0].Linear)
+		}
+	}
+
+	g, err = Generate(GeneratorSpec{Ads: 4, WrapperDepth: 2, Creatives: 3, Mix: CreativeMix{Companion: 1}})
+	if assert.NoError(t, err) {
+		assert.Len(t, g.Root.Ads, 4)
+		assert.Len(t, g.Docs, 8)
+		for i, ad := range g.Root.Ads {
+			assert.Equal(t, i+1, ad.Sequence)
+			if assert.NotNil(t, ad.Wrapper) {
+				next := g.Docs[string(ad.Wrapper.VASTAdTagURI.URI)]
+				if assert.NotNil(t, next) && assert.NotNil(t, next.Ads[0].Wrapper) {
+					in := g.Docs[string(next.Ads[0].Wrapper.VASTAdTagURI.URI)].Ads[0].InLine
+					if assert.NotNil(t, in) && assert.Len(t, in.Creatives, 3) {
+						assert.NotNil(t, in.Creatives[2].CompanionAds)
+					}
+				}
+			}
+		}
+	}
+
+	for _, spec := range []GeneratorSpec{{Version: "5.0"}, {Ads: -1}, {WrapperDepth: -1}, {Creatives: -1}, {Mix: CreativeMix{NonLinear: -1}}} {
+		_, err := Generate(spec)
+		assert.Error(t, err, "%+v", spec)
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	spec := GeneratorSpec{Seed: 42, Ads: 5, WrapperDepth: 1, Creatives: 2, Mix: CreativeMix{Linear: 1, NonLinear: 1, Companion: 1}}
+	a, err := Generate(spec)
+	assert.NoError(t, err)
+	b, err := Generate(spec)
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	spec.Seed++
+	c, err := Generate(spec)
+	assert.NoError(t, err)
+	assert.NotEqual(t, a, c)
+}
+
+func TestGeneratedTransport(t *testing.T) {
+	g, err := Generate(GeneratorSpec{Seed: 7, Ads: 3, WrapperDepth: 3})
+	if !assert.NoError(t, err) {
+		return
+	}
+	r := &resolve.Resolver{Transport: g.Transport()}
+	res, err := r.Resolve(context.Background(), g.Root)
+	if assert.NoError(t, err) {
+		assert.Empty(t, res.Errors)
+		if assert.Len(t, res.Ads, 3) {
+			assert.Len(t, res.Ads[0].Wrappers, 3)
+			assert.Equal(t, "ad-0", res.Ads[0].InLine.ID)
+		}
+	}
+}
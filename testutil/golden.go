@@ -0,0 +1,141 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/haxqer/vast"
+)
+
+// GoldenSuffix is appended to the name of a document to get the name of the
+// golden file holding its expected validation result.
+const GoldenSuffix = ".golden"
+
+// RoundTripOptions changes the behavior of RoundTrip.
+type RoundTripOptions struct {
+	// Directory of the golden files, the directory of the documents if empty
+	Golden string
+	// Write the golden files from the current validation results instead of
+	// comparing them, typically set by an -update flag of the test
+	Update bool
+}
+
+// RoundTrip checks every .xml document of dir, each in its own subtest of t
+// named after the file:
+//
+//   - the document is decoded, encoded and decoded again, and both decoded
+//     documents must be equal as defined by vast.Diff
+//   - the encoding must be stable, the second decoded document encoding to
+//     the same bytes as the first one, and vast.FastMarshal must match
+//     xml.Marshal
+//   - the validation result, "valid" or the validation errors one per line,
+//     must match the golden file of the document, and be the same for both
+//     decoded documents
+//
+// Downstream users can run it against their own partner corpora:
+//
+//	var update = flag.Bool("update", false, "update the golden files")
+//
+//	func TestPartners(t *testing.T) {
+//		testutil.RoundTrip(t, "testdata/partners", testutil.RoundTripOptions{Update: *update})
+//	}
+func RoundTrip(t *testing.T, dir string, opts RoundTripOptions) {
+	t.Helper()
+	names, err := filepath.Glob(filepath.Join(dir, "*.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		t.Fatalf("no .xml document in %s", dir)
+	}
+	sort.Strings(names)
+	golden := opts.Golden
+	if golden == "" {
+		golden = dir
+	}
+	for _, name := range names {
+		name := name
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			roundTrip(t, name, filepath.Join(golden, filepath.Base(name)+GoldenSuffix), opts.Update)
+		})
+	}
+}
+
+func roundTrip(t *testing.T, name, golden string, update bool) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first vast.VAST
+	if err := xml.Unmarshal(data, &first); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	encoded, err := xml.Marshal(&first)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if fast, err := vast.FastMarshal(&first); err != nil {
+		t.Errorf("fast encode: %v", err)
+	} else if !bytes.Equal(fast, encoded) {
+		t.Errorf("fast encoding differs from xml.Marshal's:\n%s\n%s", fast, encoded)
+	}
+	var second vast.VAST
+	if err := xml.Unmarshal(encoded, &second); err != nil {
+		t.Fatalf("decode encoded document: %v", err)
+	}
+	for _, c := range vast.Diff(&first, &second) {
+		t.Errorf("changed by encoding: %v", c)
+	}
+	if reencoded, err := xml.Marshal(&second); err != nil {
+		t.Errorf("encode decoded document: %v", err)
+	} else if !bytes.Equal(reencoded, encoded) {
+		t.Errorf("unstable encoding:\n%s\n%s", encoded, reencoded)
+	}
+
+	result := validation(&first)
+	if other := validation(&second); other != result {
+		t.Errorf("validation changed by encoding:\n%s\nbecame:\n%s", result, other)
+	}
+	if update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(golden, []byte(result), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(golden)
+	if os.IsNotExist(err) {
+		t.Fatalf("missing golden file %s, run with Update set to create it", golden)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(want) != result {
+		t.Errorf("validation result differs from %s:\n%s\nwant:\n%s", golden, result, want)
+	}
+}
+
+// validation returns the validation result of v as stored in golden files.
+func validation(v *vast.VAST) string {
+	err := v.Validate()
+	if err == nil {
+		return "valid\n"
+	}
+	errs, ok := err.(vast.ValidationErrors)
+	if !ok {
+		return err.Error() + "\n"
+	}
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = e.Error()
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,12 @@
+package testutil
+
+import (
+	"flag"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestRoundTrip(t *testing.T) {
+	RoundTrip(t, "../testdata", RoundTripOptions{Golden: "testdata/golden", Update: *update})
+}
@@ -0,0 +1,185 @@
+package vast
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// DecisionTrace accumulates every decision made while producing a final VAST
+// response: wrapper hops fetched, ads rejected and why, trackers injected and
+// macros expanded. It serializes to JSON for debugging purposes.
+//
+// A DecisionTrace is safe for concurrent use. All the recording methods are
+// no-ops on a nil *DecisionTrace so callers never need to check whether
+// tracing is enabled.
+type DecisionTrace struct {
+	mu         sync.Mutex
+	started    time.Time
+	hops       []TraceHop
+	rejections []TraceRejection
+	trackers   []TraceTracker
+	macros     []TraceMacro
+}
+
+// TraceHop is a wrapper hop fetched while resolving an ad.
+type TraceHop struct {
+	// The component which fetched the hop, e.g. "resolver"
+	Stage string `json:",omitempty"`
+	// Number of wrappers followed before this hop, starting at 0
+	Depth int
+	// The ad tag URI fetched
+	URL string
+	// The time spent fetching and parsing the hop
+	Duration time.Duration
+	// The error encountered, if any
+	Error string `json:",omitempty"`
+}
+
+// TraceRejection is an ad removed from the response.
+type TraceRejection struct {
+	// The component which rejected the ad, e.g. "selector" or "filter"
+	Stage string `json:",omitempty"`
+	// The ID of the rejected ad
+	AdID string `json:",omitempty"`
+	// Human readable reason of the rejection
+	Reason string
+}
+
+// TraceTracker is a tracking URL injected in an ad.
+type TraceTracker struct {
+	// The component which injected the tracker
+	Stage string `json:",omitempty"`
+	// The ID of the ad the tracker was injected in
+	AdID string `json:",omitempty"`
+	// The kind of tracker: "impression", "error", "click" or an event type
+	Kind string
+	// The injected URL
+	URL string
+}
+
+// TraceMacro is a macro expanded in a URL.
+type TraceMacro struct {
+	// The component which expanded the macro
+	Stage string `json:",omitempty"`
+	// The macro name, without brackets
+	Macro string
+	// The substituted value
+	Value string
+}
+
+// NewDecisionTrace returns an empty trace started now.
+func NewDecisionTrace() *DecisionTrace {
+	return &DecisionTrace{started: time.Now()}
+}
+
+// RecordHop records a fetched wrapper hop.
+func (t *DecisionTrace) RecordHop(hop TraceHop) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.hops = append(t.hops, hop)
+	t.mu.Unlock()
+}
+
+// Reject records that the ad identified by adID was removed for reason.
+func (t *DecisionTrace) Reject(stage, adID, reason string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.rejections = append(t.rejections, TraceRejection{Stage: stage, AdID: adID, Reason: reason})
+	t.mu.Unlock()
+}
+
+// RecordTracker records a tracker of the given kind injected in an ad.
+func (t *DecisionTrace) RecordTracker(stage, adID, kind, url string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.trackers = append(t.trackers, TraceTracker{Stage: stage, AdID: adID, Kind: kind, URL: url})
+	t.mu.Unlock()
+}
+
+// RecordMacro records the expansion of a macro.
+func (t *DecisionTrace) RecordMacro(stage, macro, value string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.macros = append(t.macros, TraceMacro{Stage: stage, Macro: macro, Value: value})
+	t.mu.Unlock()
+}
+
+// Hops returns a copy of the recorded hops.
+func (t *DecisionTrace) Hops() []TraceHop {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceHop(nil), t.hops...)
+}
+
+// Rejections returns a copy of the recorded rejections.
+func (t *DecisionTrace) Rejections() []TraceRejection {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceRejection(nil), t.rejections...)
+}
+
+// Trackers returns a copy of the recorded tracker injections.
+func (t *DecisionTrace) Trackers() []TraceTracker {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceTracker(nil), t.trackers...)
+}
+
+// Macros returns a copy of the recorded macro expansions.
+func (t *DecisionTrace) Macros() []TraceMacro {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceMacro(nil), t.macros...)
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (t *DecisionTrace) MarshalJSON() ([]byte, error) {
+	if t == nil {
+		return []byte("null"), nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return json.Marshal(struct {
+		Started    time.Time
+		Hops       []TraceHop       `json:",omitempty"`
+		Rejections []TraceRejection `json:",omitempty"`
+		Trackers   []TraceTracker   `json:",omitempty"`
+		Macros     []TraceMacro     `json:",omitempty"`
+	}{t.started, t.hops, t.rejections, t.trackers, t.macros})
+}
+
+type traceContextKey struct{}
+
+// WithDecisionTrace returns a copy of ctx carrying t, so that every component
+// involved in producing a response records into the same trace.
+func WithDecisionTrace(ctx context.Context, t *DecisionTrace) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, t)
+}
+
+// DecisionTraceFrom returns the trace carried by ctx, or nil if there is none.
+func DecisionTraceFrom(ctx context.Context) *DecisionTrace {
+	t, _ := ctx.Value(traceContextKey{}).(*DecisionTrace)
+	return t
+}
@@ -0,0 +1,72 @@
+package vast
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecisionTrace(t *testing.T) {
+	tr := NewDecisionTrace()
+	tr.RecordHop(TraceHop{Stage: "resolver", URL: "http://ads.example.com/tag", Duration: 10 * time.Millisecond})
+	tr.Reject("filter", "ad1", "conditional ad")
+	tr.RecordTracker("inject", "ad2", "impression", "http://track.example.com/imp")
+	tr.RecordMacro("macro", "CACHEBUSTING", "12345678")
+
+	assert.Len(t, tr.Hops(), 1)
+	assert.Equal(t, []TraceRejection{{Stage: "filter", AdID: "ad1", Reason: "conditional ad"}}, tr.Rejections())
+	assert.Equal(t, []TraceTracker{{Stage: "inject", AdID: "ad2", Kind: "impression", URL: "http://track.example.com/imp"}}, tr.Trackers())
+	assert.Equal(t, []TraceMacro{{Stage: "macro", Macro: "CACHEBUSTING", Value: "12345678"}}, tr.Macros())
+
+	b, err := json.Marshal(tr)
+	if assert.NoError(t, err) {
+		var got map[string]interface{}
+		if assert.NoError(t, json.Unmarshal(b, &got)) {
+			assert.Contains(t, got, "Started")
+			assert.Len(t, got["Hops"], 1)
+			assert.Len(t, got["Rejections"], 1)
+			assert.Len(t, got["Trackers"], 1)
+			assert.Len(t, got["Macros"], 1)
+		}
+	}
+}
+
+func TestDecisionTraceNil(t *testing.T) {
+	var tr *DecisionTrace
+	tr.RecordHop(TraceHop{})
+	tr.Reject("filter", "ad1", "reason")
+	tr.RecordTracker("inject", "ad1", "impression", "http://track.example.com/imp")
+	tr.RecordMacro("macro", "TIMESTAMP", "now")
+	assert.Nil(t, tr.Hops())
+	assert.Nil(t, tr.Rejections())
+
+	b, err := json.Marshal(tr)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "null", string(b))
+	}
+}
+
+func TestDecisionTraceConcurrent(t *testing.T) {
+	tr := NewDecisionTrace()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tr.Reject("filter", "ad", "reason")
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, tr.Rejections(), 10)
+}
+
+func TestDecisionTraceContext(t *testing.T) {
+	assert.Nil(t, DecisionTraceFrom(context.Background()))
+	tr := NewDecisionTrace()
+	ctx := WithDecisionTrace(context.Background(), tr)
+	assert.True(t, tr == DecisionTraceFrom(ctx))
+}
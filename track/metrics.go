@@ -0,0 +1,56 @@
+package track
+
+import "time"
+
+// Metrics receives the pings sent by a Tracker, to monitor the delivery of
+// the beacons. Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObservePing is called after every ping, once its retries are
+	// exhausted.
+	ObservePing(p Ping)
+}
+
+// Stats are the counters of the pings sent by a Tracker.
+type Stats struct {
+	// Number of pings sent
+	Pings int64
+	// Number of pings which eventually failed
+	Failures int64
+	// Number of retries sent
+	Retries int64
+	// Time spent sending the pings, retries included
+	Latency time.Duration
+}
+
+// SuccessRate returns the share of the pings which succeeded, 1 if none was
+// sent.
+func (s Stats) SuccessRate() float64 {
+	if s.Pings == 0 {
+		return 1
+	}
+	return float64(s.Pings-s.Failures) / float64(s.Pings)
+}
+
+// Stats returns the counters of the pings sent by the tracker so far.
+func (t *Tracker) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// observe accounts for p and reports it to the metrics of the tracker.
+func (t *Tracker) observe(p Ping) {
+	t.mu.Lock()
+	t.stats.Pings++
+	if p.Err != nil {
+		t.stats.Failures++
+	}
+	if p.Attempts > 1 {
+		t.stats.Retries += int64(p.Attempts - 1)
+	}
+	t.stats.Latency += p.Latency
+	t.mu.Unlock()
+	if t.Metrics != nil {
+		t.Metrics.ObservePing(p)
+	}
+}
@@ -0,0 +1,90 @@
+package track
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMetrics struct {
+	mu    sync.Mutex
+	pings []Ping
+}
+
+func (m *testMetrics) ObservePing(p Ping) {
+	m.mu.Lock()
+	m.pings = append(m.pings, p)
+	m.mu.Unlock()
+}
+
+func TestTrackerMetrics(t *testing.T) {
+	var flaky int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			if atomic.AddInt32(&flaky, 1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer srv.Close()
+
+	ad := &vast.Ad{InLine: &vast.InLine{Impressions: []vast.Impression{
+		{URI: vast.URI(srv.URL + "/ok")},
+		{URI: vast.URI(srv.URL + "/flaky")},
+		{URI: vast.URI(srv.URL + "/gone")},
+	}}}
+	m := &testMetrics{}
+	tr := &Tracker{MaxRetries: 2, Backoff: time.Millisecond, Metrics: m}
+	pings := tr.FireImpressions(context.Background(), ad)
+	if assert.Len(t, pings, 3) {
+		assert.Equal(t, EventImpression, pings[0].Event)
+		assert.Equal(t, 1, pings[0].Attempts)
+		assert.Equal(t, 2, pings[1].Attempts)
+		assert.NoError(t, pings[1].Err)
+		assert.Equal(t, http.StatusOK, pings[1].StatusCode)
+		// client errors aren't retried
+		assert.Equal(t, 1, pings[2].Attempts)
+		assert.Equal(t, http.StatusGone, pings[2].StatusCode)
+		assert.Error(t, pings[2].Err)
+		assert.True(t, pings[1].Latency > 0)
+	}
+	assert.Len(t, m.pings, 3)
+
+	stats := tr.Stats()
+	assert.Equal(t, int64(3), stats.Pings)
+	assert.Equal(t, int64(1), stats.Failures)
+	assert.Equal(t, int64(1), stats.Retries)
+	assert.InDelta(t, 2.0/3, stats.SuccessRate(), 1e-9)
+	assert.Equal(t, 1.0, Stats{}.SuccessRate())
+}
+
+func TestTrackerRetriesExhausted(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	tr := &Tracker{MaxRetries: 2, Backoff: time.Millisecond}
+	ad := &vast.Ad{InLine: &vast.InLine{}}
+	ad.InLine.Creatives = []vast.Creative{{Linear: &vast.Linear{TrackingEvents: []vast.Tracking{{Event: "start", URI: vast.URI(srv.URL)}}}}}
+	pings := tr.FireEvent(context.Background(), ad, vast.EventStart)
+	if assert.Len(t, pings, 1) {
+		assert.Equal(t, vast.EventStart, pings[0].Event)
+		assert.Equal(t, 3, pings[0].Attempts)
+		assert.EqualError(t, pings[0].Err, "unexpected status 503 Service Unavailable")
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, int64(2), tr.Stats().Retries)
+}
@@ -0,0 +1,22 @@
+package track
+
+import (
+	"context"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/macro"
+)
+
+// EventNoAd is the event of the pings reporting a response without ad.
+const EventNoAd = vast.EventType("noAd")
+
+// FireNoAd fires the document level error tracking URIs of doc, such as the
+// ones of a vast.EmptyResponse, expanded by macro.ErrorURLs with the
+// vast.ErrorWrapperNoAd code, and returns the pings sent. Nothing is fired if
+// doc has ads.
+func (t *Tracker) FireNoAd(ctx context.Context, doc *vast.VAST) []Ping {
+	if !doc.IsEmpty() {
+		return nil
+	}
+	return t.send(ctx, EventNoAd, macro.ErrorURLs(ctx, dedupe(doc.ErrorURIs()), vast.ErrorWrapperNoAd, t.Macros))
+}
@@ -0,0 +1,27 @@
+package track
+
+import (
+	"context"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/macro"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFireNoAd(t *testing.T) {
+	rec := newRecorder()
+	defer rec.Close()
+
+	tr := &Tracker{Macros: &macro.Context{CacheBusting: "42"}}
+	doc := vast.EmptyResponse(vast.Version4_1, rec.URL+"/noad?code=%5bErrorCode%5D&cb=[CACHEBUSTING]")
+	pings := tr.FireNoAd(context.Background(), doc)
+	if assert.Len(t, pings, 1) {
+		assert.Equal(t, EventNoAd, pings[0].Event)
+		assert.NoError(t, pings[0].Err)
+	}
+	assert.Equal(t, []string{"/noad?code=303&cb=42"}, rec.received())
+
+	doc.Ads = []vast.Ad{{}}
+	assert.Empty(t, tr.FireNoAd(context.Background(), doc))
+}
@@ -0,0 +1,185 @@
+package track
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/haxqer/vast"
+)
+
+// Headers describing the device on behalf of which server-side ad insertion
+// fires the trackers, as recommended by the IAB guidance on SSAI
+const (
+	HeaderForwardedFor         = "X-Forwarded-For"
+	HeaderDeviceIP             = "X-Device-IP"
+	HeaderDeviceUserAgent      = "X-Device-User-Agent"
+	HeaderDeviceReferer        = "X-Device-Referer"
+	HeaderDeviceAcceptLanguage = "X-Device-Accept-Language"
+)
+
+// headerKey is the context key of the headers added to the pings of a
+// request, on top of the ones of the Tracker.
+type headerKey struct{}
+
+// Device describes the device an ad is played on.
+type Device struct {
+	IP        string
+	UserAgent string
+	Referer   string
+	// The Accept-Language header of the device
+	AcceptLanguage string
+	// The X-Forwarded-For header of the device request, the proxies it went
+	// through before reaching the server
+	ForwardedFor string
+}
+
+// DeviceFromRequest returns the device sending r, as seen by the server.
+func DeviceFromRequest(r *http.Request) Device {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return Device{
+		IP:             ip,
+		UserAgent:      r.UserAgent(),
+		Referer:        r.Referer(),
+		AcceptLanguage: r.Header.Get("Accept-Language"),
+		ForwardedFor:   r.Header.Get(HeaderForwardedFor),
+	}
+}
+
+// VendorPolicy configures how the trackers of a measurement vendor are
+// relayed.
+type VendorPolicy struct {
+	// The hosts of the trackers of the vendor, their subdomains included
+	Hosts []string
+	// If true, the trackers of the vendor aren't fired by the relay, such as
+	// when they are fired by the device itself
+	Disabled bool
+	// If true, the User-Agent header of the pings is the one of the device
+	// rather than the one of the Tracker
+	DeviceUserAgent bool
+	// Device headers not sent to the vendor, such as HeaderDeviceReferer
+	OmitHeaders []string
+	// Additional headers sent to the vendor
+	Header http.Header
+}
+
+// matches returns true if the host of uri is one of the hosts of the vendor.
+func (p *VendorPolicy) matches(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range p.Hosts {
+		h = strings.ToLower(h)
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// header returns the headers of the pings sent to the vendor on behalf of
+// device.
+func (p *VendorPolicy) header(device Device) http.Header {
+	h := http.Header{}
+	set := func(name, value string) {
+		if value != "" {
+			h.Set(name, value)
+		}
+	}
+	forwarded := device.IP
+	if device.ForwardedFor != "" && device.IP != "" {
+		forwarded = device.ForwardedFor + ", " + device.IP
+	} else if device.ForwardedFor != "" {
+		forwarded = device.ForwardedFor
+	}
+	set(HeaderForwardedFor, forwarded)
+	set(HeaderDeviceIP, device.IP)
+	set(HeaderDeviceUserAgent, device.UserAgent)
+	set(HeaderDeviceReferer, device.Referer)
+	set(HeaderDeviceAcceptLanguage, device.AcceptLanguage)
+	if p.DeviceUserAgent {
+		set("User-Agent", device.UserAgent)
+	}
+	for _, name := range p.OmitHeaders {
+		h.Del(name)
+	}
+	for k, v := range p.Header {
+		h[http.CanonicalHeaderKey(k)] = v
+	}
+	return h
+}
+
+// Relay fires trackers server-side on behalf of devices, forwarding the
+// device information measurement vendors need to attribute the pings.
+type Relay struct {
+	// The tracker sending the pings
+	Tracker *Tracker
+	// The policies of the vendors, the first one matching the host of a
+	// tracker applying
+	Vendors []VendorPolicy
+	// The policy of the trackers matching no vendor
+	Default VendorPolicy
+}
+
+// FireImpressions fires the impression tracking URIs of ad on behalf of
+// device.
+func (r *Relay) FireImpressions(ctx context.Context, ad *vast.Ad, device Device) []Ping {
+	return r.Fire(ctx, EventImpression, ImpressionURIs(ad), device)
+}
+
+// FireEvent fires the tracking URIs of ad for event on behalf of device.
+func (r *Relay) FireEvent(ctx context.Context, ad *vast.Ad, event vast.EventType, device Device) []Ping {
+	return r.Fire(ctx, event, EventURIs(ad, event), device)
+}
+
+// Fire fires uris for event on behalf of device, with the headers of the
+// policy of their vendor, and returns the pings sent once all of them
+// completed. The trackers of disabled vendors are skipped.
+func (r *Relay) Fire(ctx context.Context, event vast.EventType, uris []string, device Device) []Ping {
+	policies := make([]*VendorPolicy, 0, len(r.Vendors)+1)
+	groups := map[*VendorPolicy][]string{}
+	for _, u := range uris {
+		p := r.policy(u)
+		if p.Disabled {
+			continue
+		}
+		if _, ok := groups[p]; !ok {
+			policies = append(policies, p)
+		}
+		groups[p] = append(groups[p], u)
+	}
+
+	results := make([][]Ping, len(policies))
+	var wg sync.WaitGroup
+	for i, p := range policies {
+		wg.Add(1)
+		go func(i int, p *VendorPolicy) {
+			defer wg.Done()
+			ctx := context.WithValue(ctx, headerKey{}, p.header(device))
+			results[i] = r.Tracker.fire(ctx, event, groups[p], nil)
+		}(i, p)
+	}
+	wg.Wait()
+	var pings []Ping
+	for _, res := range results {
+		pings = append(pings, res...)
+	}
+	return pings
+}
+
+func (r *Relay) policy(uri string) *VendorPolicy {
+	for i := range r.Vendors {
+		if r.Vendors[i].matches(uri) {
+			return &r.Vendors[i]
+		}
+	}
+	return &r.Default
+}
@@ -0,0 +1,66 @@
+package track
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelay(t *testing.T) {
+	var mu sync.Mutex
+	headers := map[string]http.Header{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers[r.URL.Path] = r.Header
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	// the vendor is reached through localhost, other trackers through 127.0.0.1
+	vendorURL := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	req := httptest.NewRequest(http.MethodGet, "/ad", nil)
+	req.RemoteAddr = "203.0.113.7:5555"
+	req.Header.Set("User-Agent", "Roku/DVP-9.10")
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	req.Header.Set("Accept-Language", "en-US")
+	device := DeviceFromRequest(req)
+	assert.Equal(t, Device{IP: "203.0.113.7", UserAgent: "Roku/DVP-9.10", AcceptLanguage: "en-US", ForwardedFor: "198.51.100.1"}, device)
+
+	relay := &Relay{
+		Tracker: &Tracker{UserAgent: "ssai/1.0"},
+		Vendors: []VendorPolicy{
+			{Hosts: []string{"localhost"}, DeviceUserAgent: true, OmitHeaders: []string{HeaderDeviceAcceptLanguage}, Header: http.Header{"x-vendor": {"1"}}},
+			{Hosts: []string{"client-side.example.com"}, Disabled: true},
+		},
+	}
+	pings := relay.Fire(context.Background(), EventImpression, []string{
+		srv.URL + "/default",
+		vendorURL + "/vendor",
+		"https://beacons.client-side.example.com/imp",
+	}, device)
+	assert.Len(t, pings, 2)
+	for _, p := range pings {
+		assert.NoError(t, p.Err)
+	}
+
+	def := headers["/default"]
+	if assert.NotNil(t, def) {
+		assert.Equal(t, "ssai/1.0", def.Get("User-Agent"))
+		assert.Equal(t, "198.51.100.1, 203.0.113.7", def.Get(HeaderForwardedFor))
+		assert.Equal(t, "203.0.113.7", def.Get(HeaderDeviceIP))
+		assert.Equal(t, "Roku/DVP-9.10", def.Get(HeaderDeviceUserAgent))
+		assert.Equal(t, "en-US", def.Get(HeaderDeviceAcceptLanguage))
+	}
+	vendor := headers["/vendor"]
+	if assert.NotNil(t, vendor) {
+		assert.Equal(t, "Roku/DVP-9.10", vendor.Get("User-Agent"))
+		assert.Empty(t, vendor.Get(HeaderDeviceAcceptLanguage))
+		assert.Equal(t, "1", vendor.Get("X-Vendor"))
+	}
+	assert.Equal(t, int64(2), relay.Tracker.Stats().Pings)
+}
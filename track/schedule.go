@@ -0,0 +1,133 @@
+package track
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// Cue is a set of tracking URIs due at a given time of the playback of a
+// linear creative.
+type Cue struct {
+	// The playhead position at which the URIs are due
+	Offset time.Duration
+	// The event tracked
+	Event vast.EventType
+	// The tracking URIs, macros unexpanded
+	URIs []string
+}
+
+// quartiles are the events due at a fraction of the duration of a linear
+// creative.
+var quartiles = []struct {
+	event    vast.EventType
+	fraction float32
+}{
+	{vast.EventStart, 0},
+	{vast.EventFirstQuartile, 0.25},
+	{vast.EventMidpoint, 0.5},
+	{vast.EventThirdQuartile, 0.75},
+	{vast.EventComplete, 1},
+}
+
+// Timeline returns the cues of a linear creative of the given duration,
+// ordered by offset: start, the quartiles and complete, and the progress
+// events at their offsets. Events without tracking URIs are left out.
+func Timeline(duration vast.Duration, trackings []vast.Tracking) []Cue {
+	var cues []Cue
+	for _, q := range quartiles {
+		var uris []string
+		for _, tr := range trackings {
+			if vast.EventType(tr.Event) == q.event {
+				uris = appendURI(uris, tr.URI)
+			}
+		}
+		if len(uris) > 0 {
+			offset := vast.OffsetPercent(q.fraction).ResolveAgainst(duration)
+			cues = append(cues, Cue{Offset: offset, Event: q.event, URIs: dedupe(uris)})
+		}
+	}
+	progress := map[time.Duration]int{}
+	for _, tr := range trackings {
+		if vast.EventType(tr.Event) != vast.EventProgress || tr.Offset == nil {
+			continue
+		}
+		offset := tr.Offset.ResolveAgainst(duration)
+		i, ok := progress[offset]
+		if !ok {
+			i = len(cues)
+			progress[offset] = i
+			cues = append(cues, Cue{Offset: offset, Event: vast.EventProgress})
+		}
+		cues[i].URIs = appendURI(cues[i].URIs, tr.URI)
+	}
+	sort.SliceStable(cues, func(i, j int) bool {
+		return cues[i].Offset < cues[j].Offset
+	})
+	return cues
+}
+
+// Scheduler fires the tracking events of a linear creative as the playhead
+// moves forward, so that players don't need to compute the quartiles and the
+// progress offsets. Every cue is fired once, even if the playhead moves
+// backward.
+//
+// A Scheduler is safe for concurrent use.
+type Scheduler struct {
+	session *Session
+
+	mu   sync.Mutex
+	cues []Cue
+	next int
+}
+
+// Scheduler returns a scheduler of the tracking events of the first linear
+// creative of the ad of the session. The scheduler has no cue if the ad is
+// not an InLine ad with a linear creative.
+func (s *Session) Scheduler() *Scheduler {
+	sched := &Scheduler{session: s}
+	if s.ad.InLine == nil {
+		return sched
+	}
+	for _, c := range s.ad.InLine.Creatives {
+		if c.Linear != nil {
+			sched.cues = Timeline(c.Linear.Duration, c.Linear.TrackingEvents)
+			break
+		}
+	}
+	return sched
+}
+
+// Cues returns the timeline of the scheduler.
+func (sc *Scheduler) Cues() []Cue {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return append([]Cue(nil), sc.cues...)
+}
+
+// Update fires the cues due at playhead which were not fired yet, and
+// returns the pings sent. A playhead at the duration of the creative fires
+// complete.
+func (sc *Scheduler) Update(ctx context.Context, playhead time.Duration) []Ping {
+	sc.mu.Lock()
+	var due []Cue
+	for sc.next < len(sc.cues) && sc.cues[sc.next].Offset <= playhead {
+		due = append(due, sc.cues[sc.next])
+		sc.next++
+	}
+	sc.mu.Unlock()
+
+	var pings []Ping
+	for _, c := range due {
+		key := c.Event
+		if c.Event == vast.EventProgress {
+			key += vast.EventType("@" + strconv.FormatInt(int64(c.Offset/time.Millisecond), 10))
+		}
+		pings = append(pings, sc.session.fireOnce(ctx, key, c.Event, c.URIs, nil)...)
+	}
+	return pings
+}
@@ -0,0 +1,57 @@
+package track
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeline(t *testing.T) {
+	trackings := []vast.Tracking{
+		{Event: "complete", URI: "http://t/complete"},
+		{Event: "progress", Offset: vast.OffsetDuration(5 * time.Second), URI: "http://t/5s"},
+		{Event: "progress", Offset: vast.OffsetPercent(0.1), URI: "http://t/10%"},
+		{Event: "progress", Offset: vast.OffsetDuration(2 * time.Second), URI: "http://t/2s"},
+		{Event: "progress", URI: "http://t/no-offset"},
+		{Event: "firstQuartile", URI: "http://t/q1"},
+		{Event: "start", URI: "http://t/start"},
+		{Event: "start", URI: "http://t/start"},
+		{Event: "pause", URI: "http://t/pause"},
+	}
+	assert.Equal(t, []Cue{
+		{Offset: 0, Event: vast.EventStart, URIs: []string{"http://t/start"}},
+		{Offset: 2 * time.Second, Event: vast.EventProgress, URIs: []string{"http://t/10%", "http://t/2s"}},
+		{Offset: 5 * time.Second, Event: vast.EventFirstQuartile, URIs: []string{"http://t/q1"}},
+		{Offset: 5 * time.Second, Event: vast.EventProgress, URIs: []string{"http://t/5s"}},
+		{Offset: 20 * time.Second, Event: vast.EventComplete, URIs: []string{"http://t/complete"}},
+	}, Timeline(vast.Duration(20*time.Second), trackings))
+}
+
+func TestScheduler(t *testing.T) {
+	rec := newRecorder()
+	defer rec.Close()
+	ad := parseAd(t, inlineDoc, rec.URL)
+	ad.AddTracking(vast.EventMidpoint, rec.URL+"/midpoint")
+	ad.InLine.Creatives[0].Linear.TrackingEvents = append(ad.InLine.Creatives[0].Linear.TrackingEvents,
+		vast.Tracking{Event: "progress", Offset: vast.OffsetDuration(3 * time.Second), URI: vast.URI(rec.URL + "/3s")})
+
+	sched := (&Tracker{}).NewSession(ad).Scheduler()
+	assert.Len(t, sched.Cues(), 4)
+	ctx := context.Background()
+	assert.Len(t, sched.Update(ctx, 0), 1)
+	assert.Empty(t, sched.Update(ctx, 2*time.Second))
+	// seeking forward fires every cue skipped
+	assert.Len(t, sched.Update(ctx, 8*time.Second), 2)
+	assert.Empty(t, sched.Update(ctx, time.Second))
+	pings := sched.Update(ctx, 15*time.Second)
+	if assert.Len(t, pings, 1) {
+		assert.Error(t, pings[0].Err)
+	}
+	assert.Empty(t, sched.Update(ctx, 15*time.Second))
+	assert.Equal(t, []string{"/3s", "/fail", "/midpoint", "/start"}, rec.received())
+
+	assert.Empty(t, (&Tracker{}).NewSession(&vast.Ad{}).Scheduler().Cues())
+}
@@ -0,0 +1,118 @@
+package track
+
+import (
+	"context"
+	"sync"
+
+	"github.com/haxqer/vast"
+)
+
+// onceEvents are the events tracked at most once per ad playback.
+var onceEvents = map[vast.EventType]bool{
+	vast.EventCreativeView:     true,
+	vast.EventLoaded:           true,
+	vast.EventStart:            true,
+	vast.EventFirstQuartile:    true,
+	vast.EventMidpoint:         true,
+	vast.EventThirdQuartile:    true,
+	vast.EventComplete:         true,
+	vast.EventCloseLinear:      true,
+	vast.EventSkip:             true,
+	vast.EventNotUsed:          true,
+	vast.EventInteractiveStart: true,
+}
+
+// Session fires the trackers of a single playback of an ad. Impressions and
+// one-time events such as start or complete are fired at most once per
+// session: a tracking URI successfully fired is never fired again, so that
+// the firing methods can be retried safely to resend the failed pings.
+// Other events, such as pause or mute, are fired every time.
+//
+// A Session is safe for concurrent use.
+type Session struct {
+	tracker *Tracker
+	ad      *vast.Ad
+
+	mu sync.Mutex
+	// The URIs fired or being fired, per one-time event
+	sent map[vast.EventType]map[string]bool
+	// The viewability decision reported, if decided
+	viewability Viewability
+	decided     bool
+}
+
+// NewSession returns a session firing the trackers of ad.
+func (t *Tracker) NewSession(ad *vast.Ad) *Session {
+	return &Session{tracker: t, ad: ad, sent: map[vast.EventType]map[string]bool{}}
+}
+
+// FireImpressions fires the impression tracking URIs of the ad not fired yet,
+// and returns the pings sent.
+func (s *Session) FireImpressions(ctx context.Context) []Ping {
+	return s.fireOnce(ctx, EventImpression, EventImpression, ImpressionURIs(s.ad), nil)
+}
+
+// FireEvent fires the tracking URIs of the ad for event, and returns the
+// pings sent. The URIs of one-time events already fired are skipped.
+func (s *Session) FireEvent(ctx context.Context, event vast.EventType) []Ping {
+	uris := EventURIs(s.ad, event)
+	if !onceEvents[event] {
+		return s.tracker.fire(ctx, event, uris, nil)
+	}
+	return s.fireOnce(ctx, event, event, uris, nil)
+}
+
+// Fired returns true if all the tracking URIs of the one-time event were
+// fired successfully, EventImpression standing for the impressions.
+func (s *Session) Fired(event vast.EventType) bool {
+	uris := ImpressionURIs(s.ad)
+	if event != EventImpression {
+		uris = EventURIs(s.ad, event)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sent := s.sent[event]
+	if sent == nil {
+		return false
+	}
+	for _, u := range uris {
+		if !sent[u] {
+			return false
+		}
+	}
+	return true
+}
+
+// fireOnce fires the uris of key not fired yet for event, as done by
+// Tracker.fire. The URIs are claimed before
+// being fired so that concurrent calls don't fire them twice, and released
+// if their ping failed.
+func (s *Session) fireOnce(ctx context.Context, key, event vast.EventType, uris []string, extra map[string]string) []Ping {
+	s.mu.Lock()
+	sent := s.sent[key]
+	if sent == nil {
+		sent = map[string]bool{}
+		s.sent[key] = sent
+	}
+	var pending []string
+	for _, u := range dedupe(uris) {
+		if !sent[u] {
+			sent[u] = true
+			pending = append(pending, u)
+		}
+	}
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	pings := s.tracker.fire(ctx, event, pending, extra)
+	s.mu.Lock()
+	for i, p := range pings {
+		if p.Err != nil {
+			delete(sent, pending[i])
+		}
+	}
+	s.mu.Unlock()
+	return pings
+}
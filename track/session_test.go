@@ -0,0 +1,87 @@
+package track
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionOnce(t *testing.T) {
+	rec := newRecorder()
+	defer rec.Close()
+	ad := parseAd(t, inlineDoc, rec.URL)
+	ad.AddTracking(vast.EventStart, rec.URL+"/start")
+	ad.AddTracking(vast.EventPause, rec.URL+"/pause")
+
+	s := (&Tracker{}).NewSession(ad)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.FireImpressions(context.Background())
+			s.FireEvent(context.Background(), vast.EventStart)
+		}()
+	}
+	wg.Wait()
+	assert.True(t, s.Fired(EventImpression))
+	assert.True(t, s.Fired(vast.EventStart))
+	assert.False(t, s.Fired(vast.EventMidpoint))
+
+	// pause isn't a one-time event
+	s.FireEvent(context.Background(), vast.EventPause)
+	s.FireEvent(context.Background(), vast.EventPause)
+	assert.Equal(t, []string{"/imp?cb=[CACHEBUSTING]", "/pause", "/pause", "/start"}, rec.received())
+}
+
+func TestSessionRetry(t *testing.T) {
+	var fails int32 = 1
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Path == "/flaky" && atomic.AddInt32(&fails, -1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	ad := &vast.Ad{InLine: &vast.InLine{Impressions: []vast.Impression{
+		{URI: vast.URI(srv.URL + "/ok")},
+		{URI: vast.URI(srv.URL + "/flaky")},
+		{URI: vast.URI(srv.URL + "/ok")},
+	}}}
+	s := (&Tracker{}).NewSession(ad)
+	pings := s.FireImpressions(context.Background())
+	assert.Len(t, pings, 2)
+	assert.False(t, s.Fired(EventImpression))
+
+	// only the failed ping is sent again
+	pings = s.FireImpressions(context.Background())
+	if assert.Len(t, pings, 1) {
+		assert.Equal(t, srv.URL+"/flaky", pings[0].URL)
+		assert.NoError(t, pings[0].Err)
+	}
+	assert.True(t, s.Fired(EventImpression))
+	assert.Empty(t, s.FireImpressions(context.Background()))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestTrackerDedupe(t *testing.T) {
+	ad := &vast.Ad{InLine: &vast.InLine{Impressions: []vast.Impression{{URI: "http://a"}, {URI: " http://a "}, {URI: "http://b"}}}}
+	pings := (&Tracker{Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody}, nil
+	})}}).FireImpressions(context.Background(), ad)
+	assert.Len(t, pings, 2)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
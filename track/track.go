@@ -0,0 +1,221 @@
+// Package track fires the impression and event tracking URIs of VAST ads, as
+// done by players and server-side ad insertion.
+package track
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/macro"
+)
+
+// Defaults of the Tracker settings
+const (
+	DefaultTimeout = 5 * time.Second
+	DefaultBackoff = 100 * time.Millisecond
+)
+
+// EventImpression is the event of the impression pings.
+const EventImpression = vast.EventType("impression")
+
+// Tracker fires tracking URIs. The zero value is ready to use, and a
+// Tracker is safe for concurrent use. A Tracker must not be copied after
+// first use.
+//
+// The tracking URIs of an ad only are fired: to fire the ones of the wrappers
+// an InLine ad was found through as well, pass the ad flattened with
+// vast.Flatten.
+type Tracker struct {
+	// The client sending the pings, http.DefaultClient if nil
+	Client *http.Client
+	// The User-Agent header of the pings, the one of the client if empty
+	UserAgent string
+	// Additional headers of the pings, e.g. X-Forwarded-For
+	Header http.Header
+	// Values of the macros of the tracking URIs, which are kept as is if nil
+	Macros *macro.Context
+	// Maximum time of a ping attempt, DefaultTimeout if zero
+	Timeout time.Duration
+	// Number of times a ping is retried after a network error or a 429 or
+	// 5xx response
+	MaxRetries int
+	// Delay before the first retry, doubled after every retry,
+	// DefaultBackoff if zero
+	Backoff time.Duration
+	// Receives every ping sent, if set
+	Metrics Metrics
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// Ping is a tracking URI fired.
+type Ping struct {
+	// The event tracked: an event type, EventImpression or a viewability
+	// decision
+	Event vast.EventType
+	// The URI fired, macros expanded
+	URL string
+	// The status code of the last response, 0 if the request failed
+	StatusCode int
+	// The number of requests sent, retries included
+	Attempts int
+	// The time spent sending the ping, retries included
+	Latency time.Duration
+	// The error of the last request, if any
+	Err error
+}
+
+// FireImpressions fires the impression tracking URIs of ad, and returns the
+// pings sent once all of them completed.
+func (t *Tracker) FireImpressions(ctx context.Context, ad *vast.Ad) []Ping {
+	return t.fire(ctx, EventImpression, ImpressionURIs(ad), nil)
+}
+
+// FireEvent fires the tracking URIs of ad for event, and returns the pings
+// sent once all of them completed.
+func (t *Tracker) FireEvent(ctx context.Context, ad *vast.Ad, event vast.EventType) []Ping {
+	return t.fire(ctx, event, EventURIs(ad, event), nil)
+}
+
+// ImpressionURIs returns the impression tracking URIs of ad, macros
+// unexpanded, as returned by (*vast.Ad).ImpressionURLs.
+func ImpressionURIs(ad *vast.Ad) []string {
+	return ad.ImpressionURLs()
+}
+
+// EventURIs returns the tracking URIs of ad for event, macros unexpanded, as
+// returned by (*vast.Ad).TrackingURLs.
+func EventURIs(ad *vast.Ad, event vast.EventType) []string {
+	return ad.TrackingURLs(event)
+}
+
+func appendURI(uris []string, uri vast.URI) []string {
+	if u := strings.TrimSpace(string(uri)); u != "" {
+		uris = append(uris, u)
+	}
+	return uris
+}
+
+// fire expands the macros of uris, with the values of extra on top of the
+// ones of t.Macros, and requests them concurrently, once per distinct URI.
+func (t *Tracker) fire(ctx context.Context, event vast.EventType, uris []string, extra map[string]string) []Ping {
+	uris = dedupe(uris)
+	values := map[string]string{}
+	if t.Macros != nil {
+		values = t.Macros.Values()
+	}
+	for k, v := range extra {
+		values[k] = v
+	}
+	urls := make([]string, len(uris))
+	for i, u := range uris {
+		urls[i] = macro.Expand(ctx, u, values)
+	}
+	return t.send(ctx, event, urls)
+}
+
+// send requests urls, whose macros are expanded, concurrently.
+func (t *Tracker) send(ctx context.Context, event vast.EventType, urls []string) []Ping {
+	pings := make([]Ping, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		pings[i].Event = event
+		pings[i].URL = u
+		wg.Add(1)
+		go func(p *Ping) {
+			defer wg.Done()
+			t.ping(ctx, p)
+			t.observe(*p)
+		}(&pings[i])
+	}
+	wg.Wait()
+	return pings
+}
+
+func dedupe(uris []string) []string {
+	seen := make(map[string]bool, len(uris))
+	out := uris[:0:0]
+	for _, u := range uris {
+		if !seen[u] {
+			seen[u] = true
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// ping sends p, retrying transient failures.
+func (t *Tracker) ping(ctx context.Context, p *Ping) {
+	start := time.Now()
+	defer func() { p.Latency = time.Since(start) }()
+	backoff := t.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	for {
+		p.Attempts++
+		transient := t.attempt(ctx, p)
+		if p.Err == nil || !transient || p.Attempts > t.MaxRetries {
+			return
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			p.Err = ctx.Err()
+			return
+		}
+	}
+}
+
+// attempt sends a single request for p, and reports whether its failure is
+// transient.
+func (t *Tracker) attempt(ctx context.Context, p *Ping) (transient bool) {
+	if p.Err = ctx.Err(); p.Err != nil {
+		return false
+	}
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, p.URL, nil)
+	if err != nil {
+		p.Err = err
+		return false
+	}
+	for k, v := range t.Header {
+		req.Header[k] = v
+	}
+	if t.UserAgent != "" {
+		req.Header.Set("User-Agent", t.UserAgent)
+	}
+	if h, ok := ctx.Value(headerKey{}).(http.Header); ok {
+		for k, v := range h {
+			req.Header[k] = v
+		}
+	}
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		p.StatusCode, p.Err = 0, err
+		return true
+	}
+	resp.Body.Close()
+	p.StatusCode, p.Err = resp.StatusCode, nil
+	if resp.StatusCode >= 400 {
+		p.Err = fmt.Errorf("unexpected status %s", resp.Status)
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	}
+	return false
+}
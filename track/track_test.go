@@ -0,0 +1,110 @@
+package track
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/haxqer/vast/macro"
+	"github.com/stretchr/testify/assert"
+)
+
+const inlineDoc = `<VAST version="4.1"><Ad id="inline"><InLine><AdSystem>DSP</AdSystem><AdTitle>Ad</AdTitle>` +
+	`<Impression><![CDATA[{{server}}/imp?cb=[CACHEBUSTING]]]></Impression><Impression><![CDATA[ ]]></Impression>` +
+	`<Creatives><Creative><Linear><Duration>00:00:15</Duration><TrackingEvents>` +
+	`<Tracking event="start"><![CDATA[{{server}}/start]]></Tracking>` +
+	`<Tracking event="creativeView"><![CDATA[{{server}}/view]]></Tracking>` +
+	`<Tracking event="complete"><![CDATA[{{server}}/fail]]></Tracking>` +
+	`</TrackingEvents><MediaFiles><MediaFile delivery="progressive" type="video/mp4" width="640" height="360"><![CDATA[https://cdn.example.com/ad.mp4]]></MediaFile></MediaFiles></Linear></Creative>` +
+	`<Creative><CompanionAds><Companion width="300" height="250"><StaticResource creativeType="image/png"><![CDATA[https://cdn.example.com/c.png]]></StaticResource>` +
+	`<TrackingEvents><Tracking event="creativeView"><![CDATA[{{server}}/companion]]></Tracking></TrackingEvents></Companion></CompanionAds></Creative>` +
+	`</Creatives></InLine></Ad></VAST>`
+
+// recorder is a tracking server recording the pings received.
+type recorder struct {
+	*httptest.Server
+	mu    sync.Mutex
+	paths []string
+	uas   []string
+}
+
+func newRecorder() *recorder {
+	rec := &recorder{}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.mu.Lock()
+		rec.paths = append(rec.paths, r.URL.RequestURI())
+		rec.uas = append(rec.uas, r.UserAgent()+"|"+r.Header.Get("X-Forwarded-For"))
+		rec.mu.Unlock()
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return rec
+}
+
+func (rec *recorder) received() []string {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	paths := append([]string(nil), rec.paths...)
+	sort.Strings(paths)
+	return paths
+}
+
+func parseAd(t *testing.T, doc, server string) *vast.Ad {
+	var v vast.VAST
+	if !assert.NoError(t, xml.Unmarshal([]byte(strings.Replace(doc, "{{server}}", server, -1)), &v)) {
+		t.FailNow()
+	}
+	return &v.Ads[0]
+}
+
+func TestFireImpressions(t *testing.T) {
+	rec := newRecorder()
+	defer rec.Close()
+	ad := parseAd(t, inlineDoc, rec.URL)
+
+	tr := &Tracker{
+		UserAgent: "player/1.0",
+		Header:    http.Header{"X-Forwarded-For": {"1.2.3.4"}},
+		Macros:    &macro.Context{CacheBusting: "42"},
+	}
+	pings := tr.FireImpressions(context.Background(), ad)
+	if assert.Len(t, pings, 1) {
+		assert.Equal(t, rec.URL+"/imp?cb=42", pings[0].URL)
+		assert.Equal(t, http.StatusOK, pings[0].StatusCode)
+		assert.NoError(t, pings[0].Err)
+	}
+	assert.Equal(t, []string{"/imp?cb=42"}, rec.received())
+	assert.Equal(t, []string{"player/1.0|1.2.3.4"}, rec.uas)
+}
+
+func TestFireEvent(t *testing.T) {
+	rec := newRecorder()
+	defer rec.Close()
+	ad := parseAd(t, inlineDoc, rec.URL)
+
+	tr := &Tracker{}
+	tr.FireEvent(context.Background(), ad, vast.EventCreativeView)
+	assert.Equal(t, []string{"/companion", "/view"}, rec.received())
+
+	pings := tr.FireEvent(context.Background(), ad, vast.EventComplete)
+	if assert.Len(t, pings, 1) {
+		assert.Equal(t, http.StatusNotFound, pings[0].StatusCode)
+		assert.EqualError(t, pings[0].Err, "unexpected status 404 Not Found")
+	}
+	assert.Empty(t, tr.FireEvent(context.Background(), ad, vast.EventMidpoint))
+}
+
+func TestEventURIsWrapper(t *testing.T) {
+	ad := &vast.Ad{Wrapper: &vast.Wrapper{Impressions: []vast.Impression{{URI: "https://t.example.com/w-imp"}}}}
+	ad.AddTracking(vast.EventStart, "https://t.example.com/w-start")
+	assert.Equal(t, []string{"https://t.example.com/w-imp"}, ImpressionURIs(ad))
+	assert.Equal(t, []string{"https://t.example.com/w-start"}, EventURIs(ad, vast.EventStart))
+	assert.Empty(t, EventURIs(&vast.Ad{}, vast.EventStart))
+}
@@ -0,0 +1,143 @@
+package track
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/haxqer/vast"
+)
+
+// Viewability is the viewability decision of an ad impression, as measured by
+// the player or a verification vendor.
+type Viewability int
+
+// Viewability decisions, matching the children of ViewableImpression
+const (
+	// The viewability couldn't be determined
+	ViewUndetermined Viewability = iota
+	// The ad met the viewability criteria
+	Viewable
+	// The ad was played but didn't meet the viewability criteria
+	NotViewable
+)
+
+// String implements the fmt.Stringer interface.
+func (v Viewability) String() string {
+	switch v {
+	case Viewable:
+		return "Viewable"
+	case NotViewable:
+		return "NotViewable"
+	}
+	return "ViewUndetermined"
+}
+
+// viewabilityKey is the key of the viewability URIs among the events of a
+// session.
+const viewabilityKey = vast.EventType("viewability")
+
+// event returns the event of the pings of the decision, e.g. "Viewable".
+func (v Viewability) event() vast.EventType {
+	return vast.EventType(v.String())
+}
+
+// ViewabilityURIs returns the ViewableImpression URIs of ad for the decision,
+// macros unexpanded.
+func ViewabilityURIs(ad *vast.Ad, decision Viewability) []string {
+	var vi *vast.ViewableImpression
+	switch {
+	case ad.InLine != nil:
+		vi = ad.InLine.ViewableImpression
+	case ad.Wrapper != nil:
+		vi = ad.Wrapper.ViewableImpression
+	}
+	if vi == nil {
+		return nil
+	}
+	list := vi.ViewUndetermined
+	switch decision {
+	case Viewable:
+		list = vi.Viewable
+	case NotViewable:
+		list = vi.NotViewable
+	}
+	var uris []string
+	for _, u := range list {
+		uris = appendURI(uris, u.URI)
+	}
+	return uris
+}
+
+// VerificationURIs returns the verificationNotExecuted tracking URIs of the
+// verifications of ad whose vendor is vendor, or of all of them if vendor is
+// empty, macros unexpanded.
+func VerificationURIs(ad *vast.Ad, vendor string) []string {
+	var verifications *[]vast.Verification
+	switch {
+	case ad.InLine != nil:
+		verifications = ad.InLine.AdVerifications
+	case ad.Wrapper != nil:
+		verifications = ad.Wrapper.AdVerifications
+	}
+	if verifications == nil {
+		return nil
+	}
+	var uris []string
+	for _, v := range *verifications {
+		if vendor != "" && !strings.EqualFold(v.Vendor, vendor) {
+			continue
+		}
+		for _, tr := range v.TrackingEvents {
+			if vast.EventType(tr.Event) == vast.EventVerificationNotExecuted {
+				uris = appendURI(uris, tr.URI)
+			}
+		}
+	}
+	return uris
+}
+
+// verificationValues returns the values of the verification macros.
+func (t *Tracker) verificationValues(vendor string, reason vast.VerificationReason) map[string]string {
+	values := map[string]string{"REASON": strconv.Itoa(int(reason))}
+	if vendor != "" && (t.Macros == nil || len(t.Macros.VerificationVendors) == 0) {
+		values["VERIFICATIONVENDORS"] = vendor
+	}
+	return values
+}
+
+// FireViewability fires the ViewableImpression URIs of ad for the decision,
+// and returns the pings sent.
+func (t *Tracker) FireViewability(ctx context.Context, ad *vast.Ad, decision Viewability) []Ping {
+	return t.fire(ctx, decision.event(), ViewabilityURIs(ad, decision), nil)
+}
+
+// FireVerificationNotExecuted fires the verificationNotExecuted tracking URIs
+// of the verifications of ad whose vendor is vendor, or of all of them if
+// vendor is empty, with the [REASON] macro replaced by reason. The
+// [VERIFICATIONVENDORS] macro defaults to vendor.
+func (t *Tracker) FireVerificationNotExecuted(ctx context.Context, ad *vast.Ad, vendor string, reason vast.VerificationReason) []Ping {
+	return t.fire(ctx, vast.EventVerificationNotExecuted, VerificationURIs(ad, vendor), t.verificationValues(vendor, reason))
+}
+
+// FireViewability fires the ViewableImpression URIs of the ad for the
+// decision, and returns the pings sent. A single decision is reported per
+// session: the decisions differing from the first one are ignored.
+func (s *Session) FireViewability(ctx context.Context, decision Viewability) []Ping {
+	s.mu.Lock()
+	if s.decided && s.viewability != decision {
+		s.mu.Unlock()
+		return nil
+	}
+	s.viewability, s.decided = decision, true
+	s.mu.Unlock()
+	return s.fireOnce(ctx, viewabilityKey, decision.event(), ViewabilityURIs(s.ad, decision), nil)
+}
+
+// FireVerificationNotExecuted fires the verificationNotExecuted tracking URIs
+// of the ad as done by Tracker.FireVerificationNotExecuted, once per session
+// and vendor.
+func (s *Session) FireVerificationNotExecuted(ctx context.Context, vendor string, reason vast.VerificationReason) []Ping {
+	key := vast.EventVerificationNotExecuted + vast.EventType("@"+strings.ToLower(vendor))
+	return s.fireOnce(ctx, key, vast.EventVerificationNotExecuted, VerificationURIs(s.ad, vendor), s.tracker.verificationValues(vendor, reason))
+}
@@ -0,0 +1,61 @@
+package track
+
+import (
+	"context"
+	"testing"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func viewabilityAd(server string) *vast.Ad {
+	return &vast.Ad{InLine: &vast.InLine{
+		ViewableImpression: &vast.ViewableImpression{
+			Viewable:         []vast.CDATAURI{{URI: vast.URI(server + "/viewable?cb=[CACHEBUSTING]")}, {URI: vast.URI(server + "/viewable?cb=[CACHEBUSTING]")}},
+			NotViewable:      []vast.CDATAURI{{URI: vast.URI(server + "/notviewable")}},
+			ViewUndetermined: []vast.CDATAURI{{URI: " "}},
+		},
+		AdVerifications: &[]vast.Verification{
+			{Vendor: "a.com-omid", TrackingEvents: []vast.Tracking{
+				{Event: "verificationNotExecuted", URI: vast.URI(server + "/vne-a?r=[REASON]&v=[VERIFICATIONVENDORS]")},
+			}},
+			{Vendor: "b.com-omid", TrackingEvents: []vast.Tracking{
+				{Event: "verificationNotExecuted", URI: vast.URI(server + "/vne-b?r=[REASON]")},
+			}},
+		},
+	}}
+}
+
+func TestFireViewability(t *testing.T) {
+	rec := newRecorder()
+	defer rec.Close()
+	ad := viewabilityAd(rec.URL)
+
+	tr := &Tracker{}
+	assert.Len(t, tr.FireViewability(context.Background(), ad, Viewable), 1)
+	assert.Empty(t, tr.FireViewability(context.Background(), ad, ViewUndetermined))
+	assert.Empty(t, ViewabilityURIs(&vast.Ad{InLine: &vast.InLine{}}, Viewable))
+
+	// a session reports a single decision
+	s := tr.NewSession(ad)
+	assert.Len(t, s.FireViewability(context.Background(), NotViewable), 1)
+	assert.Empty(t, s.FireViewability(context.Background(), NotViewable))
+	assert.Empty(t, s.FireViewability(context.Background(), Viewable))
+	assert.Equal(t, []string{"/notviewable", "/viewable?cb=[CACHEBUSTING]"}, rec.received())
+	assert.Equal(t, "NotViewable", NotViewable.String())
+}
+
+func TestFireVerificationNotExecuted(t *testing.T) {
+	rec := newRecorder()
+	defer rec.Close()
+	ad := viewabilityAd(rec.URL)
+
+	tr := &Tracker{}
+	assert.Len(t, tr.FireVerificationNotExecuted(context.Background(), ad, "A.com-omid", vast.VerificationNotSupported), 1)
+	assert.Equal(t, []string{"/vne-a?r=2&v=A.com-omid"}, rec.received())
+
+	s := tr.NewSession(ad)
+	assert.Len(t, s.FireVerificationNotExecuted(context.Background(), "", vast.VerificationLoadError), 2)
+	assert.Empty(t, s.FireVerificationNotExecuted(context.Background(), "", vast.VerificationLoadError))
+	assert.Equal(t, []string{"/vne-a?r=2&v=A.com-omid", "/vne-a?r=3&v=[VERIFICATIONVENDORS]", "/vne-b?r=3"}, rec.received())
+}
@@ -0,0 +1,152 @@
+package vast
+
+import "strings"
+
+// ImpressionURLs returns the impression tracking URIs of the ad, macros
+// unexpanded.
+func (a *Ad) ImpressionURLs() []string {
+	var imps []Impression
+	switch {
+	case a.InLine != nil:
+		imps = a.InLine.Impressions
+	case a.Wrapper != nil:
+		imps = a.Wrapper.Impressions
+	}
+	var urls []string
+	for _, imp := range imps {
+		urls = appendURL(urls, string(imp.URI))
+	}
+	return urls
+}
+
+// AllImpressions returns the impression tracking URIs of every ad of the
+// document, macros unexpanded.
+func (v *VAST) AllImpressions() []string {
+	var urls []string
+	for i := range v.Ads {
+		urls = append(urls, v.Ads[i].ImpressionURLs()...)
+	}
+	return urls
+}
+
+// TrackingURLs returns the tracking URIs of the ad for event, macros
+// unexpanded: the ones of the linear and non-linear creatives, and the ones
+// of the companions for creativeView events.
+func (a *Ad) TrackingURLs(event EventType) []string {
+	var urls []string
+	add := func(trackings []Tracking) {
+		for _, t := range trackings {
+			if EventType(t.Event) == event {
+				urls = appendURL(urls, string(t.URI))
+			}
+		}
+	}
+	switch {
+	case a.InLine != nil:
+		for i := range a.InLine.Creatives {
+			c := &a.InLine.Creatives[i]
+			if c.Linear != nil {
+				add(c.Linear.TrackingEvents)
+			}
+			if c.NonLinearAds != nil {
+				add(c.NonLinearAds.TrackingEvents)
+			}
+			if c.CompanionAds != nil && event == EventCreativeView {
+				for j := range c.CompanionAds.Companions {
+					add(c.CompanionAds.Companions[j].TrackingEvents)
+				}
+			}
+		}
+	case a.Wrapper != nil:
+		for i := range a.Wrapper.Creatives {
+			c := &a.Wrapper.Creatives[i]
+			if c.Linear != nil {
+				add(c.Linear.TrackingEvents)
+			}
+			if c.NonLinearAds != nil {
+				add(c.NonLinearAds.TrackingEvents)
+			}
+			if c.CompanionAds != nil && event == EventCreativeView {
+				for j := range c.CompanionAds.Companions {
+					add(c.CompanionAds.Companions[j].TrackingEvents)
+				}
+			}
+		}
+	}
+	return urls
+}
+
+func appendURL(urls []string, u string) []string {
+	if u = strings.TrimSpace(u); u != "" {
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// ClickThroughURL returns the URI to open when the user clicks on the linear
+// creative of the ad, or an empty string if there isn't any. Only InLine ads
+// define a click-through: one of a Wrapper is ignored, as wrappers contribute
+// click trackers only.
+func (a *Ad) ClickThroughURL() string {
+	if a.InLine == nil {
+		return ""
+	}
+	for i := range a.InLine.Creatives {
+		l := a.InLine.Creatives[i].Linear
+		if l == nil || l.VideoClicks == nil {
+			continue
+		}
+		for _, c := range l.VideoClicks.ClickThroughs {
+			if u := strings.TrimSpace(string(c.URI)); u != "" {
+				return u
+			}
+		}
+	}
+	return ""
+}
+
+// ClickTrackingURLs returns the click tracking URIs of the linear and
+// non-linear creatives of the ad, macros unexpanded. Once a wrapper chain is
+// flattened, they include the trackers of every wrapper.
+func (a *Ad) ClickTrackingURLs() []string {
+	var urls []string
+	add := func(vc *VideoClicks) {
+		if vc == nil {
+			return
+		}
+		for _, c := range vc.ClickTrackings {
+			urls = appendURL(urls, string(c.URI))
+		}
+	}
+	switch {
+	case a.InLine != nil:
+		for i := range a.InLine.Creatives {
+			c := &a.InLine.Creatives[i]
+			if c.Linear != nil {
+				add(c.Linear.VideoClicks)
+			}
+			if c.NonLinearAds != nil {
+				for _, nl := range c.NonLinearAds.NonLinears {
+					for _, t := range nl.NonLinearClickTrackings {
+						urls = appendURL(urls, string(t.URI))
+					}
+				}
+			}
+		}
+	case a.Wrapper != nil:
+		for i := range a.Wrapper.Creatives {
+			c := &a.Wrapper.Creatives[i]
+			if c.Linear != nil {
+				add(c.Linear.VideoClicks)
+			}
+			if c.NonLinearAds != nil {
+				for _, nl := range c.NonLinearAds.NonLinears {
+					for _, t := range nl.NonLinearClickTrackings {
+						urls = appendURL(urls, string(t.URI))
+					}
+				}
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,60 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackingURLs(t *testing.T) {
+	ad := Ad{InLine: &InLine{
+		Impressions: []Impression{{URI: " http://t/imp "}, {URI: ""}},
+		Creatives: []Creative{
+			{Linear: &Linear{TrackingEvents: []Tracking{
+				{Event: "start", URI: "http://t/linear-start"},
+				{Event: "creativeView", URI: "http://t/linear-view"},
+			}}},
+			{NonLinearAds: &NonLinearAds{TrackingEvents: []Tracking{{Event: "start", URI: "http://t/nonlinear-start"}}}},
+			{CompanionAds: &CompanionAds{Companions: []Companion{
+				{TrackingEvents: []Tracking{{Event: "creativeView", URI: "http://t/companion-view"}}},
+			}}},
+		},
+	}}
+	assert.Equal(t, []string{"http://t/imp"}, ad.ImpressionURLs())
+	assert.Equal(t, []string{"http://t/linear-start", "http://t/nonlinear-start"}, ad.TrackingURLs(EventStart))
+	assert.Equal(t, []string{"http://t/linear-view", "http://t/companion-view"}, ad.TrackingURLs(EventCreativeView))
+	assert.Empty(t, ad.TrackingURLs(EventComplete))
+
+	wrapper := Ad{Wrapper: &Wrapper{Impressions: []Impression{{URI: "http://t/w-imp"}}}}
+	wrapper.AddTracking(EventComplete, "http://t/w-complete")
+	assert.Equal(t, []string{"http://t/w-complete"}, wrapper.TrackingURLs(EventComplete))
+
+	v := VAST{Ads: []Ad{ad, wrapper, {}}}
+	assert.Equal(t, []string{"http://t/imp", "http://t/w-imp"}, v.AllImpressions())
+	assert.Empty(t, (&Ad{}).ImpressionURLs())
+}
+
+func TestClickURLs(t *testing.T) {
+	ad := Ad{InLine: &InLine{Creatives: []Creative{
+		{CompanionAds: &CompanionAds{}},
+		{Linear: &Linear{VideoClicks: &VideoClicks{
+			ClickThroughs:  []VideoClick{{URI: " "}, {URI: " http://t/landing "}, {URI: "http://t/other"}},
+			ClickTrackings: []VideoClick{{URI: "http://t/click"}},
+		}}},
+		{NonLinearAds: &NonLinearAds{NonLinears: []NonLinear{
+			{NonLinearClickTrackings: []NonLinearClickTracking{{URI: "http://t/nl-click"}}},
+		}}},
+	}}}
+	assert.Equal(t, "http://t/landing", ad.ClickThroughURL())
+	assert.Equal(t, []string{"http://t/click", "http://t/nl-click"}, ad.ClickTrackingURLs())
+
+	wrapper := Ad{Wrapper: &Wrapper{Creatives: []CreativeWrapper{
+		{Linear: &LinearWrapper{VideoClicks: &VideoClicks{ClickThroughs: []VideoClick{{URI: "http://t/ignored"}}}}},
+	}}}
+	wrapper.AddClickTracking("http://t/w-click")
+	assert.Empty(t, wrapper.ClickThroughURL())
+	assert.Equal(t, []string{"http://t/w-click"}, wrapper.ClickTrackingURLs())
+
+	assert.Empty(t, (&Ad{}).ClickThroughURL())
+	assert.Empty(t, (&Ad{}).ClickTrackingURLs())
+}
@@ -0,0 +1,72 @@
+package vast
+
+// UniversalAdIDs returns the universal ad ids of the creatives of an InLine
+// ad, in document order, ignoring the "unknown" placeholder.
+func (a *Ad) UniversalAdIDs() []UniversalAdID {
+	if a.InLine == nil {
+		return nil
+	}
+	var ids []UniversalAdID
+	for _, c := range a.InLine.Creatives {
+		if c.UniversalAdID != nil && c.UniversalAdID.ID != "" && c.UniversalAdID.ID != "unknown" {
+			ids = append(ids, *c.UniversalAdID)
+		}
+	}
+	return ids
+}
+
+// UniversalAdIDs returns the distinct universal ad ids of the creatives of
+// every ad of the document, in document order.
+func (v *VAST) UniversalAdIDs() []UniversalAdID {
+	var ids []UniversalAdID
+	seen := map[UniversalAdID]bool{}
+	for i := range v.Ads {
+		for _, id := range v.Ads[i].UniversalAdIDs() {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// SameCreative reports whether both ads share a creative, identified by its
+// universal ad id. Ads without universal ad ids are never the same.
+func (a *Ad) SameCreative(b *Ad) bool {
+	ids := a.UniversalAdIDs()
+	for _, id := range b.UniversalAdIDs() {
+		for _, other := range ids {
+			if id == other {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DedupeByCreative returns the ads keeping only the first one of those
+// sharing a creative, as reported by SameCreative, and the dropped ones. Ads
+// without universal ad ids are always kept.
+func DedupeByCreative(ads []*Ad) (kept, dropped []*Ad) {
+	seen := map[UniversalAdID]bool{}
+	for _, ad := range ads {
+		ids := ad.UniversalAdIDs()
+		dup := false
+		for _, id := range ids {
+			if seen[id] {
+				dup = true
+				break
+			}
+		}
+		if dup {
+			dropped = append(dropped, ad)
+			continue
+		}
+		for _, id := range ids {
+			seen[id] = true
+		}
+		kept = append(kept, ad)
+	}
+	return kept, dropped
+}
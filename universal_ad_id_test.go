@@ -0,0 +1,37 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniversalAdIDs(t *testing.T) {
+	uaid := func(ids ...string) *Ad {
+		in := &InLine{}
+		for _, id := range ids {
+			in.Creatives = append(in.Creatives, Creative{UniversalAdID: &UniversalAdID{IDRegistry: "Ad-ID", ID: id}})
+		}
+		return &Ad{InLine: in}
+	}
+	a, b, c, unknown := uaid("1", "2"), uaid("3"), uaid("2"), uaid("unknown")
+	wrapper := &Ad{Wrapper: &Wrapper{}}
+
+	assert.Equal(t, []UniversalAdID{{"Ad-ID", "1"}, {"Ad-ID", "2"}}, a.UniversalAdIDs())
+	assert.Empty(t, unknown.UniversalAdIDs())
+	assert.Empty(t, wrapper.UniversalAdIDs())
+
+	v := VAST{Ads: []Ad{*a, *b, *c, *unknown}}
+	assert.Equal(t, []UniversalAdID{{"Ad-ID", "1"}, {"Ad-ID", "2"}, {"Ad-ID", "3"}}, v.UniversalAdIDs())
+
+	assert.True(t, a.SameCreative(c))
+	assert.False(t, a.SameCreative(b))
+	assert.False(t, unknown.SameCreative(unknown))
+	assert.True(t, a.SameCreative(&Ad{InLine: &InLine{Creatives: []Creative{{UniversalAdID: &UniversalAdID{IDRegistry: "Ad-ID", ID: "1"}}}}}))
+	assert.False(t, a.SameCreative(&Ad{InLine: &InLine{Creatives: []Creative{{UniversalAdID: &UniversalAdID{IDRegistry: "other", ID: "1"}}}}}))
+
+	kept, dropped := DedupeByCreative([]*Ad{a, b, c, unknown, wrapper, uaid("3")})
+	assert.Equal(t, []*Ad{a, b, unknown, wrapper}, kept)
+	assert.Len(t, dropped, 2)
+	assert.Equal(t, c, dropped[0])
+}
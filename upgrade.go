@@ -0,0 +1,138 @@
+package vast
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Upgrade returns a copy of doc converted to target, a version at least as
+// recent as the effective version of doc:
+//
+//   - the version attribute is set to target, along with the VAST namespace
+//     from 4.0
+//   - the creatives lacking a UniversalAdId get the "unknown" one defined by
+//     VAST 4.0
+//   - linear creatives keep their first ClickThrough only, and wrappers none
+//     from 4.0, as only InLine ads may have one
+//   - from 4.1, InLine ads lacking an AdServingId get a new one, categories
+//     lacking an authority get the IAB one, the verifications of the legacy
+//     AdVerifications extensions are moved to the AdVerifications element,
+//     and the fullscreen and exitFullscreen events, deprecated, are renamed
+//     playerExpand and playerCollapse
+//
+// The requirements of target the copy still misses, such as impressions or
+// media files, can't be made up: they are returned as ValidationErrors along
+// with the copy.
+func Upgrade(doc *VAST, target SpecVersion) (*VAST, error) {
+	if doc == nil {
+		return nil, errors.New("upgrade: nil document")
+	}
+	if !target.Valid() {
+		return nil, fmt.Errorf("upgrade: unknown version %q", target)
+	}
+	if from := doc.EffectiveVersion(); from.Valid() && !target.AtLeast(from) {
+		return nil, fmt.Errorf("upgrade: %s is older than %s", target, from)
+	}
+	v := doc.Clone()
+	v.Version = string(target)
+	if target.AtLeast(Version4_0) && v.XMLNS == "" {
+		v.XMLNS = "http://www.iab.com/VAST"
+	}
+	for i := range v.Ads {
+		upgradeAd(&v.Ads[i], target)
+	}
+	if target.AtLeast(Version4_1) {
+		Walk(v, Visitor{Tracking: func(path string, t *Tracking) error {
+			switch EventType(t.Event) {
+			case EventFullscreen:
+				t.Event = string(EventPlayerExpand)
+			case EventExitFullscreen:
+				t.Event = string(EventPlayerCollapse)
+			}
+			return nil
+		}})
+	}
+	v.InferredVersion = inferVersion(v)
+
+	vd := &validator{version: target}
+	vd.vast("VAST", v)
+	return v, vd.result()
+}
+
+func upgradeAd(ad *Ad, target SpecVersion) {
+	if in := ad.InLine; in != nil {
+		for i := range in.Creatives {
+			c := &in.Creatives[i]
+			if target.AtLeast(Version4_0) && c.UniversalAdID == nil {
+				c.UniversalAdID = &UniversalAdID{IDRegistry: "unknown", ID: "unknown"}
+			}
+			if c.Linear != nil && c.Linear.VideoClicks != nil && len(c.Linear.VideoClicks.ClickThroughs) > 1 {
+				c.Linear.VideoClicks.ClickThroughs = c.Linear.VideoClicks.ClickThroughs[:1]
+			}
+		}
+		if target.AtLeast(Version4_1) {
+			if strings.TrimSpace(in.AdServingId) == "" {
+				name := DefaultAdSystem
+				if in.AdSystem != nil && strings.TrimSpace(in.AdSystem.Name) != "" {
+					name = strings.TrimSpace(in.AdSystem.Name)
+				}
+				in.AdServingId = NewAdServingID(name)
+			}
+			for i := range in.Categories {
+				if strings.TrimSpace(in.Categories[i].Authority) == "" {
+					in.Categories[i].Authority = IABCategoryAuthority
+				}
+			}
+			if in.Extensions != nil {
+				var exts []Extension
+				exts, in.AdVerifications = moveLegacyVerifications(*in.Extensions, in.AdVerifications)
+				in.Extensions = nil
+				if len(exts) > 0 {
+					in.Extensions = &exts
+				}
+			}
+		}
+	}
+	if w := ad.Wrapper; w != nil {
+		if target.AtLeast(Version4_0) {
+			for i := range w.Creatives {
+				if l := w.Creatives[i].Linear; l != nil && l.VideoClicks != nil {
+					l.VideoClicks.ClickThroughs = nil
+				}
+			}
+		}
+		if target.AtLeast(Version4_1) {
+			w.Extensions, w.AdVerifications = moveLegacyVerifications(w.Extensions, w.AdVerifications)
+		}
+	}
+}
+
+// moveLegacyVerifications moves the verifications of the AdVerifications
+// extensions to verifications, and returns the remaining extensions. The
+// extensions which can't be decoded are kept, and the verifications already
+// listed are not repeated.
+func moveLegacyVerifications(exts []Extension, verifications *[]Verification) ([]Extension, *[]Verification) {
+	var kept []Extension
+	for _, ext := range exts {
+		var legacy LegacyVerifications
+		if !strings.EqualFold(ext.Type, ExtensionAdVerifications) || ext.Decode(&legacy) != nil {
+			kept = append(kept, ext)
+			continue
+		}
+	next:
+		for _, lv := range legacy.Verifications {
+			if verifications == nil {
+				verifications = &[]Verification{}
+			}
+			for _, v := range *verifications {
+				if reflect.DeepEqual(v, lv) {
+					continue next
+				}
+			}
+			*verifications = append(*verifications, lv)
+		}
+	}
+	return kept, verifications
+}
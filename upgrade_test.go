@@ -0,0 +1,106 @@
+package vast
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpgrade(t *testing.T) {
+	doc, _, _, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	linear := doc.Ads[0].InLine.Creatives[0].Linear
+	linear.VideoClicks.ClickThroughs = append(linear.VideoClicks.ClickThroughs, VideoClick{URI: "https://second.example.com"})
+	linear.TrackingEvents = append(linear.TrackingEvents, Tracking{Event: "fullscreen", URI: "https://t.example.com/fs"}, Tracking{Event: "exitFullscreen", URI: "https://t.example.com/efs"})
+	doc.Ads[0].InLine.Categories = []Category{{Code: "IAB1"}}
+
+	up, err := Upgrade(doc, Version4_2)
+	assert.NoError(t, err)
+	assert.Equal(t, "4.2", up.Version)
+	assert.Equal(t, "http://www.iab.com/VAST", up.XMLNS)
+	assert.Equal(t, Version4_2, up.InferredVersion)
+	in := up.Ads[0].InLine
+	assert.NoError(t, ValidateAdServingID(in.AdServingId))
+	assert.Equal(t, IABCategoryAuthority, in.Categories[0].Authority)
+	for _, c := range in.Creatives {
+		assert.Equal(t, &UniversalAdID{IDRegistry: "unknown", ID: "unknown"}, c.UniversalAdID)
+	}
+	ul := in.Creatives[0].Linear
+	assert.Len(t, ul.VideoClicks.ClickThroughs, 1)
+	n := len(ul.TrackingEvents)
+	assert.Equal(t, "playerExpand", ul.TrackingEvents[n-2].Event)
+	assert.Equal(t, "playerCollapse", ul.TrackingEvents[n-1].Event)
+
+	// doc is left untouched
+	assert.Equal(t, "2.0", doc.Version)
+	assert.Empty(t, doc.Ads[0].InLine.AdServingId)
+	assert.Len(t, linear.VideoClicks.ClickThroughs, 2)
+
+	// 4.0 has no AdServingId nor event renaming
+	up, err = Upgrade(doc, Version4_0)
+	assert.NoError(t, err)
+	assert.Empty(t, up.Ads[0].InLine.AdServingId)
+	assert.Equal(t, "fullscreen", up.Ads[0].InLine.Creatives[0].Linear.TrackingEvents[n-2].Event)
+}
+
+func TestUpgradeLegacyVerifications(t *testing.T) {
+	doc := `<VAST version="3.0"><Ad><Wrapper>
+	<AdSystem>w</AdSystem>
+	<Impression>https://w.example.com/imp</Impression>
+	<VASTAdTagURI>https://w.example.com/vast.xml</VASTAdTagURI>
+	<Creatives><Creative><Linear><VideoClicks>
+		<ClickThrough>https://w.example.com/through</ClickThrough>
+		<ClickTracking>https://w.example.com/click</ClickTracking>
+	</VideoClicks></Linear></Creative></Creatives>
+	<Extensions>
+		<Extension type="AdVerifications"><AdVerifications>
+			<Verification vendor="a.com"><JavaScriptResource apiFramework="omid"><![CDATA[https://a.com/v.js]]></JavaScriptResource></Verification>
+			<Verification vendor="a.com"><JavaScriptResource apiFramework="omid"><![CDATA[https://a.com/v.js]]></JavaScriptResource></Verification>
+		</AdVerifications></Extension>
+		<Extension type="other"><Foo/></Extension>
+	</Extensions>
+	</Wrapper></Ad></VAST>`
+	var v VAST
+	if !assert.NoError(t, xml.Unmarshal([]byte(doc), &v)) {
+		return
+	}
+	up, err := Upgrade(&v, Version4_1)
+	assert.NoError(t, err)
+	w := up.Ads[0].Wrapper
+	if assert.NotNil(t, w.AdVerifications) && assert.Len(t, *w.AdVerifications, 1) {
+		assert.Equal(t, "a.com", (*w.AdVerifications)[0].Vendor)
+	}
+	if assert.Len(t, w.Extensions, 1) {
+		assert.Equal(t, "other", w.Extensions[0].Type)
+	}
+	assert.Empty(t, w.Creatives[0].Linear.VideoClicks.ClickThroughs)
+	assert.Len(t, w.Creatives[0].Linear.VideoClicks.ClickTrackings, 1)
+	assert.Equal(t, []VerificationResource{{Vendor: "a.com", APIFramework: "omid", URL: "https://a.com/v.js"}}, up.Ads[0].VerificationResources())
+}
+
+func TestUpgradeGaps(t *testing.T) {
+	doc := &VAST{Version: "2.0", Ads: []Ad{{InLine: &InLine{
+		AdSystem:  &AdSystem{Name: "s"},
+		AdTitle:   CDATAString{CDATA: "t"},
+		Creatives: []Creative{{Linear: &Linear{Duration: 1}}},
+	}}}}
+	up, err := Upgrade(doc, Version4_2)
+	var errs ValidationErrors
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.Equal(t, "invalid VAST.Ad[0].InLine.Impression: missing; invalid VAST.Ad[0].InLine.Creative[0].Linear.MediaFiles: missing", err.Error())
+	}
+	if assert.NotNil(t, up) {
+		assert.Equal(t, "4.2", up.Version)
+	}
+
+	_, err = Upgrade(doc, "5.0")
+	assert.EqualError(t, err, `upgrade: unknown version "5.0"`)
+	_, err = Upgrade(nil, Version4_2)
+	assert.EqualError(t, err, "upgrade: nil document")
+	_, err = Upgrade(&VAST{Version: "4.1"}, Version3_0)
+	assert.EqualError(t, err, "upgrade: 3.0 is older than 4.1")
+}
@@ -0,0 +1,108 @@
+package vast
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AllowedURISchemes lists the schemes accepted when decoding a URI, e.g.
+// []string{"http", "https"}. Decoding a URI with any other scheme fails.
+// URIs without scheme (protocol relative) or whose scheme is a macro such as
+// [PROTOCOL] are always accepted. The check is disabled when the list is empty,
+// which is the default.
+var AllowedURISchemes []string
+
+// URI is a URI found in a VAST document: a tracker, a click-through, a media
+// file, etc. It may contain VAST macros such as [CACHEBUSTING].
+type URI string
+
+// String implements the fmt.Stringer interface.
+func (u URI) String() string {
+	return string(u)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (u URI) MarshalText() ([]byte, error) {
+	return []byte(u), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. The
+// scheme is checked against AllowedURISchemes.
+func (u *URI) UnmarshalText(data []byte) error {
+	v := URI(data)
+	if err := v.checkScheme(AllowedURISchemes); err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// Scheme returns the scheme of the URI, lower-cased, or an empty string if
+// it has none.
+func (u URI) Scheme() string {
+	s := strings.TrimSpace(string(u))
+	i := strings.IndexByte(s, ':')
+	if i <= 0 || strings.ContainsAny(s[:i], "/?#[") {
+		return ""
+	}
+	return strings.ToLower(s[:i])
+}
+
+func (u URI) checkScheme(allowed []string) error {
+	s := strings.TrimSpace(string(u))
+	if len(allowed) == 0 || s == "" || strings.HasPrefix(s, "[") {
+		return nil
+	}
+	scheme := u.Scheme()
+	if scheme == "" {
+		return nil
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, scheme) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid URI scheme %q: %s", scheme, s)
+}
+
+// Host returns the host of the URI, without port, or an empty string if the
+// URI can't be parsed.
+func (u URI) Host() string {
+	parsed, err := url.Parse(strings.TrimSpace(string(u)))
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// WithQueryParam returns the URI with the query parameter key=value
+// appended. The rest of the URI is kept verbatim so that macros aren't
+// escaped.
+func (u URI) WithQueryParam(key, value string) URI {
+	s := strings.TrimSpace(string(u))
+	fragment := ""
+	if i := strings.IndexByte(s, '#'); i >= 0 {
+		s, fragment = s[:i], s[i:]
+	}
+	switch {
+	case !strings.Contains(s, "?"):
+		s += "?"
+	case !strings.HasSuffix(s, "?") && !strings.HasSuffix(s, "&"):
+		s += "&"
+	}
+	return URI(s + url.QueryEscape(key) + "=" + url.QueryEscape(value) + fragment)
+}
+
+// Equal returns true if both URIs are the same once surrounding spaces are
+// removed and percent-encoded macro brackets are decoded, so that
+// "http://t/?cb=[CACHEBUSTING]" equals "http://t/?cb=%5BCACHEBUSTING%5D".
+func (u URI) Equal(o URI) bool {
+	return u.normalize() == o.normalize()
+}
+
+var macroBrackets = strings.NewReplacer("%5B", "[", "%5b", "[", "%5D", "]", "%5d", "]")
+
+func (u URI) normalize() string {
+	return macroBrackets.Replace(strings.TrimSpace(string(u)))
+}
@@ -0,0 +1,47 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURIHost(t *testing.T) {
+	assert.Equal(t, "t.example.com", URI(" https://t.example.com:8080/imp?cb=[CACHEBUSTING] ").Host())
+	assert.Equal(t, "cdn.example.com", URI("//cdn.example.com/ad.mp4").Host())
+	assert.Equal(t, "", URI("[PROTOCOL]://t.example.com/").Host())
+}
+
+func TestURIWithQueryParam(t *testing.T) {
+	assert.Equal(t, URI("http://t/imp?a=b+c"), URI("http://t/imp").WithQueryParam("a", "b c"))
+	assert.Equal(t, URI("http://t/imp?cb=[CACHEBUSTING]&a=1"), URI("http://t/imp?cb=[CACHEBUSTING]").WithQueryParam("a", "1"))
+	assert.Equal(t, URI("http://t/imp?a=1"), URI("http://t/imp?").WithQueryParam("a", "1"))
+	assert.Equal(t, URI("http://t/imp?x=1&a=1#frag"), URI("http://t/imp?x=1#frag").WithQueryParam("a", "1"))
+}
+
+func TestURIEqual(t *testing.T) {
+	assert.True(t, URI("http://t/?cb=[CACHEBUSTING]").Equal(" http://t/?cb=%5BCACHEBUSTING%5D\n"))
+	assert.True(t, URI("http://t/?e=%5berrorcode%5d").Equal("http://t/?e=[errorcode]"))
+	assert.False(t, URI("http://t/?cb=[CACHEBUSTING]").Equal("http://t/?cb=[TIMESTAMP]"))
+}
+
+func TestURIScheme(t *testing.T) {
+	assert.Equal(t, "https", URI(" HTTPS://t/").Scheme())
+	assert.Equal(t, "", URI("//t/").Scheme())
+	assert.Equal(t, "", URI("/path?a=b:c").Scheme())
+
+	defer func(s []string) { AllowedURISchemes = s }(AllowedURISchemes)
+	var imp Impression
+	assert.NoError(t, xml.Unmarshal([]byte(`<Impression><![CDATA[javascript:alert(1)]]></Impression>`), &imp))
+
+	AllowedURISchemes = []string{"http", "https"}
+	if assert.NoError(t, xml.Unmarshal([]byte(`<Impression><![CDATA[HTTPS://t/imp]]></Impression>`), &imp)) {
+		assert.Equal(t, URI("HTTPS://t/imp"), imp.URI)
+	}
+	assert.NoError(t, xml.Unmarshal([]byte(`<Impression><![CDATA[//t/imp]]></Impression>`), &imp))
+	assert.NoError(t, xml.Unmarshal([]byte(`<Impression><![CDATA[[PROTOCOL]://t/imp]]></Impression>`), &imp))
+	assert.NoError(t, xml.Unmarshal([]byte(`<Impression></Impression>`), &imp))
+	err := xml.Unmarshal([]byte(`<Impression><![CDATA[javascript:alert(1)]]></Impression>`), &imp)
+	assert.EqualError(t, err, `invalid URI scheme "javascript": javascript:alert(1)`)
+}
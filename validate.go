@@ -0,0 +1,430 @@
+package vast
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ValidationError describes an element of a VAST document which doesn't
+// comply with the specification.
+type ValidationError struct {
+	// Path of the invalid element from the root of the validated value using
+	// XML element names, container elements being omitted, such as
+	// "VAST.Ad[0].InLine.Creative[1].Linear.MediaFile[0]"
+	Path string
+	// Name of the invalid element, such as "MediaFile"
+	Element string
+	// What is wrong with the element
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return "invalid " + e.Path + ": " + e.Reason
+}
+
+// ValidationErrors is the list of problems found while validating a value.
+type ValidationErrors []*ValidationError
+
+// Error implements the error interface.
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks the document against the requirements of its effective
+// version. It returns nil or ValidationErrors.
+func (v *VAST) Validate() error {
+	vd := &validator{version: v.EffectiveVersion()}
+	vd.vast("VAST", v)
+	return vd.result()
+}
+
+// Validate checks the linear creative. It returns nil or ValidationErrors.
+func (l *Linear) Validate() error {
+	vd := &validator{}
+	vd.linear("Linear", l)
+	return vd.result()
+}
+
+// Validate checks the media file. It returns nil or ValidationErrors.
+func (m *MediaFile) Validate() error {
+	vd := &validator{}
+	vd.mediaFile("MediaFile", m)
+	return vd.result()
+}
+
+// Validate checks the companion. It returns nil or ValidationErrors.
+func (c *Companion) Validate() error {
+	vd := &validator{}
+	vd.companion("Companion", c)
+	return vd.result()
+}
+
+// Validate checks the non-linear creative. It returns nil or ValidationErrors.
+func (n *NonLinear) Validate() error {
+	vd := &validator{}
+	vd.nonLinear("NonLinear", n)
+	return vd.result()
+}
+
+// validator walks a document accumulating the problems it finds. Version
+// specific requirements are only enforced when version is set.
+type validator struct {
+	version SpecVersion
+	errs    ValidationErrors
+	// audioOnly applies the audio checks to every ad, audio to the current
+	// one.
+	audioOnly bool
+	audio     bool
+}
+
+func (vd *validator) result() error {
+	if len(vd.errs) == 0 {
+		return nil
+	}
+	return vd.errs
+}
+
+func (vd *validator) fail(path, reason string) {
+	vd.errs = append(vd.errs, &ValidationError{Path: path, Element: elementName(path), Reason: reason})
+}
+
+// elementName returns the name of the last element of path, such as
+// "MediaFile" for "VAST.Ad[0].InLine.Creative[1].Linear.MediaFile[0]".
+func elementName(path string) string {
+	element := path[strings.LastIndexByte(path, '.')+1:]
+	if i := strings.IndexByte(element, '['); i >= 0 {
+		element = element[:i]
+	}
+	return element
+}
+
+func (vd *validator) atLeast(v SpecVersion) bool {
+	return vd.version != "" && vd.version.AtLeast(v)
+}
+
+func index(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+func (vd *validator) vast(path string, v *VAST) {
+	if !vd.version.Valid() {
+		vd.fail(path, "unknown version "+strconv.Quote(v.Version))
+	}
+	for i := range v.Ads {
+		vd.ad(index(path+".Ad", i), &v.Ads[i])
+	}
+}
+
+func (vd *validator) ad(path string, ad *Ad) {
+	switch {
+	case ad.InLine == nil && ad.Wrapper == nil:
+		vd.fail(path, "one of InLine or Wrapper is required")
+	case ad.InLine != nil && ad.Wrapper != nil:
+		vd.fail(path, "InLine and Wrapper are mutually exclusive")
+	}
+	if ad.Sequence < 0 {
+		vd.fail(path, "sequence must be positive")
+	}
+	vd.audio = vd.audioOnly || ad.AdType == AdTypeAudio
+	if ad.InLine != nil {
+		vd.inline(path+".InLine", ad.InLine)
+	}
+	if ad.Wrapper != nil {
+		vd.wrapper(path+".Wrapper", ad.Wrapper)
+	}
+}
+
+func (vd *validator) adSystem(path string, as *AdSystem) {
+	if as == nil || strings.TrimSpace(as.Name) == "" {
+		vd.fail(path, "missing")
+	}
+}
+
+func (vd *validator) impressions(path string, imps []Impression) {
+	if len(imps) == 0 {
+		vd.fail(path, "missing")
+	}
+}
+
+func (vd *validator) inline(path string, in *InLine) {
+	vd.adSystem(path+".AdSystem", in.AdSystem)
+	if strings.TrimSpace(in.AdTitle.CDATA) == "" {
+		vd.fail(path+".AdTitle", "missing")
+	}
+	vd.impressions(path+".Impression", in.Impressions)
+	if vd.atLeast(Version4_1) && strings.TrimSpace(in.AdServingId) == "" {
+		vd.fail(path+".AdServingId", "missing")
+	}
+	for i, c := range in.Categories {
+		if strings.TrimSpace(c.Code) == "" {
+			vd.fail(index(path+".Category", i), "missing code")
+		}
+		if vd.atLeast(Version4_1) && strings.TrimSpace(c.Authority) == "" {
+			vd.fail(index(path+".Category", i), "missing authority")
+		}
+	}
+	if len(in.Creatives) == 0 {
+		vd.fail(path+".Creatives", "missing")
+	}
+	for i := range in.Creatives {
+		vd.creative(index(path+".Creative", i), &in.Creatives[i])
+	}
+	vd.verifications(path+".AdVerifications", in.AdVerifications)
+}
+
+func (vd *validator) wrapper(path string, w *Wrapper) {
+	vd.adSystem(path+".AdSystem", w.AdSystem)
+	if strings.TrimSpace(string(w.VASTAdTagURI.URI)) == "" {
+		vd.fail(path+".VASTAdTagURI", "missing")
+	}
+	vd.impressions(path+".Impression", w.Impressions)
+	vd.verifications(path+".AdVerifications", w.AdVerifications)
+	for i, c := range w.Creatives {
+		cpath := index(path+".Creative", i)
+		if vd.audio && c.NonLinearAds != nil {
+			vd.fail(cpath+".NonLinearAds", "not allowed in audio ad")
+		}
+		if c.Linear != nil {
+			for j := range c.Linear.TrackingEvents {
+				vd.tracking(index(cpath+".Linear.Tracking", j), &c.Linear.TrackingEvents[j])
+			}
+			if vd.audio && c.Linear.Icons != nil {
+				vd.fail(cpath+".Linear.Icons", "not allowed in audio ad")
+			}
+			if c.Linear.Icons != nil {
+				for j := range c.Linear.Icons.Icon {
+					vd.icon(index(cpath+".Linear.Icons.Icon", j), &c.Linear.Icons.Icon[j])
+				}
+			}
+		}
+	}
+}
+
+func (vd *validator) verifications(path string, verifications *[]Verification) {
+	if verifications == nil {
+		return
+	}
+	for i, v := range *verifications {
+		vpath := index(path+".Verification", i)
+		if len(v.JavaScriptResources) == 0 && len(v.ExecutableResources) == 0 {
+			vd.fail(vpath, "one of JavaScriptResource or ExecutableResource is required")
+		}
+		for j, r := range v.JavaScriptResources {
+			if strings.TrimSpace(string(r.URI)) == "" {
+				vd.fail(index(vpath+".JavaScriptResource", j), "missing URI")
+			}
+		}
+		for j, r := range v.ExecutableResources {
+			if strings.TrimSpace(string(r.URI)) == "" {
+				vd.fail(index(vpath+".ExecutableResource", j), "missing URI")
+			}
+		}
+		for j := range v.TrackingEvents {
+			t := &v.TrackingEvents[j]
+			if t.Event != "" && !EventType(t.Event).IsValidFor(VerificationContext) {
+				vd.fail(index(vpath+".Tracking", j), "invalid event "+strconv.Quote(t.Event))
+			}
+			vd.tracking(index(vpath+".Tracking", j), t)
+		}
+	}
+}
+
+func (vd *validator) creative(path string, c *Creative) {
+	if c.Linear == nil && c.CompanionAds == nil && c.NonLinearAds == nil {
+		vd.fail(path, "one of Linear, CompanionAds or NonLinearAds is required")
+	}
+	if vd.atLeast(Version4_0) && c.UniversalAdID == nil {
+		vd.fail(path+".UniversalAdId", "missing")
+	}
+	if c.Linear != nil {
+		vd.linear(path+".Linear", c.Linear)
+	}
+	if c.CompanionAds != nil {
+		switch c.CompanionAds.Required {
+		case "", "all", "any", "none":
+		default:
+			vd.fail(path+".CompanionAds", "invalid required "+strconv.Quote(c.CompanionAds.Required))
+		}
+		for i := range c.CompanionAds.Companions {
+			vd.companion(index(path+".CompanionAds.Companion", i), &c.CompanionAds.Companions[i])
+		}
+	}
+	if c.NonLinearAds != nil {
+		if vd.audio {
+			vd.fail(path+".NonLinearAds", "not allowed in audio ad")
+		}
+		for i := range c.NonLinearAds.TrackingEvents {
+			vd.tracking(index(path+".NonLinearAds.Tracking", i), &c.NonLinearAds.TrackingEvents[i])
+		}
+		for i := range c.NonLinearAds.NonLinears {
+			vd.nonLinear(index(path+".NonLinearAds.NonLinear", i), &c.NonLinearAds.NonLinears[i])
+		}
+	}
+}
+
+func (vd *validator) linear(path string, l *Linear) {
+	if l.Duration <= 0 {
+		vd.fail(path+".Duration", "must be positive")
+	}
+	if l.SkipOffset != nil {
+		vd.offset(path, "skipoffset", l.SkipOffset)
+	}
+	if len(l.MediaFiles) == 0 {
+		vd.fail(path+".MediaFiles", "missing")
+	}
+	for i := range l.MediaFiles {
+		vd.mediaFile(index(path+".MediaFile", i), &l.MediaFiles[i])
+	}
+	for i := range l.Mezzanines {
+		vd.mezzanine(index(path+".Mezzanine", i), &l.Mezzanines[i])
+	}
+	for i, f := range l.InteractiveCreativeFiles {
+		if strings.TrimSpace(string(f.URI)) == "" {
+			vd.fail(index(path+".InteractiveCreativeFile", i), "missing URI")
+		}
+	}
+	if l.ClosedCaptionFiles != nil {
+		for i, f := range l.ClosedCaptionFiles.ClosedCaptionFile {
+			p := index(path+".ClosedCaptionFiles.ClosedCaptionFile", i)
+			if strings.TrimSpace(string(f.URI)) == "" {
+				vd.fail(p, "missing URI")
+			}
+			if f.Type == "" {
+				vd.fail(p, "missing type")
+			}
+		}
+	}
+	for i := range l.TrackingEvents {
+		vd.tracking(index(path+".Tracking", i), &l.TrackingEvents[i])
+	}
+	if vd.audio && l.Icons != nil {
+		vd.fail(path+".Icons", "not allowed in audio ad")
+	}
+	if l.Icons != nil {
+		for i := range l.Icons.Icon {
+			vd.icon(index(path+".Icons.Icon", i), &l.Icons.Icon[i])
+		}
+	}
+}
+
+func (vd *validator) offset(path, attr string, o *Offset) {
+	if o.Duration == nil && (o.Percent < 0 || o.Percent > 1) {
+		vd.fail(path, attr+" percentage must be between 0% and 100%")
+	}
+	if o.Duration != nil && *o.Duration < 0 {
+		vd.fail(path, attr+" must be positive")
+	}
+}
+
+func (vd *validator) mediaFile(path string, m *MediaFile) {
+	if strings.TrimSpace(string(m.URI)) == "" {
+		vd.fail(path, "missing URI")
+	}
+	if i := strings.IndexByte(m.Type, '/'); i <= 0 || i == len(m.Type)-1 {
+		vd.fail(path, "invalid MIME type "+strconv.Quote(m.Type))
+	}
+	if vd.audio && !m.IsAudio() && !IsAdaptiveMIMEType(m.Type) {
+		vd.fail(path, "not an audio MIME type "+strconv.Quote(m.Type))
+	}
+	if m.Delivery != DeliveryProgressive && m.Delivery != DeliveryStreaming {
+		vd.fail(path, "invalid delivery "+strconv.Quote(string(m.Delivery)))
+	}
+	if m.Bitrate < 0 || m.MinBitrate < 0 || m.MaxBitrate < 0 {
+		vd.fail(path, "negative bitrate")
+		return
+	}
+	switch {
+	case m.Bitrate > 0 && (m.MinBitrate > 0 || m.MaxBitrate > 0):
+		vd.fail(path, "bitrate and minBitrate/maxBitrate are mutually exclusive")
+	case (m.MinBitrate > 0) != (m.MaxBitrate > 0):
+		vd.fail(path, "minBitrate and maxBitrate must be supplied together")
+	case m.MinBitrate > m.MaxBitrate:
+		vd.fail(path, "minBitrate is greater than maxBitrate")
+	}
+	if IsAdaptiveMIMEType(m.Type) {
+		if m.Delivery != DeliveryStreaming {
+			vd.fail(path, m.Type+" requires streaming delivery")
+		}
+		if m.Bitrate > 0 {
+			vd.fail(path, m.Type+" must use minBitrate/maxBitrate instead of bitrate")
+		}
+	}
+}
+
+func (vd *validator) mezzanine(path string, m *Mezzanine) {
+	if strings.TrimSpace(string(m.URI)) == "" {
+		vd.fail(path, "missing URI")
+	}
+	if i := strings.IndexByte(m.Type, '/'); i <= 0 || i == len(m.Type)-1 {
+		vd.fail(path, "invalid MIME type "+strconv.Quote(m.Type))
+	}
+	if m.Delivery != DeliveryProgressive && m.Delivery != DeliveryStreaming {
+		vd.fail(path, "invalid delivery "+strconv.Quote(string(m.Delivery)))
+	}
+	if m.Width <= 0 || m.Height <= 0 {
+		vd.fail(path, "width and height must be positive")
+	}
+}
+
+func (vd *validator) tracking(path string, t *Tracking) {
+	if t.Event == "" {
+		vd.fail(path, "missing event")
+	}
+	if EventType(t.Event) == EventProgress && t.Offset == nil {
+		vd.fail(path, "progress event requires an offset")
+	}
+	if t.Offset != nil {
+		vd.offset(path, "offset", t.Offset)
+	}
+}
+
+func (vd *validator) resources(path string, static *StaticResource, iframe *CDATAURI, html *HTMLResource) {
+	if resourceCount(static, iframe, html) == 0 {
+		vd.fail(path, "one of StaticResource, IFrameResource or HTMLResource is required")
+	}
+}
+
+func (vd *validator) companion(path string, c *Companion) {
+	if c.Width <= 0 || c.Height <= 0 {
+		vd.fail(path, "width and height must be positive")
+	}
+	vd.resources(path, c.StaticResource, c.IFrameResource, c.HTMLResource)
+	if vd.audio && c.StaticResource != nil && strings.HasPrefix(baseMIMEType(c.StaticResource.CreativeType), "image/") {
+		vd.fail(path, "image companion not allowed in audio ad")
+	}
+	for i := range c.TrackingEvents {
+		vd.tracking(index(path+".Tracking", i), &c.TrackingEvents[i])
+	}
+}
+
+func (vd *validator) nonLinear(path string, n *NonLinear) {
+	if n.Width <= 0 || n.Height <= 0 {
+		vd.fail(path, "width and height must be positive")
+	}
+	vd.resources(path, n.StaticResource, n.IFrameResource, n.HTMLResource)
+}
+
+func (vd *validator) icon(path string, i *Icon) {
+	if i.Width <= 0 || i.Height <= 0 {
+		vd.fail(path, "width and height must be positive")
+	}
+	if !i.XPosition.Valid() {
+		vd.fail(path, "invalid xPosition "+strconv.Quote(string(i.XPosition)))
+	}
+	if !i.YPosition.Valid() {
+		vd.fail(path, "invalid yPosition "+strconv.Quote(string(i.YPosition)))
+	}
+	vd.resources(path, i.StaticResource, i.IFrameResource, i.HTMLResource)
+	if i.IconClickFallbackImages != nil {
+		for j, img := range *i.IconClickFallbackImages {
+			if img.StaticResource == nil || strings.TrimSpace(string(img.StaticResource.URI)) == "" {
+				vd.fail(index(path+".IconClicks.IconClickFallbackImages.IconClickFallbackImage", j), "missing StaticResource")
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package vast
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validInLineVAST(version string) *VAST {
+	return &VAST{
+		Version: version,
+		Ads: []Ad{
+			{
+				InLine: &InLine{
+					AdSystem:    &AdSystem{Name: "DSP"},
+					AdTitle:     CDATAString{CDATA: "title"},
+					AdServingId: "DSP-1",
+					Impressions: []Impression{{URI: "http://track.example.com/imp"}},
+					Creatives: []Creative{
+						{
+							UniversalAdID: &UniversalAdID{IDRegistry: "Ad-ID", ID: "1"},
+							Linear: &Linear{
+								Duration: Duration(15 * time.Second),
+								MediaFiles: []MediaFile{
+									{Delivery: "progressive", Type: "video/mp4", URI: "http://cdn.example.com/ad.mp4"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestValidateValid(t *testing.T) {
+	for _, version := range []string{"2.0", "3.0", "4.0", "4.1", "4.2"} {
+		assert.NoError(t, validInLineVAST(version).Validate(), version)
+	}
+}
+
+func TestValidateVersionRequirements(t *testing.T) {
+	v := validInLineVAST("4.1")
+	v.Ads[0].InLine.AdServingId = ""
+	v.Ads[0].InLine.Creatives[0].UniversalAdID = nil
+	assert.EqualError(t, v.Validate(), "invalid VAST.Ad[0].InLine.AdServingId: missing; invalid VAST.Ad[0].InLine.Creative[0].UniversalAdId: missing")
+
+	v.Version = "3.0"
+	assert.NoError(t, v.Validate())
+
+	v.Version = "bogus"
+	assert.EqualError(t, v.Validate(), `invalid VAST: unknown version "bogus"`)
+}
+
+func TestValidateErrors(t *testing.T) {
+	v := &VAST{
+		Version: "3.0",
+		Ads: []Ad{
+			{},
+			{
+				Sequence: -1,
+				Wrapper:  &Wrapper{},
+			},
+			{
+				InLine: &InLine{
+					AdSystem:    &AdSystem{Name: "DSP"},
+					AdTitle:     CDATAString{CDATA: "title"},
+					Impressions: []Impression{{URI: "http://track.example.com/imp"}},
+					Creatives: []Creative{
+						{
+							Linear: &Linear{
+								Duration: Duration(time.Second),
+								MediaFiles: []MediaFile{
+									{Delivery: "progressive", Type: "video/mp4", URI: "http://cdn.example.com/ad.mp4"},
+								},
+								TrackingEvents: []Tracking{{Event: Event_type_progress}},
+							},
+						},
+						{
+							CompanionAds: &CompanionAds{
+								Required:   "some",
+								Companions: []Companion{{Width: 300, Height: 250}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	err := v.Validate()
+	var errs ValidationErrors
+	if assert.True(t, errors.As(err, &errs)) {
+		var got []string
+		for _, e := range errs {
+			got = append(got, e.Element+" "+e.Error())
+		}
+		assert.Equal(t, []string{
+			"Ad invalid VAST.Ad[0]: one of InLine or Wrapper is required",
+			"Ad invalid VAST.Ad[1]: sequence must be positive",
+			"AdSystem invalid VAST.Ad[1].Wrapper.AdSystem: missing",
+			"VASTAdTagURI invalid VAST.Ad[1].Wrapper.VASTAdTagURI: missing",
+			"Impression invalid VAST.Ad[1].Wrapper.Impression: missing",
+			"Tracking invalid VAST.Ad[2].InLine.Creative[0].Linear.Tracking[0]: progress event requires an offset",
+			`CompanionAds invalid VAST.Ad[2].InLine.Creative[1].CompanionAds: invalid required "some"`,
+			"Companion invalid VAST.Ad[2].InLine.Creative[1].CompanionAds.Companion[0]: one of StaticResource, IFrameResource or HTMLResource is required",
+		}, got)
+	}
+}
+
+func TestValidateFixtures(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast_inline_linear.xml")
+	if assert.NoError(t, err) {
+		assert.NoError(t, v.Validate())
+	}
+}
+
+func TestValidateVerifications(t *testing.T) {
+	v := validInLineVAST("4.1")
+	v.Ads[0].InLine.AdVerifications = &[]Verification{
+		{Vendor: "a"},
+		{
+			Vendor:              "b",
+			JavaScriptResources: []JavaScriptResource{{APIFramework: "omid"}},
+			TrackingEvents:      []Tracking{{Event: "start", URI: "http://t/"}},
+		},
+	}
+	assert.EqualError(t, v.Validate(), "invalid VAST.Ad[0].InLine.AdVerifications.Verification[0]: one of JavaScriptResource or ExecutableResource is required; "+
+		"invalid VAST.Ad[0].InLine.AdVerifications.Verification[1].JavaScriptResource[0]: missing URI; "+
+		`invalid VAST.Ad[0].InLine.AdVerifications.Verification[1].Tracking[0]: invalid event "start"`)
+}
+
+func TestValidateIconClickFallbackImages(t *testing.T) {
+	v := validInLineVAST("4.1")
+	v.Ads[0].InLine.Creatives[0].Linear.Icons = &Icons{Icon: []Icon{{
+		Program:                 "AdChoices",
+		Width:                   20,
+		Height:                  20,
+		XPosition:               IconRight,
+		YPosition:               IconTop,
+		StaticResource:          &StaticResource{CreativeType: "image/png", URI: "http://cdn.example.com/icon.png"},
+		IconClickFallbackImages: &[]IconClickFallbackImage{{AltText: "AdChoices"}},
+	}}}
+	assert.EqualError(t, v.Validate(), "invalid VAST.Ad[0].InLine.Creative[0].Linear.Icons.Icon[0].IconClicks.IconClickFallbackImages.IconClickFallbackImage[0]: missing StaticResource")
+}
+
+func TestValidateCategories(t *testing.T) {
+	v := validInLineVAST("4.1")
+	v.Ads[0].InLine.Categories = []Category{{Authority: IABCategoryAuthority, Code: "IAB1-6"}, {Code: "IAB1"}, {Authority: "iabtechlab.com"}}
+	assert.EqualError(t, v.Validate(), "invalid VAST.Ad[0].InLine.Category[1]: missing authority; invalid VAST.Ad[0].InLine.Category[2]: missing code")
+
+	v.Version = "4.0"
+	assert.EqualError(t, v.Validate(), "invalid VAST.Ad[0].InLine.Category[2]: missing code")
+}
@@ -1,12 +1,22 @@
 // Package vast implements IAB VAST 3.0 specification http://www.iab.net/media/file/VASTv3.0.pdf
 package vast
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"time"
+)
 
 // VAST is the root <VAST> tag
 type VAST struct {
 	// The version of the VAST spec (should be either "2.0" or "3.0")
 	Version string `xml:"version,attr" json:",omitempty"`
+	// The version inferred from the elements present when the document was
+	// parsed. It is the declared version unless that one was missing, invalid,
+	// or too old for the elements used.
+	InferredVersion SpecVersion `xml:"-" json:"-"`
+	// The time the document was received, used to compute the expiry of
+	// its ads. It is not part of the document and must be set by the caller.
+	ReceivedAt time.Time `xml:"-" json:"-"`
 	// XML namespace. Most likely 'http://www.iab.com/VAST'
 	XMLNS string `xml:"xmlns,attr,omitempty" json:"xmlns,omitempty"`
 	// One or more Ad elements. Advertisers and video content publishers may
@@ -17,7 +27,7 @@ type VAST struct {
 	Ads []Ad `xml:"Ad,omitempty" json:"Ad,omitempty"`
 	// Contains a URI to a tracking resource that the video player should request
 	// upon receiving a “no ad” response
-	Errors []CDATAString `xml:"Error,omitempty" json:",omitempty"`
+	Errors []CDATAURI `xml:"Error,omitempty" json:",omitempty"`
 
 	Mute bool `xml:"mute,attr,omitempty" json:",omitempty"`
 }
@@ -36,7 +46,10 @@ type Ad struct {
 	Sequence int      `xml:"sequence,attr,omitempty" json:",omitempty"`
 	// An optional string that identifies the type of ad
 	// Possible values –video, audio, hybrid. Assumed to be video if attribute is not present
-	AdType string `xml:"adType,attr,omitempty" json:",omitempty"`
+	AdType AdType `xml:"adType,attr,omitempty" json:",omitempty"`
+	// Whether the ad is a programmatic unit, such as VPAID, which may choose
+	// not to play. Deprecated in VAST 4.1 but still widely used.
+	ConditionalAd *Bool `xml:"conditionalAd,attr,omitempty" json:",omitempty"`
 }
 
 // CDATAString ...
@@ -44,6 +57,12 @@ type CDATAString struct {
 	CDATA string `xml:",cdata" json:"Data"`
 }
 
+// CDATAURI is an element holding a URI only, such as Error or VASTAdTagURI,
+// encoded as CDATA.
+type CDATAURI struct {
+	URI URI `xml:",cdata" json:"Data"`
+}
+
 // InLine is a vast <InLine> ad element containing actual ad definition
 //
 // The last ad server in the ad supply chain serves an <InLine> element.
@@ -54,7 +73,7 @@ type InLine struct {
 	AdSystem *AdSystem
 	// A URI representing an error-tracking pixel; this element can occur multiple
 	// times.
-	Errors []CDATAString `xml:"Error,omitempty" json:"Error,omitempty"`
+	Errors []CDATAURI `xml:"Error,omitempty" json:"Error,omitempty"`
 	// XML node for custom extensions, as defined by the ad server. When used, a
 	// custom element should be nested under <Extensions> to help separate custom
 	// XML elements from VAST elements. The following example includes a custom
@@ -63,6 +82,9 @@ type InLine struct {
 	// One or more URIs that directs the video player to a tracking resource file that the
 	// video player should request when the first frame of the ad is displayed
 	Impressions []Impression `xml:"Impression"`
+	// URIs to ping once the viewability of the ad has been determined
+	// (VAST 4.x)
+	ViewableImpression *ViewableImpression `xml:",omitempty" json:",omitempty"`
 	// Provides a value that represents a price that can be used by real-time bidding
 	// (RTB) systems. VAST is not designed to handle RTB since other methods exist,
 	// but this element is offered for custom solutions if needed.
@@ -79,24 +101,97 @@ type InLine struct {
 	// to interpret values provided within this element. As with any optional
 	// elements, the video player is not required to support it.
 	Advertiser string `xml:",omitempty" json:",omitempty"`
+	// The categories of the ad content, using codes defined by the category
+	// authority such as an IAB Content Taxonomy (VAST 4.1+)
+	Categories []Category `xml:"Category,omitempty" json:"Category,omitempty"`
 	// The container for one or more <Creative> elements
 	Creatives []Creative `xml:"Creatives>Creative"`
 	// A string value that provides a longer description of the ad.
 	Description *CDATAString `xml:",omitempty" json:",omitempty"`
-	// A URI to a survey vendor that could be the survey, a tracking pixel,
+	// The number of seconds the ad can be cached after the response was
+	// received (VAST 4.1+)
+	Expires *Expires `xml:",omitempty" json:",omitempty"`
+	// URIs to a survey vendor that could be the survey, a tracking pixel,
 	// or anything to do with the survey. Multiple survey elements can be provided.
-	// A type attribute is available to specify the MIME type being served.
-	// For example, the attribute might be set to type=”text/javascript”.
 	// Surveys can be dynamically inserted into the VAST response as long as
 	// cross-domain issues are avoided.
-	Survey *CDATAString `xml:",omitempty" json:",omitempty"`
+	Surveys []Survey `xml:"Survey,omitempty" json:"Survey,omitempty"`
+	// The resources needed by verification vendors, such as Open Measurement
+	// scripts, to measure the ad (VAST 4.1+)
+	AdVerifications *[]Verification `xml:"AdVerifications>Verification,omitempty" json:",omitempty"`
+}
+
+// Verification contains the resources and metadata required to execute
+// third-party measurement code in order to verify the use of the ad.
+type Verification struct {
+	// An identifier for the verification vendor, e.g. "company.com-omid"
+	Vendor string `xml:"vendor,attr,omitempty" json:",omitempty"`
+	// JavaScript verification scripts
+	JavaScriptResources []JavaScriptResource `xml:"JavaScriptResource,omitempty" json:",omitempty"`
+	// Verification code executed by non-browser players
+	ExecutableResources []ExecutableResource `xml:"ExecutableResource,omitempty" json:",omitempty"`
+	// The verificationNotExecuted event, fired when the player did not
+	// execute the verification code
+	TrackingEvents []Tracking `xml:"TrackingEvents>Tracking,omitempty" json:",omitempty"`
+	// Parameters passed to the verification code as is
+	VerificationParameters *CDATAString `xml:",omitempty" json:",omitempty"`
+}
+
+// JavaScriptResource is a JavaScript verification script.
+type JavaScriptResource struct {
+	// The API framework used to execute the script, e.g. "omid"
+	APIFramework string `xml:"apiFramework,attr,omitempty" json:",omitempty"`
+	// Whether the script can be executed outside of a browser
+	BrowserOptional Bool `xml:"browserOptional,attr,omitempty" json:",omitempty"`
+	URI             URI  `xml:",cdata"`
+}
+
+// ExecutableResource is non JavaScript verification code.
+type ExecutableResource struct {
+	// The API framework used to execute the code
+	APIFramework string `xml:"apiFramework,attr,omitempty" json:",omitempty"`
+	// MIME type of the code
+	Type string `xml:"type,attr,omitempty" json:",omitempty"`
+	URI  URI    `xml:",cdata"`
+}
+
+// IABCategoryAuthority is the category authority of the IAB Content
+// Taxonomy used by the examples of VAST 4.1.
+const IABCategoryAuthority = "https://www.iabtechlab.com/categoryauthority"
+
+// Category is the category of the ad content, such as "IAB1-6".
+type Category struct {
+	// The URL of the organization defining the category codes
+	Authority string `xml:"authority,attr,omitempty" json:",omitempty"`
+	Code      string `xml:",chardata"`
 }
 
 // Impression is a URI that directs the video player to a tracking resource file that
 // the video player should request when the first frame of the ad is displayed
 type Impression struct {
 	ID  string `xml:"id,attr,omitempty" json:",omitempty"`
-	URI string `xml:",cdata" `
+	URI URI `xml:",cdata" `
+}
+
+// Survey is a URI to a survey vendor
+type Survey struct {
+	// MIME type of the resource being served, e.g. "text/javascript"
+	Type string `xml:"type,attr,omitempty" json:",omitempty"`
+	URI  URI    `xml:",cdata"`
+}
+
+// ViewableImpression contains the URIs to ping depending on the viewability of
+// the ad, as measured by the player or a verification vendor.
+type ViewableImpression struct {
+	// An ad server identifier for the viewable impression
+	ID string `xml:"id,attr,omitempty" json:",omitempty"`
+	// URIs to ping when the ad meets the viewability criteria
+	Viewable []CDATAURI `xml:",omitempty" json:",omitempty"`
+	// URIs to ping when the ad was played but didn't meet the viewability
+	// criteria
+	NotViewable []CDATAURI `xml:",omitempty" json:",omitempty"`
+	// URIs to ping when the viewability couldn't be determined
+	ViewUndetermined []CDATAURI `xml:",omitempty" json:",omitempty"`
 }
 
 // Pricing provides a value that represents a price that can be used by real-time
@@ -104,7 +199,7 @@ type Impression struct {
 // exist,  but this element is offered for custom solutions if needed.
 type Pricing struct {
 	// Identifies the pricing model as one of "cpm", "cpc", "cpe" or "cpv".
-	Model string `xml:"model,attr"`
+	Model PricingModel `xml:"model,attr"`
 	// The 3 letter ISO-4217 currency symbol that identifies the currency of
 	// the value provided
 	Currency string `xml:"currency,attr"`
@@ -126,7 +221,7 @@ type Wrapper struct {
 	AdSystem *AdSystem
 	// A URI representing an error-tracking pixel; this element can occur multiple
 	// times.
-	Errors []CDATAString `xml:"Error,omitempty" json:"Error,omitempty"`
+	Errors []CDATAURI `xml:"Error,omitempty" json:"Error,omitempty"`
 	// XML node for custom extensions, as defined by the ad server. When used, a
 	// custom element should be nested under <Extensions> to help separate custom
 	// XML elements from VAST elements. The following example includes a custom
@@ -135,13 +230,19 @@ type Wrapper struct {
 	// One or more URIs that directs the video player to a tracking resource file that the
 	// video player should request when the first frame of the ad is displayed
 	Impressions []Impression `xml:"Impression"`
+	// URIs to ping once the viewability of the ad has been determined
+	// (VAST 4.x)
+	ViewableImpression *ViewableImpression `xml:",omitempty" json:",omitempty"`
 	// URL of ad tag of downstream Secondary Ad Server
 	// The container for one or more <Creative> elements
 	Creatives []CreativeWrapper `xml:"Creatives>Creative"`
-	VASTAdTagURI CDATAString
-	FallbackOnNoAd           *bool `xml:"fallbackOnNoAd,attr,omitempty" json:",omitempty"`
-	AllowMultipleAds         *bool `xml:"allowMultipleAds,attr,omitempty" json:",omitempty"`
-	FollowAdditionalWrappers *bool `xml:"followAdditionalWrappers,attr,omitempty" json:",omitempty"`
+	VASTAdTagURI CDATAURI
+	// The resources needed by verification vendors to measure the ad
+	// (VAST 4.1+)
+	AdVerifications *[]Verification `xml:"AdVerifications>Verification,omitempty" json:",omitempty"`
+	FallbackOnNoAd           *Bool `xml:"fallbackOnNoAd,attr,omitempty" json:",omitempty"`
+	AllowMultipleAds         *Bool `xml:"allowMultipleAds,attr,omitempty" json:",omitempty"`
+	FollowAdditionalWrappers *Bool `xml:"followAdditionalWrappers,attr,omitempty" json:",omitempty"`
 }
 
 // AdSystem contains information about the system that returned the ad
@@ -252,6 +353,14 @@ type Linear struct {
 	// Duration in standard time format, hh:mm:ss
 	Duration       Duration		 `xml:"Duration,omitempty" json:",omitempty"`
 	MediaFiles     []MediaFile   `xml:"MediaFiles>MediaFile,omitempty" json:",omitempty"`
+	// The raw, high quality media files ad-stitching services transcode
+	// renditions from (VAST 4.x)
+	Mezzanines     []Mezzanine   `xml:"MediaFiles>Mezzanine,omitempty" json:",omitempty"`
+	// Files executing interactive code alongside the media file, such as
+	// SIMID creatives (VAST 4.x)
+	InteractiveCreativeFiles []InteractiveCreativeFile `xml:"MediaFiles>InteractiveCreativeFile,omitempty" json:",omitempty"`
+	// Closed caption files of the media files (VAST 4.1+)
+	ClosedCaptionFiles *ClosedCaptionFiles `xml:"MediaFiles>ClosedCaptionFiles,omitempty" json:",omitempty"`
 	VideoClicks    *VideoClicks  `xml:",omitempty" json:",omitempty"`
 }
 
@@ -285,7 +394,7 @@ type Companion struct {
 	// HTML to display the companion element
 	HTMLResource *HTMLResource `xml:",omitempty" json:",omitempty"`
 	// URL source for an IFrame to display the companion element
-	IFrameResource *CDATAString `xml:",omitempty" json:",omitempty"`
+	IFrameResource *CDATAURI `xml:",omitempty" json:",omitempty"`
 	// URL to a static file, such as an image or SWF file
 	StaticResource *StaticResource `xml:",omitempty" json:",omitempty"`
 	// Data to be passed into the companion ads. The apiFramework defines the method
@@ -294,7 +403,7 @@ type Companion struct {
 	// Alt text to be displayed when companion is rendered in HTML environment.
 	AltText string `xml:",omitempty" json:",omitempty"`
 	// URL to open as destination page when user clicks on the the companion banner ad.
-	CompanionClickThrough *CDATAString `xml:",omitempty" json:",omitempty"`
+	CompanionClickThrough *CDATAURI `xml:",omitempty" json:",omitempty"`
 	// URLs to ping when user clicks on the the companion banner ad.
 	CompanionClickTrackings []CompanionClickTracking `xml:"CompanionClickTracking,omitempty" json:",omitempty"`
 	// The creativeView should always be requested when present. For Companions
@@ -323,9 +432,9 @@ type CompanionWrapper struct {
 	// Used to match companion creative to publisher placement areas on the page.
 	AdSlotID string `xml:"adSlotId,attr,omitempty" json:",omitempty"`
 	// URL to open as destination page when user clicks on the the companion banner ad.
-	CompanionClickThrough *CDATAString `xml:",omitempty" json:",omitempty"`
+	CompanionClickThrough *CDATAURI `xml:",omitempty" json:",omitempty"`
 	// URLs to ping when user clicks on the the companion banner ad.
-	CompanionClickTracking []CDATAString `xml:",omitempty" json:",omitempty"`
+	CompanionClickTrackings []CompanionClickTracking `xml:"CompanionClickTracking,omitempty" json:",omitempty"`
 	// Alt text to be displayed when companion is rendered in HTML environment.
 	AltText string `xml:",omitempty" json:",omitempty"`
 	// The creativeView should always be requested when present. For Companions
@@ -337,7 +446,7 @@ type CompanionWrapper struct {
 	// URL to a static file, such as an image or SWF file
 	StaticResource *StaticResource `xml:",omitempty" json:",omitempty"`
 	// URL source for an IFrame to display the companion element
-	IFrameResource *CDATAString `xml:",omitempty" json:",omitempty"`
+	IFrameResource *CDATAURI `xml:",omitempty" json:",omitempty"`
 	// HTML to display the companion element
 	HTMLResource *HTMLResource `xml:",omitempty" json:",omitempty"`
 }
@@ -366,13 +475,13 @@ type NonLinear struct {
 	// HTML to display the companion element
 	HTMLResource *HTMLResource `xml:",omitempty" json:",omitempty"`
 	// URL source for an IFrame to display the companion element
-	IFrameResource *CDATAString `xml:",omitempty" json:",omitempty"`
+	IFrameResource *CDATAURI `xml:",omitempty" json:",omitempty"`
 	// URL to a static file, such as an image or SWF file
 	StaticResource *StaticResource `xml:",omitempty" json:",omitempty"`
 	// Data to be passed into the video ad.
 	AdParameters *AdParameters `xml:",omitempty" json:",omitempty"`
 	// URL to open as destination page when user clicks on the non-linear ad unit.
-	NonLinearClickThrough *CDATAString `xml:",omitempty" json:",omitempty"`
+	NonLinearClickThrough *CDATAURI `xml:",omitempty" json:",omitempty"`
 	// URLs to ping when user clicks on the the non-linear ad.
 	NonLinearClickTrackings []NonLinearClickTracking `xml:"NonLinearClickTracking,omitempty" json:",omitempty"`
 }
@@ -401,7 +510,7 @@ type NonLinearWrapper struct {
 	// The creativeView should always be requested when present.
 	TrackingEvents []Tracking `xml:"TrackingEvents>Tracking,omitempty" json:",omitempty"`
 	// URLs to ping when user clicks on the the non-linear ad.
-	NonLinearClickTracking []CDATAString `xml:",omitempty" json:",omitempty"`
+	NonLinearClickTrackings []NonLinearClickTracking `xml:"NonLinearClickTracking,omitempty" json:",omitempty"`
 }
 
 type Icons struct {
@@ -419,10 +528,10 @@ type Icon struct {
 	Height int `xml:"height,attr"`
 	// The horizontal alignment location (in pixels) or a specific alignment.
 	// Must match ([0-9]*|left|right)
-	XPosition string `xml:"xPosition,attr"`
+	XPosition IconXPosition `xml:"xPosition,attr"`
 	// The vertical alignment location (in pixels) or a specific alignment.
 	// Must match ([0-9]*|top|bottom)
-	YPosition string `xml:"yPosition,attr"`
+	YPosition IconYPosition `xml:"yPosition,attr"`
 	// Start time at which the player should display the icon. Expressed in standard time format hh:mm:ss.
 	Offset Offset `xml:"offset,attr"`
 	// duration for which the player must display the icon. Expressed in standard time format hh:mm:ss.
@@ -432,15 +541,31 @@ type Icon struct {
 	// HTML to display the companion element
 	HTMLResource *HTMLResource `xml:",omitempty" json:",omitempty"`
 	// URL source for an IFrame to display the companion element
-	IFrameResource *CDATAString `xml:",omitempty" json:",omitempty"`
+	IFrameResource *CDATAURI `xml:",omitempty" json:",omitempty"`
 	// URL to a static file, such as an image or SWF file
 	StaticResource *StaticResource `xml:",omitempty" json:",omitempty"`
 	// URL to open as destination page when user clicks on the icon.
-	IconClickThrough *CDATAString `xml:"IconClicks>IconClickThrough,omitempty" json:",omitempty"`
+	IconClickThrough *CDATAURI `xml:"IconClicks>IconClickThrough,omitempty" json:",omitempty"`
 	// URLs to ping when user clicks on the the icon.
-	IconClickTrackings []CDATAString `xml:"IconClicks>IconClickTracking,omitempty" json:",omitempty"`
+	IconClickTrackings []CDATAURI `xml:"IconClicks>IconClickTracking,omitempty" json:",omitempty"`
+	// Images to display when the player can't open the click-through, e.g. on
+	// connected TVs (VAST 4.1+)
+	IconClickFallbackImages *[]IconClickFallbackImage `xml:"IconClicks>IconClickFallbackImages>IconClickFallbackImage,omitempty" json:",omitempty"`
 	// A URI for the tracking resource file to be called when the icon creative is displayed.
-	IconViewTracking *CDATAString `xml:"IconViewTracking,omitempty" json:",omitempty"`
+	IconViewTracking *CDATAURI `xml:"IconViewTracking,omitempty" json:",omitempty"`
+}
+
+// IconClickFallbackImage is an image displayed in place of the icon
+// click-through on platforms unable to open it.
+type IconClickFallbackImage struct {
+	// Pixel dimensions of the image.
+	Width int `xml:"width,attr,omitempty" json:",omitempty"`
+	// Pixel dimensions of the image.
+	Height int `xml:"height,attr,omitempty" json:",omitempty"`
+	// Alternative text of the image
+	AltText string `xml:",omitempty" json:",omitempty"`
+	// The image to display
+	StaticResource *StaticResource `xml:",omitempty" json:",omitempty"`
 }
 
 // Tracking defines an event tracking URL
@@ -455,7 +580,7 @@ type Tracking struct {
 	// The time during the video at which this url should be pinged. Must be present for
 	// progress event. Must match (\d{2}:[0-5]\d:[0-5]\d(\.\d\d\d)?|1?\d?\d(\.?\d)*%)
 	Offset *Offset `xml:"offset,attr,omitempty" json:",omitempty"`
-	URI    string  `xml:",cdata"`
+	URI    URI     `xml:",cdata"`
 
 	// custom attr
 	UA string `xml:"ua,attr,omitempty" json:",omitempty"`
@@ -466,7 +591,7 @@ type StaticResource struct {
 	// Mime type of static resource
 	CreativeType string `xml:"creativeType,attr,omitempty" json:",omitempty"`
 	// URL to a static file, such as an image or SWF file
-	URI string `xml:",cdata"`
+	URI URI `xml:",cdata"`
 }
 
 // HTMLResource is a container for HTML data
@@ -493,7 +618,7 @@ type VideoClicks struct {
 // VideoClick defines a click URL for a linear creative
 type VideoClick struct {
 	ID  string `xml:"id,attr,omitempty" json:",omitempty"`
-	URI string `xml:",cdata"`
+	URI URI `xml:",cdata"`
 }
 
 // MediaFile defines a reference to a linear creative asset
@@ -501,7 +626,7 @@ type MediaFile struct {
 	// Optional identifier
 	ID string `xml:"id,attr,omitempty" json:",omitempty"`
 	// Method of delivery of ad (either "streaming" or "progressive")
-	Delivery string `xml:"delivery,attr" json:",omitempty"`
+	Delivery Delivery `xml:"delivery,attr" json:",omitempty"`
 	// MIME type. Popular MIME types include, but are not limited to
 	// “video/x-ms-wmv” for Windows Media, and “video/x-flv” for Flash
 	// Video. Image ads or interactive ads can be included in the
@@ -531,13 +656,63 @@ type MediaFile struct {
 	// (for Flash/Flex), “initParams” (for Silverlight) and “GetVariables” (variables
 	// placed in key/value pairs on the asset request).
 	APIFramework string `xml:"apiFramework,attr,omitempty" json:",omitempty"`
-	URI          string `xml:",cdata"`
+	URI          URI    `xml:",cdata"`
 	// Optional field that helps eliminate the need to calculate the size based on bitrate and duration. 
 	FileSize int `xml:"fileSize,attr,omitempty" json:",omitempty"`
 	// Type of media file (2D / 3D / 360 / etc). 
 	MediaType string `xml:"mediaType,attr,omitempty" json:",omitempty"`
 }
 
+// Mezzanine is the raw, high quality media file of a linear creative, used
+// by ad-stitching services to transcode the renditions matching the content
+// stream (VAST 4.x).
+type Mezzanine struct {
+	// Optional identifier
+	ID string `xml:"id,attr,omitempty" json:",omitempty"`
+	// Method of delivery of the file (either "streaming" or "progressive")
+	Delivery Delivery `xml:"delivery,attr" json:",omitempty"`
+	// MIME type of the file
+	Type string `xml:"type,attr"`
+	// Pixel dimensions of the video.
+	Width int `xml:"width,attr"`
+	// Pixel dimensions of the video.
+	Height int `xml:"height,attr"`
+	// The codec used to produce the file.
+	Codec string `xml:"codec,attr,omitempty" json:",omitempty"`
+	// Size of the file in bytes.
+	FileSize int `xml:"fileSize,attr,omitempty" json:",omitempty"`
+	// Type of media file (2D / 3D / 360 / etc).
+	MediaType string `xml:"mediaType,attr,omitempty" json:",omitempty"`
+	URI       URI    `xml:",cdata"`
+}
+
+// InteractiveCreativeFile references a file executing interactive code
+// alongside the media file of a linear creative (VAST 4.x).
+type InteractiveCreativeFile struct {
+	// MIME type of the file
+	Type string `xml:"type,attr,omitempty" json:",omitempty"`
+	// The API framework used to communicate with the player, e.g. "SIMID"
+	APIFramework string `xml:"apiFramework,attr,omitempty" json:",omitempty"`
+	// Whether the interactive creative may extend the ad duration
+	VariableDuration Bool `xml:"variableDuration,attr,omitempty" json:",omitempty"`
+	URI              URI  `xml:",cdata"`
+}
+
+// ClosedCaptionFiles contains the closed caption files of a linear creative
+type ClosedCaptionFiles struct {
+	ClosedCaptionFile []ClosedCaptionFile `xml:"ClosedCaptionFile,omitempty" json:",omitempty"`
+}
+
+// ClosedCaptionFile references a closed caption file of a linear creative
+// (VAST 4.1+).
+type ClosedCaptionFile struct {
+	// MIME type of the file, e.g. "text/vtt"
+	Type string `xml:"type,attr,omitempty" json:",omitempty"`
+	// Language of the captions, as a ISO 639-1 code
+	Language string `xml:"language,attr,omitempty" json:",omitempty"`
+	URI      URI    `xml:",cdata"`
+}
+
 // UniversalAdID describes a VAST 4.x universal ad id.
 type UniversalAdID struct {
 	IDRegistry string `xml:"idRegistry,attr"`
@@ -548,12 +723,12 @@ type UniversalAdID struct {
 type CompanionClickTracking struct {
 	// An id provided by the ad server to track the click in reports.
 	ID  string `xml:"id,attr,omitempty" json:",omitempty"`
-	URI string `xml:",cdata"`
+	URI URI `xml:",cdata"`
 }
 
 // NonLinearClickTracking element is used to track the click
 type NonLinearClickTracking struct {
 	// An id provided by the ad server to track the click in reports
 	ID  string `xml:"id,attr,omitempty" json:",omitempty"`
-	URI string `xml:",cdata"`
+	URI URI `xml:",cdata"`
 }
@@ -88,8 +88,8 @@ func TestQuickStart(t *testing.T) {
 func TestEmptyVast(t *testing.T) {
 	v := VAST{
 		Version: "3.0",
-		Errors: []CDATAString{
-			{CDATA: "http://xx.xx.com/e/error?e=__ERRORCODE__&co=__CONTENTPLAYHEAD__&ca=__CACHEBUSTING__&a=__ASSETURI__&t=__TIMESTAMP__&o=__OTHER__"},
+		Errors: []CDATAURI{
+			{URI: "http://xx.xx.com/e/error?e=__ERRORCODE__&co=__CONTENTPLAYHEAD__&ca=__CACHEBUSTING__&a=__ASSETURI__&t=__TIMESTAMP__&o=__OTHER__"},
 		},
 	}
 	want := []byte(`{"Version":"3.0","Errors":[{"Data":"http://xx.xx.com/e/error?e=__ERRORCODE__\u0026co=__CONTENTPLAYHEAD__\u0026ca=__CACHEBUSTING__\u0026a=__ASSETURI__\u0026t=__TIMESTAMP__\u0026o=__OTHER__"}]}`)
@@ -121,10 +121,10 @@ func createVastDemo() (*VAST, error) {
 	adTitle := "ad title"
 	assetId := "123456"
 	impressionId := "456"
-	impressionURI := "http://impression.track.cn"
+	impressionURI := URI("http://impression.track.cn")
 	seconds := Duration(15 * time.Second)
 	mediaType := "video/mp4"
-	mediaURI := "http://mp4.res.xxx.com/new_video/2020/01/14/1485/335928CBA9D02E95E63ED9F4D45DF6DF_20200114_1_1_1051.mp4"
+	mediaURI := URI("http://mp4.res.xxx.com/new_video/2020/01/14/1485/335928CBA9D02E95E63ED9F4D45DF6DF_20200114_1_1_1051.mp4")
 
 	v := &VAST{
 		Version: "3.0",
@@ -303,10 +303,10 @@ func TestCreativeExtensions(t *testing.T) {
 					if assert.Len(t, ext.CustomTracking, 2) {
 						// first tracker
 						assert.Equal(t, "viewable_impression", ext.CustomTracking[0].Event)
-						assert.Equal(t, "https://pubads.g.doubleclick.net/pagead/conversion/?ai=test&label=viewable_impression&acvw=[VIEWABILITY]&gv=[GOOGLE_VIEWABILITY]&ad_mt=[AD_MT]", ext.CustomTracking[0].URI)
+						assert.Equal(t, URI("https://pubads.g.doubleclick.net/pagead/conversion/?ai=test&label=viewable_impression&acvw=[VIEWABILITY]&gv=[GOOGLE_VIEWABILITY]&ad_mt=[AD_MT]"), ext.CustomTracking[0].URI)
 						// second tracker
 						assert.Equal(t, "abandon", ext.CustomTracking[1].Event)
-						assert.Equal(t, "https://pubads.g.doubleclick.net/pagead/conversion/?ai=test&label=video_abandon&acvw=[VIEWABILITY]&gv=[GOOGLE_VIEWABILITY]", ext.CustomTracking[1].URI)
+						assert.Equal(t, URI("https://pubads.g.doubleclick.net/pagead/conversion/?ai=test&label=video_abandon&acvw=[VIEWABILITY]&gv=[GOOGLE_VIEWABILITY]"), ext.CustomTracking[1].URI)
 					}
 					assert.Empty(t, string(ext.Data))
 					// asserting third extension
@@ -351,10 +351,10 @@ func TestInlineExtensions(t *testing.T) {
 					if assert.Len(t, ext.CustomTracking, 2) {
 						// first tracker
 						assert.Equal(t, "viewable_impression", ext.CustomTracking[0].Event)
-						assert.Equal(t, "https://pubads.g.doubleclick.net/pagead/conversion/?ai=test&label=viewable_impression&acvw=[VIEWABILITY]&gv=[GOOGLE_VIEWABILITY]&ad_mt=[AD_MT]", ext.CustomTracking[0].URI)
+						assert.Equal(t, URI("https://pubads.g.doubleclick.net/pagead/conversion/?ai=test&label=viewable_impression&acvw=[VIEWABILITY]&gv=[GOOGLE_VIEWABILITY]&ad_mt=[AD_MT]"), ext.CustomTracking[0].URI)
 						// second tracker
 						assert.Equal(t, "abandon", ext.CustomTracking[1].Event)
-						assert.Equal(t, "https://pubads.g.doubleclick.net/pagead/conversion/?ai=test&label=video_abandon&acvw=[VIEWABILITY]&gv=[GOOGLE_VIEWABILITY]", ext.CustomTracking[1].URI)
+						assert.Equal(t, URI("https://pubads.g.doubleclick.net/pagead/conversion/?ai=test&label=video_abandon&acvw=[VIEWABILITY]&gv=[GOOGLE_VIEWABILITY]"), ext.CustomTracking[1].URI)
 					}
 					assert.Empty(t, string(ext.Data))
 					// asserting third extension
@@ -392,12 +392,12 @@ func TestInlineLinear(t *testing.T) {
 			assert.Equal(t, "VAST 2.0 Instream Test 1", inline.AdTitle.CDATA)
 			assert.Equal(t, "VAST 2.0 Instream Test 1", inline.Description.CDATA)
 			if assert.Len(t, inline.Errors, 2) {
-				assert.Equal(t, "http://myErrorURL/error", inline.Errors[0].CDATA)
-				assert.Equal(t, "http://myErrorURL/error2", inline.Errors[1].CDATA)
+				assert.Equal(t, "http://myErrorURL/error", string(inline.Errors[0].URI))
+				assert.Equal(t, "http://myErrorURL/error2", string(inline.Errors[1].URI))
 			}
 			if assert.Len(t, inline.Impressions, 2) {
-				assert.Equal(t, "http://myTrackingURL/impression", inline.Impressions[0].URI)
-				assert.Equal(t, "http://myTrackingURL/impression2", inline.Impressions[1].URI)
+				assert.Equal(t, URI("http://myTrackingURL/impression"), inline.Impressions[0].URI)
+				assert.Equal(t, URI("http://myTrackingURL/impression2"), inline.Impressions[1].URI)
 				assert.Equal(t, "foo", inline.Impressions[1].ID)
 			}
 			if assert.Len(t, inline.Creatives, 2) {
@@ -410,29 +410,29 @@ func TestInlineLinear(t *testing.T) {
 					assert.Equal(t, Duration(30*time.Second), linear.Duration)
 					if assert.Len(t, linear.TrackingEvents, 6) {
 						assert.Equal(t, linear.TrackingEvents[0].Event, "creativeView")
-						assert.Equal(t, linear.TrackingEvents[0].URI, "http://myTrackingURL/creativeView")
+						assert.Equal(t, linear.TrackingEvents[0].URI, URI("http://myTrackingURL/creativeView"))
 						assert.Equal(t, linear.TrackingEvents[1].Event, "start")
-						assert.Equal(t, linear.TrackingEvents[1].URI, "http://myTrackingURL/start")
+						assert.Equal(t, linear.TrackingEvents[1].URI, URI("http://myTrackingURL/start"))
 					}
 					if assert.NotNil(t, linear.VideoClicks) {
 						if assert.Len(t, linear.VideoClicks.ClickThroughs, 1) {
-							assert.Equal(t, linear.VideoClicks.ClickThroughs[0].URI, "http://www.tremormedia.com")
+							assert.Equal(t, linear.VideoClicks.ClickThroughs[0].URI, URI("http://www.tremormedia.com"))
 						}
 						if assert.Len(t, linear.VideoClicks.ClickTrackings, 1) {
-							assert.Equal(t, linear.VideoClicks.ClickTrackings[0].URI, "http://myTrackingURL/click")
+							assert.Equal(t, linear.VideoClicks.ClickTrackings[0].URI, URI("http://myTrackingURL/click"))
 						}
 						assert.Len(t, linear.VideoClicks.CustomClicks, 0)
 					}
 					if assert.Len(t, linear.MediaFiles, 1) {
 						mf := linear.MediaFiles[0]
-						assert.Equal(t, "progressive", mf.Delivery)
+						assert.Equal(t, DeliveryProgressive, mf.Delivery)
 						assert.Equal(t, "video/x-flv", mf.Type)
 						assert.Equal(t, 500, mf.Bitrate)
 						assert.Equal(t, 400, mf.Width)
 						assert.Equal(t, 300, mf.Height)
 						assert.Equal(t, true, mf.Scalable)
 						assert.Equal(t, true, mf.MaintainAspectRatio)
-						assert.Equal(t, "http://cdnp.tremormedia.com/video/acudeo/Carrot_400x300_500kb.flv", mf.URI)
+						assert.Equal(t, URI("http://cdnp.tremormedia.com/video/acudeo/Carrot_400x300_500kb.flv"), mf.URI)
 					}
 				}
 
@@ -448,22 +448,22 @@ func TestInlineLinear(t *testing.T) {
 						assert.Equal(t, 250, comp1.Height)
 						if assert.NotNil(t, comp1.StaticResource) {
 							assert.Equal(t, "image/jpeg", comp1.StaticResource.CreativeType)
-							assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/Blistex1.jpg", comp1.StaticResource.URI)
+							assert.Equal(t, URI("http://demo.tremormedia.com/proddev/vast/Blistex1.jpg"), comp1.StaticResource.URI)
 						}
 						if assert.Len(t, comp1.TrackingEvents, 1) {
 							assert.Equal(t, "creativeView", comp1.TrackingEvents[0].Event)
-							assert.Equal(t, "http://myTrackingURL/firstCompanionCreativeView", comp1.TrackingEvents[0].URI)
+							assert.Equal(t, URI("http://myTrackingURL/firstCompanionCreativeView"), comp1.TrackingEvents[0].URI)
 						}
-						assert.Equal(t, "http://www.tremormedia.com", comp1.CompanionClickThrough.CDATA)
+						assert.Equal(t, "http://www.tremormedia.com", string(comp1.CompanionClickThrough.URI))
 
 						comp2 := crea2.CompanionAds.Companions[1]
 						assert.Equal(t, 728, comp2.Width)
 						assert.Equal(t, 90, comp2.Height)
 						if assert.NotNil(t, comp2.StaticResource) {
 							assert.Equal(t, "image/jpeg", comp2.StaticResource.CreativeType)
-							assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/728x90_banner1.jpg", comp2.StaticResource.URI)
+							assert.Equal(t, URI("http://demo.tremormedia.com/proddev/vast/728x90_banner1.jpg"), comp2.StaticResource.URI)
 						}
-						assert.Equal(t, "http://www.tremormedia.com", comp2.CompanionClickThrough.CDATA)
+						assert.Equal(t, "http://www.tremormedia.com", string(comp2.CompanionClickThrough.URI))
 					}
 				}
 			}
@@ -510,12 +510,14 @@ func TestInlineNonLinear(t *testing.T) {
 			assert.Equal(t, "Acudeo Compatible", inline.AdSystem.Name)
 			assert.Equal(t, "NonLinear Test Campaign 1", inline.AdTitle.CDATA)
 			assert.Equal(t, "NonLinear Test Campaign 1", inline.Description.CDATA)
-			assert.Equal(t, "http://mySurveyURL/survey", inline.Survey.CDATA)
+			if assert.Len(t, inline.Surveys, 1) {
+				assert.Equal(t, URI("http://mySurveyURL/survey"), inline.Surveys[0].URI)
+			}
 			if assert.Len(t, inline.Errors, 1) {
-				assert.Equal(t, "http://myErrorURL/error", inline.Errors[0].CDATA)
+				assert.Equal(t, "http://myErrorURL/error", string(inline.Errors[0].URI))
 			}
 			if assert.Len(t, inline.Impressions, 1) {
-				assert.Equal(t, "http://myTrackingURL/impression", inline.Impressions[0].URI)
+				assert.Equal(t, URI("http://myTrackingURL/impression"), inline.Impressions[0].URI)
 			}
 			if assert.Len(t, inline.Creatives, 2) {
 				crea1 := inline.Creatives[0]
@@ -526,16 +528,16 @@ func TestInlineNonLinear(t *testing.T) {
 					nonlin := crea1.NonLinearAds
 					if assert.Len(t, nonlin.TrackingEvents, 5) {
 						assert.Equal(t, nonlin.TrackingEvents[0].Event, "creativeView")
-						assert.Equal(t, nonlin.TrackingEvents[0].URI, "http://myTrackingURL/nonlinear/creativeView")
+						assert.Equal(t, nonlin.TrackingEvents[0].URI, URI("http://myTrackingURL/nonlinear/creativeView"))
 						assert.Equal(t, nonlin.TrackingEvents[1].Event, "expand")
-						assert.Equal(t, nonlin.TrackingEvents[1].URI, "http://myTrackingURL/nonlinear/expand")
+						assert.Equal(t, nonlin.TrackingEvents[1].URI, URI("http://myTrackingURL/nonlinear/expand"))
 					}
 					if assert.Len(t, nonlin.NonLinears, 2) {
 						assert.Equal(t, "image/jpeg", nonlin.NonLinears[0].StaticResource.CreativeType)
-						assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/50x300_static.jpg", strings.TrimSpace(nonlin.NonLinears[0].StaticResource.URI))
+						assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/50x300_static.jpg", strings.TrimSpace(string(nonlin.NonLinears[0].StaticResource.URI)))
 						assert.Equal(t, "image/jpeg", nonlin.NonLinears[1].StaticResource.CreativeType)
-						assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/50x450_static.jpg", strings.TrimSpace(nonlin.NonLinears[1].StaticResource.URI))
-						assert.Equal(t, "http://www.tremormedia.com", strings.TrimSpace(nonlin.NonLinears[1].NonLinearClickThrough.CDATA))
+						assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/50x450_static.jpg", strings.TrimSpace(string(nonlin.NonLinears[1].StaticResource.URI)))
+						assert.Equal(t, "http://www.tremormedia.com", strings.TrimSpace(string(nonlin.NonLinears[1].NonLinearClickThrough.URI)))
 					}
 				}
 
@@ -550,22 +552,22 @@ func TestInlineNonLinear(t *testing.T) {
 						assert.Equal(t, 250, comp1.Height)
 						if assert.NotNil(t, comp1.StaticResource) {
 							assert.Equal(t, "application/x-shockwave-flash", comp1.StaticResource.CreativeType)
-							assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/300x250_companion_1.swf", comp1.StaticResource.URI)
+							assert.Equal(t, URI("http://demo.tremormedia.com/proddev/vast/300x250_companion_1.swf"), comp1.StaticResource.URI)
 						}
-						assert.Equal(t, "http://www.tremormedia.com", comp1.CompanionClickThrough.CDATA)
+						assert.Equal(t, "http://www.tremormedia.com", string(comp1.CompanionClickThrough.URI))
 
 						comp2 := crea2.CompanionAds.Companions[1]
 						assert.Equal(t, 728, comp2.Width)
 						assert.Equal(t, 90, comp2.Height)
 						if assert.NotNil(t, comp2.StaticResource) {
 							assert.Equal(t, "image/jpeg", comp2.StaticResource.CreativeType)
-							assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/728x90_banner1.jpg", comp2.StaticResource.URI)
+							assert.Equal(t, URI("http://demo.tremormedia.com/proddev/vast/728x90_banner1.jpg"), comp2.StaticResource.URI)
 						}
 						if assert.Len(t, comp2.TrackingEvents, 1) {
 							assert.Equal(t, "creativeView", comp2.TrackingEvents[0].Event)
-							assert.Equal(t, "http://myTrackingURL/secondCompanion", comp2.TrackingEvents[0].URI)
+							assert.Equal(t, URI("http://myTrackingURL/secondCompanion"), comp2.TrackingEvents[0].URI)
 						}
-						assert.Equal(t, "http://www.tremormedia.com", comp2.CompanionClickThrough.CDATA)
+						assert.Equal(t, "http://www.tremormedia.com", string(comp2.CompanionClickThrough.URI))
 					}
 				}
 			}
@@ -587,16 +589,16 @@ func TestWrapperLinear(t *testing.T) {
 		assert.Nil(t, ad.InLine)
 		if assert.NotNil(t, ad.Wrapper) {
 			wrapper := ad.Wrapper
-			assert.Equal(t, true, *wrapper.FallbackOnNoAd)
-			assert.Equal(t, true, *wrapper.AllowMultipleAds)
+			assert.Equal(t, Bool(true), *wrapper.FallbackOnNoAd)
+			assert.Equal(t, Bool(true), *wrapper.AllowMultipleAds)
 			assert.Nil(t, wrapper.FollowAdditionalWrappers)
-			assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/vast_inline_linear.xml", wrapper.VASTAdTagURI.CDATA)
+			assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/vast_inline_linear.xml", string(wrapper.VASTAdTagURI.URI))
 			assert.Equal(t, "Acudeo Compatible", wrapper.AdSystem.Name)
 			if assert.Len(t, wrapper.Errors, 1) {
-				assert.Equal(t, "http://myErrorURL/wrapper/error", wrapper.Errors[0].CDATA)
+				assert.Equal(t, "http://myErrorURL/wrapper/error", string(wrapper.Errors[0].URI))
 			}
 			if assert.Len(t, wrapper.Impressions, 1) {
-				assert.Equal(t, "http://myTrackingURL/wrapper/impression", wrapper.Impressions[0].URI)
+				assert.Equal(t, URI("http://myTrackingURL/wrapper/impression"), wrapper.Impressions[0].URI)
 			}
 
 			if assert.Len(t, wrapper.Creatives, 3) {
@@ -608,9 +610,9 @@ func TestWrapperLinear(t *testing.T) {
 					linear := crea1.Linear
 					if assert.Len(t, linear.TrackingEvents, 11) {
 						assert.Equal(t, linear.TrackingEvents[0].Event, "creativeView")
-						assert.Equal(t, linear.TrackingEvents[0].URI, "http://myTrackingURL/wrapper/creativeView")
+						assert.Equal(t, linear.TrackingEvents[0].URI, URI("http://myTrackingURL/wrapper/creativeView"))
 						assert.Equal(t, linear.TrackingEvents[1].Event, "start")
-						assert.Equal(t, linear.TrackingEvents[1].URI, "http://myTrackingURL/wrapper/start")
+						assert.Equal(t, linear.TrackingEvents[1].URI, URI("http://myTrackingURL/wrapper/start"))
 					}
 					assert.Nil(t, linear.VideoClicks)
 				}
@@ -621,7 +623,7 @@ func TestWrapperLinear(t *testing.T) {
 				assert.Nil(t, crea2.NonLinearAds)
 				if assert.NotNil(t, crea2.Linear) {
 					if assert.Len(t, crea2.Linear.VideoClicks.ClickTrackings, 1) {
-						assert.Equal(t, "http://myTrackingURL/wrapper/click", crea2.Linear.VideoClicks.ClickTrackings[0].URI)
+						assert.Equal(t, URI("http://myTrackingURL/wrapper/click"), crea2.Linear.VideoClicks.ClickTrackings[0].URI)
 					}
 				}
 
@@ -632,7 +634,7 @@ func TestWrapperLinear(t *testing.T) {
 				if assert.NotNil(t, crea3.NonLinearAds) {
 					if assert.Len(t, crea3.NonLinearAds.TrackingEvents, 1) {
 						assert.Equal(t, "creativeView", crea3.NonLinearAds.TrackingEvents[0].Event)
-						assert.Equal(t, "http://myTrackingURL/wrapper/creativeView", crea3.NonLinearAds.TrackingEvents[0].URI)
+						assert.Equal(t, URI("http://myTrackingURL/wrapper/creativeView"), crea3.NonLinearAds.TrackingEvents[0].URI)
 					}
 				}
 			}
@@ -654,13 +656,13 @@ func TestWrapperNonLinear(t *testing.T) {
 		assert.Nil(t, ad.InLine)
 		if assert.NotNil(t, ad.Wrapper) {
 			wrapper := ad.Wrapper
-			assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/vast_inline_nonlinear2.xml", wrapper.VASTAdTagURI.CDATA)
+			assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/vast_inline_nonlinear2.xml", string(wrapper.VASTAdTagURI.URI))
 			assert.Equal(t, "Acudeo Compatible", wrapper.AdSystem.Name)
 			if assert.Len(t, wrapper.Errors, 1) {
-				assert.Equal(t, "http://myErrorURL/wrapper/error", wrapper.Errors[0].CDATA)
+				assert.Equal(t, "http://myErrorURL/wrapper/error", string(wrapper.Errors[0].URI))
 			}
 			if assert.Len(t, wrapper.Impressions, 1) {
-				assert.Equal(t, "http://myTrackingURL/wrapper/impression", wrapper.Impressions[0].URI)
+				assert.Equal(t, URI("http://myTrackingURL/wrapper/impression"), wrapper.Impressions[0].URI)
 			}
 
 			if assert.Len(t, wrapper.Creatives, 2) {
@@ -677,7 +679,7 @@ func TestWrapperNonLinear(t *testing.T) {
 				if assert.NotNil(t, crea2.NonLinearAds) {
 					if assert.Len(t, crea2.NonLinearAds.TrackingEvents, 5) {
 						assert.Equal(t, "creativeView", crea2.NonLinearAds.TrackingEvents[0].Event)
-						assert.Equal(t, "http://myTrackingURL/wrapper/nonlinear/creativeView/creativeView", crea2.NonLinearAds.TrackingEvents[0].URI)
+						assert.Equal(t, URI("http://myTrackingURL/wrapper/nonlinear/creativeView/creativeView"), crea2.NonLinearAds.TrackingEvents[0].URI)
 					}
 				}
 			}
@@ -717,11 +719,11 @@ func TestSpotXVpaid(t *testing.T) {
 					assert.Equal(t, linear.AdParameters.Parameters, string(b))
 					if assert.Len(t, crea1.Linear.MediaFiles, 1) {
 						media1 := crea1.Linear.MediaFiles[0]
-						assert.Equal(t, "progressive", media1.Delivery)
+						assert.Equal(t, DeliveryProgressive, media1.Delivery)
 						assert.Equal(t, "application/javascript", media1.Type)
 						assert.Equal(t, 300, media1.Width)
 						assert.Equal(t, 250, media1.Height)
-						assert.Equal(t, "https://cdn.spotxcdn.com/integration/instreamadbroker/v1/instreamadbroker/beta.js", media1.URI)
+						assert.Equal(t, URI("https://cdn.spotxcdn.com/integration/instreamadbroker/v1/instreamadbroker/beta.js"), media1.URI)
 					}
 				}
 				crea2 := inline.Creatives[1]
@@ -733,7 +735,7 @@ func TestSpotXVpaid(t *testing.T) {
 						assert.Equal(t, 250, companionAds1.Height)
 						assert.Equal(t, "medium_rectangle", companionAds1.ID)
 						if assert.NotNil(t, companionAds1.IFrameResource) {
-							assert.Equal(t, "https://search.spotxchange.com/banner?_a=137530&amp;_p=spotx&amp;_z=1&amp;_m=eNpVz99vgjAQB%2FD%2BLTyP0rM%2FLCZ7WOKWbFF5GJr4RAotUBUwgkHZ9rdvVB8W%2B3BJv3eXT45hQhBCwAnhEkKQKA2lZCHLhJYSwExTSgSkqcpBCEIIIKBTTglaf6JQ4glleAISAxOo7LpjOwuCvu9xrip7uJaq1tdK1ThrqgB9eefWnJLibLU385hRoTQ8FylPnSNzMgUtc6EpdY73dB%2B3bVKbflwYg6xp9tYkuqmUrceoNeqUlbg9Nt0lG7HCOGkcVGdtTZ2Z5IHyneU7zHea%2F8D93A6bcPR7e2i1XizuBW4R4oJcKPDx%2B99y5TuKD%2BU23rPlrhiW849dFG%2FstnqrVsPLJYrfYTm88mi%2BZ6uheP4DQppvcA%3D%3D&amp;_l=eNplj01rwkAYhN%2FfsleLvJv9yEbooUXoxU1BDKW5yGazNUk1SZMNtVr%2Fe9VgofQyh5mHGYYqqrhiEEYykIyDABWgAi4wlAAUKKdcRIpD3zZ%2BD5MJSBpB3dQOEI6EkdmRZGVOZgSniEySO9Kar2bwNwcvVt%2B6%2BpeJrtCQbUu7dntbmHrj1m%2FOXXOKiOfYDp3xLl%2FvTPfufLs11v1n8cKeThAni8UoCDhVAparR%2FDdsGuLxrttP7XNDlBxRjORW5pxhRYjIYULc8nDyDLm8vH3%2BRxy%2BBhM7a3p%2FdhKWSgC8XcGvuNK8%2FQp2b%2BuiiKdb1AfNNfVw6eukkBXcfk8T4L0ZVnqQ3L%2FAyjAYKo%3D&amp;_t=eNotj9Fu4yAQRf0tPFdbwK5dE%2B1b1aiVnGpXlVaJKlkDTGxaYyxMtsmm%2BfeFuDzA3MO9wyBhHNFn9Z5WQCtd6vt7xrCSOa2YlLBnZUkpZZnqwYyZ8x2MRmVySZ2JRW2gNZoIorWUpeSasbq4y%2BOOulaFrrGGUtccyQ3ZO28hRO%2F66TFKayy24TRhJMZCh2%2B3ndnHi0%2BjQx9hTmlUPZquTyl%2Bd5XBDq1ydhrQ4ph4hAZka%2BwhijTRwbYeVYCxG9KzM4JXfTtPqIg4kwk8WAzo56Q0%2FjUKU4XHkI55cuHYLriFafqekF3iwE7jQAS%2FIS5m%2BSWuLGM%2FKM1AVOI8i1KQbnASBrIygq6uYHJzWACrGY8sv%2F42gbyoKlpEVAiiTDh9m9gS9NgZNyZWsjqiKprcYQz%2B6uM8X3rppVfJ2eqSfW3Wv05b%2Fvy%2Be2j4br352NqN3f55Ys2%2Fj8%2FNQ3d8WTfHhv8eXl6bn%2F8BtbOdiA%3D%3D&amp;_b=eNozYEjRB8KUpCSzJKMUQ0NLE1NjIJmaYplskmKZaplolmJplKqXUZKbw%2BAX6uODIGp83T0NI7MysiOzHCv8wj0rI3MDq3yNwnKAtEFkuKtBVDiQrvI1jqxKtwUAEJMfUw%3D%3D&amp;resource_type=iframe", companionAds1.IFrameResource.CDATA)
+							assert.Equal(t, "https://search.spotxchange.com/banner?_a=137530&amp;_p=spotx&amp;_z=1&amp;_m=eNpVz99vgjAQB%2FD%2BLTyP0rM%2FLCZ7WOKWbFF5GJr4RAotUBUwgkHZ9rdvVB8W%2B3BJv3eXT45hQhBCwAnhEkKQKA2lZCHLhJYSwExTSgSkqcpBCEIIIKBTTglaf6JQ4glleAISAxOo7LpjOwuCvu9xrip7uJaq1tdK1ThrqgB9eefWnJLibLU385hRoTQ8FylPnSNzMgUtc6EpdY73dB%2B3bVKbflwYg6xp9tYkuqmUrceoNeqUlbg9Nt0lG7HCOGkcVGdtTZ2Z5IHyneU7zHea%2F8D93A6bcPR7e2i1XizuBW4R4oJcKPDx%2B99y5TuKD%2BU23rPlrhiW849dFG%2FstnqrVsPLJYrfYTm88mi%2BZ6uheP4DQppvcA%3D%3D&amp;_l=eNplj01rwkAYhN%2FfsleLvJv9yEbooUXoxU1BDKW5yGazNUk1SZMNtVr%2Fe9VgofQyh5mHGYYqqrhiEEYykIyDABWgAi4wlAAUKKdcRIpD3zZ%2BD5MJSBpB3dQOEI6EkdmRZGVOZgSniEySO9Kar2bwNwcvVt%2B6%2BpeJrtCQbUu7dntbmHrj1m%2FOXXOKiOfYDp3xLl%2FvTPfufLs11v1n8cKeThAni8UoCDhVAparR%2FDdsGuLxrttP7XNDlBxRjORW5pxhRYjIYULc8nDyDLm8vH3%2BRxy%2BBhM7a3p%2FdhKWSgC8XcGvuNK8%2FQp2b%2BuiiKdb1AfNNfVw6eukkBXcfk8T4L0ZVnqQ3L%2FAyjAYKo%3D&amp;_t=eNotj9Fu4yAQRf0tPFdbwK5dE%2B1b1aiVnGpXlVaJKlkDTGxaYyxMtsmm%2BfeFuDzA3MO9wyBhHNFn9Z5WQCtd6vt7xrCSOa2YlLBnZUkpZZnqwYyZ8x2MRmVySZ2JRW2gNZoIorWUpeSasbq4y%2BOOulaFrrGGUtccyQ3ZO28hRO%2F66TFKayy24TRhJMZCh2%2B3ndnHi0%2BjQx9hTmlUPZquTyl%2Bd5XBDq1ydhrQ4ph4hAZka%2BwhijTRwbYeVYCxG9KzM4JXfTtPqIg4kwk8WAzo56Q0%2FjUKU4XHkI55cuHYLriFafqekF3iwE7jQAS%2FIS5m%2BSWuLGM%2FKM1AVOI8i1KQbnASBrIygq6uYHJzWACrGY8sv%2F42gbyoKlpEVAiiTDh9m9gS9NgZNyZWsjqiKprcYQz%2B6uM8X3rppVfJ2eqSfW3Wv05b%2Fvy%2Be2j4br352NqN3f55Ys2%2Fj8%2FNQ3d8WTfHhv8eXl6bn%2F8BtbOdiA%3D%3D&amp;_b=eNozYEjRB8KUpCSzJKMUQ0NLE1NjIJmaYplskmKZaplolmJplKqXUZKbw%2BAX6uODIGp83T0NI7MysiOzHCv8wj0rI3MDq3yNwnKAtEFkuKtBVDiQrvI1jqxKtwUAEJMfUw%3D%3D&amp;resource_type=iframe", string(companionAds1.IFrameResource.URI))
 						}
 						companionAds2 := crea2.CompanionAds.Companions[1]
 						assert.Equal(t, 300, companionAds2.Width)
@@ -754,10 +756,10 @@ func TestSpotXVpaid(t *testing.T) {
 						assert.Equal(t, "medium_rectangle", companionAds3.ID)
 						if assert.NotNil(t, companionAds3.StaticResource) {
 							assert.Equal(t, "image/gif", companionAds3.StaticResource.CreativeType)
-							assert.Equal(t, "https://search.spotxchange.com/banner?_a=137530&amp;_p=spotx&amp;_z=1&amp;_m=eNpVz99vgjAQB%2FD%2BLTyP0rM%2FLCZ7WOKWbFF5GJr4RAotUBUwgkHZ9rdvVB8W%2B3BJv3eXT45hQhBCwAnhEkKQKA2lZCHLhJYSwExTSgSkqcpBCEIIIKBTTglaf6JQ4glleAISAxOo7LpjOwuCvu9xrip7uJaq1tdK1ThrqgB9eefWnJLibLU385hRoTQ8FylPnSNzMgUtc6EpdY73dB%2B3bVKbflwYg6xp9tYkuqmUrceoNeqUlbg9Nt0lG7HCOGkcVGdtTZ2Z5IHyneU7zHea%2F8D93A6bcPR7e2i1XizuBW4R4oJcKPDx%2B99y5TuKD%2BU23rPlrhiW849dFG%2FstnqrVsPLJYrfYTm88mi%2BZ6uheP4DQppvcA%3D%3D&amp;_l=eNplj01rwkAYhN%2FfsleLvJv9yEbooUXoxU1BDKW5yGazNUk1SZMNtVr%2Fe9VgofQyh5mHGYYqqrhiEEYykIyDABWgAi4wlAAUKKdcRIpD3zZ%2BD5MJSBpB3dQOEI6EkdmRZGVOZgSniEySO9Kar2bwNwcvVt%2B6%2BpeJrtCQbUu7dntbmHrj1m%2FOXXOKiOfYDp3xLl%2FvTPfufLs11v1n8cKeThAni8UoCDhVAparR%2FDdsGuLxrttP7XNDlBxRjORW5pxhRYjIYULc8nDyDLm8vH3%2BRxy%2BBhM7a3p%2FdhKWSgC8XcGvuNK8%2FQp2b%2BuiiKdb1AfNNfVw6eukkBXcfk8T4L0ZVnqQ3L%2FAyjAYKo%3D&amp;_t=eNotj9Fu4yAQRf0tPFdbwK5dE%2B1b1aiVnGpXlVaJKlkDTGxaYyxMtsmm%2BfeFuDzA3MO9wyBhHNFn9Z5WQCtd6vt7xrCSOa2YlLBnZUkpZZnqwYyZ8x2MRmVySZ2JRW2gNZoIorWUpeSasbq4y%2BOOulaFrrGGUtccyQ3ZO28hRO%2F66TFKayy24TRhJMZCh2%2B3ndnHi0%2BjQx9hTmlUPZquTyl%2Bd5XBDq1ydhrQ4ph4hAZka%2BwhijTRwbYeVYCxG9KzM4JXfTtPqIg4kwk8WAzo56Q0%2FjUKU4XHkI55cuHYLriFafqekF3iwE7jQAS%2FIS5m%2BSWuLGM%2FKM1AVOI8i1KQbnASBrIygq6uYHJzWACrGY8sv%2F42gbyoKlpEVAiiTDh9m9gS9NgZNyZWsjqiKprcYQz%2B6uM8X3rppVfJ2eqSfW3Wv05b%2Fvy%2Be2j4br352NqN3f55Ys2%2Fj8%2FNQ3d8WTfHhv8eXl6bn%2F8BtbOdiA%3D%3D&amp;_b=eNpFxl0LgjAUgGF%2FkSc%2FEhZ0EUje6AFFibxznuXmsklbGNKPL6%2FihYd350nnZnsA6Onh29m49za9mWASpDpw8jVxC%2BapBqAt4jzhIQUBi%2FfRT0Gsj4kJ1iXEQuEP6uZhk%2Bd%2FPsVaKUxPK6Z3haOO26xZ2gvKoi6Xa30eMas0rmWEtT5%2BAZ5CLzA%3D", companionAds3.StaticResource.URI)
+							assert.Equal(t, URI("https://search.spotxchange.com/banner?_a=137530&amp;_p=spotx&amp;_z=1&amp;_m=eNpVz99vgjAQB%2FD%2BLTyP0rM%2FLCZ7WOKWbFF5GJr4RAotUBUwgkHZ9rdvVB8W%2B3BJv3eXT45hQhBCwAnhEkKQKA2lZCHLhJYSwExTSgSkqcpBCEIIIKBTTglaf6JQ4glleAISAxOo7LpjOwuCvu9xrip7uJaq1tdK1ThrqgB9eefWnJLibLU385hRoTQ8FylPnSNzMgUtc6EpdY73dB%2B3bVKbflwYg6xp9tYkuqmUrceoNeqUlbg9Nt0lG7HCOGkcVGdtTZ2Z5IHyneU7zHea%2F8D93A6bcPR7e2i1XizuBW4R4oJcKPDx%2B99y5TuKD%2BU23rPlrhiW849dFG%2FstnqrVsPLJYrfYTm88mi%2BZ6uheP4DQppvcA%3D%3D&amp;_l=eNplj01rwkAYhN%2FfsleLvJv9yEbooUXoxU1BDKW5yGazNUk1SZMNtVr%2Fe9VgofQyh5mHGYYqqrhiEEYykIyDABWgAi4wlAAUKKdcRIpD3zZ%2BD5MJSBpB3dQOEI6EkdmRZGVOZgSniEySO9Kar2bwNwcvVt%2B6%2BpeJrtCQbUu7dntbmHrj1m%2FOXXOKiOfYDp3xLl%2FvTPfufLs11v1n8cKeThAni8UoCDhVAparR%2FDdsGuLxrttP7XNDlBxRjORW5pxhRYjIYULc8nDyDLm8vH3%2BRxy%2BBhM7a3p%2FdhKWSgC8XcGvuNK8%2FQp2b%2BuiiKdb1AfNNfVw6eukkBXcfk8T4L0ZVnqQ3L%2FAyjAYKo%3D&amp;_t=eNotj9Fu4yAQRf0tPFdbwK5dE%2B1b1aiVnGpXlVaJKlkDTGxaYyxMtsmm%2BfeFuDzA3MO9wyBhHNFn9Z5WQCtd6vt7xrCSOa2YlLBnZUkpZZnqwYyZ8x2MRmVySZ2JRW2gNZoIorWUpeSasbq4y%2BOOulaFrrGGUtccyQ3ZO28hRO%2F66TFKayy24TRhJMZCh2%2B3ndnHi0%2BjQx9hTmlUPZquTyl%2Bd5XBDq1ydhrQ4ph4hAZka%2BwhijTRwbYeVYCxG9KzM4JXfTtPqIg4kwk8WAzo56Q0%2FjUKU4XHkI55cuHYLriFafqekF3iwE7jQAS%2FIS5m%2BSWuLGM%2FKM1AVOI8i1KQbnASBrIygq6uYHJzWACrGY8sv%2F42gbyoKlpEVAiiTDh9m9gS9NgZNyZWsjqiKprcYQz%2B6uM8X3rppVfJ2eqSfW3Wv05b%2Fvy%2Be2j4br352NqN3f55Ys2%2Fj8%2FNQ3d8WTfHhv8eXl6bn%2F8BtbOdiA%3D%3D&amp;_b=eNpFxl0LgjAUgGF%2FkSc%2FEhZ0EUje6AFFibxznuXmsklbGNKPL6%2FihYd350nnZnsA6Onh29m49za9mWASpDpw8jVxC%2BapBqAt4jzhIQUBi%2FfRT0Gsj4kJ1iXEQuEP6uZhk%2Bd%2FPsVaKUxPK6Z3haOO26xZ2gvKoi6Xa30eMas0rmWEtT5%2BAZ5CLzA%3D"), companionAds3.StaticResource.URI)
 						}
 						if assert.NotNil(t, companionAds3.CompanionClickThrough) {
-							assert.Equal(t, "https://search.spotxchange.com/click?_a=137530&amp;_p=spotx&amp;_z=1&amp;_m=eNpVz99vgjAQB%2FD%2BLTyP0rM%2FLCZ7WOKWbFF5GJr4RAotUBUwgkHZ9rdvVB8W%2B3BJv3eXT45hQhBCwAnhEkKQKA2lZCHLhJYSwExTSgSkqcpBCEIIIKBTTglaf6JQ4glleAISAxOo7LpjOwuCvu9xrip7uJaq1tdK1ThrqgB9eefWnJLibLU385hRoTQ8FylPnSNzMgUtc6EpdY73dB%2B3bVKbflwYg6xp9tYkuqmUrceoNeqUlbg9Nt0lG7HCOGkcVGdtTZ2Z5IHyneU7zHea%2F8D93A6bcPR7e2i1XizuBW4R4oJcKPDx%2B99y5TuKD%2BU23rPlrhiW849dFG%2FstnqrVsPLJYrfYTm88mi%2BZ6uheP4DQppvcA%3D%3D&amp;_l=eNplj01rwkAYhN%2FfsleLvJv9yEbooUXoxU1BDKW5yGazNUk1SZMNtVr%2Fe9VgofQyh5mHGYYqqrhiEEYykIyDABWgAi4wlAAUKKdcRIpD3zZ%2BD5MJSBpB3dQOEI6EkdmRZGVOZgSniEySO9Kar2bwNwcvVt%2B6%2BpeJrtCQbUu7dntbmHrj1m%2FOXXOKiOfYDp3xLl%2FvTPfufLs11v1n8cKeThAni8UoCDhVAparR%2FDdsGuLxrttP7XNDlBxRjORW5pxhRYjIYULc8nDyDLm8vH3%2BRxy%2BBhM7a3p%2FdhKWSgC8XcGvuNK8%2FQp2b%2BuiiKdb1AfNNfVw6eukkBXcfk8T4L0ZVnqQ3L%2FAyjAYKo%3D&amp;_t=eNotj0tv3CAUhf1bWEcJYMeOPeomqhplMVOp8iwcVUI87tgkYCzMNPPo%2FPeAHRZwz8c5l4s0Wn5k9QFXHFeqVE9PhEAlclwRIfiBlCXGmGRy4HrMnO%2F5qGUm%2BDiCZ3LJXpEFpTnTCjVIKSFKQRUhdfGYxx1ULQtVQ81LVVNAd%2BjgvOUhel9ef0VptQUWzhNEoi3v4e9Drw%2Fx4lOrMESYYxzVALofUoo%2BLjJYw6SzkwELY%2BIRai6Ytsco0kRHyzzIwMfepGdn4F4ObJ5AouaKJu65hQB%2BTkrBPy0hVXAK6ZgnF05sxYxP0%2FeE5BYHdgoMaugdcjFLb3FlGbnHOONN1VznpmxQb5zgBm10gzcLmNwcVkBqQiPLl98mkBdVhYuIigZJHc7fJrIGPfTajYmVpI6oiiZ3HINffJTmay%2B19iop2dyy%2F93lj%2Bnandm1%2B3P3PrzvXl4%2Fu8v2tG3fzNbuL7tW6a59Hn7%2F3P%2F4Ap5ioKM%3D&amp;_b=eNozYMgoKSkottLXLy7IL6lIzkjMS0%2FVS87PZfAL9fFhsEwzsLQ0NzQ0S7GwMDRMNU8yNjA3TEpKTDM0M3MDArCqmih3V5PIrGxDX5d0g8gqr0x%2Fl0hDfxe3bN8sTwO%2FrLDMyKycDD8XEJ1uCwCmYiLM", companionAds3.CompanionClickThrough.CDATA)
+							assert.Equal(t, "https://search.spotxchange.com/click?_a=137530&amp;_p=spotx&amp;_z=1&amp;_m=eNpVz99vgjAQB%2FD%2BLTyP0rM%2FLCZ7WOKWbFF5GJr4RAotUBUwgkHZ9rdvVB8W%2B3BJv3eXT45hQhBCwAnhEkKQKA2lZCHLhJYSwExTSgSkqcpBCEIIIKBTTglaf6JQ4glleAISAxOo7LpjOwuCvu9xrip7uJaq1tdK1ThrqgB9eefWnJLibLU385hRoTQ8FylPnSNzMgUtc6EpdY73dB%2B3bVKbflwYg6xp9tYkuqmUrceoNeqUlbg9Nt0lG7HCOGkcVGdtTZ2Z5IHyneU7zHea%2F8D93A6bcPR7e2i1XizuBW4R4oJcKPDx%2B99y5TuKD%2BU23rPlrhiW849dFG%2FstnqrVsPLJYrfYTm88mi%2BZ6uheP4DQppvcA%3D%3D&amp;_l=eNplj01rwkAYhN%2FfsleLvJv9yEbooUXoxU1BDKW5yGazNUk1SZMNtVr%2Fe9VgofQyh5mHGYYqqrhiEEYykIyDABWgAi4wlAAUKKdcRIpD3zZ%2BD5MJSBpB3dQOEI6EkdmRZGVOZgSniEySO9Kar2bwNwcvVt%2B6%2BpeJrtCQbUu7dntbmHrj1m%2FOXXOKiOfYDp3xLl%2FvTPfufLs11v1n8cKeThAni8UoCDhVAparR%2FDdsGuLxrttP7XNDlBxRjORW5pxhRYjIYULc8nDyDLm8vH3%2BRxy%2BBhM7a3p%2FdhKWSgC8XcGvuNK8%2FQp2b%2BuiiKdb1AfNNfVw6eukkBXcfk8T4L0ZVnqQ3L%2FAyjAYKo%3D&amp;_t=eNotj0tv3CAUhf1bWEcJYMeOPeomqhplMVOp8iwcVUI87tgkYCzMNPPo%2FPeAHRZwz8c5l4s0Wn5k9QFXHFeqVE9PhEAlclwRIfiBlCXGmGRy4HrMnO%2F5qGUm%2BDiCZ3LJXpEFpTnTCjVIKSFKQRUhdfGYxx1ULQtVQ81LVVNAd%2BjgvOUhel9ef0VptQUWzhNEoi3v4e9Drw%2Fx4lOrMESYYxzVALofUoo%2BLjJYw6SzkwELY%2BIRai6Ytsco0kRHyzzIwMfepGdn4F4ObJ5AouaKJu65hQB%2BTkrBPy0hVXAK6ZgnF05sxYxP0%2FeE5BYHdgoMaugdcjFLb3FlGbnHOONN1VznpmxQb5zgBm10gzcLmNwcVkBqQiPLl98mkBdVhYuIigZJHc7fJrIGPfTajYmVpI6oiiZ3HINffJTmay%2B19iop2dyy%2F93lj%2Bnandm1%2B3P3PrzvXl4%2Fu8v2tG3fzNbuL7tW6a59Hn7%2F3P%2F4Ap5ioKM%3D&amp;_b=eNozYMgoKSkottLXLy7IL6lIzkjMS0%2FVS87PZfAL9fFhsEwzsLQ0NzQ0S7GwMDRMNU8yNjA3TEpKTDM0M3MDArCqmih3V5PIrGxDX5d0g8gqr0x%2Fl0hDfxe3bN8sTwO%2FrLDMyKycDD8XEJ1uCwCmYiLM", string(companionAds3.CompanionClickThrough.URI))
 						}
 						if assert.NotNil(t, companionAds3.AltText) {
 							assert.Equal(t, "IntegralAds_VAST_2_0_Ad_Wrapper", companionAds3.AltText)
@@ -807,11 +809,11 @@ func TestExtraSpacesVpaid(t *testing.T) {
 					assert.Equal(t, "        \n                  <VAST></VAST>\n                  \n                  ", linear.AdParameters.Parameters)
 					if assert.Len(t, crea1.Linear.MediaFiles, 1) {
 						media1 := crea1.Linear.MediaFiles[0]
-						assert.Equal(t, "progressive", media1.Delivery)
+						assert.Equal(t, DeliveryProgressive, media1.Delivery)
 						assert.Equal(t, "application/javascript", media1.Type)
 						assert.Equal(t, 300, media1.Width)
 						assert.Equal(t, 250, media1.Height)
-						assert.Equal(t, "\n                     https://dummy.com/dummmy.js             \n                     ", media1.URI)
+						assert.Equal(t, URI("\n                     https://dummy.com/dummmy.js             \n                     "), media1.URI)
 					}
 				}
 			}
@@ -845,12 +847,12 @@ func TestIcons(t *testing.T) {
 						assert.Equal(t, "DAA", icon1.Program)
 						assert.Equal(t, 77, icon1.Width)
 						assert.Equal(t, 15, icon1.Height)
-						assert.Equal(t, "right", icon1.XPosition)
-						assert.Equal(t, "top", icon1.YPosition)
+						assert.Equal(t, IconRight, icon1.XPosition)
+						assert.Equal(t, IconTop, icon1.YPosition)
 						if assert.NotNil(t, icon1.StaticResource) {
 							assert.Equal(t, "image/png", icon1.StaticResource.CreativeType)
-							assert.Equal(t, "https://s.aolcdn.com/ads/adchoices.png", icon1.StaticResource.URI)
-							assert.Equal(t, "https://adinfo.aol.com", icon1.IconClickThrough.CDATA)
+							assert.Equal(t, URI("https://s.aolcdn.com/ads/adchoices.png"), icon1.StaticResource.URI)
+							assert.Equal(t, "https://adinfo.aol.com", string(icon1.IconClickThrough.URI))
 						}
 					}
 				}
@@ -882,3 +884,167 @@ func TestUniversalAdID(t *testing.T) {
 		}
 	}
 }
+
+func TestMediaFilesContainer(t *testing.T) {
+	v, _, res, err := loadFixture("testdata/vast4_media_files.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, Version4_1, v.InferredVersion)
+	linear := v.Ads[0].InLine.Creatives[0].Linear
+	if assert.NotNil(t, linear) {
+		assert.Len(t, linear.MediaFiles, 1)
+		assert.Len(t, linear.Mezzanines, 1)
+		if assert.Len(t, linear.InteractiveCreativeFiles, 1) {
+			icf := linear.InteractiveCreativeFiles[0]
+			assert.Equal(t, "text/html", icf.Type)
+			assert.Equal(t, APIFrameworkSIMID, icf.APIFramework)
+			assert.Equal(t, Bool(true), icf.VariableDuration)
+			assert.Equal(t, "https://iabtechlab.com/simid/creative.html", strings.TrimSpace(string(icf.URI)))
+		}
+		if assert.NotNil(t, linear.ClosedCaptionFiles) && assert.Len(t, linear.ClosedCaptionFiles.ClosedCaptionFile, 2) {
+			ccf := linear.ClosedCaptionFiles.ClosedCaptionFile
+			assert.Equal(t, "text/vtt", ccf[0].Type)
+			assert.Equal(t, "en", ccf[0].Language)
+			assert.Equal(t, "fr", ccf[1].Language)
+			assert.Equal(t, "https://iabtechlab.com/captions/fr.vtt", strings.TrimSpace(string(ccf[1].URI)))
+		}
+	}
+	assert.True(t, v.Ads[0].InLine.Creatives[0].RequiresSIMID())
+	assert.NoError(t, v.Validate())
+
+	// all files are serialized back in a single MediaFiles container
+	assert.Equal(t, 1, strings.Count(res, "<MediaFiles>"))
+	assert.Equal(t, 1, strings.Count(res, "<ClosedCaptionFiles>"))
+}
+
+func TestAdVerifications(t *testing.T) {
+	v, _, res, err := loadFixture("testdata/vast4_verification.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	inline := v.Ads[0].InLine
+	if assert.NotNil(t, inline.AdVerifications) && assert.Len(t, *inline.AdVerifications, 2) {
+		omid := (*inline.AdVerifications)[0]
+		assert.Equal(t, "company.com-omid", omid.Vendor)
+		if assert.Len(t, omid.JavaScriptResources, 1) {
+			assert.Equal(t, APIFrameworkOMID, omid.JavaScriptResources[0].APIFramework)
+			assert.Equal(t, Bool(true), omid.JavaScriptResources[0].BrowserOptional)
+			assert.Equal(t, "https://verification.com/omid_verification.js", strings.TrimSpace(string(omid.JavaScriptResources[0].URI)))
+		}
+		if assert.Len(t, omid.TrackingEvents, 1) {
+			assert.Equal(t, string(EventVerificationNotExecuted), omid.TrackingEvents[0].Event)
+		}
+		if assert.NotNil(t, omid.VerificationParameters) {
+			assert.Equal(t, `{"campaign":"1234"}`, strings.TrimSpace(omid.VerificationParameters.CDATA))
+		}
+
+		native := (*inline.AdVerifications)[1]
+		if assert.Len(t, native.ExecutableResources, 1) {
+			assert.Equal(t, "application/octet-stream", native.ExecutableResources[0].Type)
+		}
+	}
+	assert.NoError(t, v.Validate())
+
+	// the verifications survive a round trip
+	var parsed VAST
+	if assert.NoError(t, xml.Unmarshal([]byte(res), &parsed)) {
+		assert.Equal(t, inline.AdVerifications, parsed.Ads[0].InLine.AdVerifications)
+	}
+}
+
+func TestViewableImpression(t *testing.T) {
+	v, _, res, err := loadFixture("testdata/vast4_verification.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	vi := v.Ads[0].InLine.ViewableImpression
+	if assert.NotNil(t, vi) {
+		assert.Equal(t, "1543", vi.ID)
+		assert.Equal(t, []CDATAURI{{"http://example.com/viewable"}, {"http://example.com/viewable2"}}, vi.Viewable)
+		assert.Equal(t, []CDATAURI{{"http://example.com/notviewable"}}, vi.NotViewable)
+		assert.Equal(t, []CDATAURI{{"http://example.com/undetermined"}}, vi.ViewUndetermined)
+	}
+	assert.Contains(t, res, `<ViewableImpression id="1543">`)
+
+	var w Wrapper
+	err = xml.Unmarshal([]byte(`<Wrapper><ViewableImpression><NotViewable>http://example.com/nv</NotViewable></ViewableImpression></Wrapper>`), &w)
+	if assert.NoError(t, err) && assert.NotNil(t, w.ViewableImpression) {
+		assert.Equal(t, []CDATAURI{{"http://example.com/nv"}}, w.ViewableImpression.NotViewable)
+	}
+}
+
+func TestIconClickFallbackImages(t *testing.T) {
+	raw := `<Icon program="AdChoices" width="20" height="20" xPosition="right" yPosition="top">` +
+		`<StaticResource creativeType="image/png"><![CDATA[http://example.com/icon.png]]></StaticResource>` +
+		`<IconClicks><IconClickThrough><![CDATA[http://example.com/adchoices]]></IconClickThrough>` +
+		`<IconClickFallbackImages><IconClickFallbackImage width="400" height="300"><AltText>AdChoices</AltText>` +
+		`<StaticResource creativeType="image/png"><![CDATA[http://example.com/fallback.png]]></StaticResource>` +
+		`</IconClickFallbackImage></IconClickFallbackImages></IconClicks></Icon>`
+
+	var icon Icon
+	if !assert.NoError(t, xml.Unmarshal([]byte(raw), &icon)) {
+		return
+	}
+	if assert.NotNil(t, icon.IconClickFallbackImages) && assert.Len(t, *icon.IconClickFallbackImages, 1) {
+		img := (*icon.IconClickFallbackImages)[0]
+		assert.Equal(t, 400, img.Width)
+		assert.Equal(t, 300, img.Height)
+		assert.Equal(t, "AdChoices", img.AltText)
+		if assert.NotNil(t, img.StaticResource) {
+			assert.Equal(t, URI("http://example.com/fallback.png"), img.StaticResource.URI)
+		}
+	}
+	if assert.NotNil(t, icon.IconClickThrough) {
+		assert.Equal(t, "http://example.com/adchoices", string(icon.IconClickThrough.URI))
+	}
+
+	b, err := xml.Marshal(icon)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(b), `<IconClicks><IconClickThrough><![CDATA[http://example.com/adchoices]]></IconClickThrough><IconClickFallbackImages>`)
+	}
+
+	// icons without fallback images don't get an empty container
+	icon.IconClickFallbackImages = nil
+	b, err = xml.Marshal(icon)
+	if assert.NoError(t, err) {
+		assert.NotContains(t, string(b), "IconClickFallbackImages")
+	}
+}
+
+func TestSurveysAndClickTrackings(t *testing.T) {
+	raw := `<VAST version="3.0"><Ad id="1"><InLine><AdSystem>DSP</AdSystem><AdTitle>t</AdTitle>` +
+		`<Survey type="text/javascript"><![CDATA[http://survey.example.com/a.js?x=1&y=2]]></Survey>` +
+		`<Survey><![CDATA[http://survey.example.com/b]]></Survey>` +
+		`</InLine></Ad><Ad id="2"><Wrapper><AdSystem>DSP</AdSystem><VASTAdTagURI>http://example.com/vast</VASTAdTagURI>` +
+		`<Creatives><Creative><CompanionAds><Companion width="300" height="250">` +
+		`<CompanionClickThrough><![CDATA[http://example.com/ct?a=1&b=2]]></CompanionClickThrough>` +
+		`<CompanionClickTracking id="c1"><![CDATA[http://example.com/cc?a=1&b=2]]></CompanionClickTracking>` +
+		`</Companion></CompanionAds></Creative><Creative><NonLinearAds><NonLinear>` +
+		`<NonLinearClickTracking id="n1"><![CDATA[http://example.com/nc?a=1&b=2]]></NonLinearClickTracking>` +
+		`</NonLinear></NonLinearAds></Creative></Creatives></Wrapper></Ad></VAST>`
+
+	var v VAST
+	if !assert.NoError(t, xml.Unmarshal([]byte(raw), &v)) {
+		return
+	}
+	assert.Equal(t, []Survey{
+		{Type: "text/javascript", URI: "http://survey.example.com/a.js?x=1&y=2"},
+		{URI: "http://survey.example.com/b"},
+	}, v.Ads[0].InLine.Surveys)
+	w := v.Ads[1].Wrapper
+	assert.Equal(t, []CompanionClickTracking{{ID: "c1", URI: "http://example.com/cc?a=1&b=2"}}, w.Creatives[0].CompanionAds.Companions[0].CompanionClickTrackings)
+	assert.Equal(t, []NonLinearClickTracking{{ID: "n1", URI: "http://example.com/nc?a=1&b=2"}}, w.Creatives[1].NonLinearAds.NonLinears[0].NonLinearClickTrackings)
+
+	b, err := xml.Marshal(v)
+	if assert.NoError(t, err) {
+		out := string(b)
+		assert.Contains(t, out, `<Survey type="text/javascript"><![CDATA[http://survey.example.com/a.js?x=1&y=2]]></Survey><Survey><![CDATA[http://survey.example.com/b]]></Survey>`)
+		assert.Contains(t, out, `<CompanionClickThrough><![CDATA[http://example.com/ct?a=1&b=2]]></CompanionClickThrough>`)
+		assert.Contains(t, out, `<CompanionClickTracking id="c1"><![CDATA[http://example.com/cc?a=1&b=2]]></CompanionClickTracking>`)
+		assert.Contains(t, out, `<NonLinearClickTracking id="n1"><![CDATA[http://example.com/nc?a=1&b=2]]></NonLinearClickTracking>`)
+	}
+}
@@ -0,0 +1,88 @@
+package vast
+
+import "strings"
+
+// ExtensionAdVerifications is the type of the extension carrying the
+// AdVerifications of VAST 3 documents, before the element was standardized.
+const ExtensionAdVerifications = "AdVerifications"
+
+// VerificationResource is a JavaScript verification script to be loaded by
+// an OMID client, such as the OM SDK.
+type VerificationResource struct {
+	// An identifier for the verification vendor, e.g. "company.com-omid"
+	Vendor string
+	// The API framework of the script, e.g. "omid"
+	APIFramework string
+	// The URL of the script
+	URL string
+	// Parameters passed to the script as is
+	Parameters string
+	// Whether the script can be executed outside of a browser
+	BrowserOptional bool
+	// The verificationNotExecuted tracking URIs of the verification
+	NotExecutedURLs []string
+}
+
+// VerificationResources returns the JavaScript verification resources of the
+// ad, from its AdVerifications element and from its legacy AdVerifications
+// extensions, in that order. A script listed in both forms for the same
+// vendor is returned once. Extensions which can't be decoded are ignored.
+func (a *Ad) VerificationResources() []VerificationResource {
+	var verifications []Verification
+	var exts []Extension
+	switch {
+	case a.InLine != nil:
+		if a.InLine.AdVerifications != nil {
+			verifications = *a.InLine.AdVerifications
+		}
+		if a.InLine.Extensions != nil {
+			exts = *a.InLine.Extensions
+		}
+	case a.Wrapper != nil:
+		if a.Wrapper.AdVerifications != nil {
+			verifications = *a.Wrapper.AdVerifications
+		}
+		exts = a.Wrapper.Extensions
+	}
+	for i := range exts {
+		if !strings.EqualFold(exts[i].Type, ExtensionAdVerifications) {
+			continue
+		}
+		var legacy LegacyVerifications
+		if err := exts[i].Decode(&legacy); err == nil {
+			verifications = append(verifications, legacy.Verifications...)
+		}
+	}
+
+	var resources []VerificationResource
+	seen := map[[2]string]bool{}
+	for _, v := range verifications {
+		var params string
+		if v.VerificationParameters != nil {
+			params = strings.TrimSpace(v.VerificationParameters.CDATA)
+		}
+		var notExecuted []string
+		for _, t := range v.TrackingEvents {
+			if EventType(t.Event) == EventVerificationNotExecuted {
+				notExecuted = appendURL(notExecuted, string(t.URI))
+			}
+		}
+		for _, js := range v.JavaScriptResources {
+			url := strings.TrimSpace(string(js.URI))
+			key := [2]string{v.Vendor, url}
+			if url == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			resources = append(resources, VerificationResource{
+				Vendor:          v.Vendor,
+				APIFramework:    js.APIFramework,
+				URL:             url,
+				Parameters:      params,
+				BrowserOptional: bool(js.BrowserOptional),
+				NotExecutedURLs: notExecuted,
+			})
+		}
+	}
+	return resources
+}
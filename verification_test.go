@@ -0,0 +1,59 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerificationResources(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast4_verification.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []VerificationResource{
+		{
+			Vendor:          "company.com-omid",
+			APIFramework:    "omid",
+			URL:             "https://verification.com/omid_verification.js",
+			Parameters:      `{"campaign":"1234"}`,
+			BrowserOptional: true,
+			NotExecutedURLs: []string{"https://verification.com/notexecuted?reason=[REASON]"},
+		},
+	}, v.Ads[0].VerificationResources())
+}
+
+func TestVerificationResourcesLegacyExtension(t *testing.T) {
+	doc := `<VAST version="3.0"><Ad><InLine>
+	<AdVerifications>
+		<Verification vendor="a.com"><JavaScriptResource apiFramework="omid"><![CDATA[https://a.com/v.js]]></JavaScriptResource></Verification>
+	</AdVerifications>
+	<Extensions>
+		<Extension type="AdVerifications"><AdVerifications>
+			<Verification vendor="a.com"><JavaScriptResource apiFramework="omid"><![CDATA[https://a.com/v.js]]></JavaScriptResource></Verification>
+			<Verification vendor="b.com">
+				<JavaScriptResource apiFramework="omid" browserOptional="true"><![CDATA[https://b.com/v.js]]></JavaScriptResource>
+				<VerificationParameters><![CDATA[id=1]]></VerificationParameters>
+			</Verification>
+		</AdVerifications></Extension>
+		<Extension type="adverifications">
+			<Verification vendor="c.com"><JavaScriptResource><![CDATA[https://c.com/v.js]]></JavaScriptResource></Verification>
+		</Extension>
+		<Extension type="other"><Verification vendor="d.com"><JavaScriptResource><![CDATA[https://d.com/v.js]]></JavaScriptResource></Verification></Extension>
+	</Extensions>
+	</InLine></Ad></VAST>`
+	var v VAST
+	if !assert.NoError(t, xml.Unmarshal([]byte(doc), &v)) {
+		return
+	}
+	assert.Equal(t, []VerificationResource{
+		{Vendor: "a.com", APIFramework: "omid", URL: "https://a.com/v.js"},
+		{Vendor: "b.com", APIFramework: "omid", URL: "https://b.com/v.js", Parameters: "id=1", BrowserOptional: true},
+		{Vendor: "c.com", URL: "https://c.com/v.js"},
+	}, v.Ads[0].VerificationResources())
+
+	assert.Empty(t, (&Ad{}).VerificationResources())
+	w := Ad{Wrapper: &Wrapper{AdVerifications: &[]Verification{{Vendor: "w.com", JavaScriptResources: []JavaScriptResource{{URI: "https://w.com/v.js"}}}}}}
+	assert.Equal(t, []VerificationResource{{Vendor: "w.com", URL: "https://w.com/v.js"}}, w.VerificationResources())
+}
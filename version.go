@@ -0,0 +1,302 @@
+package vast
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SpecVersion is a version of the VAST specification.
+type SpecVersion string
+
+// Supported VAST versions
+const (
+	Version2_0 SpecVersion = "2.0"
+	Version3_0 SpecVersion = "3.0"
+	Version4_0 SpecVersion = "4.0"
+	Version4_1 SpecVersion = "4.1"
+	Version4_2 SpecVersion = "4.2"
+)
+
+// specVersions lists the known versions from oldest to newest.
+var specVersions = []SpecVersion{Version2_0, Version3_0, Version4_0, Version4_1, Version4_2}
+
+func (v SpecVersion) index() int {
+	for i, sv := range specVersions {
+		if sv == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// Valid returns true if v is one of the known VAST versions.
+func (v SpecVersion) Valid() bool {
+	return v.index() >= 0
+}
+
+// AtLeast returns true if v is the same as or newer than o. Unknown versions
+// are older than any known one.
+func (v SpecVersion) AtLeast(o SpecVersion) bool {
+	return v.index() >= o.index()
+}
+
+// ParseSpecVersion leniently parses a version attribute such as "3.0", "3",
+// "v4.1" or "4.2.0". It returns false if the value is not a known version.
+func ParseSpecVersion(s string) (SpecVersion, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	parts := strings.SplitN(s, ".", 3)
+	if len(parts) == 1 {
+		parts = append(parts, "0")
+	}
+	v := SpecVersion(parts[0] + "." + parts[1])
+	if !v.Valid() {
+		return "", false
+	}
+	return v, true
+}
+
+// rawVAST is used to decode a VAST without recursing into UnmarshalXML.
+type rawVAST VAST
+
+// UnmarshalXML implements xml.Unmarshaler interface.
+func (v *VAST) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	if err := dec.DecodeElement((*rawVAST)(v), &start); err != nil {
+		return err
+	}
+	v.InferredVersion = inferVersion(v)
+	return nil
+}
+
+// EffectiveVersion returns the version the document should be treated as:
+// the inferred version when the document was parsed, the declared one
+// otherwise. It returns an empty version if neither is known.
+func (v *VAST) EffectiveVersion() SpecVersion {
+	if v.InferredVersion != "" {
+		return v.InferredVersion
+	}
+	sv, _ := ParseSpecVersion(v.Version)
+	return sv
+}
+
+// inferVersion returns the declared version of the document if it is valid
+// and compatible with the elements present, and otherwise the minimum version
+// implied by those elements.
+func inferVersion(v *VAST) SpecVersion {
+	min := minimumVersion(v)
+	if declared, ok := ParseSpecVersion(v.Version); ok && declared.AtLeast(min) {
+		return declared
+	}
+	return min
+}
+
+// RequiredVersion returns the lowest version supporting all the elements,
+// attributes and tracking events present in v, such as AdVerifications,
+// Mezzanine, ClosedCaptionFiles, Category, adType or playerExpand, whatever
+// its declared version. It
+// is the version to declare for the document to be read by as many players
+// as possible:
+//
+//	doc.Version = string(vast.RequiredVersion(doc))
+func RequiredVersion(v *VAST) SpecVersion {
+	return minimumVersion(v)
+}
+
+// versionedField is a field of an element type holding an element or an
+// attribute introduced after VAST 2.0.
+type versionedField struct {
+	typ  reflect.Type
+	name string
+}
+
+var (
+	adType        = reflect.TypeOf(Ad{})
+	inlineType    = reflect.TypeOf(InLine{})
+	wrapperType   = reflect.TypeOf(Wrapper{})
+	creativeType  = reflect.TypeOf(Creative{})
+	linearType    = reflect.TypeOf(Linear{})
+	iconType      = reflect.TypeOf(Icon{})
+	mediaFileType = reflect.TypeOf(MediaFile{})
+	trackingType  = reflect.TypeOf(Tracking{})
+	// index of Tracking.Event
+	trackingEvent = func() int {
+		f, _ := trackingType.FieldByName("Event")
+		return f.Index[0]
+	}()
+)
+
+// versionedFields are the elements and attributes introduced after VAST 2.0,
+// with the version introducing them. Both RequiredVersion and Downgrade rely
+// on it, so that a document downgraded to a version doesn't require a newer
+// one.
+var versionedFields = map[versionedField]SpecVersion{
+	{adType, "Sequence"}:                       Version3_0,
+	{adType, "ConditionalAd"}:                  Version3_0,
+	{adType, "AdType"}:                         Version4_1,
+	{inlineType, "ViewableImpression"}:         Version4_0,
+	{inlineType, "Pricing"}:                    Version3_0,
+	{inlineType, "AdServingId"}:                Version4_1,
+	{inlineType, "Advertiser"}:                 Version4_0,
+	{inlineType, "Categories"}:                 Version4_0,
+	{inlineType, "Expires"}:                    Version4_1,
+	{inlineType, "AdVerifications"}:            Version4_1,
+	{wrapperType, "ViewableImpression"}:        Version4_0,
+	{wrapperType, "AdVerifications"}:           Version4_1,
+	{wrapperType, "FallbackOnNoAd"}:            Version3_0,
+	{wrapperType, "AllowMultipleAds"}:          Version3_0,
+	{wrapperType, "FollowAdditionalWrappers"}:  Version3_0,
+	{creativeType, "UniversalAdID"}:            Version4_0,
+	{linearType, "SkipOffset"}:                 Version3_0,
+	{linearType, "Icons"}:                      Version3_0,
+	{linearType, "Mezzanines"}:                 Version4_0,
+	{linearType, "InteractiveCreativeFiles"}:   Version4_0,
+	{linearType, "ClosedCaptionFiles"}:         Version4_1,
+	{reflect.TypeOf(LinearWrapper{}), "Icons"}: Version3_0,
+	{mediaFileType, "FileSize"}:                Version4_1,
+	{mediaFileType, "MediaType"}:               Version4_1,
+	{iconType, "IconClickFallbackImages"}:      Version4_1,
+}
+
+// versionedEvents are the tracking events introduced after VAST 2.0, with
+// the version introducing them and the event they are renamed to before it.
+var versionedEvents = map[EventType]struct {
+	version SpecVersion
+	before  EventType
+}{
+	EventPlayerExpand:   {Version4_1, EventFullscreen},
+	EventPlayerCollapse: {Version4_1, EventExitFullscreen},
+}
+
+// feature is an element, an attribute or an event of a document listed in
+// versionedFields or versionedEvents.
+type feature struct {
+	// Path of the element, as in ValidationError
+	path string
+	// Name of the attribute, if the feature is one
+	attr    string
+	version SpecVersion
+	// The field holding the feature, and the element holding the field
+	field, parent reflect.Value
+	// Event an event is renamed to before its version
+	before EventType
+}
+
+// features calls visit for every feature of v, in document order, each
+// element of a list being visited on its own. The elements nested in a
+// feature cleared by visit are skipped. The paths of the features are left
+// empty unless paths is set.
+func features(v *VAST, paths bool, visit func(f feature)) {
+	if v != nil {
+		w := &featureWalker{visit: visit, paths: paths, path: append(make([]byte, 0, 128), "VAST"...)}
+		w.walk(reflect.ValueOf(v).Elem())
+	}
+}
+
+// featureWalker visits the features of a document. As it runs for every
+// decoded document, the path of the current element is built in place, and
+// only turned into a string for the features found when paths is set.
+type featureWalker struct {
+	visit func(f feature)
+	paths bool
+	path  []byte
+}
+
+// walk visits the features of value, the addressable element at w.path.
+func (w *featureWalker) walk(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			w.walk(value.Elem())
+		}
+		return
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		n := len(w.path)
+		for i := 0; i < value.Len(); i++ {
+			w.path = w.index(n, i)
+			w.walk(value.Index(i))
+		}
+		w.path = w.path[:n]
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+
+	t := value.Type()
+	if t == trackingType {
+		event := value.Field(trackingEvent)
+		if e, ok := versionedEvents[EventType(event.String())]; ok {
+			w.visit(feature{path: w.string(w.path, ""), attr: "event", version: e.version, field: event, parent: value, before: e.before})
+		}
+		return
+	}
+	n := len(w.path)
+	for i, f := range xmlFields(t) {
+		if !f.ok {
+			continue
+		}
+		attr := ""
+		switch {
+		case f.Attr:
+			attr = f.Name()
+		case f.CharData, f.InnerXML, f.Any, f.Comment:
+		default:
+			w.path = append(append(w.path, '.'), f.Name()...)
+		}
+
+		field := value.Field(i)
+		if version, ok := versionedFields[versionedField{t, f.field}]; ok && !field.IsZero() {
+			switch {
+			case field.Kind() == reflect.Slice:
+				// the items are visited even if the first one clears the
+				// field
+				for j, items := 0, field.Len(); j < items; j++ {
+					w.visit(feature{path: w.string(w.index(len(w.path), j), ""), version: version, field: field, parent: value})
+				}
+			case field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Slice && len(f.Names) > 1:
+				// a list held by a container element, such as
+				// AdVerifications>Verification, is visited as the container
+				w.visit(feature{path: w.string(w.path[:n], "."+f.Names[len(f.Names)-2]), version: version, field: field, parent: value})
+			default:
+				w.visit(feature{path: w.string(w.path, ""), attr: attr, version: version, field: field, parent: value})
+			}
+			if field.IsZero() {
+				w.path = w.path[:n]
+				continue
+			}
+		}
+		w.walk(field)
+		w.path = w.path[:n]
+	}
+}
+
+// string returns path followed by suffix, or "" unless w.paths is set.
+func (w *featureWalker) string(path []byte, suffix string) string {
+	if !w.paths {
+		return ""
+	}
+	return string(path) + suffix
+}
+
+// index returns the path of the item i of the list whose path is the first
+// n bytes of w.path, reusing w.path.
+func (w *featureWalker) index(n, i int) []byte {
+	return append(strconv.AppendInt(append(w.path[:n], '['), int64(i), 10), ']')
+}
+
+// minimumVersion returns the lowest version introducing all the elements,
+// attributes and events present in the document.
+func minimumVersion(v *VAST) SpecVersion {
+	min := Version2_0
+	features(v, false, func(f feature) {
+		if !min.AtLeast(f.version) {
+			min = f.version
+		}
+	})
+	return min
+}
@@ -0,0 +1,97 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSpecVersion(t *testing.T) {
+	for in, want := range map[string]SpecVersion{
+		"2.0":    Version2_0,
+		" 3.0 ":  Version3_0,
+		"3":      Version3_0,
+		"v4.1":   Version4_1,
+		"4.2.0":  Version4_2,
+		"4.2.1b": Version4_2,
+	} {
+		v, ok := ParseSpecVersion(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, want, v, in)
+	}
+	for _, in := range []string{"", "1.0", "5.0", "four", "4.3"} {
+		_, ok := ParseSpecVersion(in)
+		assert.False(t, ok, in)
+	}
+}
+
+func TestSpecVersionAtLeast(t *testing.T) {
+	assert.True(t, Version4_2.AtLeast(Version4_1))
+	assert.True(t, Version3_0.AtLeast(Version3_0))
+	assert.False(t, Version2_0.AtLeast(Version3_0))
+	assert.False(t, SpecVersion("bogus").AtLeast(Version2_0))
+}
+
+func TestInferVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want SpecVersion
+	}{
+		{"declared", `<VAST version="3.0"></VAST>`, Version3_0},
+		{"missing", `<VAST></VAST>`, Version2_0},
+		{"bogus", `<VAST version="VAST3"><Ad sequence="1"><InLine></InLine></Ad></VAST>`, Version3_0},
+		{"lenient", `<VAST version="4"></VAST>`, Version4_0},
+		{"universal ad id", `<VAST version="2.0"><Ad><InLine><Creatives><Creative><UniversalAdId idRegistry="Ad-ID">1</UniversalAdId></Creative></Creatives></InLine></Ad></VAST>`, Version4_0},
+		{"ad serving id", `<VAST version="4.0"><Ad><InLine><AdServingId>a-1</AdServingId><Creatives><Creative><UniversalAdId idRegistry="Ad-ID">1</UniversalAdId></Creative></Creatives></InLine></Ad></VAST>`, Version4_1},
+		{"newer declared", `<VAST version="4.2"><Ad><InLine><AdServingId>a-1</AdServingId></InLine></Ad></VAST>`, Version4_2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v VAST
+			if assert.NoError(t, xml.Unmarshal([]byte(tt.doc), &v)) {
+				assert.Equal(t, tt.want, v.InferredVersion)
+				assert.Equal(t, tt.want, v.EffectiveVersion())
+			}
+		})
+	}
+}
+
+func TestRequiredVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  *VAST
+		want SpecVersion
+	}{
+		{"empty", &VAST{Version: "4.2"}, Version2_0},
+		{"sequence", &VAST{Ads: []Ad{{Sequence: 1, InLine: &InLine{}}}}, Version3_0},
+		{"wrapper icons", &VAST{Ads: []Ad{{Wrapper: &Wrapper{Creatives: []CreativeWrapper{{Linear: &LinearWrapper{Icons: &Icons{}}}}}}}}, Version3_0},
+		{"category", &VAST{Ads: []Ad{{InLine: &InLine{Categories: []Category{{Code: "IAB1"}}}}}}, Version4_0},
+		{"mezzanine", &VAST{Ads: []Ad{{InLine: &InLine{Creatives: []Creative{{Linear: &Linear{Mezzanines: []Mezzanine{{}}}}}}}}}, Version4_0},
+		{"closed captions", &VAST{Ads: []Ad{{InLine: &InLine{Creatives: []Creative{{Linear: &Linear{ClosedCaptionFiles: &ClosedCaptionFiles{}}}}}}}}, Version4_1},
+		{"ad type", &VAST{Ads: []Ad{{AdType: AdTypeAudio, InLine: &InLine{}}}}, Version4_1},
+		{"verifications", &VAST{Ads: []Ad{{Wrapper: &Wrapper{AdVerifications: &[]Verification{}}}}}, Version4_1},
+		{"wrapper fallback images", &VAST{Ads: []Ad{{Wrapper: &Wrapper{Creatives: []CreativeWrapper{{Linear: &LinearWrapper{Icons: &Icons{Icon: []Icon{{IconClickFallbackImages: &[]IconClickFallbackImage{}}}}}}}}}}}, Version4_1},
+		{"player expand", &VAST{Version: "2.0", Ads: []Ad{{InLine: &InLine{Creatives: []Creative{{NonLinearAds: &NonLinearAds{TrackingEvents: []Tracking{{Event: "playerExpand"}}}}}}}}}, Version4_1},
+		{"kitchen sink", kitchenSink(), Version4_1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RequiredVersion(tt.doc))
+		})
+	}
+}
+
+func TestInferVersionFixture(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast4_universal_ad_id.xml")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "4.0", v.Version)
+		assert.Equal(t, Version4_0, v.InferredVersion)
+	}
+}
+
+func TestEffectiveVersionDeclared(t *testing.T) {
+	assert.Equal(t, Version3_0, (&VAST{Version: "3.0"}).EffectiveVersion())
+	assert.Equal(t, SpecVersion(""), (&VAST{Version: "bogus"}).EffectiveVersion())
+}
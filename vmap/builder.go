@@ -0,0 +1,178 @@
+package vmap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// VASTSource returns an ad source embedding the VAST document.
+func VASTSource(doc *vast.VAST) *AdSource {
+	return &AdSource{VASTAdData: &VASTAdData{VAST: doc}}
+}
+
+// TagSource returns an ad source pointing to an ad tag returning a document
+// of the given template type, such as TemplateVAST3.
+func TagSource(templateType, uri string) *AdSource {
+	return &AdSource{AdTagURI: &AdTagURI{TemplateType: templateType, URI: uri}}
+}
+
+// Builder builds a VMAP playlist by placing ad sources into breaks.
+type Builder struct {
+	breaks []*BreakBuilder
+}
+
+// NewBuilder returns an empty playlist builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// BreakBuilder sets the optional attributes of an ad break.
+type BreakBuilder struct {
+	brk AdBreak
+}
+
+// PreRoll adds a break playing the ads of src before the content.
+func (b *Builder) PreRoll(src *AdSource) *BreakBuilder {
+	return b.Break(OffsetStart, src)
+}
+
+// MidRoll adds a break playing the ads of src at the given time of the
+// content.
+func (b *Builder) MidRoll(at time.Duration, src *AdSource) *BreakBuilder {
+	return b.Break(At(at), src)
+}
+
+// PostRoll adds a break playing the ads of src after the content.
+func (b *Builder) PostRoll(src *AdSource) *BreakBuilder {
+	return b.Break(OffsetEnd, src)
+}
+
+// Break adds a linear break playing the ads of src at the given offset.
+func (b *Builder) Break(offset TimeOffset, src *AdSource) *BreakBuilder {
+	bb := &BreakBuilder{brk: AdBreak{TimeOffset: offset, BreakType: BreakLinear, AdSource: src}}
+	b.breaks = append(b.breaks, bb)
+	return bb
+}
+
+// ID sets the identifier of the break. Breaks without identifier get one
+// from their position when built, such as "preroll" or "midroll-2".
+func (bb *BreakBuilder) ID(id string) *BreakBuilder {
+	bb.brk.BreakID = id
+	return bb
+}
+
+// Type sets the types of ads allowed in the break, "linear" by default.
+func (bb *BreakBuilder) Type(types ...string) *BreakBuilder {
+	bb.brk.BreakType = strings.Join(types, ",")
+	return bb
+}
+
+// RepeatAfter makes the break repeat at the given interval.
+func (bb *BreakBuilder) RepeatAfter(d time.Duration) *BreakBuilder {
+	repeat := vast.Duration(d)
+	bb.brk.RepeatAfter = &repeat
+	return bb
+}
+
+// AllowMultipleAds sets whether the break may play several ads.
+func (bb *BreakBuilder) AllowMultipleAds(allow bool) *BreakBuilder {
+	if bb.brk.AdSource != nil {
+		bb.brk.AdSource.AllowMultipleAds = vast.NewBool(allow)
+	}
+	return bb
+}
+
+// FollowRedirects sets whether the player should follow VAST wrappers.
+func (bb *BreakBuilder) FollowRedirects(follow bool) *BreakBuilder {
+	if bb.brk.AdSource != nil {
+		bb.brk.AdSource.FollowRedirects = vast.NewBool(follow)
+	}
+	return bb
+}
+
+// Track adds a URI to ping on a break event, such as EventBreakStart.
+func (bb *BreakBuilder) Track(event, uri string) *BreakBuilder {
+	bb.brk.TrackingEvents = append(bb.brk.TrackingEvents, Tracking{Event: event, URI: uri})
+	return bb
+}
+
+// Build checks the breaks and returns the playlist, the pre-roll first and
+// the post-roll last, the other breaks in the order they were added.
+func (b *Builder) Build() (*VMAP, error) {
+	v := &VMAP{Version: Version}
+	for i, bb := range b.breaks {
+		brk := bb.brk
+		if err := brk.TimeOffset.Valid(); err != nil {
+			return nil, fmt.Errorf("vmap: break %d: %v", i, err)
+		}
+		if brk.BreakType == "" {
+			return nil, fmt.Errorf("vmap: break %d: missing break type", i)
+		}
+		if n := sourceCount(brk.AdSource); n != 1 {
+			return nil, fmt.Errorf("vmap: break %d: %d ad sources, expected 1", i, n)
+		}
+		src := *brk.AdSource
+		brk.AdSource = &src
+		v.AdBreaks = append(v.AdBreaks, brk)
+	}
+	sort.SliceStable(v.AdBreaks, func(i, j int) bool {
+		return offsetRank(v.AdBreaks[i].TimeOffset) < offsetRank(v.AdBreaks[j].TimeOffset)
+	})
+	mid := 0
+	for i := range v.AdBreaks {
+		brk := &v.AdBreaks[i]
+		switch brk.TimeOffset {
+		case OffsetStart:
+			setDefaultID(brk, "preroll")
+		case OffsetEnd:
+			setDefaultID(brk, "postroll")
+		default:
+			mid++
+			setDefaultID(brk, "midroll-"+strconv.Itoa(mid))
+		}
+	}
+	return v, nil
+}
+
+func setDefaultID(brk *AdBreak, id string) {
+	if brk.BreakID == "" {
+		brk.BreakID = id
+	}
+	if brk.AdSource.ID == "" {
+		brk.AdSource.ID = brk.BreakID + "-ad"
+	}
+}
+
+// offsetRank orders the pre-rolls first and the post-rolls last.
+func offsetRank(o TimeOffset) int {
+	switch o {
+	case OffsetStart:
+		return 0
+	case OffsetEnd:
+		return 2
+	}
+	return 1
+}
+
+// sourceCount returns how many kinds of ads the source holds.
+func sourceCount(src *AdSource) int {
+	if src == nil {
+		return 0
+	}
+	n := 0
+	if src.VASTAdData != nil && src.VASTAdData.VAST != nil {
+		n++
+	}
+	if src.AdTagURI != nil && src.AdTagURI.URI != "" {
+		n++
+	}
+	if src.CustomAdData != nil {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,77 @@
+package vmap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	doc, err := vast.Skeleton(vast.Version4_2, vast.AdKindInLineLinear)
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := NewBuilder()
+	b.PostRoll(TagSource(TemplateVAST3, "https://ads.example.com/post"))
+	b.MidRoll(10*time.Minute, VASTSource(doc)).RepeatAfter(10 * time.Minute).AllowMultipleAds(true)
+	b.PreRoll(TagSource(TemplateVAST4, "https://ads.example.com/pre")).
+		FollowRedirects(false).
+		Track(EventBreakStart, "https://t.example.com/start")
+	b.Break(Percent(0.5), TagSource(TemplateVAST3, "https://ads.example.com/mid")).ID("half").Type(BreakNonLinear, BreakDisplay)
+
+	v, err := b.Build()
+	if !assert.NoError(t, err) || !assert.Len(t, v.AdBreaks, 4) {
+		return
+	}
+	assert.Equal(t, Version, v.Version)
+	var ids, offsets []string
+	for _, brk := range v.AdBreaks {
+		ids = append(ids, brk.BreakID+"/"+brk.AdSource.ID)
+		offsets = append(offsets, string(brk.TimeOffset))
+	}
+	assert.Equal(t, []string{"preroll/preroll-ad", "midroll-1/midroll-1-ad", "half/half-ad", "postroll/postroll-ad"}, ids)
+	assert.Equal(t, []string{"start", "00:10:00", "50%", "end"}, offsets)
+	assert.Equal(t, vast.NewBool(false), v.AdBreaks[0].AdSource.FollowRedirects)
+	assert.Equal(t, []Tracking{{Event: EventBreakStart, URI: "https://t.example.com/start"}}, v.AdBreaks[0].TrackingEvents)
+	assert.Equal(t, vast.NewBool(true), v.AdBreaks[1].AdSource.AllowMultipleAds)
+	assert.Equal(t, doc, v.AdBreaks[1].AdSource.VASTAdData.VAST)
+	assert.Equal(t, "nonlinear,display", v.AdBreaks[2].BreakType)
+
+	out, err := v.Bytes()
+	if assert.NoError(t, err) {
+		again, err := Parse(out)
+		if assert.NoError(t, err) {
+			assert.Len(t, again.AdBreaks, 4)
+			assert.True(t, vast.Equal(doc, again.AdBreaks[1].AdSource.VASTAdData.VAST))
+		}
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	_, err := NewBuilder().Build()
+	assert.NoError(t, err)
+
+	b := NewBuilder()
+	b.Break("later", TagSource(TemplateVAST3, "https://ads.example.com"))
+	_, err = b.Build()
+	assert.EqualError(t, err, `vmap: break 0: invalid time offset "later"`)
+
+	b = NewBuilder()
+	b.PreRoll(nil)
+	_, err = b.Build()
+	assert.EqualError(t, err, "vmap: break 0: 0 ad sources, expected 1")
+
+	b = NewBuilder()
+	src := TagSource(TemplateVAST3, "https://ads.example.com")
+	src.CustomAdData = &CustomAdData{TemplateType: "custom"}
+	b.PreRoll(src)
+	_, err = b.Build()
+	assert.EqualError(t, err, "vmap: break 0: 2 ad sources, expected 1")
+
+	b = NewBuilder()
+	b.PreRoll(TagSource(TemplateVAST3, "https://ads.example.com")).Type()
+	_, err = b.Build()
+	assert.EqualError(t, err, "vmap: break 0: missing break type")
+}
@@ -0,0 +1,113 @@
+package vmap
+
+import (
+	"encoding/xml"
+
+	"github.com/haxqer/vast"
+)
+
+// The types below mirror the document model with prefixed element names, as
+// encoding/xml can't both match elements regardless of their namespace when
+// decoding and write namespace prefixes when encoding.
+
+type vmapXML struct {
+	XMLName    xml.Name       `xml:"vmap:VMAP"`
+	XMLNS      string         `xml:"xmlns:vmap,attr"`
+	Version    string         `xml:"version,attr"`
+	AdBreaks   []adBreakXML   `xml:"vmap:AdBreak,omitempty"`
+	Extensions *extensionsXML `xml:"vmap:Extensions,omitempty"`
+}
+
+type adBreakXML struct {
+	TimeOffset     TimeOffset         `xml:"timeOffset,attr"`
+	BreakType      string             `xml:"breakType,attr"`
+	BreakID        string             `xml:"breakId,attr,omitempty"`
+	RepeatAfter    *vast.Duration     `xml:"repeatAfter,attr,omitempty"`
+	AdSource       *adSourceXML       `xml:"vmap:AdSource,omitempty"`
+	TrackingEvents *trackingEventsXML `xml:"vmap:TrackingEvents,omitempty"`
+	Extensions     *extensionsXML     `xml:"vmap:Extensions,omitempty"`
+}
+
+type adSourceXML struct {
+	ID               string           `xml:"id,attr,omitempty"`
+	AllowMultipleAds *vast.Bool       `xml:"allowMultipleAds,attr,omitempty"`
+	FollowRedirects  *vast.Bool       `xml:"followRedirects,attr,omitempty"`
+	VASTAdData       *VASTAdData      `xml:"vmap:VASTAdData,omitempty"`
+	AdTagURI         *AdTagURI        `xml:"vmap:AdTagURI,omitempty"`
+	CustomAdData     *customAdDataXML `xml:"vmap:CustomAdData,omitempty"`
+}
+
+type customAdDataXML struct {
+	TemplateType string `xml:"templateType,attr"`
+	Data         string `xml:",innerxml"`
+}
+
+// Container elements are pointers so that they are omitted when empty.
+
+type trackingEventsXML struct {
+	Tracking []trackingXML `xml:"vmap:Tracking"`
+}
+
+type extensionsXML struct {
+	Extension []extensionXML `xml:"vmap:Extension"`
+}
+
+type trackingXML struct {
+	Event string `xml:"event,attr"`
+	URI   string `xml:",cdata"`
+}
+
+type extensionXML struct {
+	Type string `xml:"type,attr,omitempty"`
+	Data string `xml:",innerxml"`
+}
+
+// MarshalXML implements the xml.Marshaler interface, prefixing the VMAP
+// elements with "vmap" and declaring the namespace. The embedded VAST
+// documents are encoded without prefix.
+func (v VMAP) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	x := vmapXML{XMLNS: Namespace, Version: v.Version, Extensions: newExtensionsXML(v.Extensions)}
+	if x.Version == "" {
+		x.Version = Version
+	}
+	for _, b := range v.AdBreaks {
+		bx := adBreakXML{
+			TimeOffset:  b.TimeOffset,
+			BreakType:   b.BreakType,
+			BreakID:     b.BreakID,
+			RepeatAfter: b.RepeatAfter,
+			Extensions:  newExtensionsXML(b.Extensions),
+		}
+		if s := b.AdSource; s != nil {
+			bx.AdSource = &adSourceXML{
+				ID:               s.ID,
+				AllowMultipleAds: s.AllowMultipleAds,
+				FollowRedirects:  s.FollowRedirects,
+				VASTAdData:       s.VASTAdData,
+				AdTagURI:         s.AdTagURI,
+			}
+			if s.CustomAdData != nil {
+				bx.AdSource.CustomAdData = (*customAdDataXML)(s.CustomAdData)
+			}
+		}
+		if len(b.TrackingEvents) > 0 {
+			bx.TrackingEvents = &trackingEventsXML{}
+			for _, t := range b.TrackingEvents {
+				bx.TrackingEvents.Tracking = append(bx.TrackingEvents.Tracking, trackingXML(t))
+			}
+		}
+		x.AdBreaks = append(x.AdBreaks, bx)
+	}
+	return enc.Encode(x)
+}
+
+func newExtensionsXML(exts []Extension) *extensionsXML {
+	if len(exts) == 0 {
+		return nil
+	}
+	x := &extensionsXML{}
+	for _, e := range exts {
+		x.Extension = append(x.Extension, extensionXML(e))
+	}
+	return x
+}
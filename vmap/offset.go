@@ -0,0 +1,89 @@
+package vmap
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haxqer/vast"
+)
+
+// TimeOffset is the time offset of an ad break within the content: "start",
+// "end", a time such as "00:10:00.000", a percentage such as "50%", or a
+// position such as "#2".
+type TimeOffset string
+
+// Time offsets of pre-rolls and post-rolls
+const (
+	OffsetStart TimeOffset = "start"
+	OffsetEnd   TimeOffset = "end"
+)
+
+// At returns the time offset of a break playing d into the content.
+func At(d time.Duration) TimeOffset {
+	return TimeOffset(vast.Duration(d).String())
+}
+
+// Percent returns the time offset of a break playing after the given
+// fraction of the content, between 0 and 1.
+func Percent(fraction float64) TimeOffset {
+	return TimeOffset(strconv.FormatFloat(fraction*100, 'f', -1, 64) + "%")
+}
+
+// Position returns the time offset of the nth break opportunity of the
+// content, from 1.
+func Position(n int) TimeOffset {
+	return TimeOffset("#" + strconv.Itoa(n))
+}
+
+// Valid returns an error if the offset is not in one of the formats defined
+// by the spec.
+func (o TimeOffset) Valid() error {
+	s := string(o)
+	switch {
+	case o == OffsetStart || o == OffsetEnd:
+		return nil
+	case s == "":
+		return fmt.Errorf("missing time offset")
+	case strings.HasPrefix(s, "#"):
+		if n, err := strconv.Atoi(s[1:]); err != nil || n < 1 {
+			return fmt.Errorf("invalid position offset %q", s)
+		}
+	case strings.HasSuffix(s, "%"):
+		if p, err := strconv.ParseFloat(s[:len(s)-1], 64); err != nil || p < 0 || p > 100 {
+			return fmt.Errorf("invalid percent offset %q", s)
+		}
+	default:
+		var d vast.Duration
+		if err := d.UnmarshalText([]byte(s)); err != nil || d < 0 {
+			return fmt.Errorf("invalid time offset %q", s)
+		}
+	}
+	return nil
+}
+
+// Resolve returns the time of the offset within content of the given
+// duration, and false for position offsets or invalid ones.
+func (o TimeOffset) Resolve(content time.Duration) (time.Duration, bool) {
+	if o.Valid() != nil {
+		return 0, false
+	}
+	s := string(o)
+	switch {
+	case o == OffsetStart:
+		return 0, true
+	case o == OffsetEnd:
+		return content, true
+	case strings.HasPrefix(s, "#"):
+		return 0, false
+	case strings.HasSuffix(s, "%"):
+		p, _ := strconv.ParseFloat(s[:len(s)-1], 64)
+		ms := math.Round(p / 100 * float64(content/time.Millisecond))
+		return time.Duration(ms) * time.Millisecond, true
+	}
+	var d vast.Duration
+	d.UnmarshalText([]byte(s))
+	return time.Duration(d), true
+}
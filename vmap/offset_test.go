@@ -0,0 +1,39 @@
+package vmap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeOffset(t *testing.T) {
+	assert.Equal(t, TimeOffset("00:01:30.500"), At(90500*time.Millisecond))
+	assert.Equal(t, TimeOffset("25%"), Percent(0.25))
+	assert.Equal(t, TimeOffset("12.5%"), Percent(0.125))
+	assert.Equal(t, TimeOffset("#2"), Position(2))
+
+	content := 20 * time.Minute
+	for offset, want := range map[TimeOffset]time.Duration{
+		OffsetStart:    0,
+		OffsetEnd:      content,
+		"00:05:00":     5 * time.Minute,
+		"00:05:00.250": 5*time.Minute + 250*time.Millisecond,
+		"50%":          10 * time.Minute,
+	} {
+		assert.NoError(t, offset.Valid(), offset)
+		got, ok := offset.Resolve(content)
+		assert.True(t, ok, offset)
+		assert.Equal(t, want, got, offset)
+	}
+
+	assert.NoError(t, Position(1).Valid())
+	_, ok := Position(1).Resolve(content)
+	assert.False(t, ok)
+
+	for _, invalid := range []TimeOffset{"", "later", "#0", "#x", "120%", "-5%", "1:2"} {
+		assert.Error(t, invalid.Valid(), invalid)
+		_, ok := invalid.Resolve(content)
+		assert.False(t, ok, invalid)
+	}
+}
@@ -0,0 +1,148 @@
+// Package vmap implements the IAB VMAP 1.0.1 specification, describing the
+// ad breaks of a content video and the VAST ads to play in them.
+package vmap
+
+import (
+	"bytes"
+	"encoding/xml"
+
+	"github.com/haxqer/vast"
+)
+
+// Namespace is the XML namespace of the VMAP elements.
+const Namespace = "http://www.iab.net/videosuite/vmap"
+
+// Version is the version of the spec implemented by the package, as found in
+// the version attribute of a VMAP element.
+const Version = "1.0"
+
+// Break types, combined with commas in the breakType attribute of an AdBreak
+const (
+	BreakLinear    = "linear"
+	BreakNonLinear = "nonlinear"
+	BreakDisplay   = "display"
+)
+
+// Template types of AdTagURI and CustomAdData elements
+const (
+	TemplateVAST1 = "vast1"
+	TemplateVAST2 = "vast2"
+	TemplateVAST3 = "vast3"
+	TemplateVAST4 = "vast4"
+)
+
+// Events tracked by the Tracking elements of an AdBreak
+const (
+	EventBreakStart = "breakStart"
+	EventBreakEnd   = "breakEnd"
+	EventError      = "error"
+)
+
+// VMAP is the root <VMAP> element.
+type VMAP struct {
+	// The version of the VMAP spec, "1.0"
+	Version string `xml:"version,attr"`
+	// The ad breaks of the content, in any order
+	AdBreaks []AdBreak `xml:"AdBreak,omitempty" json:"AdBreak,omitempty"`
+	// Custom extensions of the document
+	Extensions []Extension `xml:"Extensions>Extension,omitempty" json:",omitempty"`
+}
+
+// AdBreak is a single ad break, possibly repeated, of the content.
+type AdBreak struct {
+	// When the break plays within the content
+	TimeOffset TimeOffset `xml:"timeOffset,attr"`
+	// The types of ads allowed in the break, comma separated, such as
+	// "linear" or "nonlinear,display"
+	BreakType string `xml:"breakType,attr"`
+	// An optional identifier of the break
+	BreakID string `xml:"breakId,attr,omitempty" json:",omitempty"`
+	// If set, the break repeats at this interval after its time offset
+	RepeatAfter *vast.Duration `xml:"repeatAfter,attr,omitempty" json:",omitempty"`
+	// The ads to play in the break
+	AdSource *AdSource `xml:",omitempty" json:",omitempty"`
+	// URIs to ping at the start or end of the break, or on error
+	TrackingEvents []Tracking `xml:"TrackingEvents>Tracking,omitempty" json:",omitempty"`
+	// Custom extensions of the break
+	Extensions []Extension `xml:"Extensions>Extension,omitempty" json:",omitempty"`
+}
+
+// AdSource holds the ads of a break, either inline as a VAST document, as the
+// URI of an ad tag returning one, or in a custom format. Only one of them
+// should be set.
+type AdSource struct {
+	// An optional identifier of the ad source
+	ID string `xml:"id,attr,omitempty" json:",omitempty"`
+	// Whether the break may play a pod of ads or several stand-alone ads
+	AllowMultipleAds *vast.Bool `xml:"allowMultipleAds,attr,omitempty" json:",omitempty"`
+	// Whether the player should follow VAST wrappers
+	FollowRedirects *vast.Bool `xml:"followRedirects,attr,omitempty" json:",omitempty"`
+	// A VAST document embedded in the VMAP document
+	VASTAdData *VASTAdData `xml:",omitempty" json:",omitempty"`
+	// The URI of an ad tag returning the ads
+	AdTagURI *AdTagURI `xml:",omitempty" json:",omitempty"`
+	// Ads in a format other than VAST
+	CustomAdData *CustomAdData `xml:",omitempty" json:",omitempty"`
+}
+
+// VASTAdData embeds a VAST document.
+type VASTAdData struct {
+	VAST *vast.VAST `xml:"VAST"`
+}
+
+// AdTagURI is the URI of an ad tag.
+type AdTagURI struct {
+	// The format of the ad tag response, such as "vast3"
+	TemplateType string `xml:"templateType,attr"`
+	URI          string `xml:",cdata"`
+}
+
+// CustomAdData holds ads in a format other than VAST.
+type CustomAdData struct {
+	// The format of the data
+	TemplateType string `xml:"templateType,attr"`
+	Data         string `xml:",innerxml" json:",omitempty"`
+}
+
+// Tracking is a URI to ping on a break event.
+type Tracking struct {
+	// The tracked event, "breakStart", "breakEnd" or "error"
+	Event string `xml:"event,attr"`
+	URI   string `xml:",cdata"`
+}
+
+// Extension holds arbitrary XML provided by the ad server.
+type Extension struct {
+	Type string `xml:"type,attr,omitempty" json:",omitempty"`
+	Data string `xml:",innerxml" json:",omitempty"`
+}
+
+// Parse decodes a VMAP document. Elements are matched by name, whether their
+// namespace is declared or not.
+func Parse(data []byte) (*VMAP, error) {
+	var v VMAP
+	if err := xml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Bytes encodes the document, with the "vmap" namespace prefix and an XML
+// declaration.
+func (v *VMAP) Bytes() ([]byte, error) {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// Decode decodes the inner XML of the extension into v, the fields of v
+// being matched against the children of the extension.
+func (e *Extension) Decode(v interface{}) error {
+	var buf bytes.Buffer
+	buf.WriteString("<Extension>")
+	buf.WriteString(e.Data)
+	buf.WriteString("</Extension>")
+	return xml.Unmarshal(buf.Bytes(), v)
+}
@@ -0,0 +1,114 @@
+package vmap
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/haxqer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadFixture(t *testing.T, path string) *VMAP {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestParse(t *testing.T) {
+	v := loadFixture(t, "testdata/vmap.xml")
+	assert.Equal(t, "1.0", v.Version)
+	if !assert.Len(t, v.AdBreaks, 3) {
+		return
+	}
+
+	pre := v.AdBreaks[0]
+	assert.Equal(t, OffsetStart, pre.TimeOffset)
+	assert.Equal(t, BreakLinear, pre.BreakType)
+	assert.Equal(t, "preroll", pre.BreakID)
+	if assert.NotNil(t, pre.AdSource) && assert.NotNil(t, pre.AdSource.AdTagURI) {
+		assert.Equal(t, "preroll-ad-1", pre.AdSource.ID)
+		assert.Equal(t, vast.NewBool(false), pre.AdSource.AllowMultipleAds)
+		assert.Equal(t, vast.NewBool(true), pre.AdSource.FollowRedirects)
+		assert.Equal(t, TemplateVAST3, pre.AdSource.AdTagURI.TemplateType)
+		assert.Equal(t, "https://ads.example.com/vast?pos=preroll&cb=[CACHEBUSTING]", pre.AdSource.AdTagURI.URI)
+	}
+	assert.Equal(t, []Tracking{
+		{Event: EventBreakStart, URI: "https://t.example.com/break?e=start"},
+		{Event: EventError, URI: "https://t.example.com/break?e=error&code=[ERRORCODE]"},
+	}, pre.TrackingEvents)
+
+	mid := v.AdBreaks[1]
+	assert.Equal(t, TimeOffset("00:10:00.000"), mid.TimeOffset)
+	assert.Equal(t, "linear,nonlinear", mid.BreakType)
+	if assert.NotNil(t, mid.RepeatAfter) {
+		assert.Equal(t, vast.Duration(10*time.Minute), *mid.RepeatAfter)
+	}
+	if assert.NotNil(t, mid.AdSource.VASTAdData) && assert.NotNil(t, mid.AdSource.VASTAdData.VAST) {
+		doc := mid.AdSource.VASTAdData.VAST
+		assert.Equal(t, "3.0", doc.Version)
+		if assert.Len(t, doc.Ads, 1) {
+			assert.Equal(t, "mid", doc.Ads[0].ID)
+			assert.Equal(t, []string{"https://t.example.com/imp"}, doc.Ads[0].ImpressionURLs())
+		}
+	}
+
+	post := v.AdBreaks[2]
+	assert.Equal(t, OffsetEnd, post.TimeOffset)
+	if assert.NotNil(t, post.AdSource.CustomAdData) {
+		assert.Equal(t, "custom", post.AdSource.CustomAdData.TemplateType)
+		assert.Equal(t, `<Ads count="1"/>`, post.AdSource.CustomAdData.Data)
+	}
+	if assert.Len(t, post.Extensions, 1) {
+		var p struct {
+			Priority int
+		}
+		assert.NoError(t, post.Extensions[0].Decode(&p))
+		assert.Equal(t, 1, p.Priority)
+	}
+}
+
+func TestParseWithoutNamespace(t *testing.T) {
+	v, err := Parse([]byte(`<VMAP version="1.0"><AdBreak timeOffset="50%" breakType="linear"><AdSource><AdTagURI templateType="vast4">https://t/vast</AdTagURI></AdSource></AdBreak></VMAP>`))
+	if assert.NoError(t, err) && assert.Len(t, v.AdBreaks, 1) {
+		assert.Equal(t, TimeOffset("50%"), v.AdBreaks[0].TimeOffset)
+		assert.Equal(t, "https://t/vast", v.AdBreaks[0].AdSource.AdTagURI.URI)
+	}
+	_, err = Parse([]byte(`<VMAP`))
+	assert.Error(t, err)
+}
+
+func TestEncode(t *testing.T) {
+	v := loadFixture(t, "testdata/vmap.xml")
+	b, err := v.Bytes()
+	if !assert.NoError(t, err) {
+		return
+	}
+	s := string(b)
+	assert.Contains(t, s, `<?xml version="1.0" encoding="UTF-8"?>`)
+	assert.Contains(t, s, `<vmap:VMAP xmlns:vmap="http://www.iab.net/videosuite/vmap" version="1.0">`)
+	assert.Contains(t, s, `<vmap:AdBreak timeOffset="start" breakType="linear" breakId="preroll">`)
+	assert.Contains(t, s, `<vmap:AdTagURI templateType="vast3"><![CDATA[https://ads.example.com/vast?pos=preroll&cb=[CACHEBUSTING]]]></vmap:AdTagURI>`)
+	assert.Contains(t, s, `<vmap:TrackingEvents><vmap:Tracking event="breakStart">`)
+	assert.Contains(t, s, `<vmap:VASTAdData><VAST version="3.0">`)
+	assert.Contains(t, s, `repeatAfter="00:10:00"`)
+	assert.Contains(t, s, `<vmap:CustomAdData templateType="custom"><Ads count="1"/></vmap:CustomAdData>`)
+	assert.Contains(t, s, `<vmap:Extension type="priority"><Priority>1</Priority></vmap:Extension>`)
+
+	again, err := Parse(b)
+	if assert.NoError(t, err) {
+		assert.Equal(t, v.AdBreaks[0], again.AdBreaks[0])
+		assert.Equal(t, v.AdBreaks[2], again.AdBreaks[2])
+		assert.True(t, vast.Equal(v.AdBreaks[1].AdSource.VASTAdData.VAST, again.AdBreaks[1].AdSource.VASTAdData.VAST))
+	}
+
+	b, err = (&VMAP{}).Bytes()
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `<vmap:VMAP xmlns:vmap="http://www.iab.net/videosuite/vmap" version="1.0"></vmap:VMAP>`)
+}
@@ -0,0 +1,242 @@
+package vast
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// VPAIDAction is what happened to a VPAID unit, or was added in its place,
+// in a VPAIDReport.
+type VPAIDAction int
+
+// Actions of a VPAIDReport
+const (
+	// The VPAID unit is left in place and must be migrated by hand
+	VPAIDFlagged VPAIDAction = iota
+	// The VPAID media file was removed, the creative having playable media
+	// files
+	VPAIDRemoved
+	// A SIMID interactive creative file was added to the creative
+	VPAIDAddedSIMID
+	// A verification was added to the ad
+	VPAIDAddedVerification
+)
+
+// String implements the fmt.Stringer interface.
+func (a VPAIDAction) String() string {
+	switch a {
+	case VPAIDFlagged:
+		return "flagged"
+	case VPAIDRemoved:
+		return "removed"
+	case VPAIDAddedSIMID:
+		return "added SIMID"
+	case VPAIDAddedVerification:
+		return "added verification"
+	}
+	return fmt.Sprintf("VPAIDAction(%d)", int(a))
+}
+
+// VPAIDFinding is an element of a VPAIDReport.
+type VPAIDFinding struct {
+	// Path of the element, as in ValidationError
+	Path   string
+	Action VPAIDAction
+	// What trafficking teams should know about the element
+	Note string
+}
+
+// String returns a description of the finding, such as
+// "VAST.Ad[0].InLine.Creative[0].Linear.MediaFile[1]: removed (...)".
+func (f VPAIDFinding) String() string {
+	return f.Path + ": " + f.Action.String() + " (" + f.Note + ")"
+}
+
+// VPAIDReport lists the VPAID units of a document, deprecated since VAST 4.1
+// in favor of SIMID for interactivity and of AdVerifications (OMID) for
+// measurement, and what was done about them.
+type VPAIDReport struct {
+	Findings []VPAIDFinding
+}
+
+// Migrated returns true if no VPAID unit is left to migrate by hand.
+func (r *VPAIDReport) Migrated() bool {
+	for _, f := range r.Findings {
+		if f.Action == VPAIDFlagged {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the findings, one per line.
+func (r *VPAIDReport) String() string {
+	lines := make([]string, len(r.Findings))
+	for i, f := range r.Findings {
+		lines[i] = f.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FindVPAID returns the report of the VPAID units of doc: the media files,
+// non-linears, companions and creatives declaring the VPAID API framework,
+// all flagged.
+func FindVPAID(doc *VAST) (*VPAIDReport, error) {
+	if doc == nil {
+		return nil, errors.New("vpaid: nil document")
+	}
+	m := &vpaidMigrator{report: &VPAIDReport{}}
+	m.vast(doc, false)
+	return m.report, nil
+}
+
+// VPAIDMigration migrates the VPAID media files of linear creatives. The
+// zero value removes the VPAID media files of the creatives having playable
+// ones, and flags the others.
+type VPAIDMigration struct {
+	// Returns the URI of the SIMID creative replacing a removed VPAID media
+	// file, if any, such as a version of the unit ported to SIMID. None is
+	// added if nil or empty.
+	SIMID func(m *MediaFile) string
+	// Returns the verification replacing the measurement done by a removed
+	// VPAID media file, if any. None is added if nil.
+	Verification func(m *MediaFile) *Verification
+}
+
+// Migrate returns a copy of doc without the VPAID media files of the linear
+// creatives having playable media files, replaced by the SIMID creative files
+// and verifications returned by the functions of mg, along with the report of
+// the changes. The VPAID units which can't be removed without leaving their
+// creative unplayable, as well as non-linears and companions, are flagged.
+//
+// SIMID creative files require VAST 4.0 and verifications VAST 4.1: they are
+// only added to documents of those versions, which older ones can be
+// converted to with Upgrade.
+func (mg VPAIDMigration) Migrate(doc *VAST) (*VAST, *VPAIDReport, error) {
+	if doc == nil {
+		return nil, nil, errors.New("vpaid: nil document")
+	}
+	v := doc.Clone()
+	m := &vpaidMigrator{migration: mg, version: v.EffectiveVersion(), report: &VPAIDReport{}}
+	m.vast(v, true)
+	v.InferredVersion = inferVersion(v)
+	return v, m.report, nil
+}
+
+type vpaidMigrator struct {
+	migration VPAIDMigration
+	version   SpecVersion
+	report    *VPAIDReport
+}
+
+func (m *vpaidMigrator) add(path string, action VPAIDAction, note string) {
+	m.report.Findings = append(m.report.Findings, VPAIDFinding{Path: path, Action: action, Note: note})
+}
+
+func (m *vpaidMigrator) vast(v *VAST, migrate bool) {
+	for i := range v.Ads {
+		in := v.Ads[i].InLine
+		if in == nil {
+			continue
+		}
+		path := index("VAST.Ad", i) + ".InLine"
+		for j := range in.Creatives {
+			c := &in.Creatives[j]
+			cpath := index(path+".Creative", j)
+			if c.Linear != nil {
+				m.linear(path, cpath+".Linear", in, c.Linear, migrate)
+			}
+			if IsAPIFramework(c.APIFramework, APIFrameworkVPAID) {
+				m.add(cpath, VPAIDFlagged, "creative declares the VPAID API framework")
+			}
+			if c.NonLinearAds != nil {
+				for k, nl := range c.NonLinearAds.NonLinears {
+					if IsAPIFramework(nl.APIFramework, APIFrameworkVPAID) {
+						m.add(index(cpath+".NonLinearAds.NonLinear", k), VPAIDFlagged, "VPAID non-linear, replace with a static, HTML or SIMID resource")
+					}
+				}
+			}
+			if c.CompanionAds != nil {
+				for k, comp := range c.CompanionAds.Companions {
+					if IsAPIFramework(comp.APIFramework, APIFrameworkVPAID) {
+						m.add(index(cpath+".CompanionAds.Companion", k), VPAIDFlagged, "VPAID companion, replace with a static, HTML or iframe resource")
+					}
+				}
+			}
+		}
+	}
+}
+
+func (m *vpaidMigrator) linear(adPath, path string, in *InLine, l *Linear, migrate bool) {
+	var kept []MediaFile
+	var vpaid []int
+	for i := range l.MediaFiles {
+		if l.MediaFiles[i].IsVPAID() {
+			vpaid = append(vpaid, i)
+		} else {
+			kept = append(kept, l.MediaFiles[i])
+		}
+	}
+	if len(vpaid) == 0 {
+		return
+	}
+	playable := 0
+	for i := range kept {
+		if !kept[i].IsInteractive() {
+			playable++
+		}
+	}
+	if !migrate || playable == 0 {
+		note := "VPAID media file deprecated by VAST 4.1, replace with a playable media file and SIMID or AdVerifications"
+		if migrate {
+			note = "no playable media file to fall back to, traffic one along with SIMID or AdVerifications"
+		}
+		for _, i := range vpaid {
+			m.add(index(path+".MediaFile", i), VPAIDFlagged, note)
+		}
+		return
+	}
+
+	for _, i := range vpaid {
+		m.add(index(path+".MediaFile", i), VPAIDRemoved, fmt.Sprintf("%d playable media files remain", playable))
+	}
+	for _, i := range vpaid {
+		mf := &l.MediaFiles[i]
+		if m.migration.SIMID == nil {
+			break
+		}
+		uri := strings.TrimSpace(m.migration.SIMID(mf))
+		if uri == "" {
+			continue
+		}
+		if !m.version.AtLeast(Version4_0) {
+			m.add(path, VPAIDFlagged, "SIMID creative "+uri+" requires VAST 4.0, upgrade the document")
+			continue
+		}
+		l.InteractiveCreativeFiles = append(l.InteractiveCreativeFiles, InteractiveCreativeFile{
+			Type: "text/html", APIFramework: APIFrameworkSIMID, URI: URI(uri),
+		})
+		m.add(index(path+".InteractiveCreativeFile", len(l.InteractiveCreativeFiles)-1), VPAIDAddedSIMID, "replaces the interactivity of "+string(mf.URI))
+	}
+	for _, i := range vpaid {
+		mf := &l.MediaFiles[i]
+		if m.migration.Verification == nil {
+			break
+		}
+		verification := m.migration.Verification(mf)
+		if verification == nil {
+			continue
+		}
+		if !m.version.AtLeast(Version4_1) {
+			m.add(path, VPAIDFlagged, "verification of "+verification.Vendor+" requires VAST 4.1, upgrade the document")
+			continue
+		}
+		if in.AdVerifications == nil {
+			in.AdVerifications = &[]Verification{}
+		}
+		*in.AdVerifications = append(*in.AdVerifications, *verification)
+		m.add(index(adPath+".AdVerifications.Verification", len(*in.AdVerifications)-1), VPAIDAddedVerification, "replaces the measurement of "+string(mf.URI))
+	}
+	l.MediaFiles = kept
+}
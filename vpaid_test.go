@@ -0,0 +1,107 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func vpaidDoc(version string) *VAST {
+	return &VAST{Version: version, Ads: []Ad{{InLine: &InLine{Creatives: []Creative{
+		{Linear: &Linear{MediaFiles: []MediaFile{
+			{Type: "application/javascript", APIFramework: "VPAID", URI: "https://v.example.com/unit.js"},
+			{Type: "video/mp4", Delivery: DeliveryProgressive, URI: "https://v.example.com/ad.mp4"},
+		}}},
+		{Linear: &Linear{MediaFiles: []MediaFile{
+			{Type: "application/javascript", APIFramework: "vpaid", URI: "https://v.example.com/only.js"},
+		}}},
+		{NonLinearAds: &NonLinearAds{NonLinears: []NonLinear{{APIFramework: "VPAID"}}}},
+	}}}}}
+}
+
+func TestFindVPAID(t *testing.T) {
+	report, err := FindVPAID(vpaidDoc("4.2"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, report.Migrated())
+	paths := []string{}
+	for _, f := range report.Findings {
+		assert.Equal(t, VPAIDFlagged, f.Action)
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{
+		"VAST.Ad[0].InLine.Creative[0].Linear.MediaFile[0]",
+		"VAST.Ad[0].InLine.Creative[1].Linear.MediaFile[0]",
+		"VAST.Ad[0].InLine.Creative[2].NonLinearAds.NonLinear[0]",
+	}, paths)
+
+	report, err = loadFixtureVPAID("testdata/spotx_vpaid.xml")
+	if assert.NoError(t, err) {
+		assert.NotEmpty(t, report.Findings)
+	}
+	report, err = FindVPAID(&VAST{})
+	if assert.NoError(t, err) {
+		assert.Empty(t, report.Findings)
+	}
+	_, err = FindVPAID(nil)
+	assert.EqualError(t, err, "vpaid: nil document")
+}
+
+func loadFixtureVPAID(path string) (*VPAIDReport, error) {
+	v, _, _, err := loadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return FindVPAID(v)
+}
+
+func TestVPAIDMigration(t *testing.T) {
+	doc := vpaidDoc("4.2")
+	mg := VPAIDMigration{
+		SIMID: func(m *MediaFile) string { return "https://v.example.com/simid.html" },
+		Verification: func(m *MediaFile) *Verification {
+			return &Verification{Vendor: "v.example.com-omid", JavaScriptResources: []JavaScriptResource{{APIFramework: "omid", URI: "https://v.example.com/omid.js"}}}
+		},
+	}
+	v, report, err := mg.Migrate(doc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `VAST.Ad[0].InLine.Creative[0].Linear.MediaFile[0]: removed (1 playable media files remain)
+VAST.Ad[0].InLine.Creative[0].Linear.InteractiveCreativeFile[0]: added SIMID (replaces the interactivity of https://v.example.com/unit.js)
+VAST.Ad[0].InLine.AdVerifications.Verification[0]: added verification (replaces the measurement of https://v.example.com/unit.js)
+VAST.Ad[0].InLine.Creative[1].Linear.MediaFile[0]: flagged (no playable media file to fall back to, traffic one along with SIMID or AdVerifications)
+VAST.Ad[0].InLine.Creative[2].NonLinearAds.NonLinear[0]: flagged (VPAID non-linear, replace with a static, HTML or SIMID resource)`, report.String())
+	assert.False(t, report.Migrated())
+
+	l := v.Ads[0].InLine.Creatives[0].Linear
+	assert.Equal(t, []MediaFile{{Type: "video/mp4", Delivery: DeliveryProgressive, URI: "https://v.example.com/ad.mp4"}}, l.MediaFiles)
+	assert.Equal(t, []InteractiveCreativeFile{{Type: "text/html", APIFramework: "SIMID", URI: "https://v.example.com/simid.html"}}, l.InteractiveCreativeFiles)
+	assert.Len(t, *v.Ads[0].InLine.AdVerifications, 1)
+	assert.Len(t, v.Ads[0].InLine.Creatives[1].Linear.MediaFiles, 1)
+	assert.Len(t, doc.Ads[0].InLine.Creatives[0].Linear.MediaFiles, 2, "doc is left untouched")
+
+	// placeholders need a recent enough version
+	v, report, err = mg.Migrate(vpaidDoc("3.0"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, v.Ads[0].InLine.Creatives[0].Linear.InteractiveCreativeFiles)
+	assert.Nil(t, v.Ads[0].InLine.AdVerifications)
+	assert.Equal(t, VPAIDFlagged, report.Findings[1].Action)
+	assert.Equal(t, "SIMID creative https://v.example.com/simid.html requires VAST 4.0, upgrade the document", report.Findings[1].Note)
+
+	// the zero value only removes the VPAID media files
+	v, _, err = VPAIDMigration{}.Migrate(&VAST{Version: "4.2", Ads: vpaidDoc("4.2").Ads[:1]})
+	if !assert.NoError(t, err) {
+		return
+	}
+	v.Ads[0].InLine.Creatives = v.Ads[0].InLine.Creatives[:1]
+	assert.Len(t, v.Ads[0].InLine.Creatives[0].Linear.MediaFiles, 1)
+	assert.Equal(t, "removed", VPAIDRemoved.String())
+	assert.Equal(t, "VPAIDAction(9)", VPAIDAction(9).String())
+
+	_, _, err = mg.Migrate(nil)
+	assert.EqualError(t, err, "vpaid: nil document")
+}
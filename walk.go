@@ -0,0 +1,135 @@
+package vast
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// SkipChildren is returned by a Visitor callback to skip the elements nested
+// in the visited one. It is not returned by Walk.
+var SkipChildren = errors.New("skip children")
+
+// Visitor holds the callbacks invoked by Walk, which may be nil. Each one is
+// given the path of the element from the root of the document, using XML
+// element names, container elements being omitted, such as
+// "VAST.Ad[0].InLine.Creative[1].Linear.MediaFile[0]", and a pointer to the
+// element so that it can be modified in place.
+//
+// A callback returning SkipChildren skips the elements nested in the visited
+// one, while any other non-nil error stops the walk.
+type Visitor struct {
+	Ad              func(path string, ad *Ad) error
+	Creative        func(path string, c *Creative) error
+	CreativeWrapper func(path string, c *CreativeWrapper) error
+	MediaFile       func(path string, m *MediaFile) error
+	Tracking        func(path string, t *Tracking) error
+	// URI is called for every URI of the document, held by the URI fields
+	// of its elements. The path is the one of the element holding the URI.
+	URI func(path string, uri *string) error
+}
+
+// Walk traverses v depth-first in document order, calling the callbacks of
+// visitor for the elements they are defined for. It returns the first error
+// returned by a callback, other than SkipChildren.
+func Walk(v *VAST, visitor Visitor) error {
+	if v == nil {
+		return nil
+	}
+	return (&walker{visitor}).walk("VAST", reflect.ValueOf(v).Elem())
+}
+
+var (
+	uriType       = reflect.TypeOf(URI(""))
+	stringPtrType = reflect.TypeOf((*string)(nil))
+)
+
+type walker struct {
+	visitor Visitor
+}
+
+// walk visits value, the addressable element at path.
+func (w *walker) walk(path string, value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		return w.walk(path, value.Elem())
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := w.walk(path+"["+strconv.Itoa(i)+"]", value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.String:
+		if value.Type() == uriType && w.visitor.URI != nil {
+			return skipped(w.visitor.URI(path, value.Addr().Convert(stringPtrType).Interface().(*string)))
+		}
+		return nil
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	if err := w.visit(path, value.Addr().Interface()); err != nil {
+		if err == SkipChildren {
+			return nil
+		}
+		return err
+	}
+	for i, tag := range xmlFields(value.Type()) {
+		switch {
+		case !tag.ok, tag.Attr, tag.InnerXML, tag.Any, tag.Comment:
+			continue
+		case tag.CharData:
+			if err := w.walk(path, value.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.walk(path+"."+tag.Name(), value.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// visit calls the callback defined for the type of element, if any.
+func (w *walker) visit(path string, element interface{}) error {
+	switch e := element.(type) {
+	case *Ad:
+		if w.visitor.Ad != nil {
+			return w.visitor.Ad(path, e)
+		}
+	case *Creative:
+		if w.visitor.Creative != nil {
+			return w.visitor.Creative(path, e)
+		}
+	case *CreativeWrapper:
+		if w.visitor.CreativeWrapper != nil {
+			return w.visitor.CreativeWrapper(path, e)
+		}
+	case *MediaFile:
+		if w.visitor.MediaFile != nil {
+			return w.visitor.MediaFile(path, e)
+		}
+	case *Tracking:
+		if w.visitor.Tracking != nil {
+			return w.visitor.Tracking(path, e)
+		}
+	}
+	return nil
+}
+
+// skipped returns err, or nil if it is SkipChildren.
+func skipped(err error) error {
+	if err == SkipChildren {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package vast
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast_wrapper_linear_1.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var ads, creatives, trackings []string
+	var uris []string
+	err = Walk(v, Visitor{
+		Ad:              func(path string, ad *Ad) error { ads = append(ads, path); return nil },
+		CreativeWrapper: func(path string, c *CreativeWrapper) error { creatives = append(creatives, path); return nil },
+		Tracking:        func(path string, tr *Tracking) error { trackings = append(trackings, path+" "+tr.Event); return nil },
+		URI: func(path string, uri *string) error {
+			uris = append(uris, path)
+			*uri = strings.Replace(*uri, "http://", "https://", 1)
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"VAST.Ad[0]"}, ads)
+	assert.NotEmpty(t, creatives)
+	assert.Equal(t, "VAST.Ad[0].Wrapper.Creative[0]", creatives[0])
+	assert.Contains(t, trackings, "VAST.Ad[0].Wrapper.Creative[0].Linear.Tracking[0] "+v.Ads[0].Wrapper.Creatives[0].Linear.TrackingEvents[0].Event)
+	assert.Contains(t, uris, "VAST.Ad[0].Wrapper.VASTAdTagURI")
+	assert.Contains(t, uris, "VAST.Ad[0].Wrapper.Impression[0]")
+	assert.Contains(t, uris, "VAST.Ad[0].Wrapper.Error[0]")
+	for _, u := range v.Ads[0].Wrapper.Impressions {
+		assert.False(t, strings.HasPrefix(string(u.URI), "http://"), u.URI)
+	}
+	assert.False(t, strings.HasPrefix(string(v.Ads[0].Wrapper.VASTAdTagURI.URI), "http://"))
+	assert.NotContains(t, uris, "VAST.Ad[0].Wrapper.AdSystem")
+}
+
+func TestWalkSkipAndStop(t *testing.T) {
+	v := &VAST{Ads: []Ad{
+		{InLine: &InLine{Impressions: []Impression{{URI: "http://t/imp"}}, Creatives: []Creative{
+			{Linear: &Linear{MediaFiles: []MediaFile{{URI: "http://t/a.mp4"}}}},
+		}}},
+		{InLine: &InLine{Impressions: []Impression{{URI: "http://t/imp2"}}}},
+	}}
+	var uris []string
+	err := Walk(v, Visitor{
+		Creative: func(string, *Creative) error { return SkipChildren },
+		URI:      func(_ string, uri *string) error { uris = append(uris, *uri); return nil },
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://t/imp", "http://t/imp2"}, uris)
+
+	stop := errors.New("stop")
+	var media []string
+	err = Walk(v, Visitor{
+		MediaFile: func(path string, m *MediaFile) error { media = append(media, path); return nil },
+		Ad: func(path string, ad *Ad) error {
+			if path == "VAST.Ad[1]" {
+				return stop
+			}
+			return nil
+		},
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []string{"VAST.Ad[0].InLine.Creative[0].Linear.MediaFile[0]"}, media)
+	assert.NoError(t, Walk(nil, Visitor{}))
+}
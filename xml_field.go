@@ -0,0 +1,91 @@
+package vast
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// XMLField is the xml struct tag of a field of an element type, as
+// interpreted by encoding/xml. Walk, Diff, Downgrade and the macro package
+// rely on it to name the fields of a document.
+type XMLField struct {
+	// Names of the nested elements holding the field, such as
+	// ["Creatives", "Creative"] for "Creatives>Creative", or the name of the
+	// attribute. It is the name of the field when the tag has none.
+	Names []string
+	// Attr is true for an attribute
+	Attr bool
+	// CharData is true for the character data of the element, with the
+	// ",chardata" or ",cdata" option
+	CharData bool
+	InnerXML bool
+	Any      bool
+	Comment  bool
+}
+
+// Name returns the name of the element, or attribute, holding the field:
+// the last of Names.
+func (x XMLField) Name() string {
+	return x.Names[len(x.Names)-1]
+}
+
+// ParseXMLField returns the xml tag of f, and false for the fields which are
+// not part of a document: unexported fields, XMLName and those tagged "-".
+func ParseXMLField(f reflect.StructField) (XMLField, bool) {
+	tag := f.Tag.Get("xml")
+	if f.PkgPath != "" || tag == "-" || f.Name == "XMLName" {
+		return XMLField{}, false
+	}
+	name, opts := tag, ""
+	if j := strings.IndexByte(tag, ','); j >= 0 {
+		name, opts = tag[:j], tag[j+1:]
+	}
+	if name == "" {
+		name = f.Name
+	}
+	x := XMLField{Names: strings.Split(name, ">")}
+	for _, opt := range strings.Split(opts, ",") {
+		switch opt {
+		case "attr":
+			x.Attr = true
+		case "chardata", "cdata":
+			x.CharData = true
+		case "innerxml":
+			x.InnerXML = true
+		case "any":
+			x.Any = true
+		case "comment":
+			x.Comment = true
+		}
+	}
+	return x, true
+}
+
+// xmlField is the xml tag of a field, as returned by ParseXMLField, along
+// with the name of the field.
+type xmlField struct {
+	XMLField
+	field string
+	ok    bool
+}
+
+// parsedFields caches the xml tags of the fields of the element types, keyed
+// by type.
+var parsedFields sync.Map
+
+// xmlFields returns the xml tags of the fields of the struct type t, in
+// field order, parsing them once per type.
+func xmlFields(t reflect.Type) []xmlField {
+	if fields, ok := parsedFields.Load(t); ok {
+		return fields.([]xmlField)
+	}
+	fields := make([]xmlField, t.NumField())
+	for i := range fields {
+		f := t.Field(i)
+		fields[i].XMLField, fields[i].ok = ParseXMLField(f)
+		fields[i].field = f.Name
+	}
+	parsedFields.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,33 @@
+package vast
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseXMLField(t *testing.T) {
+	field := func(typ interface{}, name string) reflect.StructField {
+		f, _ := reflect.TypeOf(typ).FieldByName(name)
+		return f
+	}
+	tests := []struct {
+		field reflect.StructField
+		want  XMLField
+		ok    bool
+	}{
+		{field(InLine{}, "Creatives"), XMLField{Names: []string{"Creatives", "Creative"}}, true},
+		{field(InLine{}, "AdServingId"), XMLField{Names: []string{"AdServingId"}}, true},
+		{field(Ad{}, "Sequence"), XMLField{Names: []string{"sequence"}, Attr: true}, true},
+		{field(AdSystem{}, "Name"), XMLField{Names: []string{"Name"}, CharData: true}, true},
+		{field(Icons{}, "XMLName"), XMLField{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseXMLField(tt.field)
+		assert.Equal(t, tt.ok, ok, tt.field.Name)
+		assert.Equal(t, tt.want, got, tt.field.Name)
+	}
+	x, _ := ParseXMLField(field(Icon{}, "IconClickFallbackImages"))
+	assert.Equal(t, "IconClickFallbackImage", x.Name())
+}